@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pixielabs/1lm/audit"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+)
+
+// writeAudit records the query, options and selection when audit_log is
+// set. It runs before the command is output, so a failure to record stops
+// the command from being used unlogged.
+func writeAudit(cfg *config.Config, query string, options []commands.Option, selected *commands.Option) error {
+	if cfg.AuditLog == "" {
+		return nil
+	}
+
+	path, err := config.ExpandPath(cfg.AuditLog)
+	if err != nil {
+		return fmt.Errorf("failed to expand audit_log path: %w", err)
+	}
+
+	entry := audit.NewEntry(query, options, selected)
+	entry.DryRun = *dryRun
+
+	log := audit.New(path, cfg.AuditLogMaxSize<<20, cfg.AuditLogKeep)
+	if err := log.Write(entry); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return nil
+}
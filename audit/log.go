@@ -0,0 +1,173 @@
+// Package audit writes an append-only JSON Lines record of queries and the
+// commands chosen for them, for teams that need to review what was run.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+const (
+	// DefaultMaxSize is the size in bytes at which the log is rotated.
+	DefaultMaxSize = 10 << 20
+	// DefaultKeep is how many rotated logs are kept.
+	DefaultKeep = 5
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Query   string    `json:"query"`
+	Options []Option  `json:"options"`
+	// Selected is the chosen command, or null when the user quit.
+	Selected *string `json:"selected"`
+	Risk     *Risk   `json:"risk,omitempty"`
+	DryRun   bool    `json:"dry_run,omitempty"`
+}
+
+// Option is an offered command and its risk, if one was detected before
+// the user chose.
+type Option struct {
+	Title   string `json:"title"`
+	Command string `json:"command"`
+	Risk    *Risk  `json:"risk,omitempty"`
+}
+
+// Risk is a safety verdict.
+type Risk struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Log appends entries to a file, rotating it when it grows too large.
+type Log struct {
+	path    string
+	maxSize int64
+	keep    int
+}
+
+// Public: Creates a Log at path.
+//
+// path    - File to append to; rotated copies are path.1, path.2, …
+// maxSize - Size in bytes at which to rotate; 0 for DefaultMaxSize
+// keep    - Rotated copies to keep; 0 for DefaultKeep
+//
+// Returns the Log.
+func New(path string, maxSize int64, keep int) *Log {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	return &Log{path: path, maxSize: maxSize, keep: keep}
+}
+
+// Public: Builds the entry for a query, the options offered and the one
+// selected (nil if none), stamped with the current time and user.
+func NewEntry(query string, options []commands.Option, selected *commands.Option) Entry {
+	entry := Entry{
+		Time:    time.Now().UTC(),
+		User:    currentUser(),
+		Query:   query,
+		Options: make([]Option, len(options)),
+	}
+
+	for i, opt := range options {
+		entry.Options[i] = Option{Title: opt.Title, Command: opt.Command, Risk: riskOf(opt)}
+	}
+
+	if selected != nil {
+		entry.Selected = &selected.Command
+		entry.Risk = riskOf(*selected)
+	}
+
+	return entry
+}
+
+// Public: Appends entry as one line, rotating the file first if it has
+// reached the maximum size.
+func (l *Log) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	if err := l.rotate(); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	// A single write keeps lines from concurrent runs from interleaving.
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts path to path.1, path.1 to path.2 and so on once path
+// reaches the maximum size, dropping the oldest beyond keep.
+func (l *Log) rotate() error {
+	info, err := os.Stat(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() < l.maxSize {
+		return nil
+	}
+
+	if err := os.Remove(l.rotated(l.keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for n := l.keep - 1; n >= 1; n-- {
+		if err := os.Rename(l.rotated(n), l.rotated(n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(l.path, l.rotated(1))
+}
+
+func (l *Log) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+func riskOf(opt commands.Option) *Risk {
+	if opt.Risk == nil {
+		return nil
+	}
+	return &Risk{
+		Level:   strings.ToLower(opt.Risk.Level.String()),
+		Message: opt.Risk.Message,
+	}
+}
+
+// currentUser names the user running 1lm, falling back to $USER when the
+// account can't be looked up (e.g. in minimal containers).
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
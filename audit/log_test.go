@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestNewEntry(t *testing.T) {
+	options := []commands.Option{
+		{Title: "List", Command: "ls"},
+		{Title: "Remove", Command: "rm -rf build", Risk: &safety.RiskInfo{Level: safety.RiskHigh, Message: "Deletes files"}},
+	}
+
+	entry := NewEntry("clean up", options, &options[1])
+
+	if entry.Query != "clean up" || len(entry.Options) != 2 {
+		t.Fatalf("entry = %+v, want the query and both options", entry)
+	}
+	if entry.Options[0].Risk != nil {
+		t.Errorf("option without risk recorded %+v", entry.Options[0].Risk)
+	}
+	if entry.Selected == nil || *entry.Selected != "rm -rf build" {
+		t.Errorf("Selected = %v, want rm -rf build", entry.Selected)
+	}
+	if entry.Risk == nil || entry.Risk.Level != "high" {
+		t.Errorf("Risk = %+v, want high", entry.Risk)
+	}
+	if entry.Time.IsZero() {
+		t.Error("Time not set")
+	}
+
+	if none := NewEntry("clean up", options, nil); none.Selected != nil || none.Risk != nil {
+		t.Errorf("entry with no selection = %+v, want nil Selected and Risk", none)
+	}
+}
+
+func TestWriteAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	log := New(path, 0, 0)
+
+	for _, query := range []string{"first", "second"} {
+		if err := log.Write(NewEntry(query, nil, nil)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 2 || entries[0].Query != "first" || entries[1].Query != "second" {
+		t.Errorf("entries = %+v, want first then second", entries)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
+
+func TestWriteRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// Every entry exceeds the maximum, so each write rotates the last.
+	log := New(path, 1, 2)
+
+	for _, query := range []string{"one", "two", "three", "four"} {
+		if err := log.Write(NewEntry(query, nil, nil)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	for file, want := range map[string]string{path: "four", path + ".1": "three", path + ".2": "two"} {
+		entries := readEntries(t, file)
+		if len(entries) != 1 || entries[0].Query != want {
+			t.Errorf("%s = %+v, want only %q", filepath.Base(file), entries, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should have been dropped", filepath.Base(path))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/policy"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestRelease(t *testing.T) {
+	maxLow, err := policy.Parse([]byte("max_risk = \"low\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := commands.Option{Title: "List", Command: "ls", Risk: &safety.RiskInfo{Level: safety.RiskNone}}
+	unchecked := commands.Option{Title: "List", Command: "ls"}
+
+	tests := []struct {
+		name        string
+		policy      *policy.Policy
+		brokenLog   bool
+		options     []commands.Option
+		selected    *commands.Option
+		wantErr     bool
+		wantLog     bool
+		wantDeliver bool
+	}{
+		{name: "selected", options: []commands.Option{checked}, selected: &checked, wantLog: true, wantDeliver: true},
+		{name: "refused by policy", policy: maxLow, options: []commands.Option{unchecked}, selected: &unchecked, wantErr: true},
+		{name: "audit log fails", brokenLog: true, options: []commands.Option{checked}, selected: &checked, wantErr: true},
+		{name: "every option output", policy: maxLow, options: []commands.Option{checked}, wantLog: true, wantDeliver: true},
+		{name: "every option output, one refused", policy: maxLow, options: []commands.Option{checked, unchecked}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(saved *policy.Policy) { activePolicy = saved }(activePolicy)
+			activePolicy = tt.policy
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "audit.jsonl")
+			if tt.brokenLog {
+				// A file where the log's directory should be.
+				if err := os.WriteFile(filepath.Join(dir, "log"), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				path = filepath.Join(dir, "log", "audit.jsonl")
+			}
+			cfg := &config.Config{AuditLog: path}
+
+			delivered := false
+			err := release(cfg, "list files", tt.options, tt.selected, func() error {
+				if _, err := os.Stat(path); err != nil {
+					return errors.New("delivered before the audit entry was written")
+				}
+				delivered = true
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("release() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if delivered != tt.wantDeliver {
+				t.Errorf("delivered = %v, want %v", delivered, tt.wantDeliver)
+			}
+			if _, err := os.Stat(path); (err == nil) != tt.wantLog {
+				t.Errorf("audit log written = %v, want %v", err == nil, tt.wantLog)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/output"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// fileArg matches a single argument naming a file that exists, so "1lm
+// batch rename" is still a query.
+func fileArg(args []string) bool {
+	if len(args) != 1 {
+		return false
+	}
+	info, err := os.Stat(args[0])
+	return err == nil && !info.IsDir()
+}
+
+// runBatch generates options for every query in a file, one per line, and
+// writes them all without the interactive selector. Blank lines and lines
+// starting with # are skipped.
+func runBatch(cfg *config.Config, settings ui.Settings, args []string) error {
+	queries, err := readQueries(args[0])
+	if err != nil {
+		return err
+	}
+
+	generator, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+	limitRequestSize(cfg, generator)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results := generator.GenerateBatch(ctx, queries, batchOptions())
+
+	// Each query's options are released before any results are written,
+	// leaving out those the policy refuses.
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		results[i].Options = allowedOptions(results[i].Options)
+		if err := release(cfg, results[i].Query, results[i].Options, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	decoration := output.DecorationFull
+	if settings.Plain {
+		decoration = output.DecorationPlain
+	}
+	handler := output.NewHandler(output.Mode(*outputMode), decoration)
+	handler.SetLanguage(settings.Language)
+	if err := handler.WriteBatch(results); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d queries failed", failed, len(results))
+	}
+	return nil
+}
+
+// batchOptions returns the concurrency and rate limit set by --jobs and
+// --rate.
+func batchOptions() commands.BatchOptions {
+	opts := commands.BatchOptions{Jobs: *batchJobs}
+	if *batchRate > 0 {
+		opts.Interval = time.Minute / time.Duration(*batchRate)
+	}
+	return opts
+}
+
+// readQueries reads the non-blank, non-comment lines of a batch file.
+func readQueries(path string) (queries []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close %s: %w", path, closeErr))
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return queries, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/i18n"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// runBuild strings queries together into a script in the builder: each
+// selected command becomes a step. On ctrl+s the script is written to the
+// --file, or printed.
+func runBuild(cfg *config.Config, settings ui.Settings, _ []string) error {
+	generator, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+	past := loadHistory(cfg)
+
+	finalModel, err := runUI(ui.NewBuilder(generator, past, settings), settings)
+	if err != nil {
+		return err
+	}
+	builder, ok := finalModel.(ui.BuilderModel)
+	if !ok {
+		return nil
+	}
+
+	steps := builder.Steps()
+	if !builder.Done() {
+		// Nothing is output, but the steps chosen are still recorded.
+		for _, step := range steps {
+			if err := writeAudit(cfg, step.Query, step.Options, &step.Option); err != nil {
+				return err
+			}
+			recordHistory(past, step.Query, step.Options, &step.Option, generator)
+		}
+		if len(steps) > 0 && !settings.Quiet {
+			fmt.Fprintln(os.Stderr, i18n.T(settings.Language, "Script not saved"))
+		}
+		return nil
+	}
+
+	// Every step is released before any of the script is output.
+	for _, step := range steps {
+		if err := release(cfg, step.Query, step.Options, &step.Option, nil); err != nil {
+			return err
+		}
+		recordHistory(past, step.Query, step.Options, &step.Option, generator)
+	}
+
+	sh, err := selectedShell()
+	if err != nil {
+		return err
+	}
+
+	// The script is printed rather than copied, as with --script.
+	*script = true
+	return emit("", &commands.Option{Title: "Script", Command: scriptPreamble(sh) + builder.Script()}, settings)
+}
+
+// scriptPreamble returns the line that makes a script stop at the first
+// failing command in shells that have one, followed by a blank line.
+func scriptPreamble(sh commands.Shell) string {
+	switch sh {
+	case commands.ShellSh:
+		return "set -eu\n\n"
+	case commands.ShellBash, commands.ShellZsh, "":
+		return "set -euo pipefail\n\n"
+	default:
+		return ""
+	}
+}
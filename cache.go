@@ -0,0 +1,85 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// runCache runs "1lm cache stats|clear|gc", which reports on and cleans
+// up the responses saved for --offline.
+func runCache(cfg *config.Config, _ ui.Settings, args []string) error {
+	cache, err := responseCache(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "clear":
+		removed, err := cache.Clear()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cached responses.\n", removed)
+
+	case "gc":
+		removed, err := cache.GC()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d expired or excess cached responses.\n", removed)
+
+	default:
+		entries, err := cache.List()
+		if err != nil {
+			return err
+		}
+		printCacheStats(cfg, cache, entries)
+	}
+	return nil
+}
+
+// printCacheStats summarizes the cache: its size against the limit, the
+// dates it covers and the models that answered.
+func printCacheStats(cfg *config.Config, cache *llm.ResponseCache, entries []llm.StoredEntry) {
+	fmt.Printf("Location: %s\n", cache.Dir())
+	if len(entries) == 0 {
+		fmt.Println("No cached responses yet.")
+		return
+	}
+
+	var size int64
+	models := map[string]int{}
+	for _, e := range entries {
+		size += e.Size
+		model := e.Model
+		if model == "" {
+			model = "unknown"
+		}
+		models[model]++
+	}
+
+	limit := "no size limit"
+	if megabytes := cmp.Or(cfg.CacheMaxSize, defaultCacheMaxSize); megabytes > 0 {
+		limit = fmt.Sprintf("of %d MB", megabytes)
+	}
+	fmt.Printf("Responses: %d, %.1f MB %s\n", len(entries), float64(size)/(1<<20), limit)
+
+	const day = "2006-01-02"
+	fmt.Printf("Cached: %s to %s", entries[0].Created.Local().Format(day), entries[len(entries)-1].Created.Local().Format(day))
+	if days := cmp.Or(cfg.CacheTTL, defaultCacheTTL); days > 0 {
+		fmt.Printf(", each kept for %d days\n", days)
+	} else {
+		fmt.Println(", kept until cleared")
+	}
+
+	fmt.Println("\nBy model:")
+	for _, model := range slices.Sorted(maps.Keys(models)) {
+		fmt.Printf("  %4d  %s\n", models[model], model)
+	}
+}
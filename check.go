@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/output"
+	"github.com/pixielabs/1lm/safety"
+	"github.com/pixielabs/1lm/ui"
+	"golang.org/x/term"
+)
+
+// exitRisky is the exit status of "1lm check" for a high- or critical-risk
+// command, or one the policy forbids, so scripts can tell it from a failed
+// check (status 1).
+const exitRisky = 2
+
+// checkArgs matches "check <command>" with the command as one quoted
+// argument, "check -", or a bare "check" with the command piped in. A
+// single unquoted word only counts when it is a program on the PATH, so
+// "1lm check disk space" and "1lm check ports" stay queries.
+func checkArgs(args []string) bool {
+	switch len(args) {
+	case 0:
+		return !term.IsTerminal(int(os.Stdin.Fd()))
+	case 1:
+		if args[0] == "-" || strings.ContainsAny(args[0], " \t\n") {
+			return true
+		}
+		_, err := exec.LookPath(args[0])
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// runCheck evaluates a command the user already has, rather than one 1lm
+// generated, and reports its risk. The command comes from the argument or,
+// for a script, stdin.
+func runCheck(cfg *config.Config, settings ui.Settings, args []string) error {
+	command := ""
+	if len(args) == 1 && args[0] != "-" {
+		command = args[0]
+	} else {
+		input, err := readStdin()
+		if err != nil {
+			return err
+		}
+		command = input
+	}
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return fmt.Errorf("no command to check")
+	}
+
+	generator := commands.NewGeneratorWithEvaluator(nil, checkEvaluator(cfg))
+	generator.SetPolicy(activePolicy)
+	generator.SetKeepSecrets(cfg.RedactSecrets == "off")
+
+	opt := commands.Option{Command: command, Blocked: activePolicy.Check(command)}
+	options, err := generator.EvaluateSafety(context.Background(), []commands.Option{opt})
+	if err != nil {
+		return fmt.Errorf("failed to check command: %w", err)
+	}
+	checked := options[0]
+	if checked.Unverified {
+		return fmt.Errorf("failed to check command: the safety check gave no verdict")
+	}
+
+	mode := output.Mode(*outputMode)
+	if *checkJSON {
+		mode = output.ModeJSON
+	}
+	decoration := output.DecorationFull
+	if settings.Plain {
+		decoration = output.DecorationPlain
+	}
+	handler := output.NewHandler(mode, decoration)
+	handler.SetLanguage(settings.Language)
+	if err := handler.WriteCheck(checked); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+
+	if checked.Blocked != "" || (checked.Risk != nil && checked.Risk.Level >= safety.RiskHigh) {
+		return exitStatus(exitRisky)
+	}
+	return nil
+}
+
+// checkEvaluator is the configured safety evaluator, or the local rules
+// with --offline or when there is no API key to evaluate with.
+func checkEvaluator(cfg *config.Config) commands.RiskEvaluator {
+	if !*offline {
+		if evaluator := newEvaluator(cfg); evaluator != nil {
+			return evaluator
+		}
+	}
+	return safety.NewHeuristicEvaluator()
+}
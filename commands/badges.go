@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Badge is a short tag summarizing what an option does, so a list of
+// options can be scanned without reading every description.
+type Badge string
+
+const (
+	BadgeInvalidSyntax Badge = "invalid syntax"
+	BadgeRecommended   Badge = "recommended"
+	BadgeReadOnly      Badge = "read-only"
+	BadgeSudo          Badge = "sudo"
+	BadgeNetwork       Badge = "network"
+	BadgeWritesFiles   Badge = "writes files"
+	BadgeIrreversible  Badge = "irreversible"
+)
+
+var (
+	networkBinaries      = []string{"curl", "wget", "ssh", "scp", "sftp", "ftp", "rsync", "nc", "ncat", "telnet", "ping", "dig", "nslookup", "host", "http", "aws", "gcloud", "az", "kubectl"}
+	writeBinaries        = []string{"rm", "rmdir", "mv", "cp", "mkdir", "touch", "tee", "ln", "chmod", "chown", "chgrp", "truncate", "shred", "dd", "install", "unlink", "patch", "tar", "unzip", "gzip", "gunzip", "zip", "mkfs", "wipefs"}
+	irreversibleBinaries = []string{"rm", "shred", "dd", "mkfs", "wipefs", "unlink"}
+
+	// quoted matches quoted strings, which are dropped before looking for
+	// redirections so awk '$1 > 5' isn't taken for one.
+	quoted = regexp.MustCompile(`'[^']*'|"(\\.|[^"\\])*"`)
+	// delegated matches programs run by xargs or find -exec, which
+	// UsedBinaries doesn't see.
+	delegated = regexp.MustCompile(`\b(xargs(\s+-\S+)*|-exec(dir)?)\s+(sudo\s+)?([\w.-]+)`)
+	// fileRedirect matches output redirections and their target.
+	fileRedirect = regexp.MustCompile(`>>?\s*([^\s&>|;]+)`)
+
+	networkSubcommands      = regexp.MustCompile(`\bgit\s+(push|pull|fetch|clone|ls-remote)\b|\b(apt|apt-get|yum|dnf|pacman|brew|pip3?|npm|yarn|cargo|go)\s+(install|get|add|update|upgrade)\b`)
+	writeSubcommands        = regexp.MustCompile(`\b(sed|perl)\s+(-[a-zA-Z]*\s+)*-[a-zA-Z]*i|\bgit\s+(add|commit|checkout|reset|clean|merge|rebase|stash)\b`)
+	irreversibleSubcommands = regexp.MustCompile(`\bgit\s+(reset\s+--hard|clean\s+-[a-zA-Z]*f|push\s+.*(-f\b|--force\b))|(?i)\b(drop|truncate)\s+(table|database|schema)\b`)
+)
+
+// Public: Summarizes what the option's command does as badges, from a
+// static look at the binaries and redirections it uses plus its safety
+// check. An option only gets BadgeReadOnly when nothing else applies and
+// its check, if it had one, found no risk.
+//
+// Returns the badges in a fixed order, starting with BadgeInvalidSyntax
+// and BadgeRecommended.
+func (o Option) Badges() []Badge {
+	command := quoted.ReplaceAllString(o.Command, "''")
+	bins := UsedBinaries(command)
+	for _, m := range delegated.FindAllStringSubmatch(command, -1) {
+		bins = append(bins, m[5])
+	}
+	for i, bin := range bins {
+		// mkfs.ext4, mkfs.xfs and so on.
+		if strings.HasPrefix(bin, "mkfs") {
+			bins[i] = "mkfs"
+		}
+	}
+	uses := func(list []string) bool {
+		return slices.ContainsFunc(bins, func(bin string) bool { return slices.Contains(list, bin) })
+	}
+
+	var badges []Badge
+	if slices.Contains(bins, "sudo") || slices.Contains(bins, "doas") {
+		badges = append(badges, BadgeSudo)
+	}
+	if uses(networkBinaries) || networkSubcommands.MatchString(o.Command) {
+		badges = append(badges, BadgeNetwork)
+	}
+	if uses(writeBinaries) || writeSubcommands.MatchString(command) || redirectsToFile(command) {
+		badges = append(badges, BadgeWritesFiles)
+	}
+	critical := o.Risk != nil && o.Risk.Level == safety.RiskCritical
+	if critical || uses(irreversibleBinaries) || irreversibleSubcommands.MatchString(o.Command) {
+		badges = append(badges, BadgeIrreversible)
+	}
+
+	if len(badges) == 0 && (o.Risk == nil || o.Risk.Level == safety.RiskNone) {
+		badges = append(badges, BadgeReadOnly)
+	}
+	if o.Recommended {
+		badges = append([]Badge{BadgeRecommended}, badges...)
+	}
+	if o.ShellError != "" {
+		badges = append([]Badge{BadgeInvalidSyntax}, badges...)
+	}
+	return badges
+}
+
+// discardTargets are redirection targets that don't write a file.
+var discardTargets = []string{"/dev/null", "/dev/stdout", "/dev/stderr", "/dev/tty"}
+
+// redirectsToFile reports whether command redirects output to a file
+// rather than discarding it or sending it to another descriptor.
+func redirectsToFile(command string) bool {
+	for _, m := range fileRedirect.FindAllStringSubmatch(command, -1) {
+		if !slices.Contains(discardTargets, m[1]) {
+			return true
+		}
+	}
+	return false
+}
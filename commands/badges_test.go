@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestOptionBadges(t *testing.T) {
+	tests := []struct {
+		command string
+		risk    *safety.RiskInfo
+		want    []Badge
+	}{
+		{"ls -la", nil, []Badge{BadgeReadOnly}},
+		{"find . -name '*.log' 2>/dev/null | wc -l", nil, []Badge{BadgeReadOnly}},
+		{"awk '$3 > 100' access.log", nil, []Badge{BadgeReadOnly}},
+		{"du -sh * > sizes.txt", nil, []Badge{BadgeWritesFiles}},
+		{"sed -i 's/foo/bar/' config.yml", nil, []Badge{BadgeWritesFiles}},
+		{"curl -s https://example.com | jq .", &safety.RiskInfo{Level: safety.RiskLow}, []Badge{BadgeNetwork}},
+		{"git push origin main", nil, []Badge{BadgeNetwork}},
+		{"sudo systemctl restart nginx", &safety.RiskInfo{Level: safety.RiskLow}, []Badge{BadgeSudo}},
+		{"find . -name '*.tmp' | xargs rm -f", nil, []Badge{BadgeWritesFiles, BadgeIrreversible}},
+		{"sudo mkfs.ext4 /dev/sdb1", nil, []Badge{BadgeSudo, BadgeWritesFiles, BadgeIrreversible}},
+		{"psql -c 'DROP DATABASE app'", &safety.RiskInfo{Level: safety.RiskCritical}, []Badge{BadgeIrreversible}},
+		// A flagged command with nothing to show isn't called read-only.
+		{"kill -9 1234", &safety.RiskInfo{Level: safety.RiskLow}, nil},
+	}
+
+	for _, tt := range tests {
+		got := Option{Command: tt.command, Risk: tt.risk}.Badges()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Badges(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestOptionBadgesRecommended(t *testing.T) {
+	got := Option{Command: "ls -la", Recommended: true}.Badges()
+	if want := []Badge{BadgeRecommended, BadgeReadOnly}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Badges() = %v, want %v", got, want)
+	}
+}
+
+func TestOptionBadgesInvalidSyntax(t *testing.T) {
+	got := Option{Command: "ls | | wc", ShellError: "invalid"}.Badges()
+	if len(got) == 0 || got[0] != BadgeInvalidSyntax {
+		t.Errorf("Badges() = %v, want %v first", got, BadgeInvalidSyntax)
+	}
+}
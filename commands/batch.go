@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchOptions controls how GenerateBatch spreads out its requests.
+type BatchOptions struct {
+	Jobs     int           // queries generated at once; values below 1 mean 1
+	Interval time.Duration // minimum time between starting queries; 0 for none
+}
+
+// BatchResult is the outcome of one query in a batch.
+type BatchResult struct {
+	Query   string
+	Options []Option
+	Err     error
+	// SafetyErr is set when safety evaluation failed, leaving Options
+	// without risk info.
+	SafetyErr error
+}
+
+// Public: Generates and safety-checks options for each query, for
+// non-interactive use such as pre-generating a cheatsheet. A failed query
+// doesn't stop the others; its error is recorded in its result.
+//
+// ctx     - Cancels queries that haven't finished
+// queries - Natural language queries
+// opts    - Concurrency and rate limit
+//
+// Returns one result per query, in the order given.
+func (g *Generator) GenerateBatch(ctx context.Context, queries []string, opts BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(queries))
+
+	var limit <-chan time.Time
+	if opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		limit = ticker.C
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range max(opts.Jobs, 1) {
+		wg.Go(func() {
+			for i := range next {
+				results[i] = g.generateOne(ctx, queries[i])
+			}
+		})
+	}
+
+	for i, query := range queries {
+		if i > 0 && limit != nil {
+			select {
+			case <-limit:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Query: query, Err: ctx.Err()}
+			continue
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return results
+}
+
+// generateOne runs one batch query. Safety evaluation is best-effort here
+// as in the selector: on failure the options are kept without risk info.
+func (g *Generator) generateOne(ctx context.Context, query string) BatchResult {
+	options, err := g.Generate(ctx, query)
+	if err != nil {
+		return BatchResult{Query: query, Err: err}
+	}
+
+	evaluated, err := g.EvaluateSafety(ctx, options)
+	if err != nil {
+		// Options come back only when the policy blocked them.
+		if evaluated != nil {
+			options = evaluated
+		}
+		return BatchResult{Query: query, Options: options, SafetyErr: err}
+	}
+
+	return BatchResult{Query: query, Options: evaluated}
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// echoClient returns one option whose command is the query, and fails for
+// the query "fail". It is safe for concurrent use, unlike llm.MockClient.
+type echoClient struct{}
+
+func (echoClient) GenerateOptions(_ context.Context, query string) ([]llm.CommandOption, error) {
+	if query == "fail" {
+		return nil, errors.New("API error")
+	}
+	return []llm.CommandOption{{Title: "Echo", Command: query}}, nil
+}
+
+func TestGeneratorGenerateBatch(t *testing.T) {
+	queries := []string{"one", "fail", "three", "four", "five"}
+	evaluator := stubEvaluator{risks: []*safety.RiskInfo{{Level: safety.RiskLow, Message: "network"}}}
+	gen := NewGeneratorWithEvaluator(echoClient{}, evaluator)
+
+	for _, jobs := range []int{0, 1, 3} {
+		results := gen.GenerateBatch(context.Background(), queries, BatchOptions{Jobs: jobs})
+
+		if len(results) != len(queries) {
+			t.Fatalf("jobs=%d: got %d results, want %d", jobs, len(results), len(queries))
+		}
+		for i, r := range results {
+			if r.Query != queries[i] {
+				t.Errorf("jobs=%d: results[%d].Query = %q, want %q", jobs, i, r.Query, queries[i])
+			}
+			if r.Query == "fail" {
+				if r.Err == nil {
+					t.Errorf("jobs=%d: failing query has no error", jobs)
+				}
+				continue
+			}
+			if r.Err != nil || len(r.Options) != 1 || r.Options[0].Command != r.Query {
+				t.Errorf("jobs=%d: results[%d] = %+v, want one option echoing the query", jobs, i, r)
+				continue
+			}
+			if r.Options[0].Risk == nil {
+				t.Errorf("jobs=%d: results[%d] missing risk", jobs, i)
+			}
+		}
+	}
+}
+
+func TestGeneratorGenerateBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	gen := NewGeneratorWithEvaluator(echoClient{}, nil)
+	results := gen.GenerateBatch(ctx, []string{"one", "two"}, BatchOptions{})
+
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want context.Canceled", i, r.Err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// wrapperCommands run another command, so the interesting binary is the
+// one that follows them. Values list the wrapper's flags that take an
+// argument, so "sudo -u www find" skips "www" too.
+var wrapperCommands = map[string][]string{
+	"sudo":    {"-u", "-g", "-C", "-D", "-h", "-p", "-r", "-t", "-U"},
+	"doas":    {"-u", "-C"},
+	"env":     {"-u", "-C", "-S"},
+	"time":    {"-f", "-o"},
+	"nohup":   nil,
+	"nice":    {"-n"},
+	"command": nil,
+	"exec":    {"-a"},
+}
+
+// envAssignment matches a leading VAR=value prefix.
+var envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// Public: Returns the name of the main program a command runs.
+//
+// Skips leading environment assignments (FOO=bar) and wrappers such as
+// sudo and env, along with their flags, so "sudo -u www LANG=C find ." yields
+// "find". Only the first pipeline segment is considered.
+//
+// command - A shell command line
+//
+// Returns the binary's base name, or "" if none could be found.
+func PrimaryBinary(command string) string {
+	var wrapperFlags []string
+	skipNext := false
+	for _, word := range strings.Fields(command) {
+		if skipNext {
+			skipNext = false
+			continue
+		}
+
+		if flags, ok := wrapperCommands[word]; ok {
+			wrapperFlags = flags
+			continue
+		}
+
+		switch {
+		case envAssignment.MatchString(word):
+			continue
+		case strings.HasPrefix(word, "-"):
+			// Flags belonging to a wrapper we skipped.
+			skipNext = slices.Contains(wrapperFlags, word)
+			continue
+		}
+
+		word = strings.Trim(word, `"'(`)
+		if word == "" || strings.ContainsAny(word, "|;&<>$`") {
+			return ""
+		}
+		return filepath.Base(word)
+	}
+	return ""
+}
+
+// flagPattern matches short and long flags, stopping at "=" so --foo=bar
+// yields --foo.
+var flagPattern = regexp.MustCompile(`^(--?[A-Za-z0-9][A-Za-z0-9-]*)`)
+
+// Public: Returns the flags used in a command, in order of appearance and
+// without duplicates.
+func Flags(command string) []string {
+	seen := map[string]bool{}
+	var flags []string
+	for _, word := range strings.Fields(command) {
+		m := flagPattern.FindStringSubmatch(word)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		flags = append(flags, m[1])
+	}
+	return flags
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrimaryBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "simple", command: "ls -la", want: "ls"},
+		{name: "pipeline", command: "find . -name '*.go' | xargs wc -l", want: "find"},
+		{name: "sudo with flags", command: "sudo -u www find /var", want: "find"},
+		{name: "env assignment", command: "LANG=C sort file.txt", want: "sort"},
+		{name: "env wrapper", command: "env FOO=1 make test", want: "make"},
+		{name: "absolute path", command: "/usr/bin/git status", want: "git"},
+		{name: "empty", command: "", want: ""},
+		{name: "subshell variable", command: "$EDITOR file", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrimaryBinary(tt.command); got != tt.want {
+				t.Errorf("PrimaryBinary(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{name: "short and long", command: "git log -p --all", want: []string{"-p", "--all"}},
+		{name: "value after equals", command: "ls --color=auto", want: []string{"--color"}},
+		{name: "duplicates", command: "grep -r foo -r", want: []string{"-r"}},
+		{name: "no flags", command: "make test", want: nil},
+		{name: "numeric flag", command: "head -5 file", want: []string{"-5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Flags(tt.command); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flags(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
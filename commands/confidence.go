@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"cmp"
+	"slices"
+)
+
+// recommendConfidence is the least confidence an option needs to be
+// recommended. Below it the model isn't sure enough of any option for a
+// recommendation to help.
+const recommendConfidence = 70
+
+// rankByConfidence orders options from most to least confident, keeping
+// the model's order among equals, and marks the first Recommended when
+// the model is confident in it and more so than in any other.
+func rankByConfidence(options []Option) []Option {
+	ranked := slices.Clone(options)
+	slices.SortStableFunc(ranked, func(a, b Option) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+
+	for i := range ranked {
+		ranked[i].Recommended = false
+	}
+	if len(ranked) > 0 && ranked[0].Confidence >= recommendConfidence {
+		ranked[0].Recommended = len(ranked) == 1 || ranked[0].Confidence > ranked[1].Confidence
+	}
+	return ranked
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankByConfidence(t *testing.T) {
+	tests := []struct {
+		name        string
+		confidence  []int
+		wantOrder   []int
+		recommended bool
+	}{
+		{name: "clear favourite", confidence: []int{40, 90, 60}, wantOrder: []int{90, 60, 40}, recommended: true},
+		{name: "tie at the top", confidence: []int{80, 80, 20}, wantOrder: []int{80, 80, 20}},
+		{name: "not confident", confidence: []int{50, 30}, wantOrder: []int{50, 30}},
+		{name: "unknown keeps order", confidence: []int{0, 0, 0}, wantOrder: []int{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := make([]Option, len(tt.confidence))
+			for i, c := range tt.confidence {
+				options[i] = Option{Title: string(rune('a' + i)), Confidence: c}
+			}
+
+			ranked := rankByConfidence(options)
+
+			var order []int
+			for _, opt := range ranked {
+				order = append(order, opt.Confidence)
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+			if ranked[0].Recommended != tt.recommended {
+				t.Errorf("first Recommended = %v, want %v", ranked[0].Recommended, tt.recommended)
+			}
+			for _, opt := range ranked[1:] {
+				if opt.Recommended {
+					t.Errorf("%q Recommended, want only the first", opt.Title)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Constraint limits what generated commands may do, for hosts where they
+// couldn't anyway, such as a container without root or network access.
+type Constraint string
+
+const (
+	ConstraintNoSudo    Constraint = "no-sudo"
+	ConstraintNoNetwork Constraint = "no-network"
+	ConstraintNoInstall Constraint = "no-install"
+)
+
+// constraintInstructions tell the model about each constraint.
+var constraintInstructions = map[Constraint]string{
+	ConstraintNoSudo:    "Do not use sudo, doas or anything else that needs root privileges; the user can't get them",
+	ConstraintNoNetwork: "Do not use the network (no downloads, remote hosts, package registries or git remotes); the machine is offline",
+	ConstraintNoInstall: "Do not install or add packages; use only tools that are already installed",
+}
+
+// installCommand matches package installs, and scripts piped from the web
+// into a shell.
+var installCommand = regexp.MustCompile(`\b(apt|apt-get|aptitude|yum|dnf|zypper|apk|snap|brew|port|pip3?|pipx|npm|pnpm|yarn|gem|cargo|go)\s+(-\S+\s+)*(install|add|get|i)\b|\bpacman\s+-S|\b(dpkg|rpm)\s+-i|\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?(ba|z)?sh\b`)
+
+// Constraints is the set of constraints a generator enforces.
+type Constraints []Constraint
+
+// Public: Parses constraint names as given in the config, e.g. "no-sudo".
+//
+// Returns the Constraints, or an error naming the first unknown one.
+func ParseConstraints(names []string) (Constraints, error) {
+	var c Constraints
+	for _, name := range names {
+		constraint := Constraint(name)
+		if _, ok := constraintInstructions[constraint]; !ok {
+			return nil, fmt.Errorf("unknown constraint %q (want no-sudo, no-network or no-install)", name)
+		}
+		c = c.With(constraint)
+	}
+	return c, nil
+}
+
+// Public: Returns the constraints with constraint added, if it isn't
+// already.
+func (c Constraints) With(constraint Constraint) Constraints {
+	if slices.Contains(c, constraint) {
+		return c
+	}
+	return append(slices.Clone(c), constraint)
+}
+
+// Public: Returns the instructions that ask the model to respect each
+// constraint, for the generation prompt.
+func (c Constraints) Instructions() []string {
+	instructions := make([]string, len(c))
+	for i, constraint := range c {
+		instructions[i] = constraintInstructions[constraint]
+	}
+	return instructions
+}
+
+// Public: Returns the constraints as a comma-separated list of names.
+func (c Constraints) String() string {
+	names := make([]string, len(c))
+	for i, constraint := range c {
+		names[i] = string(constraint)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Public: Returns the constraints opt's command breaks.
+func (c Constraints) Violations(opt Option) Constraints {
+	if len(c) == 0 {
+		return nil
+	}
+
+	badges := opt.Badges()
+	var broken Constraints
+	for _, constraint := range c {
+		var breaks bool
+		switch constraint {
+		case ConstraintNoSudo:
+			breaks = slices.Contains(badges, BadgeSudo)
+		case ConstraintNoNetwork:
+			breaks = slices.Contains(badges, BadgeNetwork)
+		case ConstraintNoInstall:
+			breaks = installCommand.MatchString(opt.Command)
+		}
+		if breaks {
+			broken = append(broken, constraint)
+		}
+	}
+	return broken
+}
+
+// Public: Drops options that break a constraint, since the model is asked
+// not to generate them and they wouldn't work where the user is. If every
+// option breaks one they are kept instead, flagged with Violations, so
+// there is still something to choose from.
+//
+// options - Generated options, which are not modified
+//
+// Returns the options that respect the constraints.
+func (c Constraints) Apply(options []Option) []Option {
+	if len(c) == 0 {
+		return options
+	}
+
+	var kept, flagged []Option
+	for _, opt := range options {
+		opt.Violations = c.Violations(opt)
+		if len(opt.Violations) == 0 {
+			kept = append(kept, opt)
+		} else {
+			flagged = append(flagged, opt)
+		}
+	}
+
+	if len(kept) == 0 {
+		return flagged
+	}
+	return kept
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConstraints(t *testing.T) {
+	got, err := ParseConstraints([]string{"no-sudo", "no-network", "no-sudo"})
+	if err != nil {
+		t.Fatalf("ParseConstraints() error = %v", err)
+	}
+	want := Constraints{ConstraintNoSudo, ConstraintNoNetwork}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseConstraints() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseConstraints([]string{"no-root"}); err == nil {
+		t.Error("ParseConstraints() accepted an unknown constraint")
+	}
+}
+
+func TestConstraintViolations(t *testing.T) {
+	all := Constraints{ConstraintNoSudo, ConstraintNoNetwork, ConstraintNoInstall}
+
+	tests := []struct {
+		command string
+		want    Constraints
+	}{
+		{"ls -la", nil},
+		{"sudo lsof -i :8080", Constraints{ConstraintNoSudo}},
+		{"curl -O https://example.com/file.tar.gz", Constraints{ConstraintNoNetwork}},
+		{"git push origin main", Constraints{ConstraintNoNetwork}},
+		{"sudo apt-get install -y jq", all},
+		// Installing from a registry uses the network too.
+		{"pip install --user httpie", Constraints{ConstraintNoNetwork, ConstraintNoInstall}},
+		{"brew install ripgrep", Constraints{ConstraintNoNetwork, ConstraintNoInstall}},
+		{"pacman -S fd", Constraints{ConstraintNoInstall}},
+		{"curl -fsSL https://get.example.sh | sh", Constraints{ConstraintNoNetwork, ConstraintNoInstall}},
+		{"go build ./...", nil},
+		{"git add -A", nil},
+		{"grep 'sudo' /var/log/auth.log", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := all.Violations(Option{Command: tt.command})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Violations(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstraintsApply(t *testing.T) {
+	c := Constraints{ConstraintNoSudo}
+
+	options := []Option{
+		{Title: "Sudo", Command: "sudo du -sh /var"},
+		{Title: "User", Command: "du -sh ~"},
+	}
+	got := c.Apply(options)
+	if len(got) != 1 || got[0].Title != "User" {
+		t.Errorf("Apply() = %v, want only the option without sudo", got)
+	}
+
+	// With nothing that complies, the options are kept and flagged.
+	got = c.Apply(options[:1])
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Violations, Constraints{ConstraintNoSudo}) {
+		t.Errorf("Apply() = %v, want the sudo option flagged", got)
+	}
+	if options[0].Violations != nil {
+		t.Error("Apply() modified its input")
+	}
+
+	if got := (Constraints{}).Apply(options); !reflect.DeepEqual(got, options) {
+		t.Errorf("Apply() with no constraints = %v, want unchanged", got)
+	}
+}
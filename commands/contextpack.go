@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ContextPack steers generation toward one platform's tools, such as
+// kubectl for Kubernetes. It adds idioms to the prompt, the platform's
+// risks to the safety check and, when asked, details of the local setup
+// like the current kubectl context, read from local files and commands
+// only.
+type ContextPack struct {
+	Name string // Pack name, as typed: "k8s"
+
+	// Instructions are added to the prompt to steer the model toward the
+	// platform's tools and idioms.
+	Instructions string
+	// RiskNotes tell the safety check what is dangerous on the platform.
+	RiskNotes string
+
+	// environment reads the local setup, e.g. "Current kubectl context:
+	// prod". Empty when nothing could be read.
+	environment func(ctx context.Context) string
+}
+
+// contextPacks lists the supported packs by name.
+var contextPacks = map[string]ContextPack{
+	"k8s": {
+		Name:         "k8s",
+		Instructions: "The user works with Kubernetes. Prefer kubectl, with -n for the namespace and -l label selectors over piping through grep; use -o jsonpath or -o json with jq to extract fields, and kubectl's --dry-run=client or diff before changes.",
+		RiskNotes:    "Kubernetes: deleting namespaces, persistent volumes, CRDs or anything with --all is HIGH risk, as is draining nodes or scaling to zero; treat the current context as production unless it says otherwise.",
+		environment:  kubernetesEnvironment,
+	},
+	"aws": {
+		Name:         "aws",
+		Instructions: "The user works with AWS. Prefer the aws CLI v2 with --query (JMESPath) and --output text or json over parsing with grep, and pass --profile or --region only when they differ from the current ones.",
+		RiskNotes:    "AWS: aws s3 rm --recursive, s3 rb --force, terminate-instances, and delete-* calls on databases, buckets, stacks or IAM are HIGH risk; deleting a database with --skip-final-snapshot is CRITICAL.",
+		environment:  awsEnvironment,
+	},
+}
+
+// Public: Returns the context pack with the given name. "kubernetes" is
+// accepted for "k8s".
+func LookupContextPack(name string) (ContextPack, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "kubernetes" {
+		name = "k8s"
+	}
+	p, ok := contextPacks[name]
+	return p, ok
+}
+
+// Public: Returns the names of all context packs, sorted.
+func ContextPackNames() []string {
+	names := make([]string, 0, len(contextPacks))
+	for name := range contextPacks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Public: Reads details of the local setup for the platform, such as the
+// current kubectl context and namespace.
+//
+// Returns one "Name: value" line per detail, or "" if none could be read.
+func (p ContextPack) Environment(ctx context.Context) string {
+	if p.environment == nil {
+		return ""
+	}
+	return p.environment(ctx)
+}
+
+// environmentTimeout bounds each local command run to read a setup.
+const environmentTimeout = 2 * time.Second
+
+// kubernetesEnvironment reads the current context and namespace from the
+// kubeconfig with kubectl, which doesn't contact the cluster for these.
+func kubernetesEnvironment(ctx context.Context) string {
+	current := localOutput(ctx, "kubectl", "config", "current-context")
+	if current == "" {
+		return ""
+	}
+	namespace := localOutput(ctx, "kubectl", "config", "view", "--minify", "--output", "jsonpath={..namespace}")
+	if namespace == "" {
+		namespace = "default"
+	}
+	return fmt.Sprintf("Current kubectl context: %s\nCurrent namespace: %s", current, namespace)
+}
+
+// awsEnvironment reads the profile and region the aws CLI would use, from
+// the environment and the CLI's config file.
+func awsEnvironment(context.Context) string {
+	profile := firstEnv("AWS_PROFILE", "AWS_DEFAULT_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = awsConfigRegion(profile)
+	}
+
+	lines := []string{"AWS profile: " + profile}
+	if region != "" {
+		lines = append(lines, "AWS region: "+region)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// awsConfigRegion returns the region set for profile in the aws CLI's
+// config file, or "".
+func awsConfigRegion(profile string) string {
+	path := os.Getenv("AWS_CONFIG_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		path = filepath.Join(home, ".aws", "config")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	section := "profile " + profile
+	if profile == "default" {
+		section = "default"
+	}
+
+	var inSection bool
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = strings.TrimSpace(strings.Trim(line, "[]")) == section
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if inSection && ok && strings.TrimSpace(key) == "region" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// firstEnv returns the first of the environment variables that is set.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// localOutput runs a local command and returns its trimmed output, or ""
+// if it isn't installed or fails.
+func localOutput(ctx context.Context, name string, args ...string) string {
+	if _, err := exec.LookPath(name); err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, environmentTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
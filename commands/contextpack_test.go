@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupContextPack(t *testing.T) {
+	for _, name := range []string{"k8s", "K8S", "kubernetes", " aws "} {
+		if _, ok := LookupContextPack(name); !ok {
+			t.Errorf("LookupContextPack(%q) found nothing", name)
+		}
+	}
+	if _, ok := LookupContextPack("gcp"); ok {
+		t.Error("LookupContextPack() found an unknown pack")
+	}
+}
+
+func TestAWSEnvironment(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(config, []byte("[default]\nregion = us-east-1\n\n[profile work]\noutput = json\nregion = eu-west-2\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", config)
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+
+	pack, _ := LookupContextPack("aws")
+
+	tests := []struct {
+		name    string
+		profile string
+		region  string
+		want    string
+	}{
+		{"default profile", "", "", "AWS profile: default\nAWS region: us-east-1"},
+		{"named profile", "work", "", "AWS profile: work\nAWS region: eu-west-2"},
+		{"region from the environment", "work", "ap-south-1", "AWS profile: work\nAWS region: ap-south-1"},
+		{"unknown profile", "other", "", "AWS profile: other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_PROFILE", tt.profile)
+			t.Setenv("AWS_REGION", tt.region)
+			if got := pack.Environment(context.Background()); got != tt.want {
+				t.Errorf("Environment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DSL describes a focused mode for one tool's expression language, such
+// as jq filters or awk programs. In a DSL mode the model is asked for a
+// minimal invocation around a carefully written expression, and the
+// expression is checked locally where the tool allows it without running
+// anything.
+type DSL struct {
+	Name string // Mode name, as typed: "jq"
+	Tool string // Name shown to the model: "jq"
+
+	// Instructions are added to the prompt to focus the model on the tool.
+	Instructions string
+
+	// expressionFlags are flags whose value is the expression, for tools
+	// like ffmpeg that take it as a flag argument rather than positionally.
+	expressionFlags []string
+
+	// validate checks an expression's syntax without executing it. Nil
+	// when the tool offers no safe way to do that.
+	validate func(ctx context.Context, expr string) error
+}
+
+// dsls lists the supported modes by name.
+var dsls = map[string]DSL{
+	"jq": {
+		Name:         "jq",
+		Tool:         "jq",
+		Instructions: "Focus on the jq filter. Each command must be a minimal jq invocation with the filter in single quotes; vary the filter, not the surrounding command.",
+		validate:     validateJQ,
+	},
+	"awk": {
+		Name:         "awk",
+		Tool:         "awk",
+		Instructions: "Focus on the awk program. Each command must be a minimal awk invocation with the program in single quotes, portable to POSIX awk unless a gawk feature is essential.",
+		validate:     validateAwk,
+	},
+	"sed": {
+		Name:         "sed",
+		Tool:         "sed",
+		Instructions: "Focus on the sed script. Each command must be a minimal sed invocation with the script in single quotes; note in the description when it relies on GNU or BSD sed behavior.",
+	},
+	"regex": {
+		Name:         "regex",
+		Tool:         "regular expressions",
+		Instructions: "Focus on the regular expression. Each command must be a minimal grep -E (or grep -P where lookaround is needed) invocation with the pattern in single quotes; the description should explain each part of the pattern.",
+	},
+	"ffmpeg": {
+		Name:            "ffmpeg",
+		Tool:            "ffmpeg",
+		Instructions:    "Focus on the ffmpeg options and filter graph. Each command must be a single ffmpeg invocation with placeholder file names like input.mp4, with any filter graph in single quotes.",
+		expressionFlags: []string{"-vf", "-af", "-filter:v", "-filter:a", "-filter_complex", "-lavfi"},
+	},
+}
+
+// Public: Returns the DSL mode with the given name.
+func LookupDSL(name string) (DSL, bool) {
+	d, ok := dsls[strings.ToLower(name)]
+	return d, ok
+}
+
+// Public: Returns the names of all DSL modes, sorted.
+func DSLNames() []string {
+	names := make([]string, 0, len(dsls))
+	for name := range dsls {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Public: Extracts the expression from a generated invocation: the value
+// of an expression flag for tools that have them, otherwise the first
+// quoted argument.
+//
+// Returns the expression, or "" if none could be found.
+func (d DSL) Expression(command string) string {
+	words := shellWords(command)
+
+	if len(d.expressionFlags) > 0 {
+		for i, w := range words[:max(len(words)-1, 0)] {
+			if slices.Contains(d.expressionFlags, w.text) {
+				return words[i+1].text
+			}
+		}
+		return ""
+	}
+
+	for _, w := range words {
+		if w.quoted {
+			return w.text
+		}
+	}
+	return ""
+}
+
+// Public: Checks an expression's syntax with the locally installed tool.
+//
+// Returns nil if the expression is valid, or if it can't be checked
+// because the mode has no validator or the tool isn't installed.
+func (d DSL) Validate(ctx context.Context, expr string) error {
+	if d.validate == nil || expr == "" {
+		return nil
+	}
+	return d.validate(ctx, expr)
+}
+
+// validateTimeout bounds each syntax check.
+const validateTimeout = 2 * time.Second
+
+// validateJQ compiles a filter with null input. jq filters can't touch the
+// filesystem or run programs, so evaluating one is safe; only compile
+// errors (exit status 3) are reported.
+func validateJQ(ctx context.Context, expr string) error {
+	return runValidator(ctx, 3, "jq", "-n", expr)
+}
+
+// validateAwk uses gawk's pretty-printer, which parses the program without
+// running it (a BEGIN block could otherwise execute commands). Other awks
+// have no parse-only mode, so they are skipped.
+func validateAwk(ctx context.Context, expr string) error {
+	return runValidator(ctx, 0, "gawk", "--pretty-print=/dev/null", "--", expr)
+}
+
+// runValidator runs a checker and reports its stderr as a syntax error. If
+// errorStatus is non-zero, only that exit status counts as a syntax error.
+func runValidator(ctx context.Context, errorStatus int, name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, validateTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader("")
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return nil
+	}
+	if errorStatus != 0 && exitErr.ExitCode() != errorStatus {
+		return nil
+	}
+
+	msg := strings.TrimSpace(stderr.String())
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = msg[:i]
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// shellWord is one word of a command line after quote removal.
+type shellWord struct {
+	text   string
+	quoted bool
+}
+
+// shellWords splits a command line into words, honoring single quotes,
+// double quotes and backslash escapes. It is deliberately simple: enough to
+// find an expression argument, not a full shell parser.
+func shellWords(command string) []shellWord {
+	var words []shellWord
+	var cur strings.Builder
+	inWord, quoted := false, false
+	var quote rune
+
+	flush := func() {
+		if inWord {
+			words = append(words, shellWord{text: cur.String(), quoted: quoted})
+		}
+		cur.Reset()
+		inWord, quoted = false, false
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				i++
+				cur.WriteRune(runes[i])
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord, quoted = r, true, true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+
+	return words
+}
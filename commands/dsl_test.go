@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestDSLExpression(t *testing.T) {
+	tests := []struct {
+		mode    string
+		command string
+		want    string
+	}{
+		{mode: "jq", command: `jq '.items[] | .name' data.json`, want: ".items[] | .name"},
+		{mode: "jq", command: `jq -r ".users[].email" users.json`, want: ".users[].email"},
+		{mode: "awk", command: `awk -F, '{ sum += $3 } END { print sum }' sales.csv`, want: "{ sum += $3 } END { print sum }"},
+		{mode: "regex", command: `grep -E '^[0-9]{3}-[0-9]{4}$' phones.txt`, want: "^[0-9]{3}-[0-9]{4}$"},
+		{mode: "ffmpeg", command: `ffmpeg -i "my input.mp4" -vf 'scale=640:-1,fps=10' out.gif`, want: "scale=640:-1,fps=10"},
+		{mode: "ffmpeg", command: `ffmpeg -i input.mp4 -c copy out.mkv`, want: ""},
+		{mode: "jq", command: `jq . data.json`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			d, ok := LookupDSL(tt.mode)
+			if !ok {
+				t.Fatalf("LookupDSL(%q) not found", tt.mode)
+			}
+			if got := d.Expression(tt.command); got != tt.want {
+				t.Errorf("Expression(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupDSL(t *testing.T) {
+	if _, ok := LookupDSL("JQ"); !ok {
+		t.Error("LookupDSL() should be case-insensitive")
+	}
+	if _, ok := LookupDSL("perl"); ok {
+		t.Error("LookupDSL(\"perl\") should not exist")
+	}
+}
+
+func TestValidateJQ(t *testing.T) {
+	if _, err := exec.LookPath("jq"); err != nil {
+		t.Skip("jq not installed")
+	}
+
+	d, _ := LookupDSL("jq")
+	if err := d.Validate(context.Background(), ".items[] | .name"); err != nil {
+		t.Errorf("Validate() valid filter error = %v", err)
+	}
+	// Runtime errors on null input are not syntax errors.
+	if err := d.Validate(context.Background(), ".[0] | keys"); err != nil {
+		t.Errorf("Validate() runtime-only error = %v", err)
+	}
+	if err := d.Validate(context.Background(), ".items[ | .name"); err == nil {
+		t.Error("Validate() invalid filter should fail")
+	}
+}
+
+func TestValidateWithoutValidator(t *testing.T) {
+	d, _ := LookupDSL("sed")
+	if err := d.Validate(context.Background(), "s/(/"); err != nil {
+		t.Errorf("Validate() without a validator = %v, want nil", err)
+	}
+}
+
+func TestShellWords(t *testing.T) {
+	got := shellWords(`a 'b c' "d \"e\"" f\ g`)
+	want := []shellWord{
+		{text: "a"},
+		{text: "b c", quoted: true},
+		{text: `d "e"`, quoted: true},
+		{text: "f g"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("shellWords() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("shellWords()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
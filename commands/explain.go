@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+// RiskExplainer is implemented by evaluators that can also assess a single
+// command in depth, such as *safety.Evaluator.
+type RiskExplainer interface {
+	Explain(ctx context.Context, command string) (*safety.RiskDetail, error)
+}
+
+// Public: Assesses one command in depth, for the detailed safety view.
+// Secrets are redacted from the command before sending, and restored in
+// the assessment.
+//
+// Returns an error if the evaluator can't give detailed assessments.
+func (g *Generator) ExplainRisk(ctx context.Context, command string) (*safety.RiskDetail, error) {
+	explainer, ok := g.evaluator.(RiskExplainer)
+	if !ok {
+		return nil, fmt.Errorf("detailed safety analysis isn't available with this setup")
+	}
+
+	var redacted *redaction
+	if !g.keepSecrets {
+		command, redacted = redactSecrets(command)
+	}
+
+	detail, err := explainer.Explain(ctx, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain risk: %w", err)
+	}
+
+	detail.Reasoning = redacted.restore(detail.Reasoning)
+	detail.DataLoss = redacted.restore(detail.DataLoss)
+	detail.Recovery = redacted.restore(detail.Recovery)
+	for i, path := range detail.Paths {
+		detail.Paths[i] = redacted.restore(path)
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Failure describes a command that didn't work, for the fix flow.
+type Failure struct {
+	Command    string
+	ExitStatus int    // -1 when unknown
+	Output     string // stderr (or combined output), if captured
+}
+
+// Public: Builds the query that asks for corrected versions of the failed
+// command. It is an ordinary query, so it works with every provider and
+// through the daemon.
+func (f Failure) Query() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "This command failed; give corrected commands that do what it was meant to do: %s", f.Command)
+	if f.ExitStatus >= 0 {
+		fmt.Fprintf(&b, "\nExit status: %d%s", f.ExitStatus, exitStatusHint(f.ExitStatus))
+	}
+
+	if out := strings.TrimSpace(f.Output); out != "" {
+		fmt.Fprintf(&b, "\nError output:\n%s", truncateHead(out, maxAttachment))
+	}
+
+	b.WriteString("\nExplain in each description what was wrong with the original.")
+
+	return b.String()
+}
+
+// exitStatusHint names the shell's conventional meaning of an exit status,
+// which often says more than the status itself.
+func exitStatusHint(status int) string {
+	switch {
+	case status == 126:
+		return " (found but not executable)"
+	case status == 127:
+		return " (command not found)"
+	case status > 128 && status < 160:
+		return fmt.Sprintf(" (killed by signal %d)", status-128)
+	}
+	return ""
+}
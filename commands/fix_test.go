@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFailureQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure Failure
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "command only",
+			failure: Failure{Command: "git pus origin main", ExitStatus: -1},
+			want:    []string{"git pus origin main"},
+			notWant: []string{"Exit status", "Error output"},
+		},
+		{
+			name:    "command not found",
+			failure: Failure{Command: "dokcer ps", ExitStatus: 127},
+			want:    []string{"Exit status: 127 (command not found)"},
+		},
+		{
+			name:    "signal",
+			failure: Failure{Command: "sleep 100", ExitStatus: 130},
+			want:    []string{"(killed by signal 2)"},
+		},
+		{
+			name:    "with output",
+			failure: Failure{Command: "tar xf a.tgz", ExitStatus: 2, Output: "tar: a.tgz: Cannot open\n"},
+			want:    []string{"Exit status: 2\n", "Error output:\ntar: a.tgz: Cannot open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.failure.Query()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Query() missing %q, got:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Query() contains %q, got:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFailureQueryTruncatesOutput(t *testing.T) {
+	output := strings.Repeat("x", maxAttachment) + "FATAL: the real error"
+	got := Failure{Command: "make", ExitStatus: 2, Output: output}.Query()
+
+	if !strings.Contains(got, "FATAL: the real error") {
+		t.Error("Query() dropped the end of the output")
+	}
+	if len(got) > maxAttachment+500 {
+		t.Errorf("Query() length = %d, want output capped near %d", len(got), maxAttachment)
+	}
+}
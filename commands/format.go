@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Public: Breaks a one-line pipeline or && / || list across lines for
+// reading. Each top-level operator starts a new line, indented so the
+// operators line up, and the line before it ends in a \ continuation, so
+// the result still runs as one command. Operators inside quotes,
+// subshells or command substitutions are left alone.
+//
+// Returns the command unchanged when it already spans lines, doesn't
+// parse, or has nothing to break.
+func Reflow(command string) string {
+	if strings.Contains(strings.TrimRight(command, "\n"), "\n") {
+		return command
+	}
+	file, err := parseShell(command, syntax.LangBash)
+	if err != nil || len(file.Stmts) != 1 {
+		return command
+	}
+
+	type operator struct {
+		offset int
+		text   string
+	}
+	var ops []operator
+	var collect func(stmt *syntax.Stmt)
+	collect = func(stmt *syntax.Stmt) {
+		bin, ok := stmt.Cmd.(*syntax.BinaryCmd)
+		// A negated or backgrounded list binds as a whole, so breaking it
+		// up would read as if it didn't.
+		if !ok || stmt.Negated || stmt.Background || len(stmt.Redirs) > 0 {
+			return
+		}
+		collect(bin.X)
+		ops = append(ops, operator{offset: int(bin.OpPos.Offset()), text: bin.Op.String()})
+		collect(bin.Y)
+	}
+	collect(file.Stmts[0])
+	if len(ops) == 0 {
+		return command
+	}
+
+	lines := []string{strings.TrimSpace(command[:ops[0].offset])}
+	for i, op := range ops {
+		end := len(command)
+		if i+1 < len(ops) {
+			end = ops[i+1].offset
+		}
+		segment := strings.TrimSpace(command[op.offset+len(op.text) : end])
+		lines = append(lines, "  "+op.text+" "+segment)
+	}
+	return strings.Join(lines, " \\\n")
+}
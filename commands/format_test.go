@@ -0,0 +1,39 @@
+package commands
+
+import "testing"
+
+func TestReflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{
+			name:    "pipeline",
+			command: "find . -name '*.log' | xargs grep -l ERROR | sort -u",
+			want:    "find . -name '*.log' \\\n  | xargs grep -l ERROR \\\n  | sort -u",
+		},
+		{
+			name:    "list",
+			command: "make build && ./bin/app --check || echo failed",
+			want:    "make build \\\n  && ./bin/app --check \\\n  || echo failed",
+		},
+		{
+			name:    "quoted and nested operators",
+			command: `echo "a | b" | grep -c "$(ls | wc -l)"`,
+			want:    "echo \"a | b\" \\\n  | grep -c \"$(ls | wc -l)\"",
+		},
+		{name: "single command", command: "ls -la", want: "ls -la"},
+		{name: "several statements", command: "cd /tmp; ls | wc -l", want: "cd /tmp; ls | wc -l"},
+		{name: "multi-line", command: "ls |\nwc -l", want: "ls |\nwc -l"},
+		{name: "invalid", command: "ls | | wc", want: "ls | | wc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reflow(tt.command); got != tt.want {
+				t.Errorf("Reflow(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
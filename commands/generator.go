@@ -2,67 +2,418 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/policy"
 	"github.com/pixielabs/1lm/safety"
 )
 
+// RiskEvaluator assesses commands for safety risks. *safety.Evaluator is
+// the standard implementation; embedders can supply their own policy.
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, commands []string) ([]*safety.RiskInfo, error)
+}
+
 // Generator handles command generation from natural language queries.
 type Generator struct {
-	client    llm.Client
-	evaluator *safety.Evaluator
+	client      llm.Client
+	evaluator   RiskEvaluator
+	tools       ToolPolicy
+	constraints Constraints
+	shell       Shell
+	dsl         *DSL
+	attachments []Attachment
+	policy      *policy.Policy
+	keepSecrets bool
+	tokenizer   llm.Tokenizer
+	maxTokens   int
+	latency     atomic.Int64 // nanoseconds the last generation took
+
+	confirmTokens int    // size needing confirmation; 0 or negative never asks
+	model         string // what large requests are priced for
+	confirmed     atomic.Bool
+
+	safetyTimeout time.Duration // 0 for DefaultSafetyTimeout, negative for none
 }
 
+// DefaultSafetyTimeout is how long an option's safety check may take
+// before the option is shown as unverified.
+const DefaultSafetyTimeout = 20 * time.Second
+
 // Public: Creates a new Generator with the given LLM client and a safety
 // evaluator backed by the Anthropic client.
 func NewGenerator(client llm.Client, anthropicClient *anthropic.Client, model string) *Generator {
+	return NewGeneratorWithEvaluator(client, safety.NewEvaluator(anthropicClient, model))
+}
+
+// Public: Creates a new Generator with a custom safety evaluator.
+//
+// client    - Generates the command options
+// evaluator - Assesses them for risk; nil disables safety evaluation
+//
+// Returns the Generator.
+func NewGeneratorWithEvaluator(client llm.Client, evaluator RiskEvaluator) *Generator {
 	return &Generator{
 		client:    client,
-		evaluator: safety.NewEvaluator(anthropicClient, model),
+		evaluator: evaluator,
 	}
 }
 
-// Public: Generates command options from a natural language query.
+// Public: Sets the tool policy applied to generated options.
+func (g *Generator) SetToolPolicy(policy ToolPolicy) {
+	g.tools = policy
+}
+
+// Public: Sets the constraints generated options must respect.
+func (g *Generator) SetConstraints(c Constraints) {
+	g.constraints = c
+}
+
+// Public: Sets the shell generated commands are parsed for. Commands that
+// don't parse are flagged, and unbalanced quotes closed. The zero Shell
+// parses them as bash.
+func (g *Generator) SetShell(sh Shell) {
+	g.shell = sh
+}
+
+// Public: Sets the DSL mode, so generated options carry their extracted
+// and validated expression. Nil turns DSL mode off.
+func (g *Generator) SetDSL(dsl *DSL) {
+	g.dsl = dsl
+}
+
+// Public: Sets the policy options are checked against. Forbidden options
+// are marked Blocked; nil removes the policy.
+func (g *Generator) SetPolicy(p *policy.Policy) {
+	g.policy = p
+}
+
+// Public: Reports whether the policy requires options to be safety checked
+// before one can be selected.
+func (g *Generator) RequiresSafety() bool {
+	return g.policy.RequiresSafety()
+}
+
+// Public: Sets whether secrets and private IP addresses in queries are sent
+// as they are. By default they are replaced with placeholders before the
+// query leaves the machine.
+func (g *Generator) SetKeepSecrets(keep bool) {
+	g.keepSecrets = keep
+}
+
+// Public: Sets how long each option's safety check may take before the
+// option is shown as unverified. Zero uses DefaultSafetyTimeout; a
+// negative duration waits as long as the check takes.
+func (g *Generator) SetSafetyTimeout(timeout time.Duration) {
+	g.safetyTimeout = timeout
+}
+
+// Public: Returns how long each option's safety check may take, or 0 for
+// no limit.
+func (g *Generator) SafetyTimeout() time.Duration {
+	switch {
+	case g.safetyTimeout == 0:
+		return DefaultSafetyTimeout
+	case g.safetyTimeout < 0:
+		return 0
+	}
+	return g.safetyTimeout
+}
+
+// Public: Returns how long the model took to answer the last query, or
+// zero before the first.
+func (g *Generator) Latency() time.Duration {
+	return time.Duration(g.latency.Load())
+}
+
+// Public: Generates command options from a natural language query and any
+// attached context. Options breaking a constraint are dropped, and those
+// using avoided tools are flagged and listed last; the rest are ordered by
+// the model's confidence. Options that don't parse for the target shell
+// are replaced by asking the model again, once. Secrets are redacted
+// from what is sent, and restored in the options. Attached context is
+// trimmed to the token limit, and checked for text trying to instruct the
+// model. A query that, with its context, is larger than the confirmation
+// limit isn't sent until it is confirmed.
 func (g *Generator) Generate(ctx context.Context, query string) ([]Option, error) {
+	if err := g.checkSize(ctx, query); err != nil {
+		return nil, err
+	}
+	out, redacted, err := g.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	query, trimmed, suspicious := out.Query, out.Trimmed, out.Suspicious
+
+	start := time.Now()
 	llmOptions, err := g.client.GenerateOptions(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate options: %w", err)
 	}
+	elapsed := time.Since(start)
+	g.latency.Store(int64(elapsed))
+	slog.Debug("generated options", "count", len(llmOptions), "elapsed", elapsed.Round(time.Millisecond), "trimmed", trimmed)
 
-	options := make([]Option, len(llmOptions))
-	for i, opt := range llmOptions {
-		options[i] = Option{
-			Title:       opt.Title,
-			Command:     opt.Command,
-			Description: opt.Description,
+	convert := func(llmOptions []llm.CommandOption) []Option {
+		options := make([]Option, len(llmOptions))
+		for i, opt := range llmOptions {
+			options[i] = Option{
+				Title:       redacted.restore(opt.Title),
+				Command:     redacted.restore(opt.Command),
+				Description: redacted.restore(opt.Description),
+				Confidence:  opt.Confidence,
+				Fallback:    opt.Fallback,
+				Source:      opt.Source,
+				Trimmed:     trimmed,
+				Suspicious:  suspicious,
+			}
+			if redacted != nil {
+				options[i].Redacted = redacted.labels
+			}
+			g.annotate(ctx, &options[i])
 		}
+		return options
+	}
+
+	options := g.replaceInvalid(ctx, query, llmOptions, convert(llmOptions), convert)
+
+	return g.tools.Apply(g.constraints.Apply(rankByConfidence(options))), nil
+}
+
+// Outgoing is a query as Generate sends it to the model.
+type Outgoing struct {
+	// Query is the query with any attached context, trimmed to the token
+	// limit and with secrets redacted.
+	Query string
+	// Trimmed lists the sources of attachments cut to fit the token limit.
+	Trimmed []string
+	// Redacted lists the kinds of secret replaced with placeholders.
+	Redacted []string
+	// Suspicious lists the sources of attachments that look like they try
+	// to instruct the model.
+	Suspicious []string
+}
+
+// Public: Returns the query as Generate would send it to the model,
+// without sending it, so users can see what leaves the machine.
+func (g *Generator) Preview(ctx context.Context, query string) (Outgoing, error) {
+	out, _, err := g.prepare(ctx, query)
+	return out, err
+}
+
+// prepare redacts secrets from query and its attached context unless they
+// are kept, then fits them to the token limit. Redacting first keeps
+// secrets from the tokenizer too, which may count tokens through the API.
+// The redaction restores them in answers.
+func (g *Generator) prepare(ctx context.Context, query string) (Outgoing, *redaction, error) {
+	attachments := g.attachments
+	var redacted *redaction
+	if !g.keepSecrets {
+		redacted = newRedaction()
+		query = redacted.redact(query)
+		attachments = make([]Attachment, len(g.attachments))
+		for i, a := range g.attachments {
+			attachments[i] = Attachment{Source: a.Source, Text: redacted.redact(a.Text)}
+		}
+	}
+
+	query, trimmed, err := fitAttachments(ctx, g.tokenizer, g.maxTokens, query, attachments)
+	if err != nil {
+		return Outgoing{}, nil, err
+	}
+	out := Outgoing{Query: query, Trimmed: trimmed, Suspicious: suspiciousAttachments(g.attachments)}
+	if redacted != nil {
+		out.Redacted = redacted.labels
 	}
+	return out, redacted, nil
+}
+
+// annotate checks an option's command parses for the target shell, and
+// sets its DSL expression and policy verdict from it.
+func (g *Generator) annotate(ctx context.Context, opt *Option) {
+	check := checkShell(opt.Command, g.shell)
+	opt.Command, opt.ShellFix, opt.ShellError, opt.Unquoted = check.Command, check.Fix, check.Err, check.Unquoted
 
-	return options, nil
+	if g.dsl != nil {
+		opt.Expression = g.dsl.Expression(opt.Command)
+		if err := g.dsl.Validate(ctx, opt.Expression); err != nil {
+			opt.SyntaxError = err.Error()
+		}
+	}
+	opt.Blocked = g.policy.Check(opt.Command)
 }
 
 // Public: Evaluates commands for safety risks and returns updated options.
-// Best-effort: returns (nil, err) on failure so callers can ignore silently.
+// Best-effort: returns (nil, err) on failure, logged as a warning, so
+// callers can carry on without risk info. Without an evaluator the options
+// are returned unchanged.
+//
+// When the policy requires safety checks, options over its risk threshold
+// are marked Blocked, and a failed or unavailable check blocks every
+// option; the blocked options are returned alongside any error.
+//
+// When the evaluator proposes a safer version of a high-risk option, it is
+// checked in turn and inserted after that option, with SaferFor set.
 func (g *Generator) EvaluateSafety(ctx context.Context, options []Option) ([]Option, error) {
+	if g.evaluator == nil {
+		if g.RequiresSafety() {
+			return blockAll(options, "policy requires a safety check, but none is available"), nil
+		}
+		return options, nil
+	}
+
+	result, err := g.assess(ctx, options)
+	if err != nil {
+		slog.Warn("safety check failed", "err", err)
+		return result, err
+	}
+
+	return g.addSaferVersions(ctx, result), nil
+}
+
+// assess evaluates options and records each one's risk, blocking those
+// the policy doesn't allow.
+func (g *Generator) assess(ctx context.Context, options []Option) ([]Option, error) {
+	// Commands carry restored secrets, so they are redacted again before
+	// being sent for evaluation.
 	cmds := make([]string, len(options))
+	redactions := make([]*redaction, len(options))
 	for i, opt := range options {
 		cmds[i] = opt.Command
+		if !g.keepSecrets {
+			cmds[i], redactions[i] = redactSecrets(opt.Command)
+		}
 	}
 
 	risks, err := g.evaluator.Evaluate(ctx, cmds)
+	var unverified []bool
+	var incomplete *safety.IncompleteError
+	if errors.As(err, &incomplete) && len(risks) == len(cmds) {
+		slog.Warn("safety check incomplete, retrying the rest one at a time", "err", err)
+		unverified, err = g.retryEvaluations(ctx, cmds, risks, incomplete.Missing), nil
+	}
 	if err != nil {
+		if g.RequiresSafety() {
+			return blockAll(options, "policy requires a safety check, which failed"), err
+		}
 		return nil, err
 	}
 
 	result := make([]Option, len(options))
 	copy(result, options)
+	for i := range unverified {
+		if !unverified[i] {
+			continue
+		}
+		result[i].Unverified = true
+		if result[i].Blocked == "" && g.RequiresSafety() {
+			result[i].Blocked = "policy requires a safety check, which failed"
+		}
+	}
 	for i, risk := range risks {
 		if risk != nil && risk.Level != safety.RiskNone {
+			risk.Fragment = redactions[i].restore(risk.Fragment)
+			if risk.Safer != nil {
+				risk.Safer.Command = redactions[i].restore(risk.Safer.Command)
+				risk.Safer.Description = redactions[i].restore(risk.Safer.Description)
+			}
 			result[i].Risk = risk
+			if result[i].Blocked == "" && !g.policy.AllowsRisk(risk.Level) {
+				result[i].Blocked = fmt.Sprintf("policy forbids %s-risk commands", strings.ToLower(risk.Level.String()))
+			}
 		}
 	}
 
 	return result, nil
 }
+
+// retryEvaluations evaluates each command at missing on its own, after a
+// response that left them out, filling in their risks. It returns which
+// commands still have no verdict, indexed like cmds.
+func (g *Generator) retryEvaluations(ctx context.Context, cmds []string, risks []*safety.RiskInfo, missing []int) []bool {
+	unverified := make([]bool, len(cmds))
+	var wg sync.WaitGroup
+	for _, i := range missing {
+		wg.Go(func() {
+			retried, err := g.evaluator.Evaluate(ctx, cmds[i:i+1])
+			if err != nil || len(retried) != 1 {
+				slog.Warn("safety check failed", "command", cmds[i], "err", err)
+				unverified[i] = true
+				return
+			}
+			risks[i] = retried[0]
+		})
+	}
+	wg.Wait()
+	return unverified
+}
+
+// addSaferVersions inserts the safer alternatives the evaluator proposed
+// after the options they replace. The alternatives are assessed too; if
+// that fails they are left out, since their risk is unknown.
+func (g *Generator) addSaferVersions(ctx context.Context, options []Option) []Option {
+	var safer []Option
+	var after []int // index in options each alternative follows
+	for i, opt := range options {
+		if opt.Risk == nil || opt.Risk.Safer == nil || opt.SaferFor != "" {
+			continue
+		}
+
+		alt := opt
+		alt.Command = opt.Risk.Safer.Command
+		alt.Description = opt.Risk.Safer.Description
+		alt.SaferFor = opt.Title
+		alt.Recommended = false
+		alt.Risk, alt.Expression, alt.SyntaxError, alt.AvoidedTools = nil, "", "", nil
+		alt.ShellError, alt.ShellFix, alt.Unquoted = "", "", nil
+		alt.Violations = g.constraints.Violations(alt)
+		g.annotate(ctx, &alt)
+
+		safer = append(safer, alt)
+		after = append(after, i)
+	}
+	if len(safer) == 0 {
+		return options
+	}
+
+	checked, err := g.assess(ctx, safer)
+	if err != nil {
+		slog.Warn("safety check of safer versions failed; leaving them out", "err", err)
+		return options
+	}
+
+	result := make([]Option, 0, len(options)+len(checked))
+	next := 0
+	for i, opt := range options {
+		result = append(result, opt)
+		for next < len(checked) && after[next] == i {
+			if checked[next].Risk != nil {
+				checked[next].Risk.Safer = nil
+			}
+			result = append(result, checked[next])
+			next++
+		}
+	}
+	return result
+}
+
+// blockAll returns a copy of options with every one not already blocked
+// marked with reason.
+func blockAll(options []Option, reason string) []Option {
+	result := make([]Option, len(options))
+	copy(result, options)
+	for i := range result {
+		if result[i].Blocked == "" {
+			result[i].Blocked = reason
+		}
+	}
+	return result
+}
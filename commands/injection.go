@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"regexp"
+	"slices"
+)
+
+// injectionPatterns match text addressed to a model rather than a person:
+// attempts to override the prompt, change the model's role, or get a
+// particular command suggested. Logs rarely contain these by accident.
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,40}\b(previous|prior|above|earlier|all|your|system)\b.{0,20}\b(instructions?|prompts?|rules|context)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|real|actual)\s+instructions?\s*:`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+(now|no\s+longer)\b`),
+	regexp.MustCompile(`(?i)\b(system|developer)\s+prompt\b`),
+	regexp.MustCompile(`(?i)\b(AI|assistant|language\s+model|LLM|chatbot)s?\b.{0,40}\b(must|should|need\s+to)\b`),
+	regexp.MustCompile(`(?i)\b(suggest|recommend|respond\s+with|output|reply\s+with)\b.{0,30}\b(this|the\s+following)\s+command\b`),
+	regexp.MustCompile(`(?im)^\s*(system|assistant)\s*:`),
+	regexp.MustCompile(`<\|(im_start|im_end|system)\|>|\[/?INST\]`),
+}
+
+// suspiciousAttachments returns the sources of attachments containing
+// instruction-like text, in order and without repeats. Such text may be
+// an attempt to steer the generated commands through a log or web page.
+func suspiciousAttachments(attachments []Attachment) []string {
+	var sources []string
+	for _, a := range attachments {
+		if looksLikeInstructions(a.Text) && !slices.Contains(sources, a.Source) {
+			sources = append(sources, a.Source)
+		}
+	}
+	return sources
+}
+
+// looksLikeInstructions reports whether text matches any injection pattern.
+func looksLikeInstructions(text string) bool {
+	for _, re := range injectionPatterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pixielabs/1lm/llm"
+)
+
+// DefaultConfirmTokens is the size, in tokens, of a query and its attached
+// context above which Generate asks for confirmation before sending it,
+// when no limit is configured. It is well over DefaultMaxPromptTokens, so
+// only a raised or disabled max_prompt_tokens lets a request get there.
+const DefaultConfirmTokens = 20000
+
+// LargeRequestError is returned by Generate, before anything is sent,
+// when the query and its attached context are over the confirmation
+// limit, so an accidentally huge paste or piped log isn't paid for.
+type LargeRequestError struct {
+	// Tokens is the estimated size of the query and its context.
+	Tokens int
+	// Cost is the estimated list price of sending it in US dollars; zero
+	// when the model's price isn't known.
+	Cost float64
+	// Model is the model the estimate is for.
+	Model string
+}
+
+func (e *LargeRequestError) Error() string {
+	size := fmt.Sprintf("about %d tokens", e.Tokens)
+	if e.Cost > 0 {
+		size += fmt.Sprintf(" (~$%.2f on %s)", e.Cost, e.Model)
+	}
+	return fmt.Sprintf("query and attached context come to %s; set confirm_tokens = -1 to send large requests without asking", size)
+}
+
+// Public: Sets the size above which a query and its attached context are
+// confirmed before they are sent. Until ConfirmLargeRequests is called,
+// Generate returns a *LargeRequestError for such queries.
+//
+// limit - Tokens; 0 for DefaultConfirmTokens, negative never asks
+// model - The model the cost is estimated for
+func (g *Generator) SetConfirmLimit(limit int, model string) {
+	if limit == 0 {
+		limit = DefaultConfirmTokens
+	}
+	g.confirmTokens, g.model = limit, model
+}
+
+// Public: Lets Generate send queries over the confirmation limit, once
+// the user has agreed to the cost.
+func (g *Generator) ConfirmLargeRequests() {
+	g.confirmed.Store(true)
+}
+
+// checkSize returns a *LargeRequestError when query and the attached
+// context need confirming. The size is estimated locally, after trimming
+// to the token limit, so nothing is sent to count it.
+func (g *Generator) checkSize(ctx context.Context, query string) error {
+	if g.confirmTokens <= 0 || g.confirmed.Load() {
+		return nil
+	}
+
+	// Trimming is estimated the same way, and skipped when prepare skips
+	// it.
+	heuristic := llm.HeuristicTokenizer{}
+	var trimmer llm.Tokenizer
+	if g.tokenizer != nil {
+		trimmer = heuristic
+	}
+	assembled, _, err := fitAttachments(ctx, trimmer, g.maxTokens, query, g.attachments)
+	if err != nil {
+		// The query alone is over the token limit; prepare reports that.
+		return nil
+	}
+	tokens, _ := heuristic.CountTokens(ctx, assembled)
+	if tokens <= g.confirmTokens {
+		return nil
+	}
+
+	return &LargeRequestError{
+		Tokens: tokens,
+		Cost:   llm.Usage{InputTokens: int64(tokens)}.Cost(g.model),
+		Model:  g.model,
+	}
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/llm"
+)
+
+func TestGeneratorConfirmsLargeRequests(t *testing.T) {
+	logs := strings.Repeat("starting up\n", 8000) // about 24000 tokens
+
+	tests := []struct {
+		name     string
+		limit    int
+		budget   int // max_prompt_tokens; 0 for the default
+		query    string
+		context  string
+		confirm  bool
+		wantSent bool
+	}{
+		{name: "typed query", limit: 0, budget: -1, query: "free space", wantSent: true},
+		{name: "small context", limit: 0, budget: -1, query: "free space", context: "error: disk full", wantSent: true},
+		{name: "large context", limit: 0, budget: -1, query: "free space", context: logs},
+		{name: "large context raised budget", limit: 0, budget: 50000, query: "free space", context: logs},
+		{name: "large context trimmed to the default budget", limit: 0, query: "free space", context: logs, wantSent: true},
+		{name: "piped query", limit: 0, budget: -1, query: logs + "why is the disk full"},
+		{name: "confirmed", limit: 0, budget: -1, query: logs, confirm: true, wantSent: true},
+		{name: "never asks", limit: -1, budget: -1, query: logs, context: logs, wantSent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &llm.MockClient{Response: []llm.CommandOption{{Title: "Disk usage", Command: "df -h"}}}
+			gen := NewGeneratorWithEvaluator(mock, nil)
+			gen.SetTokenLimit(llm.HeuristicTokenizer{}, tt.budget)
+			gen.SetConfirmLimit(tt.limit, "claude-sonnet-4-5-20250929")
+			if tt.context != "" {
+				gen.AddContext("standard input", tt.context)
+			}
+			if tt.confirm {
+				gen.ConfirmLargeRequests()
+			}
+
+			_, err := gen.Generate(context.Background(), tt.query)
+			if sent := mock.LastQuery != ""; sent != tt.wantSent {
+				t.Fatalf("sent = %v, want %v (err %v)", sent, tt.wantSent, err)
+			}
+			if tt.wantSent {
+				if err != nil {
+					t.Errorf("Generate() error = %v", err)
+				}
+				return
+			}
+
+			var large *LargeRequestError
+			if !errors.As(err, &large) {
+				t.Fatalf("Generate() error = %v, want a LargeRequestError", err)
+			}
+			want := llm.Usage{InputTokens: int64(large.Tokens)}.Cost("claude-sonnet-4-5")
+			if large.Tokens <= tt.limit || large.Cost != want {
+				t.Errorf("estimate = %+v, want over the limit at $3 per million tokens", large)
+			}
+		})
+	}
+}
+
+func TestLargeRequestErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  LargeRequestError
+		want string
+	}{
+		{LargeRequestError{Tokens: 52000, Cost: 0.156, Model: "claude-sonnet-4-5"}, "about 52000 tokens (~$0.16 on claude-sonnet-4-5)"},
+		{LargeRequestError{Tokens: 52000, Model: "plugin:local"}, "about 52000 tokens;"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); !strings.Contains(got, tt.want) {
+			t.Errorf("Error() = %q, want it to contain %q", got, tt.want)
+		}
+	}
+}
@@ -9,4 +9,62 @@ type Option struct {
 	Command     string
 	Description string
 	Risk        *safety.RiskInfo // nil when no risk detected
+	// Unverified is set when the safety check gave no verdict for the
+	// command, even when asked about it alone, so a nil Risk doesn't mean
+	// it's safe.
+	Unverified bool
+
+	// Confidence is the model's confidence, from 0 to 100, that this is
+	// the command wanted; zero when unknown.
+	Confidence int
+	// Recommended marks the option the model is clearly most confident
+	// in. Options are listed in order of confidence, so it comes first.
+	Recommended bool
+
+	// AvoidedTools lists tools from the user's avoid_tools setting that the
+	// command uses anyway.
+	AvoidedTools []string
+	// Violations lists the constraints, such as no-sudo, the command
+	// breaks. Such options are only kept when every option breaks one.
+	Violations Constraints
+
+	// ShellError says why Command doesn't parse for the target shell.
+	// Such options can't be selected.
+	ShellError string
+	// ShellFix describes a change made so Command parses, such as closing
+	// an unbalanced quote.
+	ShellFix string
+	// Unquoted lists parameter expansions in Command, such as $file, that
+	// aren't quoted and so split on spaces.
+	Unquoted []string
+
+	// Expression is the DSL expression inside Command (e.g. the jq filter)
+	// when generated in a DSL mode.
+	Expression string
+	// SyntaxError describes why Expression failed local validation.
+	SyntaxError string
+
+	// Fallback names the fallback model that generated the option, when
+	// the primary model was unavailable.
+	Fallback string
+	// Source lists the models that suggested the option, in parallel mode.
+	Source string
+
+	// Blocked says why the policy forbids selecting the option; empty when
+	// it is allowed.
+	Blocked string
+
+	// Redacted lists the kinds of secret removed from the query before it
+	// was sent, e.g. "API key". Their values are restored in Command.
+	Redacted []string
+	// Trimmed lists the attached context, e.g. "standard input", that was
+	// cut to fit the token limit.
+	Trimmed []string
+	// Suspicious lists the attached context containing text that reads
+	// like instructions to a model, which may be steering the commands.
+	Suspicious []string
+
+	// SaferFor is the title of the high-risk option this is a safer
+	// version of, when the safety check proposed one.
+	SaferFor string
 }
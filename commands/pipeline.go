@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+// PipelineAnnotator is implemented by evaluators that can explain a
+// command one segment at a time, such as *safety.Evaluator.
+type PipelineAnnotator interface {
+	Annotate(ctx context.Context, command string, segments []string) ([]safety.SegmentNote, error)
+}
+
+// Segment is one stage of a pipeline or command list.
+type Segment struct {
+	// Operator joins the segment to the one before: "|", "&&", "||" or
+	// ";". It is empty for the first segment.
+	Operator string
+	Command  string
+	Note     safety.SegmentNote
+}
+
+// Public: Splits a command line at |, &&, || and ;, leaving separators
+// inside quotes, subshells and command substitutions alone. Newlines
+// count as ;.
+//
+// Returns the segments in order; a command without separators is a single
+// segment.
+func SplitPipeline(command string) []Segment {
+	var segments []Segment
+	var current strings.Builder
+	operator := ""
+	var quote rune
+	depth := 0
+	escaped := false
+
+	flush := func(next string) {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			segments = append(segments, Segment{Operator: operator, Command: text})
+			operator = next
+		}
+		current.Reset()
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth > 0:
+		case r == '|' || r == '&' || r == ';' || r == '\n':
+			next := i+1 < len(runes) && runes[i+1] == r
+			switch {
+			case r == '|' && next:
+				flush("||")
+				i++
+				continue
+			case r == '|':
+				flush("|")
+				// |& pipes stderr too.
+				if i+1 < len(runes) && runes[i+1] == '&' {
+					i++
+				}
+				continue
+			case r == '&' && next:
+				flush("&&")
+				i++
+				continue
+			case r == ';' || r == '\n':
+				flush(";")
+				continue
+			}
+		}
+		current.WriteRune(r)
+	}
+	flush("")
+
+	if len(segments) > 0 {
+		segments[0].Operator = ""
+	}
+	return segments
+}
+
+// Public: Explains a command segment by segment, for the pipeline view.
+// Secrets are redacted before sending, and restored in the notes.
+//
+// Returns an error if the evaluator can't annotate commands.
+func (g *Generator) AnnotatePipeline(ctx context.Context, command string) ([]Segment, error) {
+	annotator, ok := g.evaluator.(PipelineAnnotator)
+	if !ok {
+		return nil, fmt.Errorf("pipeline annotation isn't available with this setup")
+	}
+
+	var redacted *redaction
+	if !g.keepSecrets {
+		command, redacted = redactSecrets(command)
+	}
+
+	segments := SplitPipeline(command)
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Command
+	}
+
+	notes, err := annotator.Annotate(ctx, command, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to annotate pipeline: %w", err)
+	}
+	if len(notes) != len(segments) {
+		return nil, fmt.Errorf("expected %d segment notes, got %d", len(segments), len(notes))
+	}
+
+	for i, note := range notes {
+		segments[i].Command = redacted.restore(segments[i].Command)
+		segments[i].Note = safety.SegmentNote{
+			Input:       redacted.restore(note.Input),
+			Output:      redacted.restore(note.Output),
+			Explanation: redacted.restore(note.Explanation),
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// secretPattern finds one kind of secret. When group is set, only that
+// submatch is redacted, so "password=hunter2" keeps its "password=".
+type secretPattern struct {
+	kind  string // placeholder name, e.g. API_KEY
+	label string // what the user is told was redacted
+	re    *regexp.Regexp
+	group int
+}
+
+// secretPatterns are checked in order; earlier, more specific patterns
+// win over later, looser ones.
+var secretPatterns = []secretPattern{
+	{kind: "PRIVATE_KEY", label: "private key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{kind: "API_KEY", label: "API key", re: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: "API_KEY", label: "API key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{kind: "AWS_KEY", label: "AWS access key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "TOKEN", label: "token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+	{kind: "TOKEN", label: "token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{kind: "TOKEN", label: "token", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{kind: "TOKEN", label: "token", re: regexp.MustCompile(`(?i)\bBearer\s+([A-Za-z0-9._~+/=-]{16,})`), group: 1},
+	{kind: "PASSWORD", label: "password", re: regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`), group: 1},
+	{kind: "SECRET", label: "secret", re: regexp.MustCompile(`(?i)\b[A-Z0-9_]*(?:password|passwd|pwd|secret|token|api_?key|access_?key)[A-Z0-9_]*(?:\s*[:=]\s*|\s+is\s+)["']?([^\s"']{4,})`), group: 1},
+	{kind: "PRIVATE_IP", label: "private IP address", re: regexp.MustCompile(`\b(?:10\.\d{1,3}|172\.(?:1[6-9]|2\d|3[01])|192\.168)\.\d{1,3}\.\d{1,3}\b`)},
+}
+
+// placeholderPattern matches placeholders inserted by redactSecrets.
+var placeholderPattern = regexp.MustCompile(`REDACTED_[A-Z_]+?_\d+`)
+
+// redaction records what redactSecrets replaced, so the originals can be
+// put back into generated commands, which never leave the machine.
+type redaction struct {
+	originals map[string]string // placeholder → original text
+	labels    []string          // kinds found, in order of first appearance
+	byValue   map[string]string // original text → placeholder
+	counts    map[string]int    // placeholders handed out per kind
+}
+
+func newRedaction() *redaction {
+	return &redaction{originals: map[string]string{}, byValue: map[string]string{}, counts: map[string]int{}}
+}
+
+// redactSecrets replaces things that look like credentials or private IP
+// addresses with placeholders such as REDACTED_API_KEY_1. The same value
+// always gets the same placeholder.
+func redactSecrets(text string) (string, *redaction) {
+	r := newRedaction()
+	return r.redact(text), r
+}
+
+// Public: Replaces things that look like credentials or private IP
+// addresses in text with placeholders, for text that is stored or shared
+// rather than sent with a query. Redacting text twice changes nothing.
+//
+// Returns the redacted text.
+func RedactSecrets(text string) string {
+	redacted, _ := redactSecrets(text)
+	return redacted
+}
+
+// redact replaces the secrets in text, continuing r's numbering, so
+// several texts redacted in turn share placeholders for the same value.
+func (r *redaction) redact(text string) string {
+	byValue, counts := r.byValue, r.counts
+	for _, p := range secretPatterns {
+		text = replaceGroup(p.re, text, p.group, func(secret string) string {
+			// Values already replaced by an earlier pattern stay as they are.
+			if placeholderPattern.MatchString(secret) {
+				return secret
+			}
+			if placeholder, ok := byValue[secret]; ok {
+				return placeholder
+			}
+
+			counts[p.kind]++
+			placeholder := fmt.Sprintf("REDACTED_%s_%d", p.kind, counts[p.kind])
+			byValue[secret] = placeholder
+			r.originals[placeholder] = secret
+			if !slices.Contains(r.labels, p.label) {
+				r.labels = append(r.labels, p.label)
+			}
+			return placeholder
+		})
+	}
+
+	return text
+}
+
+// replaceGroup replaces submatch group of every match of re in s with
+// repl's result; group 0 replaces the whole match.
+func replaceGroup(re *regexp.Regexp, s string, group int, repl func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(repl(s[start:end]))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// restore puts the original values back in place of their placeholders.
+func (r *redaction) restore(s string) string {
+	if r == nil || len(r.originals) == 0 {
+		return s
+	}
+	return placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if original, ok := r.originals[placeholder]; ok {
+			return original
+		}
+		return placeholder
+	})
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shell identifies a shell that commands can be generated for.
+type Shell string
+
+const (
+	ShellSh         Shell = "sh"
+	ShellBash       Shell = "bash"
+	ShellZsh        Shell = "zsh"
+	ShellFish       Shell = "fish"
+	ShellPowerShell Shell = "powershell"
+	ShellNushell    Shell = "nushell"
+)
+
+// shellAliases maps accepted names, including executable names, to shells.
+var shellAliases = map[string]Shell{
+	"sh":         ShellSh,
+	"posix":      ShellSh,
+	"bash":       ShellBash,
+	"zsh":        ShellZsh,
+	"fish":       ShellFish,
+	"powershell": ShellPowerShell,
+	"pwsh":       ShellPowerShell,
+	"nushell":    ShellNushell,
+	"nu":         ShellNushell,
+}
+
+// Public: Parses a shell name such as "bash", "pwsh" or "nu",
+// case-insensitively.
+//
+// Returns the Shell, or an error listing the supported shells.
+func ParseShell(name string) (Shell, error) {
+	if sh, ok := shellAliases[strings.ToLower(name)]; ok {
+		return sh, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (want sh, bash, zsh, fish, powershell or nushell)", name)
+}
+
+// Public: Returns the shell's name as the model should see it.
+func (s Shell) DisplayName() string {
+	switch s {
+	case ShellSh:
+		return "POSIX sh"
+	case ShellPowerShell:
+		return "PowerShell"
+	case ShellNushell:
+		return "Nushell"
+	default:
+		return string(s)
+	}
+}
+
+// Public: Returns the executable that runs scripts for the shell, for a
+// "#!/usr/bin/env" line. The zero Shell runs as bash.
+func (s Shell) Interpreter() string {
+	switch s {
+	case "":
+		return "bash"
+	case ShellPowerShell:
+		return "pwsh"
+	case ShellNushell:
+		return "nu"
+	default:
+		return string(s)
+	}
+}
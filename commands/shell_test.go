@@ -0,0 +1,32 @@
+package commands
+
+import "testing"
+
+func TestParseShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Shell
+		wantErr bool
+	}{
+		{name: "bash", want: ShellBash},
+		{name: "ZSH", want: ShellZsh},
+		{name: "pwsh", want: ShellPowerShell},
+		{name: "PowerShell", want: ShellPowerShell},
+		{name: "nu", want: ShellNushell},
+		{name: "posix", want: ShellSh},
+		{name: "cmd", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseShell(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseShell(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseShell(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/pixielabs/1lm/llm"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// shellCheck is what parsing a command for its shell found.
+type shellCheck struct {
+	// Command is the command, with an unbalanced quote closed if that was
+	// all that kept it from parsing.
+	Command string
+	// Fix describes the change made to Command, or is empty.
+	Fix string
+	// Err describes why the command doesn't parse, or is empty.
+	Err string
+	// Unquoted lists parameter expansions, such as $file, that are subject
+	// to word splitting and globbing.
+	Unquoted []string
+}
+
+// checkShell parses command with the grammar of sh. Only POSIX sh and bash
+// can be checked in full: zsh is parsed as bash, but only input the parser
+// ran out of, such as an unclosed quote, counts against it, and zsh doesn't
+// split unquoted parameters. Other shells aren't checked.
+func checkShell(command string, sh Shell) shellCheck {
+	check := shellCheck{Command: command}
+
+	lang := syntax.LangBash
+	switch sh {
+	case ShellSh:
+		lang = syntax.LangPOSIX
+	case ShellBash, ShellZsh, "":
+	default:
+		return check
+	}
+
+	file, err := parseShell(command, lang)
+	if err != nil && syntax.IsIncomplete(err) {
+		for _, quote := range []string{"'", `"`} {
+			if f, ferr := parseShell(command+quote, lang); ferr == nil {
+				check.Command = command + quote
+				check.Fix = "closed an unbalanced " + quote + " quote"
+				file, err = f, nil
+				break
+			}
+		}
+	}
+	if err != nil {
+		if sh != ShellZsh || syntax.IsIncomplete(err) {
+			check.Err = err.Error()
+		}
+		return check
+	}
+
+	if sh != ShellZsh {
+		check.Unquoted = unquotedParams(file, check.Command)
+	}
+	return check
+}
+
+// parseShell parses src as a whole program in lang.
+func parseShell(src string, lang syntax.LangVariant) (*syntax.File, error) {
+	return syntax.NewParser(syntax.Variant(lang)).Parse(strings.NewReader(src), "")
+}
+
+// numericParams are special parameters that always expand to a single
+// word, so leaving them unquoted is harmless.
+var numericParams = []string{"#", "?", "$", "!"}
+
+// unquotedParams returns the parameter expansions in command arguments and
+// redirections that aren't quoted, as written in src, each once.
+func unquotedParams(file *syntax.File, src string) []string {
+	var found []string
+	check := func(word *syntax.Word) {
+		if word == nil {
+			return
+		}
+		for _, part := range word.Parts {
+			pe, ok := part.(*syntax.ParamExp)
+			if !ok || pe.Length || pe.Param == nil || slices.Contains(numericParams, pe.Param.Value) {
+				continue
+			}
+			text := src[pe.Pos().Offset():pe.End().Offset()]
+			if !slices.Contains(found, text) {
+				found = append(found, text)
+			}
+		}
+	}
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.CallExpr:
+			for _, arg := range node.Args {
+				check(arg)
+			}
+		case *syntax.Redirect:
+			check(node.Word)
+		}
+		return true
+	})
+	return found
+}
+
+// replaceInvalid asks the model once more for commands to take the place
+// of options that don't parse. Replacements that parse and aren't already
+// offered are swapped in, in order; options left without one stay,
+// flagged with their ShellError.
+//
+// query   - The query as sent, with secrets redacted
+// raw     - The model's options, as sent back
+// options - raw converted to options, in the same order
+// convert - Converts the model's options as for the first answer
+func (g *Generator) replaceInvalid(ctx context.Context, query string, raw []llm.CommandOption, options []Option, convert func([]llm.CommandOption) []Option) []Option {
+	var invalid []string
+	for i, opt := range options {
+		if opt.ShellError != "" {
+			invalid = append(invalid, raw[i].Command)
+		}
+	}
+	if len(invalid) == 0 {
+		return options
+	}
+
+	more, err := g.client.GenerateOptions(ctx, replacementQuery(query, invalid, g.shell))
+	if err != nil {
+		slog.Warn("failed to replace options with invalid syntax", "err", err)
+		return options
+	}
+
+	offered := func(list []Option, cmd string) bool {
+		return slices.ContainsFunc(list, func(o Option) bool { return o.Command == cmd })
+	}
+	var replacements []Option
+	for _, opt := range convert(more) {
+		if opt.ShellError == "" && !offered(options, opt.Command) && !offered(replacements, opt.Command) {
+			replacements = append(replacements, opt)
+		}
+	}
+	slog.Debug("replaced options with invalid syntax", "invalid", len(invalid), "replacements", len(replacements))
+
+	result := slices.Clone(options)
+	for i := range result {
+		if result[i].ShellError != "" && len(replacements) > 0 {
+			result[i], replacements = replacements[0], replacements[1:]
+		}
+	}
+	return result
+}
+
+// replacementQuery asks again for query, naming the commands that didn't
+// parse so the model gives different ones.
+func replacementQuery(query string, invalid []string, sh Shell) string {
+	if sh == "" {
+		sh = ShellBash
+	}
+
+	var b strings.Builder
+	b.WriteString(query)
+	fmt.Fprintf(&b, "\n\nThese commands are not valid %s syntax. Give different commands that are:", sh.DisplayName())
+	for _, cmd := range invalid {
+		fmt.Fprintf(&b, "\n%s", cmd)
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/llm"
+)
+
+func TestCheckShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		shell    Shell
+		want     string
+		fix      bool
+		err      bool
+		unquoted []string
+	}{
+		{name: "valid", command: `find . -name "*.go" | xargs wc -l`, shell: ShellBash, want: `find . -name "*.go" | xargs wc -l`},
+		{name: "unbalanced single quote", command: `grep 'foo bar`, shell: ShellBash, want: `grep 'foo bar'`, fix: true},
+		{name: "unbalanced double quote", command: `echo "hello`, shell: ShellSh, want: `echo "hello"`, fix: true},
+		{name: "invalid syntax", command: `ls | | wc -l`, shell: ShellBash, want: `ls | | wc -l`, err: true},
+		{name: "bash-only syntax in sh", command: `diff <(ls a) <(ls b)`, shell: ShellSh, want: `diff <(ls a) <(ls b)`, err: true},
+		{name: "unquoted parameters", command: `cp $src "$dst" > $log; echo $? ${#src}`, shell: ShellBash, want: `cp $src "$dst" > $log; echo $? ${#src}`, unquoted: []string{"$src", "$log"}},
+		{name: "zsh glob qualifier", command: `ls *(.)`, shell: ShellZsh, want: `ls *(.)`},
+		{name: "zsh doesn't split", command: `rm $file`, shell: ShellZsh, want: `rm $file`},
+		{name: "fish isn't checked", command: `echo (date`, shell: ShellFish, want: `echo (date`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkShell(tt.command, tt.shell)
+			if got.Command != tt.want {
+				t.Errorf("Command = %q, want %q", got.Command, tt.want)
+			}
+			if (got.Fix != "") != tt.fix {
+				t.Errorf("Fix = %q, want fix %v", got.Fix, tt.fix)
+			}
+			if (got.Err != "") != tt.err {
+				t.Errorf("Err = %q, want error %v", got.Err, tt.err)
+			}
+			if !reflect.DeepEqual(got.Unquoted, tt.unquoted) {
+				t.Errorf("Unquoted = %q, want %q", got.Unquoted, tt.unquoted)
+			}
+		})
+	}
+}
+
+// sequenceClient answers each call with the next response.
+type sequenceClient struct {
+	responses [][]llm.CommandOption
+	queries   []string
+}
+
+func (c *sequenceClient) GenerateOptions(_ context.Context, query string) ([]llm.CommandOption, error) {
+	c.queries = append(c.queries, query)
+	if len(c.queries) > len(c.responses) {
+		return nil, errors.New("no more responses")
+	}
+	return c.responses[len(c.queries)-1], nil
+}
+
+func TestGenerateReplacesInvalidSyntax(t *testing.T) {
+	client := &sequenceClient{responses: [][]llm.CommandOption{
+		{{Title: "Count", Command: "ls | | wc -l"}, {Title: "List", Command: "ls -la"}},
+		{{Title: "Again", Command: "ls -la"}, {Title: "Count", Command: "ls | wc -l"}},
+	}}
+	g := NewGeneratorWithEvaluator(client, nil)
+	g.SetShell(ShellBash)
+
+	options, err := g.Generate(context.Background(), "count files")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(client.queries) != 2 || !strings.Contains(client.queries[1], "ls | | wc -l") {
+		t.Fatalf("queries = %q, want a second naming the invalid command", client.queries)
+	}
+	var got []string
+	for _, opt := range options {
+		got = append(got, opt.Command)
+	}
+	if want := []string{"ls | wc -l", "ls -la"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateKeepsInvalidWithoutReplacement(t *testing.T) {
+	client := &sequenceClient{responses: [][]llm.CommandOption{
+		{{Title: "Count", Command: "ls | | wc -l"}},
+	}}
+	g := NewGeneratorWithEvaluator(client, nil)
+
+	options, err := g.Generate(context.Background(), "count files")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(options) != 1 || options[0].ShellError == "" {
+		t.Errorf("options = %+v, want the invalid option kept and flagged", options)
+	}
+}
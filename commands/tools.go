@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ToolPolicy records the user's tool preferences. Preferred tools are only
+// suggested to the model; avoided tools are also checked after generation.
+type ToolPolicy struct {
+	Preferred []string
+	Avoid     []string
+}
+
+// Public: Flags options that use avoided tools and moves them after the
+// options that don't, keeping the relative order within each group. The
+// model can't always avoid a tool, so flagged options are kept rather than
+// dropped.
+//
+// options - Generated options, which are not modified
+//
+// Returns the reordered options.
+func (p ToolPolicy) Apply(options []Option) []Option {
+	if len(p.Avoid) == 0 {
+		return options
+	}
+
+	var clean, flagged []Option
+	for _, opt := range options {
+		opt.AvoidedTools = nil
+		for _, bin := range UsedBinaries(opt.Command) {
+			if slices.Contains(p.Avoid, bin) && !slices.Contains(opt.AvoidedTools, bin) {
+				opt.AvoidedTools = append(opt.AvoidedTools, bin)
+			}
+		}
+
+		if len(opt.AvoidedTools) > 0 {
+			flagged = append(flagged, opt)
+		} else {
+			clean = append(clean, opt)
+		}
+	}
+
+	return append(clean, flagged...)
+}
+
+// segmentSeparator splits a command line into the simple commands it runs:
+// pipelines, lists, newlines and command substitutions. A lone "&" is left
+// alone so redirections like 2>&1 aren't split.
+var segmentSeparator = regexp.MustCompile("\\|\\||&&|[|;\\n]|\\$\\(|`")
+
+// Public: Returns every program a command runs, including wrappers such as
+// sudo and the commands in each pipeline segment, in order of appearance.
+//
+// Unlike PrimaryBinary, wrappers are reported too, so a policy can avoid
+// sudo itself.
+func UsedBinaries(command string) []string {
+	var bins []string
+	for _, segment := range segmentSeparator.Split(command, -1) {
+		bins = append(bins, segmentWrappers(segment)...)
+		if bin := PrimaryBinary(segment); bin != "" {
+			bins = append(bins, bin)
+		}
+	}
+	return bins
+}
+
+// segmentWrappers returns the wrapper commands at the start of a segment.
+func segmentWrappers(segment string) []string {
+	var wrappers []string
+	var wrapperFlags []string
+	skipNext := false
+	for _, word := range strings.Fields(segment) {
+		if skipNext {
+			skipNext = false
+			continue
+		}
+
+		if flags, ok := wrapperCommands[word]; ok {
+			wrappers = append(wrappers, word)
+			wrapperFlags = flags
+			continue
+		}
+
+		switch {
+		case envAssignment.MatchString(word):
+			continue
+		case strings.HasPrefix(word, "-"):
+			skipNext = slices.Contains(wrapperFlags, word)
+			continue
+		}
+
+		return wrappers
+	}
+	return wrappers
+}
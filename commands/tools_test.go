@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUsedBinaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{name: "simple", command: "grep -r foo .", want: []string{"grep"}},
+		{name: "pipeline", command: "find . -name '*.go' | xargs grep foo", want: []string{"find", "xargs"}},
+		{name: "wrapper", command: "sudo -u www find /var", want: []string{"sudo", "find"}},
+		{name: "list", command: "make build && sudo make install", want: []string{"make", "sudo", "make"}},
+		{name: "substitution", command: "kill $(pgrep node)", want: []string{"kill", "pgrep"}},
+		{name: "redirection", command: "make test 2>&1 | tee log", want: []string{"make", "tee"}},
+		{name: "empty", command: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UsedBinaries(tt.command); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UsedBinaries(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolPolicyApply(t *testing.T) {
+	options := []Option{
+		{Title: "Sudo find", Command: "sudo find / -name x"},
+		{Title: "Grep", Command: "grep -r x ."},
+		{Title: "Ripgrep", Command: "rg x"},
+	}
+
+	policy := ToolPolicy{Avoid: []string{"sudo", "grep"}}
+	got := policy.Apply(options)
+
+	var titles []string
+	for _, opt := range got {
+		titles = append(titles, opt.Title)
+	}
+	wantTitles := []string{"Ripgrep", "Sudo find", "Grep"}
+	if !reflect.DeepEqual(titles, wantTitles) {
+		t.Errorf("Apply() order = %v, want %v", titles, wantTitles)
+	}
+
+	if !reflect.DeepEqual(got[1].AvoidedTools, []string{"sudo"}) {
+		t.Errorf("Apply() AvoidedTools = %v, want [sudo]", got[1].AvoidedTools)
+	}
+	if got[0].AvoidedTools != nil {
+		t.Errorf("Apply() flagged %q, want no avoided tools", got[0].Title)
+	}
+	if options[0].AvoidedTools != nil {
+		t.Error("Apply() modified its input")
+	}
+}
+
+func TestToolPolicyApplyEmpty(t *testing.T) {
+	options := []Option{{Title: "A", Command: "sudo ls"}}
+	if got := (ToolPolicy{}).Apply(options); !reflect.DeepEqual(got, options) {
+		t.Errorf("Apply() with no policy = %v, want unchanged", got)
+	}
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/llm"
+)
+
+// DefaultMaxPromptTokens is the token budget for a query and its attached
+// context when none is configured.
+const DefaultMaxPromptTokens = 8000
+
+// charsPerToken is the heuristic tokenizer's estimate of bytes per token,
+// used to cut attachments close to the limit before they are counted.
+const charsPerToken = 4
+
+// minAttachment is the smallest trimmed attachment worth keeping; anything
+// shorter is dropped whole.
+const minAttachment = 100
+
+// Public: Sets the tokenizer and token limit the query and its attached
+// context are fitted to before sending. Attachments are trimmed, oldest
+// first, keeping the end of each, until the prompt fits.
+//
+// tokenizer - Counts tokens for the model; nil disables the check
+// limit     - Maximum tokens; 0 for the default, negative for no limit
+func (g *Generator) SetTokenLimit(tokenizer llm.Tokenizer, limit int) {
+	if limit == 0 {
+		limit = DefaultMaxPromptTokens
+	}
+	g.tokenizer, g.maxTokens = tokenizer, limit
+}
+
+// fitAttachments assembles query and attachments within limit tokens,
+// trimming attachments in the order they were added. Each is cut from its
+// start, since the end of a log or error output matters most, and dropped
+// when little would be left.
+//
+// Without a tokenizer nothing is counted, so each attachment is capped at
+// maxAttachment bytes instead; a negative limit sends them whole.
+//
+// Returns the assembled query, the sources that were trimmed, and an error
+// if the query alone is over the limit. Counting is best-effort: if it
+// fails, attachments are capped as without a tokenizer.
+func fitAttachments(ctx context.Context, tokenizer llm.Tokenizer, limit int, query string, attachments []Attachment) (string, []string, error) {
+	capped := withAttachments(query, capAttachments(attachments, maxAttachment))
+	if tokenizer == nil {
+		return capped, nil, nil
+	}
+	full := withAttachments(query, attachments)
+	if limit < 0 || len(attachments) == 0 {
+		return full, nil, nil
+	}
+
+	// The heuristic is free, so a prompt well under the limit doesn't need
+	// an exact count.
+	if estimate, _ := (llm.HeuristicTokenizer{}).CountTokens(ctx, full); estimate <= limit/2 {
+		return full, nil, nil
+	}
+
+	// No attachment can keep more than the whole limit, so each starts cut
+	// to what the heuristic puts there, rather than be counted whole.
+	remaining := capAttachments(attachments, limit*charsPerToken)
+
+	var trimmed []string
+	for i := 0; ; {
+		assembled := withAttachments(query, remaining)
+		n, err := tokenizer.CountTokens(ctx, assembled)
+		if err != nil {
+			return capped, nil, nil
+		}
+		if n <= limit {
+			return assembled, trimmed, nil
+		}
+
+		for i < len(remaining) && remaining[i].Text == "" {
+			i++
+		}
+		if i == len(remaining) {
+			return "", nil, fmt.Errorf("query is %d tokens, over the limit of %d", n, limit)
+		}
+
+		a := &remaining[i]
+		if len(trimmed) == 0 || trimmed[len(trimmed)-1] != a.Source {
+			trimmed = append(trimmed, a.Source)
+		}
+
+		size, err := tokenizer.CountTokens(ctx, a.Text)
+		if err != nil {
+			return capped, nil, nil
+		}
+		keep := 0
+		if excess := n - limit; size > excess {
+			// Bytes per token vary, so aim a little under the proportional
+			// share and let the next count confirm it.
+			keep = len(a.Text) * (size - excess) / size * 9 / 10
+		}
+		if keep < minAttachment {
+			a.Text = ""
+			continue
+		}
+		a.Text = truncateHead(a.Text, keep)
+	}
+}
+
+// capAttachments returns attachments with surrounding space removed and
+// each cut to at most n bytes, keeping its end.
+func capAttachments(attachments []Attachment, n int) []Attachment {
+	capped := make([]Attachment, len(attachments))
+	for i, a := range attachments {
+		capped[i] = Attachment{Source: a.Source, Text: truncateHead(strings.TrimSpace(a.Text), n)}
+	}
+	return capped
+}
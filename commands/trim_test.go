@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/llm"
+)
+
+func TestFitAttachments(t *testing.T) {
+	logs := strings.Repeat("starting up\n", 200) + "error: disk full"
+	tests := []struct {
+		name        string
+		limit       int
+		attachments []Attachment
+		wantTrimmed []string
+		wantErr     bool
+		check       func(t *testing.T, got string)
+	}{
+		{
+			name:        "under the limit",
+			limit:       2000,
+			attachments: []Attachment{{Source: "standard input", Text: logs}},
+			check: func(t *testing.T, got string) {
+				if got != withAttachments("free space", []Attachment{{Source: "standard input", Text: logs}}) {
+					t.Errorf("prompt was changed: %q", got)
+				}
+			},
+		},
+		{
+			name:        "no limit",
+			limit:       -1,
+			attachments: []Attachment{{Source: "standard input", Text: logs}},
+		},
+		{
+			name:        "keeps the tail",
+			limit:       300,
+			attachments: []Attachment{{Source: "standard input", Text: logs}},
+			wantTrimmed: []string{"standard input"},
+			check: func(t *testing.T, got string) {
+				if !strings.HasSuffix(got, "error: disk full\n</context>") {
+					t.Errorf("prompt lost the end of the logs: %q", got)
+				}
+				if !strings.Contains(got, "<context source=\"standard input\">\n…") {
+					t.Errorf("prompt doesn't mark the cut: %q", got)
+				}
+			},
+		},
+		{
+			name:  "oldest dropped first",
+			limit: 80,
+			attachments: []Attachment{
+				{Source: "clipboard", Text: strings.Repeat("copied ", 100)},
+				{Source: "standard input", Text: "error: disk full"},
+			},
+			wantTrimmed: []string{"clipboard"},
+			check: func(t *testing.T, got string) {
+				if strings.Contains(got, "clipboard") {
+					t.Errorf("clipboard wasn't dropped: %q", got)
+				}
+				if !strings.HasSuffix(got, "<context source=\"standard input\">\nerror: disk full\n</context>") {
+					t.Errorf("standard input was trimmed: %q", got)
+				}
+			},
+		},
+		{
+			name:        "query alone too long",
+			limit:       1,
+			attachments: []Attachment{{Source: "standard input", Text: logs}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, trimmed, err := fitAttachments(context.Background(), llm.HeuristicTokenizer{}, tt.limit, "free space", tt.attachments)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fitAttachments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(trimmed, tt.wantTrimmed) {
+				t.Errorf("trimmed = %v, want %v", trimmed, tt.wantTrimmed)
+			}
+			if n, _ := (llm.HeuristicTokenizer{}).CountTokens(context.Background(), got); tt.limit > 0 && n > tt.limit {
+				t.Errorf("prompt is %d tokens, over the limit of %d", n, tt.limit)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
+
+func TestGeneratorTrimsContext(t *testing.T) {
+	mock := &llm.MockClient{Response: []llm.CommandOption{{Title: "Disk usage", Command: "df -h"}}}
+	gen := NewGeneratorWithEvaluator(mock, nil)
+	gen.AddContext("standard input", strings.Repeat("starting up\n", 300))
+	gen.SetTokenLimit(llm.HeuristicTokenizer{}, 100)
+
+	options, err := gen.Generate(context.Background(), "free space")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !slices.Equal(options[0].Trimmed, []string{"standard input"}) {
+		t.Errorf("Trimmed = %v, want [standard input]", options[0].Trimmed)
+	}
+	if len(mock.LastQuery) > 400 {
+		t.Errorf("sent %d bytes, want the context trimmed", len(mock.LastQuery))
+	}
+}
+
+// recordingTokenizer counts like the heuristic and keeps every text it
+// was asked to count.
+type recordingTokenizer struct {
+	texts []string
+}
+
+func (r *recordingTokenizer) CountTokens(ctx context.Context, text string) (int, error) {
+	r.texts = append(r.texts, text)
+	return llm.HeuristicTokenizer{}.CountTokens(ctx, text)
+}
+
+func TestGeneratorRedactsBeforeCounting(t *testing.T) {
+	const secret = "sk-ant-REDACTED"
+	mock := &llm.MockClient{Response: []llm.CommandOption{{Title: "Disk usage", Command: "df -h"}}}
+	gen := NewGeneratorWithEvaluator(mock, nil)
+	gen.AddContext("clipboard", strings.Repeat("starting up\n", 300)+"ANTHROPIC_API_KEY="+secret)
+	tokenizer := &recordingTokenizer{}
+	gen.SetTokenLimit(tokenizer, 200)
+
+	if _, err := gen.Generate(context.Background(), "free space"); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(tokenizer.texts) == 0 {
+		t.Fatal("tokenizer wasn't asked to count, want the context over half the limit")
+	}
+	for _, text := range tokenizer.texts {
+		if strings.Contains(text, secret) {
+			t.Fatalf("tokenizer saw the secret in %q", text)
+		}
+	}
+	if strings.Contains(mock.LastQuery, secret) {
+		t.Errorf("query sent = %q, want the secret redacted", mock.LastQuery)
+	}
+}
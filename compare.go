@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// compareArgs matches "compare --models a,b <query>". Without --models,
+// "1lm compare file sizes" is a query.
+func compareArgs(args []string) bool {
+	return len(args) > 0 && *compareModels != ""
+}
+
+// runCompare generates options for the query from each model in --models
+// and shows them side by side. Entries are Anthropic models or
+// "plugin:name", as in fallback_models.
+func runCompare(cfg *config.Config, settings ui.Settings, args []string) error {
+	if *offline {
+		return fmt.Errorf("compare queries models directly, so it can't be used with --offline")
+	}
+
+	var names []string
+	for _, name := range strings.Split(*compareModels, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) < 2 {
+		return fmt.Errorf("--models needs at least two models to compare")
+	}
+
+	prompt, err := loadPrompt(cfg)
+	if err != nil {
+		return err
+	}
+	attachments, err := readContext()
+	if err != nil {
+		return err
+	}
+	evaluator := newEvaluator(cfg)
+
+	columns := make([]ui.CompareColumn, len(names))
+	for i, name := range names {
+		mc, err := newModelClient(cfg, name, prompt)
+		if err != nil {
+			return err
+		}
+
+		generator := commands.NewGeneratorWithEvaluator(mc.Client, evaluator)
+		if err := configureGenerator(cfg, generator, attachments); err != nil {
+			return err
+		}
+		columns[i] = ui.CompareColumn{Name: name, Generator: generator}
+	}
+
+	finalModel, err := runUI(ui.NewCompareModel(columns, strings.Join(args, " "), settings), settings)
+	if err != nil {
+		return err
+	}
+
+	compareModel, ok := finalModel.(ui.CompareModel)
+	if !ok {
+		return nil
+	}
+	query := strings.Join(args, " ")
+	return release(cfg, query, compareModel.Options(), compareModel.Selected(), func() error {
+		return emit(query, compareModel.Selected(), settings)
+	})
+}
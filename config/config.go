@@ -2,37 +2,93 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/pixielabs/1lm/policy"
 )
 
 // Config represents the application configuration.
 type Config struct {
-	AnthropicAPIKey string `toml:"anthropic_api_key"`
-	Model           string `toml:"model"`
-	Provider        string `toml:"provider"`
+	AnthropicAPIKey   string    `toml:"anthropic_api_key"`
+	AnthropicAPIKeys  []string  `toml:"anthropic_api_keys"` // more keys to spread requests across
+	KeyRotation       string    `toml:"key_rotation"`       // "round-robin" (default) or "on-rate-limit"
+	Model             string    `toml:"model"`
+	Provider          string    `toml:"provider"`       // "anthropic", "vertex" or "plugin:name"
+	VertexProject     string    `toml:"vertex_project"` // Google Cloud project for provider "vertex"
+	VertexRegion      string    `toml:"vertex_region"`  // e.g. "us-east5" or "global"
+	LowPower          string    `toml:"low_power"`      // "auto", "on" or "off"
+	Accessible        bool      `toml:"accessible"`
+	AltScreen         bool      `toml:"alt_screen"`
+	Preview           bool      `toml:"preview"`
+	Mouse             bool      `toml:"mouse"`
+	PromptTemplate    string    `toml:"prompt_template"` // Path to a text/template file
+	Examples          []Example `toml:"examples"`
+	PreferredTools    []string  `toml:"preferred_tools"`
+	AvoidTools        []string  `toml:"avoid_tools"`
+	Constraints       []string  `toml:"constraints"`
+	Language          string    `toml:"language"` // e.g. "es"; empty for English
+	Thinking          string    `toml:"thinking"` // "auto", "on" or "off"
+	ThinkingBudget    int64     `toml:"thinking_budget"`
+	StructuredOutputs string    `toml:"structured_outputs"` // "auto", "on" or "off"
+	FallbackModels    []string  `toml:"fallback_models"`    // Anthropic models or "plugin:name"
+	ParallelModels    []string  `toml:"parallel_models"`    // queried alongside the primary
+	RateLimitWait     int       `toml:"rate_limit_wait"`    // seconds; 0 for 60, negative never waits
+	MaxPromptTokens   int       `toml:"max_prompt_tokens"`  // query and context; 0 for 8000, negative for no limit
+	ConfirmTokens     int       `toml:"confirm_tokens"`     // ask before sending more; 0 for 20000, negative never asks
+	SafetyTimeout     int       `toml:"safety_timeout"`     // seconds per safety check; 0 for 20, negative never times out
+	AuditLog          string    `toml:"audit_log"`          // JSON Lines path; empty disables
+	AuditLogMaxSize   int64     `toml:"audit_log_max_size"` // megabytes before rotating; 0 for 10
+	AuditLogKeep      int       `toml:"audit_log_keep"`     // rotated logs kept; 0 for 5
+	CacheTTL          int       `toml:"cache_ttl"`          // days cached responses are used; 0 for 30, negative forever
+	CacheMaxSize      int64     `toml:"cache_max_size"`     // megabytes of cached responses; 0 for 50, negative no limit
+	RedactSecrets     string    `toml:"redact_secrets"`     // "on" (default) or "off"
+	ContextPacks      []string  `toml:"context_packs"`      // e.g. ["k8s"], as with --context
+	ContextDetails    string    `toml:"context_details"`    // "on" or "off" (default)
+	History           string    `toml:"history"`            // "on" (default) or "off"
+	LocalMatch        string    `toml:"local_match"`        // "on" (default) or "off"; option 0 from history and snippets
+	PolicyPath        string    `toml:"policy_path"`        // organization policy file
+	PolicyURL         string    `toml:"policy_url"`         // or where it is published
+	PolicyPublicKey   string    `toml:"policy_public_key"`  // base64 Ed25519 key it is signed with
+	IncludeURL        string    `toml:"include_url"`        // shared config merged under this one
+	SyncURL           string    `toml:"sync_url"`           // where history and snippets are synced
+	PrimarySelection  string    `toml:"primary_selection"`  // "on" or "off" (default); also for middle click
+	TerminalFeatures  string    `toml:"terminal_features"`  // "auto" (default), "on" (iTerm2's) or "off"
+	ASCIISymbols      string    `toml:"ascii_symbols"`      // "auto" (default), "on" or "off"
+	// Policy holds local rules, merged with the organization's; it can
+	// add restrictions but not remove them.
+	Policy *policy.Policy `toml:"policy"`
 }
 
-// Public: Reads and parses the configuration file from ~/.config/1lm/config.toml.
-// Returns default config if the file doesn't exist.
-func Load() (*Config, error) {
-	path, err := ConfigPath()
-	if err != nil {
-		return nil, err
-	}
-
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return DefaultConfig(), nil
+// Public: Returns the Anthropic API keys to use: anthropic_api_key, then
+// those in anthropic_api_keys, each once.
+func (c *Config) APIKeys() []string {
+	var keys []string
+	for _, key := range append([]string{c.AnthropicAPIKey}, c.AnthropicAPIKeys...) {
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
 	}
+	return keys
+}
 
-	var cfg Config
-	if _, err := toml.DecodeFile(path, &cfg); err != nil {
-		return nil, err
-	}
+// Example is a few-shot demonstration, written as an [[examples]] table,
+// showing the command the user would want for a request.
+type Example struct {
+	Query   string `toml:"query"`
+	Command string `toml:"command"`
+}
 
-	return &cfg, nil
+// Public: Reads and parses the configuration file from ~/.config/1lm/config.toml,
+// decrypting it if it is encrypted with age or SOPS.
+// Returns default config if the file doesn't exist.
+func Load() (*Config, error) {
+	cfg, _, err := LoadWithWarnings()
+	return cfg, err
 }
 
 // Public: Writes the configuration to ~/.config/1lm/config.toml.
@@ -42,6 +98,13 @@ func Save(cfg *Config) error {
 		return err
 	}
 
+	// Writing would replace an encrypted file with the plain text.
+	if data, err := os.ReadFile(path); err == nil {
+		if format := Encryption(data); format != EncryptionNone {
+			return fmt.Errorf("%s is encrypted with %s; edit it by hand instead", path, format)
+		}
+	}
+
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
@@ -63,12 +126,50 @@ func Save(cfg *Config) error {
 
 // Public: Returns the path to the configuration file.
 func ConfigPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Public: Returns the directory holding 1lm's config and local data
+// (~/.config/1lm).
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "1lm"), nil
+}
+
+// Public: Returns the directory for 1lm's cached data, such as saved
+// responses (~/.cache/1lm on Linux).
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "1lm"), nil
+}
+
+// Public: Expands a leading "~/" in path to the user's home directory, so
+// paths in the config file can be written as they would be in a shell.
+func ExpandPath(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(home, ".config", "1lm", "config.toml"), nil
+	return filepath.Join(home, rest), nil
 }
 
 // Public: Returns a Config with sensible defaults.
@@ -76,5 +177,6 @@ func DefaultConfig() *Config {
 	return &Config{
 		Provider: "anthropic",
 		Model:    "claude-sonnet-4-5-20250929",
+		LowPower: LowPowerAuto,
 	}
 }
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/BurntSushi/toml"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -82,3 +87,171 @@ func TestSupportedProviders(t *testing.T) {
 		t.Error("SupportedProviders() missing 'anthropic'")
 	}
 }
+
+func TestLowPowerEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		lowPower string
+		want     bool
+	}{
+		{name: "explicitly on", lowPower: LowPowerOn, want: true},
+		{name: "explicitly off", lowPower: LowPowerOff, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{LowPower: tt.lowPower}
+			if got := cfg.LowPowerEnabled(); got != tt.want {
+				t.Errorf("LowPowerEnabled() with %q = %v, want %v", tt.lowPower, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnBattery(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[string]string
+		want     bool
+	}{
+		{name: "no battery", want: false},
+		{name: "charging", statuses: map[string]string{"BAT0": "Charging"}, want: false},
+		{name: "discharging", statuses: map[string]string{"BAT0": "Discharging\n"}, want: true},
+		{name: "second battery discharging", statuses: map[string]string{"BAT0": "Full", "BAT1": "Discharging"}, want: true},
+		{name: "charger only", statuses: map[string]string{"AC": "Discharging"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, status := range tt.statuses {
+				if err := os.Mkdir(filepath.Join(dir, name), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name, "status"), []byte(status), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			defer func(saved string) { powerSupplyDir = saved }(powerSupplyDir)
+			powerSupplyDir = dir
+
+			if got := onBattery(); got != tt.want {
+				t.Errorf("onBattery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetered(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want bool
+	}{
+		{name: "no devices", out: "", want: false},
+		{name: "unmetered", out: "GENERAL.METERED:no\nGENERAL.METERED:unknown\n", want: false},
+		{name: "metered", out: "GENERAL.METERED:no\nGENERAL.METERED:yes\n", want: true},
+		{name: "guessed metered", out: "GENERAL.METERED:yes (guessed)\n", want: true},
+		{name: "guessed unmetered", out: "GENERAL.METERED:no (guessed)\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metered(tt.out); got != tt.want {
+				t.Errorf("metered(%q) = %v, want %v", tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestASCIISymbolsEnabled(t *testing.T) {
+	t.Setenv("TERM", "linux")
+	if !(&Config{}).ASCIISymbolsEnabled() {
+		t.Error("ASCIISymbolsEnabled() on the Linux console = false, want true")
+	}
+	if (&Config{ASCIISymbols: "off"}).ASCIISymbolsEnabled() {
+		t.Error("ASCIISymbolsEnabled() with \"off\" = true, want false")
+	}
+
+	t.Setenv("TERM", "xterm-256color")
+	if !(&Config{ASCIISymbols: "on"}).ASCIISymbolsEnabled() {
+		t.Error("ASCIISymbolsEnabled() with \"on\" = false, want true")
+	}
+}
+
+func TestDetectASCII(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{name: "utf-8 terminal", env: map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}, want: false},
+		{name: "linux console", env: map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}, want: true},
+		{name: "serial console", env: map[string]string{"TERM": "vt220"}, want: true},
+		{name: "C locale", env: map[string]string{"TERM": "xterm", "LANG": "C"}, want: true},
+		{name: "LC_ALL wins", env: map[string]string{"TERM": "xterm", "LC_ALL": "en_GB.utf8", "LANG": "C"}, want: false},
+		{name: "nothing set", env: map[string]string{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			if got := detectASCII(getenv); got != tt.want {
+				t.Errorf("detectASCII() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "~/.config/1lm/prompt.tmpl", want: filepath.Join(home, ".config/1lm/prompt.tmpl")},
+		{path: "/etc/1lm/prompt.tmpl", want: "/etc/1lm/prompt.tmpl"},
+		{path: "relative/prompt.tmpl", want: "relative/prompt.tmpl"},
+		{path: "~user/prompt.tmpl", want: "~user/prompt.tmpl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ExpandPath(tt.path)
+			if err != nil {
+				t.Fatalf("ExpandPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadExamples(t *testing.T) {
+	data := `
+[[examples]]
+query = "find go files"
+command = "fd --extension go"
+
+[[examples]]
+query = "search for TODO"
+command = "rg --fixed-strings TODO"
+`
+	var cfg Config
+	if _, err := toml.Decode(data, &cfg); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	want := []Example{
+		{Query: "find go files", Command: "fd --extension go"},
+		{Query: "search for TODO", Command: "rg --fixed-strings TODO"},
+	}
+	if !reflect.DeepEqual(cfg.Examples, want) {
+		t.Errorf("Examples = %+v, want %+v", cfg.Examples, want)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Encryption formats a config file can be written in, so API keys can be
+// kept in a dotfiles repository.
+const (
+	EncryptionNone = ""
+	EncryptionAge  = "age"
+	EncryptionSOPS = "sops"
+)
+
+// ageHeaders start files encrypted with age, in its binary and ASCII
+// armored forms.
+var ageHeaders = [][]byte{
+	[]byte("age-encryption.org/v1\n"),
+	[]byte("-----BEGIN AGE ENCRYPTED FILE-----"),
+}
+
+// Public: Reports how a config file's contents are encrypted: with age,
+// with SOPS, or EncryptionNone for plain TOML.
+func Encryption(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	for _, header := range ageHeaders {
+		if bytes.HasPrefix(trimmed, header) {
+			return EncryptionAge
+		}
+	}
+
+	// SOPS can't edit TOML in place, so it stores the whole file as a
+	// JSON document with its metadata under "sops", as with any binary
+	// file.
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var doc map[string]json.RawMessage
+		if json.Unmarshal(trimmed, &doc) == nil && doc["sops"] != nil {
+			return EncryptionSOPS
+		}
+	}
+
+	return EncryptionNone
+}
+
+// decrypt returns the TOML in the config file at path, whose contents are
+// data, decrypting it with the age or sops command if it is encrypted.
+func decrypt(path string, data []byte) ([]byte, error) {
+	var cmd *exec.Cmd
+	switch Encryption(data) {
+	case EncryptionAge:
+		identity, err := ageIdentity()
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.Command("age", "--decrypt", "--identity", identity, path)
+	case EncryptionSOPS:
+		cmd = exec.Command("sops", "--decrypt", path)
+	default:
+		return data, nil
+	}
+
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return nil, fmt.Errorf("%s is encrypted with %s, which isn't installed", path, cmd.Args[0])
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("failed to decrypt %s: %s", path, msg)
+		}
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// ageIdentity returns the age key file to decrypt with: the one in
+// SOPS_AGE_KEY_FILE, as SOPS uses, or the default SOPS keeps keys in.
+func ageIdentity() (string, error) {
+	if path := os.Getenv("SOPS_AGE_KEY_FILE"); path != "" {
+		return ExpandPath(path)
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "sops", "age", "keys.txt")
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.New("config file is encrypted with age: set SOPS_AGE_KEY_FILE to the key file to decrypt it with")
+	}
+	return path, nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEncryption(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "plain TOML",
+			data: "model = \"claude-haiku-4-5\"\n",
+			want: EncryptionNone,
+		},
+		{
+			name: "age binary",
+			data: "age-encryption.org/v1\n-> X25519 abc\n",
+			want: EncryptionAge,
+		},
+		{
+			name: "age armored",
+			data: "-----BEGIN AGE ENCRYPTED FILE-----\nYWdl\n-----END AGE ENCRYPTED FILE-----\n",
+			want: EncryptionAge,
+		},
+		{
+			name: "sops",
+			data: `{"data": "ENC[AES256_GCM,data:abc,type:str]", "sops": {"version": "3.9.0"}}`,
+			want: EncryptionSOPS,
+		},
+		{
+			name: "JSON without sops metadata",
+			data: `{"data": "abc"}`,
+			want: EncryptionNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Encryption([]byte(tt.data)); got != tt.want {
+				t.Errorf("Encryption() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeTool installs a shell script named name on the PATH that prints out
+// when run.
+func fakeTool(t *testing.T, name, out string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' '" + out + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestLoadFileEncrypted(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		data string
+	}{
+		{
+			name: "age",
+			tool: "age",
+			data: "-----BEGIN AGE ENCRYPTED FILE-----\nYWdl\n-----END AGE ENCRYPTED FILE-----\n",
+		},
+		{
+			name: "sops",
+			tool: "sops",
+			data: `{"data": "ENC[AES256_GCM,data:abc,type:str]", "sops": {"version": "3.9.0"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeTool(t, tt.tool, "anthropic_api_key = \"sk-ant-secret\"\n")
+			t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(t.TempDir(), "keys.txt"))
+
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, _, err := LoadFile(path)
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+			if cfg.AnthropicAPIKey != "sk-ant-secret" {
+				t.Errorf("LoadFile() key = %q, want the decrypted key", cfg.AnthropicAPIKey)
+			}
+		})
+	}
+}
+
+func TestLoadFileEncryptedWithoutTool(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := `{"data": "ENC[AES256_GCM,data:abc,type:str]", "sops": {"version": "3.9.0"}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := LoadFile(path)
+	if err == nil || !strings.Contains(err.Error(), "sops, which isn't installed") {
+		t.Errorf("LoadFile() error = %v, want one saying sops isn't installed", err)
+	}
+}
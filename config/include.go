@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pixielabs/1lm/policy"
+)
+
+// includeCacheTTL is how long a fetched include_url is used before it is
+// fetched again, unless the caller allows longer. A stale copy is still
+// used if fetching fails.
+const includeCacheTTL = time.Hour
+
+// includeFetchTimeout bounds fetching include_url, which happens before
+// every query once the cached copy is stale.
+const includeFetchTimeout = 5 * time.Second
+
+// maxIncludeSize bounds a fetched config fragment.
+const maxIncludeSize = 1 << 20
+
+// Public: Fetches the shared config fragment at url, such as a platform
+// team's 1lm-team.toml, and caches it in cacheDir. A copy younger than
+// maxAge is used without fetching, and a stale one if fetching fails.
+//
+// ctx      - Bounds the fetch
+// url      - Where the fragment is published; must be https
+// cacheDir - Directory for the cached copy
+// maxAge   - How long a cached copy is used before fetching; zero is 1h
+//
+// Returns the fragment's TOML, or an error if no copy is available.
+func FetchInclude(ctx context.Context, url, cacheDir string, maxAge time.Duration) ([]byte, error) {
+	if !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("include_url must be an https URL: %s", url)
+	}
+	if maxAge <= 0 {
+		maxAge = includeCacheTTL
+	}
+
+	cache := includeCachePath(url, cacheDir)
+	if info, err := os.Stat(cache); err == nil && time.Since(info.ModTime()) < maxAge {
+		if data, err := os.ReadFile(cache); err == nil {
+			return data, nil
+		}
+	}
+
+	data, err := fetchInclude(ctx, url)
+	if err == nil {
+		// Failing to cache only costs a fetch next time.
+		if os.MkdirAll(cacheDir, 0o755) == nil {
+			_ = os.WriteFile(cache, data, 0o600)
+		}
+		return data, nil
+	}
+
+	if cached, cacheErr := os.ReadFile(cache); cacheErr == nil {
+		return cached, nil
+	}
+	return nil, err
+}
+
+// Public: Loads the cached copy of the fragment at url from cacheDir,
+// without the network, for offline use.
+func LoadCachedInclude(url, cacheDir string) ([]byte, error) {
+	data, err := os.ReadFile(includeCachePath(url, cacheDir))
+	if err != nil {
+		return nil, fmt.Errorf("no cached copy of include_url available offline: %w", err)
+	}
+	return data, nil
+}
+
+// includeCachePath names the cached copy after a hash of url, so a changed
+// include_url is fetched rather than answered from the old one's copy.
+func includeCachePath(url, cacheDir string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, "include-"+hex.EncodeToString(sum[:8])+".toml")
+}
+
+func fetchInclude(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, includeFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch include_url: %w", err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch include_url: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, res.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxIncludeSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch include_url: %w", err)
+	}
+	return data, nil
+}
+
+// sharedKeys are the settings a fragment may set. Anything else, such as
+// sync_url, API keys, providers or local paths, would let whoever
+// publishes the fragment redirect where queries, history or secrets go,
+// so it stays under the local file's control.
+var sharedKeys = []string{"examples", "preferred_tools", "avoid_tools", "constraints", "context_packs", "policy"}
+
+// Public: Merges a shared config fragment under the config. Only the
+// settings in sharedKeys are taken: the lists combine the fragment's
+// entries with the local ones, and the fragment's [policy] is merged with
+// the local one, which can add restrictions but not remove them.
+//
+// data - The fragment's TOML, from FetchInclude
+//
+// Returns Warnings for keys the fragment misspells or isn't allowed to
+// set, or an error if it isn't valid TOML.
+func (c *Config) MergeInclude(data []byte) ([]Warning, error) {
+	var shared Config
+	md, err := toml.Decode(string(data), &shared)
+	if err != nil {
+		return nil, fmt.Errorf("include_url: %w", err)
+	}
+
+	var warnings []Warning
+	unknown := map[string]bool{}
+	for _, w := range unknownKeys(md) {
+		unknown[w.Key] = true
+		w.Key = "include_url: " + w.Key
+		warnings = append(warnings, w)
+	}
+	for _, key := range md.Keys() {
+		if len(key) == 1 && !unknown[key[0]] && !slices.Contains(sharedKeys, key[0]) {
+			warnings = append(warnings, Warning{Key: "include_url: " + key[0], Message: "can only be set in the local config, ignored"})
+		}
+	}
+
+	c.Examples = append(shared.Examples, c.Examples...)
+	c.PreferredTools = mergeLists(shared.PreferredTools, c.PreferredTools)
+	c.AvoidTools = mergeLists(shared.AvoidTools, c.AvoidTools)
+	c.Constraints = mergeLists(shared.Constraints, c.Constraints)
+	c.ContextPacks = mergeLists(shared.ContextPacks, c.ContextPacks)
+	if shared.Policy != nil {
+		c.Policy = policy.Merge(shared.Policy, c.Policy)
+	}
+	return warnings, nil
+}
+
+// mergeLists returns the shared list followed by the local entries it
+// doesn't already have.
+func mergeLists(shared, local []string) []string {
+	merged := slices.Clone(shared)
+	for _, item := range local {
+		if !slices.Contains(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
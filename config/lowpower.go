@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Low-power mode settings accepted by the low_power config key.
+const (
+	LowPowerAuto = "auto"
+	LowPowerOn   = "on"
+	LowPowerOff  = "off"
+)
+
+// Public: Reports whether low-power mode should be active.
+//
+// "on" and "off" are explicit. Anything else (including unset) falls back
+// to auto-detection from the battery state in sysfs and, when that finds
+// nothing, NetworkManager's metered flag, asked for under a short timeout
+// so startup isn't held up.
+func (c *Config) LowPowerEnabled() bool {
+	switch c.LowPower {
+	case LowPowerOn:
+		return true
+	case LowPowerOff:
+		return false
+	default:
+		return detectLowPower()
+	}
+}
+
+// powerSupplyDir is where Linux lists batteries and chargers.
+var powerSupplyDir = "/sys/class/power_supply"
+
+// meteredTimeout bounds asking NetworkManager about the connection, which
+// happens at every startup in auto mode.
+const meteredTimeout = 200 * time.Millisecond
+
+// detectLowPower reports whether a battery is discharging or the network
+// connection is metered. Only Linux exposes these without elevated APIs,
+// so other platforms always report false.
+func detectLowPower() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	return onBattery() || meteredConnection()
+}
+
+// onBattery reports whether any battery in sysfs is discharging.
+func onBattery() bool {
+	matches, _ := filepath.Glob(filepath.Join(powerSupplyDir, "BAT*", "status"))
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err == nil && strings.TrimSpace(string(data)) == "Discharging" {
+			return true
+		}
+	}
+	return false
+}
+
+// meteredConnection asks NetworkManager whether any device is on a metered
+// connection. Without nmcli, or when it doesn't answer in time, the
+// connection is treated as unmetered.
+func meteredConnection() bool {
+	if _, err := exec.LookPath("nmcli"); err != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), meteredTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nmcli", "-t", "-f", "GENERAL.METERED", "device", "show").Output()
+	if err != nil {
+		return false
+	}
+	return metered(string(out))
+}
+
+// metered reports whether nmcli's GENERAL.METERED lines flag any device,
+// including NetworkManager's guesses such as a phone's hotspot.
+func metered(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		// Values look like "GENERAL.METERED:yes (guessed)".
+		if _, value, ok := strings.Cut(line, ":"); ok && strings.HasPrefix(value, "yes") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// asciiTerminals are $TERM values for consoles whose fonts lack emoji and
+// most symbols, such as the Linux virtual console and serial terminals.
+var asciiTerminals = []string{"linux", "dumb", "vt100", "vt102", "vt220", "ansi", "cons25"}
+
+// Public: Reports whether the UI should use ASCII in place of emoji,
+// arrows and box-drawing characters.
+//
+// "on" and "off" are explicit. Anything else (including unset) falls back
+// to detection from $TERM and the locale.
+func (c *Config) ASCIISymbolsEnabled() bool {
+	switch c.ASCIISymbols {
+	case "on":
+		return true
+	case "off":
+		return false
+	default:
+		return detectASCII(os.Getenv)
+	}
+}
+
+// detectASCII reports whether the terminal is a console known to draw
+// symbols as boxes, or the locale's character set isn't UTF-8. Windows
+// consoles are left alone: their locale isn't set in the environment.
+func detectASCII(getenv func(string) string) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	if slices.Contains(asciiTerminals, getenv("TERM")) {
+		return true
+	}
+
+	// The first of these that is set decides the character set, as in C.
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := strings.ToLower(getenv(name)); locale != "" {
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	return false
+}
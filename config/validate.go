@@ -0,0 +1,268 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Warning is a problem in the config file that 1lm works around rather
+// than refusing to start, such as a misspelt key it ignores.
+type Warning struct {
+	Key     string // TOML key, e.g. "modle"
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Key, w.Message)
+}
+
+// Public: Reads and parses the configuration file like Load, and checks it
+// for unknown keys, model names the provider doesn't serve, invalid
+// settings and options that conflict.
+//
+// Returns the Config, the Warnings found, and an error only if the file
+// can't be read or isn't valid TOML.
+func LoadWithWarnings() (*Config, []Warning, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	return LoadFile(path)
+}
+
+// Public: Reads, parses and checks the configuration file at path, as
+// LoadWithWarnings does. A file encrypted with age or SOPS is decrypted
+// first. A missing file gives the default config.
+func LoadFile(path string) (*Config, []Warning, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return DefaultConfig(), nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = decrypt(path, data); err != nil {
+		return nil, nil, err
+	}
+
+	var cfg Config
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings := unknownKeys(md)
+	warnings = append(warnings, cfg.Validate()...)
+	return &cfg, warnings, nil
+}
+
+// Public: Checks the config's values: the provider, model names, on/off
+// settings and options that conflict. Keys the file misspells are only
+// found when loading it.
+//
+// Returns a Warning for each problem, in the order of the config's fields.
+func (c *Config) Validate() []Warning {
+	var warnings []Warning
+	warn := func(key, format string, args ...any) {
+		warnings = append(warnings, Warning{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	provider := c.Provider
+	if provider == "" {
+		provider = "anthropic"
+	}
+	_, known := GetProvider(provider)
+	if !known && provider != "vertex" && !strings.HasPrefix(provider, "plugin:") {
+		warn("provider", "unknown provider %q (want \"anthropic\", \"vertex\" or \"plugin:name\")", c.Provider)
+	}
+
+	if !strings.HasPrefix(provider, "plugin:") {
+		if c.Model != "" && !isClaudeModel(c.Model) {
+			warn("model", "%q is not a model %s serves; Claude model names start with \"claude-\"", c.Model, provider)
+		}
+		for _, list := range []struct {
+			key    string
+			models []string
+		}{
+			{"fallback_models", c.FallbackModels},
+			{"parallel_models", c.ParallelModels},
+		} {
+			for _, model := range list.models {
+				if !isClaudeModel(model) && !strings.HasPrefix(model, "plugin:") {
+					warn(list.key, "%q is not a model %s serves; use a Claude model name or \"plugin:name\"", model, provider)
+				}
+			}
+		}
+	}
+
+	if provider != "vertex" {
+		if c.VertexProject != "" {
+			warn("vertex_project", "only used with provider \"vertex\"")
+		}
+		if c.VertexRegion != "" {
+			warn("vertex_region", "only used with provider \"vertex\"")
+		}
+	}
+
+	switch {
+	case c.KeyRotation != "" && c.KeyRotation != "round-robin" && c.KeyRotation != "on-rate-limit":
+		warn("key_rotation", "%q is not \"round-robin\" or \"on-rate-limit\"", c.KeyRotation)
+	case c.KeyRotation != "" && len(c.APIKeys()) < 2:
+		warn("key_rotation", "has no effect with fewer than two API keys")
+	}
+
+	for _, mode := range []struct{ key, value string }{
+		{"low_power", c.LowPower},
+		{"thinking", c.Thinking},
+		{"structured_outputs", c.StructuredOutputs},
+		{"terminal_features", c.TerminalFeatures},
+		{"ascii_symbols", c.ASCIISymbols},
+	} {
+		if mode.value != "" && !slices.Contains([]string{"auto", "on", "off"}, mode.value) {
+			warn(mode.key, "%q is not \"auto\", \"on\" or \"off\"", mode.value)
+		}
+	}
+	for _, mode := range []struct{ key, value string }{
+		{"redact_secrets", c.RedactSecrets},
+		{"context_details", c.ContextDetails},
+		{"history", c.History},
+		{"primary_selection", c.PrimarySelection},
+	} {
+		if mode.value != "" && mode.value != "on" && mode.value != "off" {
+			warn(mode.key, "%q is not \"on\" or \"off\"", mode.value)
+		}
+	}
+
+	if c.ThinkingBudget != 0 {
+		switch {
+		case c.Thinking == "off":
+			warn("thinking_budget", "has no effect with thinking = \"off\"")
+		case c.ThinkingBudget < 1024:
+			warn("thinking_budget", "%d is below the minimum of 1024, so the default of 2048 is used", c.ThinkingBudget)
+		}
+	}
+
+	if c.AuditLog == "" {
+		if c.AuditLogMaxSize != 0 {
+			warn("audit_log_max_size", "has no effect without audit_log")
+		}
+		if c.AuditLogKeep != 0 {
+			warn("audit_log_keep", "has no effect without audit_log")
+		}
+	}
+
+	if c.ContextDetails == "on" && len(c.ContextPacks) == 0 {
+		warn("context_details", "has no effect without context_packs, unless --context is given")
+	}
+
+	if c.PolicyPath != "" && c.PolicyURL != "" {
+		warn("policy_url", "ignored because policy_path is also set")
+	}
+	if c.PolicyPublicKey != "" && c.PolicyPath == "" && c.PolicyURL == "" {
+		warn("policy_public_key", "has no effect without policy_path or policy_url")
+	}
+
+	if c.SyncURL != "" {
+		scheme, _, _ := strings.Cut(c.SyncURL, "://")
+		if scheme != "s3" && scheme != "webdav" && scheme != "webdavs" && !strings.HasPrefix(scheme, "git+") {
+			warn("sync_url", "unsupported backend %q (want s3://, git+ssh://, git+https:// or webdavs://)", scheme)
+		}
+	}
+
+	return warnings
+}
+
+// isClaudeModel reports whether model names a Claude model, as the
+// Anthropic API ("claude-sonnet-4-5-20250929") and Vertex AI
+// ("claude-sonnet-4-5@20250929") spell them.
+func isClaudeModel(model string) bool {
+	return strings.HasPrefix(model, "claude-")
+}
+
+// unknownKeys returns a Warning for each key in the file that no Config
+// field reads, suggesting the known key it is closest to.
+func unknownKeys(md toml.MetaData) []Warning {
+	known := knownKeys(reflect.TypeOf(Config{}), "")
+
+	var warnings []Warning
+	for _, key := range md.Undecoded() {
+		name := key.String()
+		message := "unknown key, ignored"
+		if suggestion := closestKey(name, known); suggestion != "" {
+			message = fmt.Sprintf("unknown key, ignored; did you mean %q?", suggestion)
+		}
+		warnings = append(warnings, Warning{Key: name, Message: message})
+	}
+	return warnings
+}
+
+// knownKeys lists the dotted TOML keys of t's fields, including those of
+// nested tables such as "policy.max_risk".
+func knownKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		keys = append(keys, key)
+
+		nested := field.Type
+		for nested.Kind() == reflect.Pointer || nested.Kind() == reflect.Slice {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct {
+			keys = append(keys, knownKeys(nested, key+".")...)
+		}
+	}
+	return keys
+}
+
+// closestKey returns the known key within two edits of key, for typos such
+// as "modle", or "" if there is none.
+func closestKey(key string, known []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range known {
+		if d := editDistance(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the number of single-character insertions,
+// deletions, substitutions or swaps of adjacent characters that turn a
+// into b.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want []Warning
+	}{
+		{
+			name: "valid file",
+			toml: "model = \"claude-haiku-4-5\"\nthinking = \"auto\"\n",
+			want: nil,
+		},
+		{
+			name: "misspelt key",
+			toml: "modle = \"claude-haiku-4-5\"\n",
+			want: []Warning{{Key: "modle", Message: `unknown key, ignored; did you mean "model"?`}},
+		},
+		{
+			name: "misspelt nested key",
+			toml: "[policy]\nmax_rsk = \"high\"\n",
+			want: []Warning{{Key: "policy.max_rsk", Message: `unknown key, ignored; did you mean "policy.max_risk"?`}},
+		},
+		{
+			name: "unknown key with nothing close",
+			toml: "colour_scheme = \"dark\"\n",
+			want: []Warning{{Key: "colour_scheme", Message: "unknown key, ignored"}},
+		},
+		{
+			name: "misspelt key in array table",
+			toml: "[[examples]]\nquery = \"q\"\ncomand = \"ls\"\n",
+			want: []Warning{{Key: "examples.comand", Message: `unknown key, ignored; did you mean "examples.command"?`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.toml), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, got, err := LoadFile(path)
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFile() warnings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg, warnings, err := LoadFile(filepath.Join(t.TempDir(), "config.toml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("LoadFile() = %+v, want the default config", cfg)
+	}
+	if warnings != nil {
+		t.Errorf("LoadFile() warnings = %v, want none", warnings)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("model = \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadFile(path); err == nil {
+		t.Error("LoadFile() error = nil, want a parse error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantKeys []string
+	}{
+		{
+			name:     "default config",
+			cfg:      *DefaultConfig(),
+			wantKeys: nil,
+		},
+		{
+			name:     "unknown provider",
+			cfg:      Config{Provider: "openai"},
+			wantKeys: []string{"provider"},
+		},
+		{
+			name:     "model the provider doesn't serve",
+			cfg:      Config{Provider: "anthropic", Model: "gpt-4o"},
+			wantKeys: []string{"model"},
+		},
+		{
+			name:     "vertex model",
+			cfg:      Config{Provider: "vertex", Model: "claude-sonnet-4-5@20250929", VertexProject: "p"},
+			wantKeys: nil,
+		},
+		{
+			name:     "plugin serves any model",
+			cfg:      Config{Provider: "plugin:ollama", Model: "llama3"},
+			wantKeys: nil,
+		},
+		{
+			name:     "fallback and parallel models",
+			cfg:      Config{FallbackModels: []string{"claude-haiku-4-5", "plugin:ollama", "gpt-4o"}, ParallelModels: []string{"gemini"}},
+			wantKeys: []string{"fallback_models", "parallel_models"},
+		},
+		{
+			name:     "vertex settings without vertex",
+			cfg:      Config{VertexProject: "p", VertexRegion: "global"},
+			wantKeys: []string{"vertex_project", "vertex_region"},
+		},
+		{
+			name:     "invalid key rotation",
+			cfg:      Config{AnthropicAPIKeys: []string{"a", "b"}, KeyRotation: "random"},
+			wantKeys: []string{"key_rotation"},
+		},
+		{
+			name:     "key rotation with one key",
+			cfg:      Config{AnthropicAPIKey: "a", AnthropicAPIKeys: []string{"a"}, KeyRotation: "on-rate-limit"},
+			wantKeys: []string{"key_rotation"},
+		},
+		{
+			name:     "key rotation with several keys",
+			cfg:      Config{AnthropicAPIKey: "a", AnthropicAPIKeys: []string{"b"}, KeyRotation: "round-robin"},
+			wantKeys: nil,
+		},
+		{
+			name:     "invalid modes",
+			cfg:      Config{LowPower: "sometimes", Thinking: "yes", History: "auto"},
+			wantKeys: []string{"low_power", "thinking", "history"},
+		},
+		{
+			name:     "thinking budget with thinking off",
+			cfg:      Config{Thinking: "off", ThinkingBudget: 4096},
+			wantKeys: []string{"thinking_budget"},
+		},
+		{
+			name:     "thinking budget below the minimum",
+			cfg:      Config{ThinkingBudget: 500},
+			wantKeys: []string{"thinking_budget"},
+		},
+		{
+			name:     "audit log settings without audit log",
+			cfg:      Config{AuditLogMaxSize: 20, AuditLogKeep: 3},
+			wantKeys: []string{"audit_log_max_size", "audit_log_keep"},
+		},
+		{
+			name:     "context details without packs",
+			cfg:      Config{ContextDetails: "on"},
+			wantKeys: []string{"context_details"},
+		},
+		{
+			name:     "policy path and url",
+			cfg:      Config{PolicyPath: "policy.toml", PolicyURL: "https://example.com/policy.toml"},
+			wantKeys: []string{"policy_url"},
+		},
+		{
+			name:     "policy key without policy",
+			cfg:      Config{PolicyPublicKey: "key"},
+			wantKeys: []string{"policy_public_key"},
+		},
+		{
+			name: "sync url",
+			cfg:  Config{SyncURL: "git+ssh://git@example.com/alice/1lm-sync.git"},
+		},
+		{
+			name:     "unsupported sync url",
+			cfg:      Config{SyncURL: "ftp://example.com/1lm"},
+			wantKeys: []string{"sync_url"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			for _, w := range tt.cfg.Validate() {
+				keys = append(keys, w.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("Validate() keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"model", "model", 0},
+		{"modle", "model", 1},
+		{"mdel", "model", 1},
+		{"modell", "model", 1},
+		{"histroy", "history", 1},
+		{"", "abc", 3},
+		{"thinking", "language", 7},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
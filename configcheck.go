@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// configWarnings holds the problems found in the config file when it was
+// loaded.
+var configWarnings []config.Warning
+
+// checkConfig adds the problems only the main package can find, in the
+// settings that name constraints and context packs, to those found while
+// loading cfg.
+func checkConfig(cfg *config.Config, warnings []config.Warning) []config.Warning {
+	if _, err := commands.ParseConstraints(cfg.Constraints); err != nil {
+		warnings = append(warnings, config.Warning{Key: "constraints", Message: err.Error()})
+	}
+	for _, name := range cfg.ContextPacks {
+		if _, ok := commands.LookupContextPack(name); !ok {
+			warnings = append(warnings, config.Warning{Key: "context_packs", Message: fmt.Sprintf("unknown context %q", name)})
+		}
+	}
+	return warnings
+}
+
+// includeConfig merges the shared config fragment at include_url under
+// cfg. A fragment that can't be fetched, with no cached copy, only warns:
+// 1lm still works with the local config.
+func includeConfig(cfg *config.Config) []config.Warning {
+	if cfg.IncludeURL == "" {
+		return nil
+	}
+
+	dir, err := config.CacheDir()
+	if err != nil {
+		return []config.Warning{{Key: "include_url", Message: err.Error()}}
+	}
+
+	var data []byte
+	if *offline {
+		data, err = config.LoadCachedInclude(cfg.IncludeURL, dir)
+	} else {
+		data, err = config.FetchInclude(context.Background(), cfg.IncludeURL, dir, cacheMaxAge())
+	}
+	if err != nil {
+		return []config.Warning{{Key: "include_url", Message: err.Error()}}
+	}
+
+	warnings, err := cfg.MergeInclude(data)
+	if err != nil {
+		return []config.Warning{{Key: "include_url", Message: err.Error()}}
+	}
+	return warnings
+}
+
+// warnConfig prints the config file's problems to stderr at startup, so a
+// misspelt key doesn't silently fall back to the default.
+func warnConfig() {
+	path, _ := config.ConfigPath()
+	for _, w := range configWarnings {
+		slog.Warn(fmt.Sprintf("%s: %s", path, w))
+	}
+}
+
+// runConfig runs "1lm config validate", which reports every problem in
+// the config file and exits with status 1 if there are any.
+func runConfig(_ *config.Config, _ ui.Settings, _ []string) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if len(configWarnings) == 0 {
+		fmt.Printf("%s: no problems found\n", path)
+		return nil
+	}
+	for _, w := range configWarnings {
+		fmt.Printf("%s: %s\n", path, w)
+	}
+	return exitStatus(1)
+}
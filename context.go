@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// queryPlaceholder stands in for the query when "1lm context" shows the
+// prompt without one.
+const queryPlaceholder = "<query>"
+
+// runContext shows what would be sent with every query: the prompt, with
+// a placeholder where the query goes, and any context attached with
+// --from-clipboard or --stdin-context.
+func runContext(cfg *config.Config, _ ui.Settings, _ []string) error {
+	return showPrompt(os.Stdout, cfg, queryPlaceholder)
+}
+
+// showPrompt writes where query would be sent and the prompt exactly as it
+// would be sent, after attaching context, trimming it and redacting
+// secrets, without sending anything.
+func showPrompt(w io.Writer, cfg *config.Config, query string) error {
+	if query == "" {
+		return errors.New("--show-prompt needs a query")
+	}
+
+	attachments, err := readContext()
+	if err != nil {
+		return err
+	}
+	generator := commands.NewGeneratorWithEvaluator(nil, nil)
+	if err := configureGenerator(cfg, generator, attachments); err != nil {
+		return err
+	}
+	// Counting tokens exactly would send the text to the API.
+	generator.SetTokenLimit(llm.HeuristicTokenizer{}, promptTokenLimit(cfg))
+
+	out, err := generator.Preview(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	prompt, err := loadPrompt(cfg)
+	if err != nil {
+		return err
+	}
+	text, err := prompt.Render(out.Query)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Sent to: %s\n", destination(cfg))
+	for _, a := range attachments {
+		fmt.Fprintf(w, "Attached: %s (%d bytes)\n", a.Source, len(a.Text))
+	}
+	if len(out.Trimmed) > 0 {
+		fmt.Fprintf(w, "Trimmed for the token limit: %s\n", strings.Join(out.Trimmed, ", "))
+	}
+	if len(out.Redacted) > 0 {
+		fmt.Fprintf(w, "Redacted: %s\n", strings.Join(out.Redacted, ", "))
+	}
+	if len(out.Suspicious) > 0 {
+		fmt.Fprintf(w, "Possible prompt injection in: %s\n", strings.Join(out.Suspicious, ", "))
+	}
+	fmt.Fprintf(w, "\n%s\n", text)
+	return nil
+}
+
+// destination describes where queries go with the current config and
+// flags, including any fallback and parallel models.
+func destination(cfg *config.Config) string {
+	switch {
+	case *replayPath != "":
+		return "nowhere, answered from " + *replayPath
+	case *offline:
+		return "nowhere, answered from cached responses and snippets"
+	}
+
+	var dest string
+	switch {
+	case strings.HasPrefix(cfg.Provider, llm.PluginPrefix):
+		dest = "the " + strings.TrimPrefix(cfg.Provider, llm.PluginPrefix) + " plugin"
+	case cfg.Provider == "vertex":
+		dest = fmt.Sprintf("%s on Vertex AI (project %s, region %s)", cfg.Model, cfg.VertexProject, cfg.VertexRegion)
+	default:
+		dest = cfg.Model + " on the Anthropic API"
+	}
+	if len(cfg.FallbackModels) > 0 {
+		dest += ", falling back to " + strings.Join(cfg.FallbackModels, ", ")
+	}
+	if len(cfg.ParallelModels) > 0 {
+		dest += ", and in parallel to " + strings.Join(cfg.ParallelModels, ", ")
+	}
+	return dest
+}
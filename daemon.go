@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/daemon"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// runDaemon serves queries on the daemon socket until interrupted. Config
+// is read once at startup, so restart the daemon after changing it.
+func runDaemon(cfg *config.Config, _ ui.Settings, _ []string) (err error) {
+	client, evaluator, err := newBackend(cfg)
+	if err != nil {
+		return err
+	}
+
+	path, err := daemon.SocketPath()
+	if err != nil {
+		return fmt.Errorf("failed to find socket path: %w", err)
+	}
+	l, err := daemon.Listen(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Closing the listener usually removes the socket already.
+		if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+			err = errors.Join(err, fmt.Errorf("failed to remove socket: %w", rmErr))
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "1lm daemon listening on %s\n", path)
+	return daemon.NewServer(client, evaluator).Serve(ctx, l)
+}
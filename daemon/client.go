@@ -0,0 +1,130 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// dialTimeout bounds how long Dial waits, so an unresponsive daemon falls
+// back to direct API calls quickly.
+const dialTimeout = 200 * time.Millisecond
+
+// Client sends queries to a running daemon. It implements llm.Client and
+// commands.RiskEvaluator, so it can back a commands.Generator directly.
+type Client struct {
+	path string
+}
+
+// Public: Connects to the daemon listening at path.
+//
+// Returns a Client, or an error if no daemon is accepting connections.
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("daemon not reachable: %w", err)
+	}
+	_ = conn.Close()
+
+	return &Client{path: path}, nil
+}
+
+// Public: Generates command options through the daemon.
+func (c *Client) GenerateOptions(ctx context.Context, query string) ([]llm.CommandOption, error) {
+	res, err := c.call(ctx, request{Op: opGenerate, Query: query})
+	if err != nil {
+		return nil, err
+	}
+	if res.Refusal != nil {
+		return nil, res.Refusal
+	}
+	if len(res.Options) == 0 {
+		return nil, fmt.Errorf("no options returned")
+	}
+	return res.Options, nil
+}
+
+// Public: Evaluates commands for safety risks through the daemon.
+func (c *Client) Evaluate(ctx context.Context, commands []string) ([]*safety.RiskInfo, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	res, err := c.call(ctx, request{Op: opEvaluate, Commands: commands})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Risks) != len(commands) {
+		return nil, fmt.Errorf("expected %d evaluations, got %d", len(commands), len(res.Risks))
+	}
+	if len(res.Missing) > 0 {
+		return decodeRisks(res.Risks), &safety.IncompleteError{Missing: res.Missing, Total: len(commands)}
+	}
+	return decodeRisks(res.Risks), nil
+}
+
+// Public: Assesses one command in depth through the daemon.
+func (c *Client) Explain(ctx context.Context, command string) (*safety.RiskDetail, error) {
+	res, err := c.call(ctx, request{Op: opExplain, Command: command})
+	if err != nil {
+		return nil, err
+	}
+	if res.Detail == nil {
+		return nil, fmt.Errorf("no assessment returned")
+	}
+	return decodeDetail(res.Detail), nil
+}
+
+// Public: Explains a command segment by segment through the daemon.
+func (c *Client) Annotate(ctx context.Context, command string, segments []string) ([]safety.SegmentNote, error) {
+	res, err := c.call(ctx, request{Op: opAnnotate, Command: command, Commands: segments})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Notes) != len(segments) {
+		return nil, fmt.Errorf("expected %d segment notes, got %d", len(segments), len(res.Notes))
+	}
+	return decodeNotes(res.Notes), nil
+}
+
+// call sends one request on a fresh connection. Connections are cheap on a
+// unix socket; the expensive state lives in the daemon.
+func (c *Client) call(ctx context.Context, req request) (res response, err error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return response{}, fmt.Errorf("daemon not reachable: %w", err)
+	}
+	defer func() {
+		// Already closed when ctx was cancelled, which err reports.
+		if closeErr := conn.Close(); closeErr != nil && !errors.Is(closeErr, net.ErrClosed) {
+			err = errors.Join(err, fmt.Errorf("failed to close daemon connection: %w", closeErr))
+		}
+	}()
+
+	// Closing the connection unblocks the read below if ctx is cancelled.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return response{}, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if err := json.NewDecoder(conn).Decode(&res); err != nil {
+		if ctx.Err() != nil {
+			return response{}, ctx.Err()
+		}
+		return response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if res.Error != "" {
+		return response{}, errors.New(res.Error)
+	}
+
+	return res, nil
+}
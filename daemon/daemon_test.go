@@ -0,0 +1,287 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// stubEvaluator returns fixed risks for any commands.
+type stubEvaluator struct {
+	risks []*safety.RiskInfo
+	err   error
+}
+
+func (s stubEvaluator) Evaluate(_ context.Context, _ []string) ([]*safety.RiskInfo, error) {
+	return s.risks, s.err
+}
+
+// startServer runs a server on a temporary socket for the duration of the
+// test and returns a connected client.
+func startServer(t *testing.T, server *Server) *Client {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "daemon", "d.sock")
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = server.Serve(ctx, l)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	return client
+}
+
+func TestGenerateOptions(t *testing.T) {
+	mock := llm.NewMockClient()
+	client := startServer(t, NewServer(mock, nil))
+
+	options, err := client.GenerateOptions(context.Background(), "list files")
+	if err != nil {
+		t.Fatalf("GenerateOptions() error = %v", err)
+	}
+
+	if len(options) != len(mock.Response) {
+		t.Fatalf("GenerateOptions() returned %d options, want %d", len(options), len(mock.Response))
+	}
+	if options[0] != mock.Response[0] {
+		t.Errorf("GenerateOptions()[0] = %+v, want %+v", options[0], mock.Response[0])
+	}
+	if mock.LastQuery != "list files" {
+		t.Errorf("server received query %q, want %q", mock.LastQuery, "list files")
+	}
+}
+
+func TestGenerateOptionsError(t *testing.T) {
+	mock := &llm.MockClient{Err: errors.New("API call failed")}
+	client := startServer(t, NewServer(mock, nil))
+
+	_, err := client.GenerateOptions(context.Background(), "list files")
+	if err == nil || err.Error() != "API call failed" {
+		t.Errorf("GenerateOptions() error = %v, want %q", err, "API call failed")
+	}
+}
+
+func TestGenerateOptionsRefusal(t *testing.T) {
+	want := &llm.Refusal{Reason: "poems are prose", Suggestion: "count words in a file"}
+	client := startServer(t, NewServer(&llm.MockClient{Err: want}, nil))
+
+	_, err := client.GenerateOptions(context.Background(), "write a poem")
+	var refusal *llm.Refusal
+	if !errors.As(err, &refusal) {
+		t.Fatalf("GenerateOptions() error = %v, want a refusal", err)
+	}
+	if *refusal != *want {
+		t.Errorf("refusal = %+v, want %+v", refusal, want)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name      string
+		evaluator stubEvaluator
+		wantErr   bool
+		want      []safety.RiskLevel
+	}{
+		{
+			name: "risks round-trip",
+			evaluator: stubEvaluator{risks: []*safety.RiskInfo{
+				nil,
+				{Level: safety.RiskHigh, Message: "Deletes files"},
+			}},
+			want: []safety.RiskLevel{safety.RiskNone, safety.RiskHigh},
+		},
+		{
+			name:      "evaluator error",
+			evaluator: stubEvaluator{err: errors.New("unavailable")},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := startServer(t, NewServer(llm.NewMockClient(), tt.evaluator))
+
+			risks, err := client.Evaluate(context.Background(), []string{"ls", "rm -rf build"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for i, want := range tt.want {
+				got := safety.RiskNone
+				if risks[i] != nil {
+					got = risks[i].Level
+				}
+				if got != want {
+					t.Errorf("risk %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateIncomplete(t *testing.T) {
+	client := startServer(t, NewServer(llm.NewMockClient(), stubEvaluator{
+		risks: []*safety.RiskInfo{{Level: safety.RiskHigh, Message: "Deletes files"}, nil},
+		err:   &safety.IncompleteError{Missing: []int{1}, Total: 2},
+	}))
+
+	risks, err := client.Evaluate(context.Background(), []string{"rm -rf build", "ls"})
+	var incomplete *safety.IncompleteError
+	if !errors.As(err, &incomplete) || !reflect.DeepEqual(incomplete.Missing, []int{1}) {
+		t.Fatalf("Evaluate() error = %v, want command 1 reported missing", err)
+	}
+	if len(risks) != 2 || risks[0] == nil || risks[0].Level != safety.RiskHigh {
+		t.Errorf("Evaluate() = %v, want the answered command's risk kept", risks)
+	}
+}
+
+func TestListenRemovesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon", "d.sock")
+	if err := os.Mkdir(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer l.Close()
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Listen() on a live socket should fail")
+	}
+}
+
+func TestListenCreatesPrivateSocketDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config", "daemon")
+
+	l, err := Listen(filepath.Join(dir, "d.sock"))
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("socket directory mode = %o, want 700", perm)
+	}
+}
+
+func TestListenRefusesSharedSocketDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if l, err := Listen(filepath.Join(dir, "d.sock")); err == nil {
+		_ = l.Close()
+		t.Fatal("Listen() in a directory others can open should fail")
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0755 {
+		t.Errorf("socket directory mode = %o, want it left at 755", perm)
+	}
+}
+
+func TestDialWithoutDaemon(t *testing.T) {
+	if _, err := Dial(filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+		t.Error("Dial() with no daemon should fail")
+	}
+}
+
+// stubExplainer is a stubEvaluator that also gives a fixed assessment.
+type stubExplainer struct {
+	stubEvaluator
+	detail *safety.RiskDetail
+}
+
+func (s stubExplainer) Explain(_ context.Context, _ string) (*safety.RiskDetail, error) {
+	return s.detail, s.err
+}
+
+func TestExplain(t *testing.T) {
+	want := &safety.RiskDetail{
+		Level:      safety.RiskHigh,
+		Reasoning:  "Deletes the build directory",
+		DataLoss:   "Build output",
+		Paths:      []string{"build"},
+		Reversible: "no",
+		Recovery:   "Rebuild the project",
+	}
+	client := startServer(t, NewServer(llm.NewMockClient(), stubExplainer{detail: want}))
+
+	got, err := client.Explain(context.Background(), "rm -rf build")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Explain() = %+v, want %+v", got, want)
+	}
+
+	// An evaluator without detailed assessments is an error, not a panic.
+	client = startServer(t, NewServer(llm.NewMockClient(), stubEvaluator{}))
+	if _, err := client.Explain(context.Background(), "rm -rf build"); err == nil {
+		t.Error("Explain() without an explainer succeeded, want an error")
+	}
+}
+
+// stubAnnotator is a stubEvaluator that also gives fixed segment notes.
+type stubAnnotator struct {
+	stubEvaluator
+	notes []safety.SegmentNote
+}
+
+func (s stubAnnotator) Annotate(_ context.Context, _ string, _ []string) ([]safety.SegmentNote, error) {
+	return s.notes, s.err
+}
+
+func TestAnnotate(t *testing.T) {
+	want := []safety.SegmentNote{
+		{Input: "The current directory", Output: "File names", Explanation: "Lists files"},
+		{Input: "File names", Output: "A count", Explanation: "Counts lines"},
+	}
+	client := startServer(t, NewServer(llm.NewMockClient(), stubAnnotator{notes: want}))
+
+	got, err := client.Annotate(context.Background(), "ls | wc -l", []string{"ls", "wc -l"})
+	if err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Annotate() = %+v, want %+v", got, want)
+	}
+
+	client = startServer(t, NewServer(llm.NewMockClient(), stubEvaluator{}))
+	if _, err := client.Annotate(context.Background(), "ls | wc -l", []string{"ls", "wc -l"}); err == nil {
+		t.Error("Annotate() without an annotator succeeded, want an error")
+	}
+}
@@ -0,0 +1,168 @@
+// Package daemon keeps a 1lm backend running behind a unix socket so each
+// query skips process startup, config loading and the TLS handshake.
+//
+// The protocol is one JSON request and one JSON response per connection:
+//
+//	{"op": "generate", "query": "find large files"}
+//	{"options": [{"title": ..., "command": ..., "description": ..., "confidence": ...}]}
+//
+//	{"op": "evaluate", "commands": ["rm -rf build"]}
+//	{"risks": [{"level": "high", "message": "Deletes files"}]}
+//
+//	{"op": "explain", "command": "rm -rf build"}
+//	{"detail": {"level": "high", "reasoning": ..., "paths": ["build"], ...}}
+//
+//	{"op": "annotate", "command": "ls | wc -l", "commands": ["ls", "wc -l"]}
+//	{"notes": [{"input": ..., "output": ..., "explanation": ...}, ...]}
+//
+// Queries that aren't shell tasks get {"refusal": {"reason": ..., "suggestion": ...}},
+// and failures are reported as {"error": "..."}.
+package daemon
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+const (
+	opGenerate = "generate"
+	opEvaluate = "evaluate"
+	opExplain  = "explain"
+	opAnnotate = "annotate"
+)
+
+// request is sent by the client.
+type request struct {
+	Op       string   `json:"op"`
+	Query    string   `json:"query,omitempty"`
+	Commands []string `json:"commands,omitempty"`
+	Command  string   `json:"command,omitempty"`
+}
+
+// response is sent by the server. Risks has one entry per evaluated
+// command, null for commands with no detected risk. Missing lists the
+// commands the evaluation left out, which are null in Risks too.
+type response struct {
+	Options []llm.CommandOption `json:"options,omitempty"`
+	Refusal *llm.Refusal        `json:"refusal,omitempty"`
+	Risks   []*risk             `json:"risks,omitempty"`
+	Missing []int               `json:"missing,omitempty"`
+	Detail  *detail             `json:"detail,omitempty"`
+	Notes   []note              `json:"notes,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// risk is the wire form of safety.RiskInfo.
+type risk struct {
+	Level    string `json:"level"`
+	Message  string `json:"message"`
+	Fragment string `json:"fragment,omitempty"`
+
+	SaferCommand     string `json:"safer_command,omitempty"`
+	SaferDescription string `json:"safer_description,omitempty"`
+}
+
+// detail is the wire form of safety.RiskDetail.
+type detail struct {
+	Level      string   `json:"level"`
+	Reasoning  string   `json:"reasoning"`
+	DataLoss   string   `json:"data_loss,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+	Reversible string   `json:"reversible"`
+	Recovery   string   `json:"recovery"`
+}
+
+// note is the wire form of safety.SegmentNote.
+type note struct {
+	Input       string `json:"input"`
+	Output      string `json:"output"`
+	Explanation string `json:"explanation"`
+}
+
+// Public: Returns the default socket path, ~/.config/1lm/daemon/daemon.sock.
+// The socket gets a directory of its own so Listen can restrict it without
+// touching the rest of the config directory.
+func SocketPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon", "daemon.sock"), nil
+}
+
+func encodeRisks(infos []*safety.RiskInfo) []*risk {
+	risks := make([]*risk, len(infos))
+	for i, info := range infos {
+		if info != nil {
+			risks[i] = &risk{
+				Level:    strings.ToLower(info.Level.String()),
+				Message:  info.Message,
+				Fragment: info.Fragment,
+			}
+			if info.Safer != nil {
+				risks[i].SaferCommand = info.Safer.Command
+				risks[i].SaferDescription = info.Safer.Description
+			}
+		}
+	}
+	return risks
+}
+
+func decodeRisks(risks []*risk) []*safety.RiskInfo {
+	infos := make([]*safety.RiskInfo, len(risks))
+	for i, r := range risks {
+		if r != nil {
+			infos[i] = &safety.RiskInfo{
+				Level:    safety.ParseRiskLevel(r.Level),
+				Message:  r.Message,
+				Fragment: r.Fragment,
+			}
+			if r.SaferCommand != "" {
+				infos[i].Safer = &safety.Alternative{Command: r.SaferCommand, Description: r.SaferDescription}
+			}
+		}
+	}
+	return infos
+}
+
+func encodeDetail(d *safety.RiskDetail) *detail {
+	return &detail{
+		Level:      strings.ToLower(d.Level.String()),
+		Reasoning:  d.Reasoning,
+		DataLoss:   d.DataLoss,
+		Paths:      d.Paths,
+		Reversible: d.Reversible,
+		Recovery:   d.Recovery,
+	}
+}
+
+func decodeDetail(d *detail) *safety.RiskDetail {
+	return &safety.RiskDetail{
+		Level:      safety.ParseRiskLevel(d.Level),
+		Reasoning:  d.Reasoning,
+		DataLoss:   d.DataLoss,
+		Paths:      d.Paths,
+		Reversible: d.Reversible,
+		Recovery:   d.Recovery,
+	}
+}
+
+func encodeNotes(notes []safety.SegmentNote) []note {
+	wire := make([]note, len(notes))
+	for i, n := range notes {
+		wire[i] = note{Input: n.Input, Output: n.Output, Explanation: n.Explanation}
+	}
+	return wire
+}
+
+func decodeNotes(wire []note) []safety.SegmentNote {
+	notes := make([]safety.SegmentNote, len(wire))
+	for i, n := range wire {
+		notes[i] = safety.SegmentNote{Input: n.Input, Output: n.Output, Explanation: n.Explanation}
+	}
+	return notes
+}
@@ -0,0 +1,168 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Server answers client requests using long-lived generation and safety
+// clients, so their HTTP connections stay warm between queries.
+type Server struct {
+	client    llm.Client
+	evaluator commands.RiskEvaluator
+}
+
+// Public: Creates a server backed by the given clients. A nil evaluator
+// answers evaluate requests with no risks.
+func NewServer(client llm.Client, evaluator commands.RiskEvaluator) *Server {
+	return &Server{client: client, evaluator: evaluator}
+}
+
+// Public: Listens on a unix socket at path, reachable only by the current
+// user. The socket needs a directory of its own, since the socket itself
+// can only be restricted once it exists: it is created with mode 0700, and
+// one that already exists must be owned by the current user with mode
+// 0700. An existing directory is refused rather than changed, since it may
+// be shared with other files.
+//
+// A leftover socket from a daemon that exited uncleanly is removed; a
+// socket that still accepts connections is an error.
+//
+// Returns the listener.
+func Listen(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := checkSocketDir(dir); err != nil {
+		return nil, fmt.Errorf("unsafe socket directory: %w", err)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = l.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	return l, nil
+}
+
+// Public: Accepts connections until ctx is cancelled, handling each on its
+// own goroutine. Closes l before returning.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept failed: %w", err)
+		}
+		go func() {
+			// A client that hung up only loses its own answer.
+			if err := s.handle(ctx, conn); err != nil {
+				slog.Warn("daemon connection failed", "err", err)
+			}
+		}()
+	}
+}
+
+// handle answers one request on conn, returning an error if the answer
+// couldn't be sent.
+func (s *Server) handle(ctx context.Context, conn net.Conn) (err error) {
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close connection: %w", closeErr))
+		}
+	}()
+
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return json.NewEncoder(conn).Encode(response{Error: fmt.Sprintf("invalid request: %v", err)})
+	}
+
+	return json.NewEncoder(conn).Encode(s.respond(ctx, req))
+}
+
+func (s *Server) respond(ctx context.Context, req request) response {
+	switch req.Op {
+	case opGenerate:
+		options, err := s.client.GenerateOptions(ctx, req.Query)
+		var refusal *llm.Refusal
+		if errors.As(err, &refusal) {
+			return response{Refusal: refusal}
+		}
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Options: options}
+
+	case opEvaluate:
+		if s.evaluator == nil {
+			return response{Risks: make([]*risk, len(req.Commands))}
+		}
+		infos, err := s.evaluator.Evaluate(ctx, req.Commands)
+		var incomplete *safety.IncompleteError
+		if errors.As(err, &incomplete) && len(infos) == len(req.Commands) {
+			return response{Risks: encodeRisks(infos), Missing: incomplete.Missing}
+		}
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Risks: encodeRisks(infos)}
+
+	case opExplain:
+		explainer, ok := s.evaluator.(commands.RiskExplainer)
+		if !ok {
+			return response{Error: "detailed safety analysis isn't available from the daemon"}
+		}
+		d, err := explainer.Explain(ctx, req.Command)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Detail: encodeDetail(d)}
+
+	case opAnnotate:
+		annotator, ok := s.evaluator.(commands.PipelineAnnotator)
+		if !ok {
+			return response{Error: "pipeline annotation isn't available from the daemon"}
+		}
+		notes, err := annotator.Annotate(ctx, req.Command, req.Commands)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Notes: encodeNotes(notes)}
+	}
+
+	return response{Error: fmt.Sprintf("unknown op %q", req.Op)}
+}
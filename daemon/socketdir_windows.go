@@ -0,0 +1,22 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkSocketDir refuses a socket directory that isn't a real directory.
+// Windows has no mode bits to check; the directory's ACL, inherited from
+// the user's profile, keeps other users out.
+func checkSocketDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return nil
+}
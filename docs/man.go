@@ -0,0 +1,185 @@
+// Package docs looks up local documentation for commands so generated
+// flags can be checked against ground truth.
+package docs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// helpTimeout bounds the --help fallback, since it runs an arbitrary
+// binary from PATH.
+const helpTimeout = 2 * time.Second
+
+// overstrike matches the backspace sequences man uses for bold and
+// underline when writing to a non-terminal.
+var overstrike = regexp.MustCompile(".\x08")
+
+// Public: Fetches the manual page for a binary as plain text.
+//
+// Falls back to running "<binary> --help" when no man page is installed,
+// but only for binaries found on PATH.
+//
+// ctx    - Context for cancellation
+// binary - Program name, e.g. "find"
+//
+// Returns the documentation text, or an error if neither source works.
+func Lookup(ctx context.Context, binary string) (string, error) {
+	if binary == "" {
+		return "", fmt.Errorf("no command to look up")
+	}
+
+	if text, err := manPage(ctx, binary); err == nil {
+		return text, nil
+	}
+
+	if _, err := exec.LookPath(binary); err != nil {
+		return "", fmt.Errorf("no manual entry or executable for %s", binary)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, helpTimeout)
+	defer cancel()
+
+	// Many tools print --help to stderr or exit non-zero, so take combined
+	// output and only fail if there is nothing to show.
+	out, _ := exec.CommandContext(ctx, binary, "--help").CombinedOutput()
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return "", fmt.Errorf("no manual entry or --help output for %s", binary)
+	}
+	return string(out), nil
+}
+
+// manPage renders a man page without a pager or terminal formatting.
+func manPage(ctx context.Context, binary string) (string, error) {
+	cmd := exec.CommandContext(ctx, "man", binary)
+	cmd.Env = append(os.Environ(),
+		"MANPAGER=cat",
+		"PAGER=cat",
+		"MANWIDTH=80",
+		"GROFF_NO_SGR=1",
+	)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return overstrike.ReplaceAllString(string(out), ""), nil
+}
+
+// maxDefinitionLines caps how much of each flag's description is shown.
+const maxDefinitionLines = 12
+
+// Public: Extracts the parts of a manual relevant to the given flags.
+//
+// Returns the NAME line followed by each flag's definition paragraph. When
+// no flag definitions are found the opening of the page is returned instead,
+// so the user always sees something.
+func Excerpt(page string, flags []string) string {
+	lines := strings.Split(page, "\n")
+
+	var b strings.Builder
+	if name := nameLine(lines); name != "" {
+		b.WriteString(name)
+		b.WriteString("\n\n")
+	}
+
+	found := false
+	for _, flag := range flags {
+		if def := definition(lines, flag); def != "" {
+			b.WriteString(def)
+			b.WriteString("\n\n")
+			found = true
+		}
+	}
+
+	if !found {
+		return strings.Join(lines[:min(len(lines), 30)], "\n")
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// nameLine returns the first line of the NAME section, which summarizes
+// what the program does.
+func nameLine(lines []string) string {
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "NAME" {
+			continue
+		}
+		for _, next := range lines[i+1:] {
+			if text := strings.TrimSpace(next); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// definition finds the paragraph defining flag: the line that introduces
+// it plus the more-indented lines that follow.
+func definition(lines []string, flag string) string {
+	for i, line := range lines {
+		if !definesFlag(line, flag) {
+			continue
+		}
+
+		depth := indentation(line)
+		block := []string{strings.TrimRight(line, " ")}
+		for _, next := range lines[i+1:] {
+			if len(block) >= maxDefinitionLines {
+				break
+			}
+			if strings.TrimSpace(next) != "" && indentation(next) <= depth {
+				break
+			}
+			block = append(block, strings.TrimRight(next, " "))
+		}
+
+		return strings.TrimRight(strings.Join(block, "\n"), "\n")
+	}
+	return ""
+}
+
+// optionSeparators splits an option list like "-p, --patch=<n>" into names.
+var optionSeparators = regexp.MustCompile(`[,=\[<\s]+`)
+
+// definesFlag reports whether line starts an option list containing flag,
+// e.g. "   -p, -u, --patch" defines both -p and --patch.
+func definesFlag(line, flag string) bool {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "-") {
+		return false
+	}
+
+	// The option list ends where the prose begins, marked by a run of
+	// spaces in most man page layouts.
+	list, _, _ := strings.Cut(trimmed, "  ")
+	for _, name := range optionSeparators.Split(list, -1) {
+		if name == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// indentation counts leading spaces, treating a tab as eight.
+func indentation(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 8
+		default:
+			return n
+		}
+	}
+	return n
+}
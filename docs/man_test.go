@@ -0,0 +1,100 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+const samplePage = `LS(1)                     User Commands                    LS(1)
+
+NAME
+       ls - list directory contents
+
+SYNOPSIS
+       ls [OPTION]... [FILE]...
+
+DESCRIPTION
+       -a, --all
+              do not ignore entries starting with .
+
+       -l     use a long listing format
+
+       --color[=WHEN]
+              color the output WHEN; more info below
+
+       -r, --reverse
+              reverse order while sorting
+`
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       []string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "short flag with long alias",
+			flags:       []string{"-a"},
+			contains:    []string{"ls - list directory contents", "-a, --all", "do not ignore"},
+			notContains: []string{"reverse order"},
+		},
+		{
+			name:     "long alias",
+			flags:    []string{"--reverse"},
+			contains: []string{"reverse order while sorting"},
+		},
+		{
+			name:     "inline description",
+			flags:    []string{"-l"},
+			contains: []string{"use a long listing format"},
+		},
+		{
+			name:     "optional value",
+			flags:    []string{"--color"},
+			contains: []string{"color the output WHEN"},
+		},
+		{
+			name:     "unknown flag falls back to page opening",
+			flags:    []string{"-Z"},
+			contains: []string{"SYNOPSIS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Excerpt(samplePage, tt.flags)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Excerpt() missing %q, got:\n%s", want, got)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Excerpt() should not contain %q, got:\n%s", unwanted, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDefinesFlag(t *testing.T) {
+	tests := []struct {
+		line string
+		flag string
+		want bool
+	}{
+		{line: "       -p, -u, --patch", flag: "--patch", want: true},
+		{line: "       -p, -u, --patch", flag: "-u", want: true},
+		{line: "       -S<string>", flag: "-S", want: true},
+		{line: "       -l     use a long listing format", flag: "-l", want: true},
+		{line: "       -l     use -a too", flag: "-a", want: false},
+		{line: "       mentions -p in prose", flag: "-p", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := definesFlag(tt.line, tt.flag); got != tt.want {
+			t.Errorf("definesFlag(%q, %q) = %v, want %v", tt.line, tt.flag, got, tt.want)
+		}
+	}
+}
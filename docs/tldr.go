@@ -0,0 +1,152 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// tldrCacheDirs lists where common tldr clients keep their downloaded
+// pages. Each entry is a directory containing platform subdirectories
+// (common, linux, osx).
+func tldrCacheDirs() []string {
+	var dirs []string
+
+	// Explicit overrides used by tealdeer and the C client.
+	if dir := os.Getenv("TEALDEER_CACHE_DIR"); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, "tldr-pages", "pages"))
+	}
+	if dir := os.Getenv("TLDR_CACHE_DIR"); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, "pages"))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return dirs
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = filepath.Join(home, ".cache")
+	}
+
+	return append(dirs,
+		filepath.Join(cache, "tealdeer", "tldr-pages", "pages"), // tealdeer
+		filepath.Join(cache, "tlrc", "pages.en"),                // tlrc
+		filepath.Join(cache, "tldr", "pages"),                   // python client
+		filepath.Join(home, ".tldrc", "tldr", "pages"),          // C client
+		filepath.Join(home, ".tldr", "cache", "pages"),          // node client
+	)
+}
+
+// tldrPlatforms returns page subdirectories to search, most specific first.
+func tldrPlatforms() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"osx", "common"}
+	case "windows":
+		return []string{"windows", "common"}
+	default:
+		return []string{runtime.GOOS, "common"}
+	}
+}
+
+// Public: Finds the cached tldr page for a binary. Never touches the
+// network; pages must already have been downloaded by a tldr client.
+//
+// binary - Program name, e.g. "tar"
+//
+// Returns the raw markdown page, or an error if no cache has it.
+func TLDR(binary string) (string, error) {
+	if binary == "" {
+		return "", fmt.Errorf("no command to look up")
+	}
+
+	for _, dir := range tldrCacheDirs() {
+		for _, platform := range tldrPlatforms() {
+			data, err := os.ReadFile(filepath.Join(dir, platform, binary+".md"))
+			if err == nil {
+				return string(data), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no cached tldr page for %s (run your tldr client's update)", binary)
+}
+
+// Public: Renders a tldr page as plain text and cross-checks the flags a
+// command uses against the page's examples.
+//
+// Flags that never appear in an example aren't necessarily wrong, but
+// they're the ones worth checking in the man page.
+//
+// page  - Raw tldr markdown
+// flags - Flags used by the generated command
+//
+// Returns the formatted page with a cross-check summary.
+func FormatTLDR(page string, flags []string) string {
+	var b strings.Builder
+	var examples []string
+
+	for _, line := range strings.Split(page, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "# "):
+			b.WriteString(strings.TrimPrefix(line, "# "))
+			b.WriteString("\n")
+		case strings.HasPrefix(line, "> "):
+			b.WriteString(strings.TrimPrefix(line, "> "))
+			b.WriteString("\n")
+		case strings.HasPrefix(line, "- "):
+			b.WriteString("\n")
+			b.WriteString(strings.TrimPrefix(line, "- "))
+			b.WriteString("\n")
+		case strings.HasPrefix(line, "`") && strings.HasSuffix(line, "`"):
+			example := strings.Trim(line, "`")
+			examples = append(examples, example)
+			b.WriteString("    ")
+			b.WriteString(example)
+			b.WriteString("\n")
+		}
+	}
+
+	if len(flags) == 0 {
+		return strings.TrimRight(b.String(), "\n")
+	}
+
+	var seen, unseen []string
+	for _, flag := range flags {
+		if slices.ContainsFunc(examples, func(ex string) bool { return containsWord(ex, flag) }) {
+			seen = append(seen, flag)
+		} else {
+			unseen = append(unseen, flag)
+		}
+	}
+
+	b.WriteString("\n")
+	if len(seen) > 0 {
+		fmt.Fprintf(&b, "Flags shown in tldr examples: %s\n", strings.Join(seen, " "))
+	}
+	if len(unseen) > 0 {
+		fmt.Fprintf(&b, "Flags not in tldr examples (check the man page): %s\n", strings.Join(unseen, " "))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// containsWord reports whether flag appears in text as a whole word, so
+// "-r" doesn't match inside "--recursive". Placeholders like "{{path}}"
+// and "=" values are treated as boundaries.
+func containsWord(text, flag string) bool {
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '=' || r == '{' || r == '}'
+	}) {
+		if word == flag {
+			return true
+		}
+	}
+	return false
+}
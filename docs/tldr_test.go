@@ -0,0 +1,65 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const sampleTLDR = "# tar\n\n" +
+	"> Archiving utility.\n" +
+	"> More information: <https://www.gnu.org/software/tar>.\n\n" +
+	"- Create an archive from files:\n\n" +
+	"`tar cf {{path/to/target.tar}} {{path/to/file1}}`\n\n" +
+	"- Extract a gzipped archive:\n\n" +
+	"`tar -x -z -f {{path/to/source.tar.gz}}`\n"
+
+func TestFormatTLDR(t *testing.T) {
+	got := FormatTLDR(sampleTLDR, []string{"-x", "-z", "--overwrite"})
+
+	for _, want := range []string{
+		"Archiving utility.",
+		"Extract a gzipped archive:",
+		"    tar -x -z -f {{path/to/source.tar.gz}}",
+		"Flags shown in tldr examples: -x -z",
+		"Flags not in tldr examples (check the man page): --overwrite",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatTLDR() missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatTLDRWithoutFlags(t *testing.T) {
+	got := FormatTLDR(sampleTLDR, nil)
+	if strings.Contains(got, "Flags") {
+		t.Errorf("FormatTLDR() with no flags should skip cross-check, got:\n%s", got)
+	}
+}
+
+func TestTLDRFromCache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TLDR_CACHE_DIR", dir)
+
+	pages := filepath.Join(dir, "pages", "common")
+	if err := os.MkdirAll(pages, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pages, "tar.md"), []byte(sampleTLDR), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := TLDR("tar")
+	if err != nil {
+		t.Fatalf("TLDR() error = %v", err)
+	}
+	if page != sampleTLDR {
+		t.Errorf("TLDR() returned unexpected page: %q", page)
+	}
+
+	if _, err := TLDR("definitely-not-a-command-" + runtime.GOOS); err == nil {
+		t.Error("TLDR() for missing page should error")
+	}
+}
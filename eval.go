@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/eval"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// runEval runs a golden suite against the configured provider, model and
+// prompt, and reports which cases pass.
+func runEval(cfg *config.Config, _ ui.Settings, args []string) error {
+	suite, err := eval.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	// A running daemon may have loaded an older prompt or config than the
+	// one being evaluated.
+	*noDaemon = true
+
+	generator, err := newGenerator(cfg)
+	if err != nil {
+		return err
+	}
+
+	queries := make([]string, len(suite.Cases))
+	for i, c := range suite.Cases {
+		queries[i] = c.Query
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results := generator.GenerateBatch(ctx, queries, batchOptions())
+
+	failed := 0
+	for i, r := range results {
+		c := suite.Cases[i]
+
+		var failures []string
+		switch {
+		case r.Err != nil:
+			failures = []string{r.Err.Error()}
+		case c.ChecksRisk() && r.SafetyErr != nil:
+			failures = []string{fmt.Sprintf("safety evaluation failed: %v", r.SafetyErr)}
+		default:
+			failures = c.Check(r.Options)
+		}
+
+		if len(failures) == 0 {
+			fmt.Printf("PASS  %s\n", c.Name)
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL  %s\n", c.Name)
+		for _, f := range failures {
+			fmt.Printf("      %s\n", f)
+		}
+		for _, o := range r.Options {
+			fmt.Printf("      > %s\n", o.Command)
+		}
+	}
+
+	fmt.Printf("\n%d/%d passed\n", len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cases failed", failed, len(results))
+	}
+	return nil
+}
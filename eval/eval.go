@@ -0,0 +1,123 @@
+// Package eval checks generated commands against golden cases, giving
+// regression signal when tuning prompts, models or providers.
+package eval
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Case is a golden query and the properties its options must have. Unset
+// fields aren't checked.
+type Case struct {
+	Name  string `toml:"name"`
+	Query string `toml:"query"`
+
+	// Contains must each appear in at least one option's command, and
+	// NotContains in none.
+	Contains    []string `toml:"contains"`
+	NotContains []string `toml:"not_contains"`
+
+	// Uses must each be run by at least one option, and Avoids by none
+	// (e.g. "sudo"). Tools are matched as programs, not substrings.
+	Uses   []string `toml:"uses"`
+	Avoids []string `toml:"avoids"`
+
+	// MinRisk and MaxRisk bound every option's risk level: "none", "low"
+	// or "high".
+	MinRisk string `toml:"min_risk"`
+	MaxRisk string `toml:"max_risk"`
+}
+
+// Suite is a file of golden cases.
+type Suite struct {
+	Cases []Case `toml:"cases"`
+}
+
+// Public: Loads a suite from a TOML file of [[cases]] tables.
+func Load(path string) (*Suite, error) {
+	var suite Suite
+	if _, err := toml.DecodeFile(path, &suite); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	for i, c := range suite.Cases {
+		if c.Query == "" {
+			return nil, fmt.Errorf("%s: case %d has no query", path, i+1)
+		}
+		for _, level := range []string{c.MinRisk, c.MaxRisk} {
+			if level != "" && level != "none" && level != "low" && level != "high" {
+				return nil, fmt.Errorf("%s: case %d: unknown risk level %q (want none, low or high)", path, i+1, level)
+			}
+		}
+		if suite.Cases[i].Name == "" {
+			suite.Cases[i].Name = c.Query
+		}
+	}
+
+	return &suite, nil
+}
+
+// Public: Reports whether the case needs safety evaluation.
+func (c Case) ChecksRisk() bool {
+	return c.MinRisk != "" || c.MaxRisk != ""
+}
+
+// Public: Checks generated options against the case.
+//
+// options - The options generated for c.Query, with risk evaluated
+//
+// Returns a description of each failed property; empty means the case
+// passed.
+func (c Case) Check(options []commands.Option) []string {
+	var failures []string
+	if len(options) == 0 {
+		return []string{"no options generated"}
+	}
+
+	for _, want := range c.Contains {
+		if !slices.ContainsFunc(options, func(o commands.Option) bool { return strings.Contains(o.Command, want) }) {
+			failures = append(failures, fmt.Sprintf("no command contains %q", want))
+		}
+	}
+	for _, unwanted := range c.NotContains {
+		for _, o := range options {
+			if strings.Contains(o.Command, unwanted) {
+				failures = append(failures, fmt.Sprintf("%q contains %q", o.Command, unwanted))
+			}
+		}
+	}
+
+	for _, tool := range c.Uses {
+		if !slices.ContainsFunc(options, func(o commands.Option) bool { return slices.Contains(commands.UsedBinaries(o.Command), tool) }) {
+			failures = append(failures, fmt.Sprintf("no command uses %s", tool))
+		}
+	}
+	for _, tool := range c.Avoids {
+		for _, o := range options {
+			if slices.Contains(commands.UsedBinaries(o.Command), tool) {
+				failures = append(failures, fmt.Sprintf("%q uses %s", o.Command, tool))
+			}
+		}
+	}
+
+	for _, o := range options {
+		level := safety.RiskNone
+		if o.Risk != nil {
+			level = o.Risk.Level
+		}
+		if c.MinRisk != "" && level < safety.ParseRiskLevel(c.MinRisk) {
+			failures = append(failures, fmt.Sprintf("%q is %s risk, want at least %s", o.Command, strings.ToLower(level.String()), c.MinRisk))
+		}
+		if c.MaxRisk != "" && level > safety.ParseRiskLevel(c.MaxRisk) {
+			failures = append(failures, fmt.Sprintf("%q is %s risk, want at most %s", o.Command, strings.ToLower(level.String()), c.MaxRisk))
+		}
+	}
+
+	return failures
+}
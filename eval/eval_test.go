@@ -0,0 +1,111 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestCaseCheck(t *testing.T) {
+	options := []commands.Option{
+		{Command: "git log -S myFunction"},
+		{Command: "sudo rm -rf /tmp/build", Risk: &safety.RiskInfo{Level: safety.RiskHigh}},
+	}
+
+	tests := []struct {
+		name string
+		c    Case
+		want []string
+	}{
+		{name: "no checks", c: Case{}},
+		{name: "contains", c: Case{Contains: []string{"-S"}}},
+		{name: "contains missing", c: Case{Contains: []string{"--pickaxe"}}, want: []string{`no command contains "--pickaxe"`}},
+		{name: "not contains", c: Case{NotContains: []string{"rm -rf"}}, want: []string{`contains "rm -rf"`}},
+		{name: "uses", c: Case{Uses: []string{"git", "rm"}}},
+		{name: "uses missing", c: Case{Uses: []string{"rg"}}, want: []string{"no command uses rg"}},
+		{name: "avoids sudo", c: Case{Avoids: []string{"sudo"}}, want: []string{"uses sudo"}},
+		{name: "avoids is not a substring match", c: Case{Avoids: []string{"log"}}},
+		{name: "min risk", c: Case{MinRisk: "high"}, want: []string{`"git log -S myFunction" is none risk, want at least high`}},
+		{name: "max risk", c: Case{MaxRisk: "low"}, want: []string{"is high risk, want at most low"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.c.Check(options)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Check() = %q, want %d failures", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("Check()[%d] = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCaseCheckNoOptions(t *testing.T) {
+	if got := (Case{}).Check(nil); len(got) != 1 {
+		t.Errorf("Check(nil) = %q, want one failure", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			content: `
+[[cases]]
+query = "search git history for myFunction"
+contains = ["-S"]
+avoids = ["sudo"]
+
+[[cases]]
+name = "destructive"
+query = "delete everything in /tmp/build"
+min_risk = "high"
+`,
+		},
+		{
+			name:    "missing query",
+			content: "[[cases]]\nname = \"x\"\n",
+			wantErr: "has no query",
+		},
+		{
+			name:    "bad risk level",
+			content: "[[cases]]\nquery = \"x\"\nmin_risk = \"severe\"\n",
+			wantErr: "unknown risk level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "golden.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			suite, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(suite.Cases) != 2 || suite.Cases[0].Name != suite.Cases[0].Query || !suite.Cases[1].ChecksRisk() {
+				t.Errorf("Load() = %+v", suite.Cases)
+			}
+		})
+	}
+}
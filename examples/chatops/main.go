@@ -0,0 +1,117 @@
+// Chatops serves 1lm over HTTP in the shape of a chat slash command: POST a
+// form with a "text" field and get back the options as JSON. It uses a
+// custom safety evaluator so the bot can refuse commands its policy
+// forbids outright before asking the model.
+//
+//	ANTHROPIC_API_KEY=sk-ant-... go run ./examples/chatops
+//	curl -d text="show disk usage by directory" localhost:8080/1lm
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+const model = "claude-sonnet-4-5-20250929"
+
+// forbidden lists binaries the bot always reports as high risk, without
+// spending an API call on them.
+var forbidden = map[string]bool{"rm": true, "dd": true, "mkfs": true, "shutdown": true}
+
+// policyEvaluator applies the local forbidden list and defers everything
+// else to the standard LLM evaluator.
+type policyEvaluator struct {
+	next *safety.Evaluator
+}
+
+func (p policyEvaluator) Evaluate(ctx context.Context, cmds []string) ([]*safety.RiskInfo, error) {
+	risks, err := p.next.Evaluate(ctx, cmds)
+	if err != nil {
+		risks = make([]*safety.RiskInfo, len(cmds))
+	}
+
+	for i, cmd := range cmds {
+		if forbidden[commands.PrimaryBinary(cmd)] {
+			risks[i] = &safety.RiskInfo{Level: safety.RiskHigh, Message: "Blocked by chatops policy"}
+		}
+	}
+
+	return risks, nil
+}
+
+type optionJSON struct {
+	Title       string `json:"title"`
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	Risk        string `json:"risk"`
+	RiskReason  string `json:"risk_reason,omitempty"`
+}
+
+func main() {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		log.Fatal("ANTHROPIC_API_KEY not set")
+	}
+
+	client, err := llm.NewAnthropicClient(apiKey, model)
+	if err != nil {
+		log.Fatal(err)
+	}
+	anthropicClient := anthropic.NewClient(option.WithAPIKey(apiKey))
+	generator := commands.NewGeneratorWithEvaluator(client, policyEvaluator{
+		next: safety.NewEvaluator(&anthropicClient, model),
+	})
+
+	http.HandleFunc("POST /1lm", func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.FormValue("text"))
+		if query == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		options, err := generator.Generate(ctx, query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if evaluated, err := generator.EvaluateSafety(ctx, options); err == nil {
+			options = evaluated
+		}
+
+		out := make([]optionJSON, len(options))
+		for i, opt := range options {
+			out[i] = optionJSON{
+				Title:       opt.Title,
+				Command:     opt.Command,
+				Description: opt.Description,
+				Risk:        safety.RiskNone.String(),
+			}
+			if opt.Risk != nil {
+				out[i].Risk = opt.Risk.Level.String()
+				out[i].RiskReason = opt.Risk.Message
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			log.Printf("write response: %v", err)
+		}
+	})
+
+	log.Println("listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
@@ -0,0 +1,66 @@
+// Generate shows the smallest useful embedding of 1lm: generate options for
+// a query, evaluate them for risk, and print them without any TUI.
+//
+//	ANTHROPIC_API_KEY=sk-ant-... go run ./examples/generate "find large files"
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
+)
+
+const model = "claude-sonnet-4-5-20250929"
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("ANTHROPIC_API_KEY not set")
+	}
+	if len(os.Args) < 2 {
+		return fmt.Errorf("usage: generate <query>")
+	}
+	query := strings.Join(os.Args[1:], " ")
+
+	client, err := llm.NewAnthropicClient(apiKey, model)
+	if err != nil {
+		return err
+	}
+	anthropicClient := anthropic.NewClient(option.WithAPIKey(apiKey))
+	generator := commands.NewGenerator(client, &anthropicClient, model)
+
+	ctx := context.Background()
+	options, err := generator.Generate(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	// Safety evaluation is best-effort; keep the unevaluated options if it
+	// fails.
+	if evaluated, err := generator.EvaluateSafety(ctx, options); err == nil {
+		options = evaluated
+	}
+
+	for _, opt := range options {
+		fmt.Printf("%s\n  %s\n  %s\n", opt.Title, opt.Command, opt.Description)
+		if opt.Risk != nil {
+			fmt.Printf("  Risk: %s - %s\n", opt.Risk.Level, opt.Risk.Message)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// Plugin is a minimal provider plugin. It answers every query with fixed
+// options, showing the request/response shapes a real provider would use
+// to call its own model.
+//
+//	go build -o ~/bin/1lm-echo ./examples/plugin
+//	# config.toml: provider = "plugin:1lm-echo"
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pixielabs/1lm/llm"
+)
+
+func main() {
+	var req llm.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid request: %v\n", err)
+		os.Exit(1)
+	}
+
+	res := llm.PluginResponse{}
+	if req.Version != llm.PluginProtocolVersion {
+		res.Error = fmt.Sprintf("unsupported protocol version %d", req.Version)
+	} else {
+		res.Options = []llm.CommandOption{
+			{
+				Title:       "Echo the query",
+				Command:     fmt.Sprintf("echo %q", req.Query),
+				Description: "Prints the query back; replace with a call to your model",
+			},
+		}
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(res); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// unattended is set when there's no terminal to show the UI on, so the
+// first option is output without the selector.
+var unattended bool
+
+// runFirst runs query for --first: it outputs the first option that could
+// be selected, skipping those the policy blocks or that don't parse, as
+// if it had been picked in the selector.
+func runFirst(cfg *config.Config, settings ui.Settings, query string) error {
+	if query == "" {
+		return errors.New("--first needs a query")
+	}
+
+	generator, options, allowed, err := selectableOptions(cfg, query)
+	if err != nil {
+		return err
+	}
+	selected := &allowed[0]
+
+	return release(cfg, query, options, selected, func() error {
+		recordHistory(loadHistory(cfg), query, options, selected, generator)
+		return emit(query, selected, settings)
+	})
+}
+
+// runUnattended answers query when there's no terminal for the UI, such
+// as under cron or in CI. It behaves as --first, printing the command
+// alone and undecorated, and prints it rather than copying it to a
+// clipboard nobody is at.
+func runUnattended(cfg *config.Config, settings ui.Settings, query string) error {
+	if query == "" {
+		return errors.New(`no terminal to prompt for a query: pass it as an argument, or pipe it in with "1lm -"`)
+	}
+	slog.Info("no terminal for the selector, outputting the first option")
+
+	unattended = true
+	settings.Plain, settings.Quiet = true, true
+	return runFirst(cfg, settings, query)
+}
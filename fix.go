@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/ui"
+	"golang.org/x/term"
+)
+
+// fixArgs matches "fix <exit-status> <command...>", the form the shell
+// integration sends. "1lm fix the build" stays a query, which the model
+// handles sensibly anyway.
+func fixArgs(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+	_, err := strconv.Atoi(args[0])
+	return err == nil
+}
+
+// runFix asks for corrected versions of a failed command and runs them
+// through the normal selector. Error output is read from stdin when it's
+// piped, e.g. "make 2>&1 | 1lm fix 2 make".
+func runFix(cfg *config.Config, settings ui.Settings, args []string) error {
+	status, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid exit status %q", args[0])
+	}
+
+	failure := commands.Failure{
+		Command:    strings.TrimSpace(strings.Join(args[1:], " ")),
+		ExitStatus: status,
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		if failure.Output, err = readStdin(); err != nil {
+			return err
+		}
+	}
+
+	return runQuery(cfg, settings, failure.Query())
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/output"
+)
+
+// printOptions runs query for --output fzf: rather than showing the
+// selector, it prints every option that could be selected as a line for
+// fzf to pick from.
+func printOptions(cfg *config.Config, query string) error {
+	if query == "" {
+		return errors.New("--output fzf needs a query")
+	}
+
+	_, _, allowed, err := selectableOptions(cfg, query)
+	if err != nil {
+		return err
+	}
+
+	// fzf picks among every option printed, so all of them are released.
+	return release(cfg, query, allowed, nil, func() error {
+		return output.NewHandler(output.ModeFZF, output.DecorationPlain).WriteOptions(allowed)
+	})
+}
+
+// allowedOptions returns the options that could be selected: allowed by
+// the policy and valid for the target shell.
+func allowedOptions(options []commands.Option) []commands.Option {
+	var allowed []commands.Option
+	for _, opt := range options {
+		if opt.Blocked == "" && opt.ShellError == "" && checkPolicy(&opt) == nil {
+			allowed = append(allowed, opt)
+		}
+	}
+	return allowed
+}
+
+// selectableOptions generates options for query without the selector, and
+// returns the generator, every option and those that could be selected:
+// allowed by the policy and valid for the target shell. Options are
+// safety checked first, so the policy's risk limit applies as it would in
+// the selector.
+func selectableOptions(cfg *config.Config, query string) (*commands.Generator, []commands.Option, []commands.Option, error) {
+	generator, err := newGenerator(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	limitRequestSize(cfg, generator)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	options, err := generator.Generate(ctx, query)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate options: %w", err)
+	}
+	evaluated, err := generator.EvaluateSafety(ctx, options)
+	switch {
+	case err != nil && generator.RequiresSafety():
+		return nil, nil, nil, fmt.Errorf("the policy requires a safety check, which failed: %w", err)
+	case err != nil:
+		slog.Warn("safety check failed", "err", err)
+		// Options come back only when the policy blocked them.
+		if evaluated != nil {
+			options = evaluated
+		}
+	default:
+		options = evaluated
+	}
+
+	allowed := allowedOptions(options)
+	if len(allowed) == 0 {
+		return nil, nil, nil, errors.New("every option was blocked by policy or has invalid syntax")
+	}
+
+	return generator, options, allowed, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/history"
+	"github.com/pixielabs/1lm/i18n"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// loadHistory returns the query history, or nil when it is turned off or
+// can't be read. History is a convenience, so problems with it never stop
+// a query.
+func loadHistory(cfg *config.Config) *history.History {
+	if cfg.History == "off" {
+		return nil
+	}
+	past, err := history.Load()
+	if err != nil {
+		return nil
+	}
+	return past
+}
+
+// recordHistory adds the query and the selected command, if any, with its
+// risk, to the history loaded by loadHistory. Where the command was among
+// the options, how long the model took and the tokens this run used are
+// kept for "1lm stats". A dry run records nothing, as it has no other
+// side effects.
+func recordHistory(past *history.History, query string, options []commands.Option, selected *commands.Option, generator *commands.Generator) {
+	if past == nil || query == "" || *dryRun {
+		return
+	}
+
+	entry := history.NewEntry(query, selected)
+	entry.Options = len(options)
+	if selected != nil {
+		entry.Position = slices.IndexFunc(options, func(opt commands.Option) bool { return opt.Command == selected.Command }) + 1
+	}
+	entry.LatencyMS = generator.Latency().Milliseconds()
+	for model, usage := range llm.UsageByModel() {
+		entry.InputTokens += usage.InputTokens
+		entry.OutputTokens += usage.OutputTokens
+		entry.Cost += usage.Cost(model)
+	}
+	_ = past.Record(entry)
+}
+
+// runHistory runs "1lm history", which lists past queries, oldest first,
+// with the command chosen for each and its risk when it was chosen.
+func runHistory(cfg *config.Config, settings ui.Settings, _ []string) error {
+	if cfg.History == "off" {
+		fmt.Println(`History is turned off (history = "off" in the config).`)
+		return nil
+	}
+
+	past, err := history.Load()
+	if err != nil {
+		return err
+	}
+	if len(past.Entries) == 0 {
+		fmt.Println("No history yet.")
+		return nil
+	}
+
+	for _, entry := range past.Entries {
+		fmt.Printf("%s  %s\n", entry.Time.Local().Format("2006-01-02 15:04"), entry.Query)
+		if entry.Command == "" {
+			continue
+		}
+		opt := entry.Option()
+		fmt.Printf("  %s\n", opt.Command)
+		if opt.Risk != nil {
+			risk := fmt.Sprintf("%s - %s", i18n.T(settings.Language, opt.Risk.Level.String()), opt.Risk.Message)
+			fmt.Printf("  %s\n", i18n.Tf(settings.Language, "Risk: %s", risk))
+		}
+	}
+	return nil
+}
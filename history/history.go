@@ -0,0 +1,264 @@
+// Package history keeps the queries asked and the commands chosen for
+// them, so past questions can be recalled from the input prompt.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// MaxEntries is how many entries are kept; older ones are dropped.
+const MaxEntries = 1000
+
+// Entry is one query and the command chosen for it.
+type Entry struct {
+	Time  time.Time `json:"time"`
+	Query string    `json:"query"`
+	// Command is the selected command, or empty when the user quit.
+	Command string `json:"command,omitempty"`
+	// RiskLevel and RiskReason are the command's safety verdict when it
+	// was chosen, so it can be shown again without re-evaluating.
+	RiskLevel  string `json:"risk_level,omitempty"`
+	RiskReason string `json:"risk_reason,omitempty"`
+	// Position is where the chosen command was in the list of Options
+	// offered, counting from 1, for stats.
+	Position int `json:"position,omitempty"`
+	Options  int `json:"options,omitempty"`
+	// LatencyMS is how long the model took to answer, and the tokens and
+	// list price in US dollars are what the query cost.
+	LatencyMS    int64   `json:"latency_ms,omitempty"`
+	InputTokens  int64   `json:"input_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+}
+
+// History is the list of past entries backed by a JSON Lines file, oldest
+// first.
+type History struct {
+	path    string
+	Entries []Entry
+}
+
+// Public: Returns the path of the history file.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// Public: Loads the history from its default location.
+func Load() (*History, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFrom(path)
+}
+
+// Public: Loads the history from path. A missing file is an empty history,
+// and lines that can't be parsed are skipped rather than losing the rest.
+func LoadFrom(path string) (*History, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &History{path: path}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entries, err := parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return &History{path: path, Entries: entries}, nil
+}
+
+// parse reads JSON Lines entries, skipping lines that can't be parsed.
+func parse(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil && entry.Query != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Public: Records a query and the command chosen for it (empty if none).
+// The file is appended to, and rewritten without the oldest entries once
+// it holds more than MaxEntries.
+func (h *History) Add(query, command string) error {
+	return h.Record(Entry{Time: time.Now().UTC(), Query: query, Command: command})
+}
+
+// Public: Records a query and the option chosen for it, with the option's
+// risk, as Add does.
+func (h *History) AddOption(query string, opt commands.Option) error {
+	return h.Record(NewEntry(query, &opt))
+}
+
+// Public: Builds an entry for query and the option chosen for it, nil if
+// none, with the option's risk. Fields for stats are left for the caller.
+func NewEntry(query string, opt *commands.Option) Entry {
+	entry := Entry{Time: time.Now().UTC(), Query: query}
+	if opt == nil {
+		return entry
+	}
+	entry.Command = opt.Command
+	if opt.Risk != nil && opt.Risk.Level != safety.RiskNone {
+		entry.RiskLevel = strings.ToLower(opt.Risk.Level.String())
+		entry.RiskReason = opt.Risk.Message
+	}
+	return entry
+}
+
+// Public: Records an entry, as Add does.
+func (h *History) Record(entry Entry) error {
+	h.Entries = append(h.Entries, entry)
+
+	if len(h.Entries) > MaxEntries {
+		h.Entries = h.Entries[len(h.Entries)-MaxEntries:]
+		return h.write(os.O_TRUNC, h.Entries)
+	}
+	return h.write(os.O_APPEND, []Entry{entry})
+}
+
+// write writes entries to the file, appending or truncating per mode.
+func (h *History) write(mode int, entries []Entry) (err error) {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|mode, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	enc := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Public: Returns the history in its JSON Lines format, to share with
+// another machine. Secrets in queries, commands and risk reasons are
+// replaced with placeholders unless keepSecrets is set.
+func (h *History) Export(keepSecrets bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range h.Entries {
+		if !keepSecrets {
+			entry.Query = commands.RedactSecrets(entry.Query)
+			entry.Command = commands.RedactSecrets(entry.Command)
+			entry.RiskReason = commands.RedactSecrets(entry.RiskReason)
+		}
+		if err := enc.Encode(entry); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Public: Merges another machine's history file, in the same JSON Lines
+// format, into this one: entries from both are kept in time order, once
+// each, and the file is rewritten. Nothing is lost by merging either way,
+// so machines that sync converge on the same history. An entry matches its
+// redacted copy from Export, and the local one, with its secrets, is kept.
+func (h *History) Merge(data []byte) error {
+	remote, err := parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to read synced history: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var merged []Entry
+	for _, entry := range append(h.Entries, remote...) {
+		key := entry.Time.UTC().Format(time.RFC3339Nano) + "\x00" +
+			commands.RedactSecrets(entry.Query) + "\x00" + commands.RedactSecrets(entry.Command)
+		if !seen[key] {
+			seen[key] = true
+			merged = append(merged, entry)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b Entry) int { return a.Time.Compare(b.Time) })
+	if len(merged) > MaxEntries {
+		merged = merged[len(merged)-MaxEntries:]
+	}
+
+	h.Entries = merged
+	return h.write(os.O_TRUNC, h.Entries)
+}
+
+// Public: Returns each distinct query, most recent first.
+func (h *History) Queries() []string {
+	seen := map[string]bool{}
+	var queries []string
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		query := h.Entries[i].Query
+		if !seen[query] {
+			seen[query] = true
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+// Public: Finds the most recent entry for query, ignoring case and
+// spacing, in which a command was chosen.
+//
+// Returns the entry and whether one was found.
+func (h *History) Find(query string) (Entry, bool) {
+	want := normalizeQuery(query)
+	if want == "" {
+		return Entry{}, false
+	}
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		entry := h.Entries[i]
+		if entry.Command != "" && normalizeQuery(entry.Query) == want {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// normalizeQuery lowercases query and collapses its whitespace, so
+// repeats typed slightly differently still match.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// Public: Converts the entry's command back to a command option, titled
+// with its query and with its saved risk restored.
+func (e Entry) Option() commands.Option {
+	opt := commands.Option{Title: e.Query, Command: e.Command}
+	if level := safety.ParseRiskLevel(e.RiskLevel); level != safety.RiskNone {
+		opt.Risk = &safety.RiskInfo{Level: level, Message: e.RiskReason}
+	}
+	return opt
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	h, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error = %v", err)
+	}
+
+	for _, e := range []Entry{
+		{Query: "list open ports", Command: "ss -tlnp"},
+		{Query: "find large files", Command: ""},
+		{Query: "list open ports", Command: "lsof -i -P"},
+	} {
+		if err := h.Add(e.Query, e.Command); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if len(reloaded.Entries) != 3 || reloaded.Entries[2].Command != "lsof -i -P" {
+		t.Errorf("Entries = %+v", reloaded.Entries)
+	}
+
+	want := []string{"list open ports", "find large files"}
+	if got := reloaded.Queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Queries() = %v, want %v", got, want)
+	}
+}
+
+func TestHistorySkipsBadLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	data := "not json\n{\"query\":\"list files\",\"command\":\"ls\"}\n{\"command\":\"no query\"}\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if len(h.Entries) != 1 || h.Entries[0].Query != "list files" {
+		t.Errorf("Entries = %+v, want just list files", h.Entries)
+	}
+}
+
+func TestHistoryDropsOldest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h, _ := LoadFrom(path)
+	for i := 0; i < MaxEntries+5; i++ {
+		h.Entries = append(h.Entries, Entry{Query: "old"})
+	}
+	if err := h.Add("newest", "ls"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if len(reloaded.Entries) != MaxEntries || reloaded.Entries[MaxEntries-1].Query != "newest" {
+		t.Errorf("got %d entries ending in %+v", len(reloaded.Entries), reloaded.Entries[len(reloaded.Entries)-1])
+	}
+}
+
+func TestHistoryKeepsRisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h, _ := LoadFrom(path)
+
+	risky := commands.Option{
+		Title:   "Remove build",
+		Command: "rm -rf build",
+		Risk:    &safety.RiskInfo{Level: safety.RiskHigh, Message: "deletes build recursively"},
+	}
+	if err := h.AddOption("clean build", risky); err != nil {
+		t.Fatalf("AddOption() error = %v", err)
+	}
+	if err := h.AddOption("list files", commands.Option{Command: "ls"}); err != nil {
+		t.Fatalf("AddOption() error = %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	opt := reloaded.Entries[0].Option()
+	if opt.Command != "rm -rf build" || opt.Title != "clean build" {
+		t.Errorf("Option() = %+v, want the command titled with its query", opt)
+	}
+	if opt.Risk == nil || opt.Risk.Level != safety.RiskHigh || opt.Risk.Message != "deletes build recursively" {
+		t.Errorf("Option().Risk = %v, want the saved high risk", opt.Risk)
+	}
+	if risk := reloaded.Entries[1].Option().Risk; risk != nil {
+		t.Errorf("Option().Risk = %v, want none for a safe command", risk)
+	}
+}
+
+func TestHistoryMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h, _ := LoadFrom(path)
+	at := func(minute int) time.Time { return time.Date(2026, 10, 1, 9, minute, 0, 0, time.UTC) }
+
+	h.Entries = []Entry{
+		{Time: at(1), Query: "list files", Command: "ls"},
+		{Time: at(3), Query: "show disk usage", Command: "df -h"},
+	}
+	remote := `{"time":"2026-10-01T09:01:00Z","query":"list files","command":"ls"}
+{"time":"2026-10-01T09:02:00Z","query":"find large files","command":"du -ah | sort -h"}
+not json
+`
+	if err := h.Merge([]byte(remote)); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	var got []string
+	for _, e := range reloaded.Entries {
+		got = append(got, e.Query)
+	}
+	if want := []string{"list files", "find large files", "show disk usage"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged queries = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryExport(t *testing.T) {
+	const secret = "sk-ant-REDACTED"
+	at := time.Date(2026, 10, 1, 9, 1, 0, 0, time.UTC)
+	h, _ := LoadFrom(filepath.Join(t.TempDir(), "history.jsonl"))
+	h.Entries = []Entry{{Time: at, Query: "call the API with " + secret, Command: "curl -H 'x-api-key: " + secret + "' api"}}
+
+	shared, err := h.Export(false)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if strings.Contains(string(shared), secret) {
+		t.Errorf("Export() = %s, want the key redacted", shared)
+	}
+	if kept, _ := h.Export(true); !strings.Contains(string(kept), secret) {
+		t.Errorf("Export(keepSecrets) = %s, want the key kept", kept)
+	}
+
+	// Merging the redacted copy back keeps the original, once.
+	if err := h.Merge(shared); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(h.Entries) != 1 || !strings.Contains(h.Entries[0].Query, secret) {
+		t.Errorf("merged entries = %+v, want only the original", h.Entries)
+	}
+}
+
+func TestHistoryFind(t *testing.T) {
+	h := &History{Entries: []Entry{
+		{Query: "list open ports", Command: "lsof -i -P"},
+		{Query: "List open  ports", Command: "ss -tlnp"},
+		{Query: "list open ports"}, // cancelled
+		{Query: "disk usage", Command: "du -sh *"},
+	}}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"list open ports", "ss -tlnp"},
+		{"  LIST OPEN PORTS ", "ss -tlnp"},
+		{"disk usage", "du -sh *"},
+		{"list ports", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			entry, ok := h.Find(tt.query)
+			if ok != (tt.want != "") || entry.Command != tt.want {
+				t.Errorf("Find(%q) = %q, %v, want %q", tt.query, entry.Command, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package history
+
+import (
+	"cmp"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+// Stats summarizes how 1lm has been used, from the history alone.
+type Stats struct {
+	Queries int
+	Chosen  int     // queries a command was chosen for
+	Topics  []Count // words most often in queries
+	Tools   []Count // programs most often in chosen commands
+	// Positions has how often the option at each position was chosen
+	// when offered, for entries that recorded it.
+	Positions []Acceptance
+	// Latency is how long the model took to answer, on average.
+	Latency time.Duration
+	Months  []Month // oldest first
+}
+
+// Count is how many times something came up.
+type Count struct {
+	Name  string
+	Count int
+}
+
+// Acceptance is how often the option at a position was chosen.
+type Acceptance struct {
+	Position int // counting from 1
+	Offered  int
+	Chosen   int
+}
+
+// Public: Returns the share of queries offering an option at this position
+// that chose it, from 0 to 1.
+func (a Acceptance) Rate() float64 {
+	if a.Offered == 0 {
+		return 0
+	}
+	return float64(a.Chosen) / float64(a.Offered)
+}
+
+// Month totals a calendar month's queries and what they cost.
+type Month struct {
+	Month        string // e.g. "2026-10"
+	Queries      int
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64 // list price in US dollars
+}
+
+// topicWord splits queries into words for Stats.
+var topicWord = regexp.MustCompile(`[a-z][a-z0-9+#.-]*[a-z0-9+#]`)
+
+// stopWords are too common in queries to say what they are about.
+var stopWords = map[string]bool{
+	"all": true, "and": true, "any": true, "are": true, "but": true,
+	"can": true, "for": true, "from": true, "get": true, "how": true,
+	"into": true, "its": true, "list": true, "make": true, "not": true,
+	"one": true, "only": true, "out": true, "show": true, "than": true,
+	"that": true, "the": true, "them": true, "then": true, "this": true,
+	"use": true, "using": true, "what": true, "which": true, "with": true,
+	"without": true, "you": true, "your": true,
+}
+
+// Public: Summarizes the history, keeping the top most common topics and
+// tools.
+func (h *History) Stats(top int) Stats {
+	var s Stats
+	topics := map[string]int{}
+	tools := map[string]int{}
+	var positions []Acceptance
+	var latency time.Duration
+	answered := 0
+	months := map[string]*Month{}
+
+	for _, e := range h.Entries {
+		s.Queries++
+
+		for _, word := range uniqueStrings(topicWord.FindAllString(strings.ToLower(e.Query), -1)) {
+			if !stopWords[word] {
+				topics[word]++
+			}
+		}
+
+		if e.Command != "" {
+			s.Chosen++
+			for _, tool := range uniqueStrings(commands.UsedBinaries(e.Command)) {
+				tools[tool]++
+			}
+		}
+
+		for p := 1; p <= e.Options; p++ {
+			if p > len(positions) {
+				positions = append(positions, Acceptance{Position: p})
+			}
+			positions[p-1].Offered++
+			if e.Position == p {
+				positions[p-1].Chosen++
+			}
+		}
+
+		if e.LatencyMS > 0 {
+			latency += time.Duration(e.LatencyMS) * time.Millisecond
+			answered++
+		}
+
+		key := e.Time.Local().Format("2006-01")
+		month, ok := months[key]
+		if !ok {
+			month = &Month{Month: key}
+			months[key] = month
+		}
+		month.Queries++
+		month.InputTokens += e.InputTokens
+		month.OutputTokens += e.OutputTokens
+		month.Cost += e.Cost
+	}
+
+	s.Topics = topCounts(topics, top)
+	s.Tools = topCounts(tools, top)
+	s.Positions = positions
+	if answered > 0 {
+		s.Latency = latency / time.Duration(answered)
+	}
+	for _, key := range slices.Sorted(maps.Keys(months)) {
+		s.Months = append(s.Months, *months[key])
+	}
+	return s
+}
+
+// topCounts returns the n highest counts, highest first and then by name.
+func topCounts(counts map[string]int, n int) []Count {
+	var sorted []Count
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	slices.SortFunc(sorted, func(a, b Count) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// uniqueStrings drops repeats from list, so a word or tool counts once
+// per entry.
+func uniqueStrings(list []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
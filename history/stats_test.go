@@ -0,0 +1,54 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	sept := time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC)
+	oct := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	h := &History{Entries: []Entry{
+		{Time: sept, Query: "find large log files", Command: "find . -name '*.log' -size +100M", Position: 1, Options: 3, LatencyMS: 1000, InputTokens: 400, OutputTokens: 100, Cost: 0.003},
+		{Time: oct, Query: "compress log files", Command: "tar czf logs.tgz *.log | tee out", Position: 2, Options: 3, LatencyMS: 3000, InputTokens: 500, OutputTokens: 200, Cost: 0.005},
+		{Time: oct, Query: "show the disk usage", Options: 2},
+		{Time: oct, Query: "list files by size", Command: "ls -S"},
+	}}
+
+	s := h.Stats(2)
+
+	if s.Queries != 4 || s.Chosen != 3 {
+		t.Errorf("Queries, Chosen = %d, %d, want 4, 3", s.Queries, s.Chosen)
+	}
+	if want := []Count{{"files", 3}, {"log", 2}}; !reflect.DeepEqual(s.Topics, want) {
+		t.Errorf("Topics = %v, want %v", s.Topics, want)
+	}
+	if want := []Count{{"find", 1}, {"ls", 1}}; !reflect.DeepEqual(s.Tools, want) {
+		t.Errorf("Tools = %v, want %v", s.Tools, want)
+	}
+
+	wantPositions := []Acceptance{
+		{Position: 1, Offered: 3, Chosen: 1},
+		{Position: 2, Offered: 3, Chosen: 1},
+		{Position: 3, Offered: 2, Chosen: 0},
+	}
+	if !reflect.DeepEqual(s.Positions, wantPositions) {
+		t.Errorf("Positions = %v, want %v", s.Positions, wantPositions)
+	}
+	if got := s.Positions[0].Rate(); got < 0.333 || got > 0.334 {
+		t.Errorf("Rate() = %v, want 1/3", got)
+	}
+
+	if s.Latency != 2*time.Second {
+		t.Errorf("Latency = %v, want the 2s average of the answered queries", s.Latency)
+	}
+
+	wantMonths := []Month{
+		{Month: "2026-09", Queries: 1, InputTokens: 400, OutputTokens: 100, Cost: 0.003},
+		{Month: "2026-10", Queries: 3, InputTokens: 500, OutputTokens: 200, Cost: 0.005},
+	}
+	if !reflect.DeepEqual(s.Months, wantMonths) {
+		t.Errorf("Months = %v, want %v", s.Months, wantMonths)
+	}
+}
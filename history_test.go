@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/history"
+)
+
+func TestRecordHistoryDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantEntries int
+	}{
+		{name: "normal run", wantEntries: 1},
+		{name: "dry run", dryRun: true, wantEntries: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(saved bool) { *dryRun = saved }(*dryRun)
+			*dryRun = tt.dryRun
+
+			path := filepath.Join(t.TempDir(), "history.jsonl")
+			past, err := history.LoadFrom(path)
+			if err != nil {
+				t.Fatalf("LoadFrom() error = %v", err)
+			}
+			selected := commands.Option{Title: "Disk usage", Command: "df -h"}
+			recordHistory(past, "free space", []commands.Option{selected}, &selected, commands.NewGeneratorWithEvaluator(nil, nil))
+
+			reloaded, err := history.LoadFrom(path)
+			if err != nil {
+				t.Fatalf("LoadFrom() error = %v", err)
+			}
+			if len(reloaded.Entries) != tt.wantEntries {
+				t.Errorf("history has %d entries, want %d", len(reloaded.Entries), tt.wantEntries)
+			}
+		})
+	}
+}
@@ -0,0 +1,588 @@
+package i18n
+
+// catalogs holds UI translations keyed by language code and then by the
+// English message. Format verbs must appear in the same order as in the
+// English message.
+var catalogs = map[string]map[string]string{
+	"de": {
+		// Input and loading
+		"What command do you need?":               "Welchen Befehl brauchst du?",
+		"e.g., search git history for myFunction": "z. B. Git-Verlauf nach myFunction durchsuchen",
+		"Enter to submit":                         "Enter zum Absenden",
+		"Esc/Ctrl+C to quit":                      "Esc/Strg+C zum Beenden",
+		"Generating options...":                   "Optionen werden erstellt...",
+		"Rate limited, retrying in %ds...":        "Ratenlimit erreicht, neuer Versuch in %ds...",
+		"Blocked by policy: %s":                   "Durch Richtlinie gesperrt: %s",
+		"Redacted before sending: %s":             "Vor dem Senden entfernt: %s",
+		"Context trimmed for token limit: %s":     "Kontext für das Token-Limit gekürzt: %s",
+		"clipboard":                               "Zwischenablage",
+		"standard input":                          "Standardeingabe",
+		"Not a shell task: %s":                    "Keine Aufgabe für die Shell: %s",
+		"Try instead: %s":                         "Versuch stattdessen: %s",
+		"Possible prompt injection in: %s":        "Mögliche Prompt-Injection in: %s",
+		"private key":                             "privater Schlüssel",
+		"API key":                                 "API-Schlüssel",
+		"AWS access key":                          "AWS-Zugangsschlüssel",
+		"token":                                   "Token",
+		"password":                                "Passwort",
+		"secret":                                  "Geheimnis",
+		"private IP address":                      "private IP-Adresse",
+		"Policy requires a safety check; wait for it to finish": "Die Richtlinie verlangt eine Sicherheitsprüfung; bitte warten, bis sie fertig ist",
+
+		// Selector
+		"Select a command:":               "Befehl auswählen:",
+		"checking safety...":              "Sicherheit wird geprüft...",
+		"No options match the filter":     "Keine Optionen passen zum Filter",
+		"No history matches":              "Kein Verlaufseintrag passt",
+		"Uses avoided tool: %s":           "Verwendet gemiedenes Werkzeug: %s",
+		"Breaks constraint: %s":           "Verletzt Einschränkung: %s",
+		"Syntax error: %s":                "Syntaxfehler: %s",
+		"Invalid shell syntax: %s":        "Ungültige Shell-Syntax: %s",
+		"Fixed: %s":                       "Korrigiert: %s",
+		"May split on spaces: %s":         "Kann an Leerzeichen zerfallen: %s",
+		"Safer version of: %s":            "Sicherere Version von: %s",
+		"Answered by fallback model %s":   "Antwort vom Ersatzmodell %s",
+		"%s: %d options.":                 "%s: %d Optionen.",
+		"From %s":                         "Von %s",
+		"filter":                          "Filter",
+		"Search history":                  "Verlauf durchsuchen",
+		"%s %d more lines (v: view full)": "%s %d weitere Zeilen (v: alles anzeigen)",
+		"Could not save snippet: %v":      "Snippet konnte nicht gespeichert werden: %v",
+		"Saved snippet %q":                "Snippet %q gespeichert",
+		"Replace with placeholders: %s":   "Platzhalter einsetzen für: %s",
+		"[low risk]":                      "[geringes Risiko]",
+		"[HIGH RISK]":                     "[HOHES RISIKO]",
+		"Low":                             "Gering",
+		"High":                            "Hoch",
+		"[CRITICAL RISK]":                 "[KRITISCHES RISIKO]",
+		"Critical":                        "Kritisch",
+		"recommended":                     "empfohlen",
+		"invalid syntax":                  "ungültige Syntax",
+		"read-only":                       "nur lesend",
+		"network":                         "Netzwerk",
+		"writes files":                    "schreibt Dateien",
+		"irreversible":                    "unumkehrbar",
+		"Type %s to confirm:":             "Tippe %s zur Bestätigung:",
+		"Confirmation doesn't match":      "Bestätigung stimmt nicht überein",
+
+		// Help
+		"↑/↓: move":          "↑/↓: bewegen",
+		"↑/k: up":            "↑/k: hoch",
+		"↓/j: down":          "↓/j: runter",
+		"/: filter":          "/: filtern",
+		"v: view full":       "v: alles anzeigen",
+		"f: format":          "f: umbrechen",
+		"tab: expand":        "Tab: aufklappen",
+		"m/t: man/tldr":      "m/t: man/tldr",
+		"s: save":            "s: speichern",
+		"i: safety details":  "i: Sicherheitsdetails",
+		"p: pipeline steps":  "p: Pipeline-Schritte",
+		"enter: select":      "Enter: auswählen",
+		"esc: clear filter":  "Esc: Filter löschen",
+		"q: quit":            "q: beenden",
+		"←/→: switch model":  "←/→: Modell wechseln",
+		"e: expression only": "e: nur Ausdruck",
+		"↑/↓: scroll":        "↑/↓: blättern",
+		"↑/↓: history":       "↑/↓: Verlauf",
+		"alt+enter: newline": "Alt+Enter: neue Zeile",
+		"→: accept":          "→: übernehmen",
+		"ctrl+r: search":     "Strg+R: suchen",
+		"esc/q: back":        "Esc/q: zurück",
+		"esc: back":          "Esc: zurück",
+		"enter: confirm":     "Enter: bestätigen",
+		"esc: cancel":        "Esc: abbrechen",
+		"y: placeholders":    "y: Platzhalter",
+		"n: save as is":      "n: unverändert",
+
+		// Documentation pager
+		"Loading %s...":            "%s wird geladen...",
+		"Loading documentation...": "Dokumentation wird geladen...",
+		"Safety details":           "Sicherheitsdetails",
+		"Analyzing risks...":       "Risiken werden analysiert...",
+		"Data at risk:":            "Gefährdete Daten:",
+		"Affected paths:":          "Betroffene Pfade:",
+		"Reversible: %s":           "Umkehrbar: %s",
+		"Pipeline steps":           "Pipeline-Schritte",
+		"Analyzing pipeline...":    "Pipeline wird analysiert...",
+		"In: %s":                   "Ein: %s",
+		"Out: %s":                  "Aus: %s",
+		"yes":                      "ja",
+		"partly":                   "teilweise",
+		"no":                       "nein",
+
+		// Screen reader announcements
+		"%d options. Up and down to move, enter to select, q to quit.":           "%d Optionen. Hoch und runter zum Bewegen, Enter zum Auswählen, q zum Beenden.",
+		"%d options generated. Up and down to move, enter to select, q to quit.": "%d Optionen erstellt. Hoch und runter zum Bewegen, Enter zum Auswählen, q zum Beenden.",
+		"Option %d of %d: %s. Command: %s":                                       "Option %d von %d: %s. Befehl: %s",
+		"%s risk: %s":                                                            "Risiko %s: %s",
+		"Option %d: %s risk: %s":                                                 "Option %d: Risiko %s: %s",
+		"Option %d is a safer version of: %s":                                    "Option %d ist eine sicherere Version von: %s",
+		"Critical risk: %s. Type %s to confirm, or escape to cancel.":            "Kritisches Risiko: %s. Tippe %s zur Bestätigung oder Escape zum Abbrechen.",
+		"Safety check complete.":                                                 "Sicherheitsprüfung abgeschlossen.",
+		"Safety check unavailable.":                                              "Sicherheitsprüfung nicht verfügbar.",
+		"No options match the filter.":                                           "Keine Optionen passen zum Filter.",
+		"Back to the prompt.":                                                    "Zurück zur Eingabe.",
+		"%d options match.":                                                      "%d Optionen passen.",
+
+		// Output
+		"No option selected":            "Keine Option ausgewählt",
+		"Selected command:":             "Ausgewählter Befehl:",
+		"Copied to clipboard:":          "In die Zwischenablage kopiert:",
+		"Sent to Neovim:":               "An Neovim gesendet:",
+		"Written to file:":              "In Datei geschrieben:",
+		"Clipboard not available":       "Zwischenablage nicht verfügbar",
+		"Dry run, no output performed.": "Probelauf, keine Ausgabe erfolgt.",
+		"Would %s:":                     "Würde %s:",
+		"Risk: %s":                      "Risiko: %s",
+		"none detected":                 "keines erkannt",
+		"Risky part: %s":                "Riskanter Teil: %s",
+		"Safer: %s":                     "Sicherer: %s",
+		"print the command for the shell function to insert into the prompt": "den Befehl ausgeben, damit die Shell-Funktion ihn in die Eingabezeile einfügt",
+		"print the command to stdout":                                        "den Befehl auf stdout ausgeben",
+		"print the command as JSON to stdout":                                "den Befehl als JSON auf stdout ausgeben",
+		"copy the command to the clipboard (falling back to stdout)":         "den Befehl in die Zwischenablage kopieren (sonst auf stdout ausgeben)",
+		"insert the command into Neovim":                                     "den Befehl in Neovim einfügen",
+		"print the command as a markdown block to stdout":                    "den Befehl als Markdown-Block auf stdout ausgeben",
+		"write the command to a file as an executable script":                "den Befehl als ausführbares Skript in eine Datei schreiben",
+
+		// Script builder
+		"Script":                               "Skript",
+		"Select a command to add a step first": "Wähle zuerst einen Befehl als Schritt aus",
+		"Added step %d: %s":                    "Schritt %d hinzugefügt: %s",
+		"No step added: %v":                    "Kein Schritt hinzugefügt: %v",
+		"Step %d: %s":                          "Schritt %d: %s",
+		"Step removed. %d steps.":              "Schritt entfernt. %d Schritte.",
+		"Script not saved":                     "Skript nicht gespeichert",
+		"K/J: reorder":                         "K/J: verschieben",
+		"d: remove":                            "d: entfernen",
+		"tab: back":                            "Tab: zurück",
+		"tab: edit steps":                      "Tab: Schritte bearbeiten",
+		"ctrl+s: save script":                  "Strg+S: Skript speichern",
+		"Each command you select is added here as a step.": "Jeder ausgewählte Befehl wird hier als Schritt hinzugefügt.",
+
+		// Errors
+		"Couldn't copy to the clipboard": "Kopieren in die Zwischenablage fehlgeschlagen",
+		"Couldn't write the file":        "Datei konnte nicht geschrieben werden",
+		"Couldn't send to Neovim":        "Senden an Neovim fehlgeschlagen",
+		"Couldn't output the command":    "Befehl konnte nicht ausgegeben werden",
+		"Trying again...":                "Neuer Versuch...",
+		"r: retry":                       "r: erneut versuchen",
+		"c: copy instead":                "c: stattdessen kopieren",
+		"p: print instead":               "p: stattdessen ausgeben",
+		"%s: %v. r to retry, p to print instead, escape to go back.": "%s: %v. r zum erneuten Versuch, p zum Ausgeben, Escape zum Zurückgehen.",
+		"Couldn't generate options":                                  "Optionen konnten nicht erstellt werden",
+		"e: edit query":                                              "e: Anfrage bearbeiten",
+		"Couldn't generate options: %v. r to retry, e to edit the query, q to quit.": "Optionen konnten nicht erstellt werden: %v. r zum erneuten Versuch, e zum Bearbeiten der Anfrage, q zum Beenden.",
+
+		// Safety check timeouts
+		"safety check didn't finish (r: check again)":     "Sicherheitsprüfung nicht abgeschlossen (r: erneut prüfen)",
+		"r: recheck safety":                               "r: erneut prüfen",
+		"Checking safety again...":                        "Sicherheit wird erneut geprüft...",
+		"unverified":                                      "ungeprüft",
+		"Safety check timed out. Press r to check again.": "Zeitüberschreitung bei der Sicherheitsprüfung. Drücke r, um erneut zu prüfen.",
+
+		// Generation progress
+		"%ds elapsed":                       "%d s vergangen",
+		"Still generating options (%ds)...": "Optionen werden noch erstellt (%ds)...",
+
+		// Local matches
+		"0 (local)":                         "0 (lokal)",
+		"0: use local match":                "0: lokalen Treffer verwenden",
+		"Local match: %s. 0 to use it now.": "Lokaler Treffer: %s. 0, um ihn sofort zu verwenden.",
+
+		// Large requests
+		"Large request": "Große Anfrage",
+		"About %d tokens, including attached context.":                "Etwa %d Tokens, einschließlich angehängtem Kontext.",
+		"About %d tokens, including attached context (~$%.2f on %s).": "Etwa %d Tokens, einschließlich angehängtem Kontext (~$%.2f mit %s).",
+		"y: send anyway": "y: trotzdem senden",
+		"Large request: about %d tokens. y to send it, e to edit the query, q to quit.": "Große Anfrage: etwa %d Tokens. y zum Senden, e zum Bearbeiten der Anfrage, q zum Beenden.",
+	},
+
+	"es": {
+		// Input and loading
+		"What command do you need?":               "¿Qué comando necesitas?",
+		"e.g., search git history for myFunction": "p. ej., buscar myFunction en el historial de git",
+		"Enter to submit":                         "Enter para enviar",
+		"Esc/Ctrl+C to quit":                      "Esc/Ctrl+C para salir",
+		"Generating options...":                   "Generando opciones...",
+		"Rate limited, retrying in %ds...":        "Límite de frecuencia alcanzado, reintentando en %ds...",
+		"Blocked by policy: %s":                   "Bloqueado por la política: %s",
+		"Redacted before sending: %s":             "Ocultado antes de enviar: %s",
+		"Context trimmed for token limit: %s":     "Contexto recortado por el límite de tokens: %s",
+		"clipboard":                               "portapapeles",
+		"standard input":                          "entrada estándar",
+		"Not a shell task: %s":                    "No es una tarea para la shell: %s",
+		"Try instead: %s":                         "Prueba en su lugar: %s",
+		"Possible prompt injection in: %s":        "Posible inyección de instrucciones en: %s",
+		"private key":                             "clave privada",
+		"API key":                                 "clave de API",
+		"AWS access key":                          "clave de acceso de AWS",
+		"token":                                   "token",
+		"password":                                "contraseña",
+		"secret":                                  "secreto",
+		"private IP address":                      "dirección IP privada",
+		"Policy requires a safety check; wait for it to finish": "La política exige una comprobación de seguridad; espera a que termine",
+
+		// Selector
+		"Select a command:":               "Elige un comando:",
+		"checking safety...":              "comprobando seguridad...",
+		"No options match the filter":     "Ninguna opción coincide con el filtro",
+		"No history matches":              "Nada en el historial coincide",
+		"Uses avoided tool: %s":           "Usa una herramienta a evitar: %s",
+		"Breaks constraint: %s":           "Incumple la restricción: %s",
+		"Syntax error: %s":                "Error de sintaxis: %s",
+		"Invalid shell syntax: %s":        "Sintaxis de shell no válida: %s",
+		"Fixed: %s":                       "Corregido: %s",
+		"May split on spaces: %s":         "Puede dividirse en los espacios: %s",
+		"Safer version of: %s":            "Versión más segura de: %s",
+		"Answered by fallback model %s":   "Respuesta del modelo de respaldo %s",
+		"%s: %d options.":                 "%s: %d opciones.",
+		"From %s":                         "De %s",
+		"filter":                          "filtro",
+		"Search history":                  "Buscar en el historial",
+		"%s %d more lines (v: view full)": "%s %d líneas más (v: ver todo)",
+		"Could not save snippet: %v":      "No se pudo guardar el fragmento: %v",
+		"Saved snippet %q":                "Fragmento %q guardado",
+		"Replace with placeholders: %s":   "Sustituir por marcadores: %s",
+		"[low risk]":                      "[riesgo bajo]",
+		"[HIGH RISK]":                     "[RIESGO ALTO]",
+		"Low":                             "Bajo",
+		"High":                            "Alto",
+		"[CRITICAL RISK]":                 "[RIESGO CRÍTICO]",
+		"Critical":                        "Crítico",
+		"recommended":                     "recomendado",
+		"invalid syntax":                  "sintaxis no válida",
+		"read-only":                       "solo lectura",
+		"network":                         "red",
+		"writes files":                    "escribe archivos",
+		"irreversible":                    "irreversible",
+		"Type %s to confirm:":             "Escribe %s para confirmar:",
+		"Confirmation doesn't match":      "La confirmación no coincide",
+
+		// Help
+		"↑/↓: move":          "↑/↓: mover",
+		"↑/k: up":            "↑/k: arriba",
+		"↓/j: down":          "↓/j: abajo",
+		"/: filter":          "/: filtrar",
+		"v: view full":       "v: ver todo",
+		"f: format":          "f: formatear",
+		"tab: expand":        "Tab: desplegar",
+		"m/t: man/tldr":      "m/t: man/tldr",
+		"s: save":            "s: guardar",
+		"i: safety details":  "i: detalles de seguridad",
+		"p: pipeline steps":  "p: pasos de la tubería",
+		"enter: select":      "enter: elegir",
+		"esc: clear filter":  "esc: borrar filtro",
+		"q: quit":            "q: salir",
+		"←/→: switch model":  "←/→: cambiar de modelo",
+		"e: expression only": "e: solo la expresión",
+		"↑/↓: scroll":        "↑/↓: desplazar",
+		"↑/↓: history":       "↑/↓: historial",
+		"alt+enter: newline": "Alt+Enter: nueva línea",
+		"→: accept":          "→: aceptar",
+		"ctrl+r: search":     "Ctrl+R: buscar",
+		"esc/q: back":        "esc/q: volver",
+		"esc: back":          "esc: volver",
+		"enter: confirm":     "Enter: confirmar",
+		"esc: cancel":        "Esc: cancelar",
+		"y: placeholders":    "y: usar marcadores",
+		"n: save as is":      "n: guardar tal cual",
+
+		// Documentation pager
+		"Loading %s...":            "Cargando %s...",
+		"Loading documentation...": "Cargando documentación...",
+		"Safety details":           "Detalles de seguridad",
+		"Analyzing risks...":       "Analizando riesgos...",
+		"Data at risk:":            "Datos en riesgo:",
+		"Affected paths:":          "Rutas afectadas:",
+		"Reversible: %s":           "Reversible: %s",
+		"Pipeline steps":           "Pasos de la tubería",
+		"Analyzing pipeline...":    "Analizando la tubería...",
+		"In: %s":                   "Entrada: %s",
+		"Out: %s":                  "Salida: %s",
+		"yes":                      "sí",
+		"partly":                   "en parte",
+		"no":                       "no",
+
+		// Screen reader announcements
+		"%d options. Up and down to move, enter to select, q to quit.":           "%d opciones. Arriba y abajo para moverte, enter para elegir, q para salir.",
+		"%d options generated. Up and down to move, enter to select, q to quit.": "%d opciones generadas. Arriba y abajo para moverte, enter para elegir, q para salir.",
+		"Option %d of %d: %s. Command: %s":                                       "Opción %d de %d: %s. Comando: %s",
+		"%s risk: %s":                                                            "Riesgo %s: %s",
+		"Option %d: %s risk: %s":                                                 "Opción %d: riesgo %s: %s",
+		"Option %d is a safer version of: %s":                                    "La opción %d es una versión más segura de: %s",
+		"Critical risk: %s. Type %s to confirm, or escape to cancel.":            "Riesgo crítico: %s. Escribe %s para confirmar o Escape para cancelar.",
+		"Safety check complete.":                                                 "Comprobación de seguridad terminada.",
+		"Safety check unavailable.":                                              "Comprobación de seguridad no disponible.",
+		"No options match the filter.":                                           "Ninguna opción coincide con el filtro.",
+		"Back to the prompt.":                                                    "De vuelta a la consulta.",
+		"%d options match.":                                                      "%d opciones coinciden.",
+
+		// Output
+		"No option selected":            "No se eligió ninguna opción",
+		"Selected command:":             "Comando elegido:",
+		"Copied to clipboard:":          "Copiado al portapapeles:",
+		"Sent to Neovim:":               "Enviado a Neovim:",
+		"Written to file:":              "Escrito en el archivo:",
+		"Clipboard not available":       "Portapapeles no disponible",
+		"Dry run, no output performed.": "Simulación, no se realizó ninguna salida.",
+		"Would %s:":                     "Se haría lo siguiente: %s:",
+		"Risk: %s":                      "Riesgo: %s",
+		"none detected":                 "ninguno detectado",
+		"Risky part: %s":                "Parte arriesgada: %s",
+		"Safer: %s":                     "Más seguro: %s",
+		"print the command for the shell function to insert into the prompt": "imprimir el comando para que la función de shell lo inserte en la línea de comandos",
+		"print the command to stdout":                                        "imprimir el comando en stdout",
+		"print the command as JSON to stdout":                                "imprimir el comando como JSON en stdout",
+		"copy the command to the clipboard (falling back to stdout)":         "copiar el comando al portapapeles (o imprimirlo en stdout)",
+		"insert the command into Neovim":                                     "insertar el comando en Neovim",
+		"print the command as a markdown block to stdout":                    "imprimir el comando como bloque Markdown en stdout",
+		"write the command to a file as an executable script":                "escribir el comando en un archivo como script ejecutable",
+
+		// Script builder
+		"Script":                               "Script",
+		"Select a command to add a step first": "Elige primero un comando para añadir un paso",
+		"Added step %d: %s":                    "Paso %d añadido: %s",
+		"No step added: %v":                    "No se añadió ningún paso: %v",
+		"Step %d: %s":                          "Paso %d: %s",
+		"Step removed. %d steps.":              "Paso eliminado. %d pasos.",
+		"Script not saved":                     "Script no guardado",
+		"K/J: reorder":                         "K/J: reordenar",
+		"d: remove":                            "d: eliminar",
+		"tab: back":                            "Tab: volver",
+		"tab: edit steps":                      "Tab: editar pasos",
+		"ctrl+s: save script":                  "Ctrl+S: guardar script",
+		"Each command you select is added here as a step.": "Cada comando que elijas se añade aquí como un paso.",
+
+		// Errors
+		"Couldn't copy to the clipboard": "No se pudo copiar al portapapeles",
+		"Couldn't write the file":        "No se pudo escribir el archivo",
+		"Couldn't send to Neovim":        "No se pudo enviar a Neovim",
+		"Couldn't output the command":    "No se pudo emitir el comando",
+		"Trying again...":                "Reintentando...",
+		"r: retry":                       "r: reintentar",
+		"c: copy instead":                "c: copiar en su lugar",
+		"p: print instead":               "p: imprimir en su lugar",
+		"%s: %v. r to retry, p to print instead, escape to go back.": "%s: %v. r para reintentar, p para imprimir, escape para volver.",
+		"Couldn't generate options":                                  "No se pudieron generar opciones",
+		"e: edit query":                                              "e: editar consulta",
+		"Couldn't generate options: %v. r to retry, e to edit the query, q to quit.": "No se pudieron generar opciones: %v. r para reintentar, e para editar la consulta, q para salir.",
+
+		// Safety check timeouts
+		"safety check didn't finish (r: check again)":     "la comprobación de seguridad no terminó (r: volver a comprobar)",
+		"r: recheck safety":                               "r: volver a comprobar",
+		"Checking safety again...":                        "Comprobando la seguridad de nuevo...",
+		"unverified":                                      "sin verificar",
+		"Safety check timed out. Press r to check again.": "La comprobación de seguridad agotó el tiempo. Pulsa r para volver a comprobar.",
+
+		// Generation progress
+		"%ds elapsed":                       "%d s transcurridos",
+		"Still generating options (%ds)...": "Todavía generando opciones (%ds)...",
+
+		// Local matches
+		"0 (local)":                         "0 (local)",
+		"0: use local match":                "0: usar coincidencia local",
+		"Local match: %s. 0 to use it now.": "Coincidencia local: %s. 0 para usarla ya.",
+
+		// Large requests
+		"Large request": "Solicitud grande",
+		"About %d tokens, including attached context.":                "Unos %d tokens, incluido el contexto adjunto.",
+		"About %d tokens, including attached context (~$%.2f on %s).": "Unos %d tokens, incluido el contexto adjunto (~$%.2f con %s).",
+		"y: send anyway": "y: enviar de todos modos",
+		"Large request: about %d tokens. y to send it, e to edit the query, q to quit.": "Solicitud grande: unos %d tokens. y para enviarla, e para editar la consulta, q para salir.",
+	},
+
+	"fr": {
+		// Input and loading
+		"What command do you need?":               "De quelle commande avez-vous besoin ?",
+		"e.g., search git history for myFunction": "ex. : chercher myFunction dans l'historique git",
+		"Enter to submit":                         "Entrée pour valider",
+		"Esc/Ctrl+C to quit":                      "Échap/Ctrl+C pour quitter",
+		"Generating options...":                   "Génération des options...",
+		"Rate limited, retrying in %ds...":        "Limite de débit atteinte, nouvel essai dans %ds...",
+		"Blocked by policy: %s":                   "Bloqué par la politique : %s",
+		"Redacted before sending: %s":             "Masqué avant l'envoi : %s",
+		"Context trimmed for token limit: %s":     "Contexte raccourci pour la limite de tokens : %s",
+		"clipboard":                               "presse-papiers",
+		"standard input":                          "entrée standard",
+		"Not a shell task: %s":                    "Ce n'est pas une tâche pour le shell : %s",
+		"Try instead: %s":                         "Essayez plutôt : %s",
+		"Possible prompt injection in: %s":        "Injection d'instructions possible dans : %s",
+		"private key":                             "clé privée",
+		"API key":                                 "clé d'API",
+		"AWS access key":                          "clé d'accès AWS",
+		"token":                                   "jeton",
+		"password":                                "mot de passe",
+		"secret":                                  "secret",
+		"private IP address":                      "adresse IP privée",
+		"Policy requires a safety check; wait for it to finish": "La politique exige une vérification de sécurité ; attendez qu'elle se termine",
+
+		// Selector
+		"Select a command:":               "Choisissez une commande :",
+		"checking safety...":              "vérification de la sécurité...",
+		"No options match the filter":     "Aucune option ne correspond au filtre",
+		"No history matches":              "Rien ne correspond dans l'historique",
+		"Uses avoided tool: %s":           "Utilise un outil à éviter : %s",
+		"Breaks constraint: %s":           "Enfreint la contrainte : %s",
+		"Syntax error: %s":                "Erreur de syntaxe : %s",
+		"Invalid shell syntax: %s":        "Syntaxe shell invalide : %s",
+		"Fixed: %s":                       "Corrigé : %s",
+		"May split on spaces: %s":         "Peut être coupé aux espaces : %s",
+		"Safer version of: %s":            "Version plus sûre de : %s",
+		"Answered by fallback model %s":   "Réponse du modèle de secours %s",
+		"%s: %d options.":                 "%s : %d options.",
+		"From %s":                         "De %s",
+		"filter":                          "filtre",
+		"Search history":                  "Rechercher dans l'historique",
+		"%s %d more lines (v: view full)": "%s %d lignes de plus (v : tout afficher)",
+		"Could not save snippet: %v":      "Impossible d'enregistrer l'extrait : %v",
+		"Saved snippet %q":                "Extrait %q enregistré",
+		"Replace with placeholders: %s":   "Remplacer par des variables : %s",
+		"[low risk]":                      "[risque faible]",
+		"[HIGH RISK]":                     "[RISQUE ÉLEVÉ]",
+		"Low":                             "Faible",
+		"High":                            "Élevé",
+		"[CRITICAL RISK]":                 "[RISQUE CRITIQUE]",
+		"Critical":                        "Critique",
+		"recommended":                     "recommandé",
+		"invalid syntax":                  "syntaxe invalide",
+		"read-only":                       "lecture seule",
+		"network":                         "réseau",
+		"writes files":                    "écrit des fichiers",
+		"irreversible":                    "irréversible",
+		"Type %s to confirm:":             "Saisissez %s pour confirmer :",
+		"Confirmation doesn't match":      "La confirmation ne correspond pas",
+
+		// Help
+		"↑/↓: move":          "↑/↓ : déplacer",
+		"↑/k: up":            "↑/k : haut",
+		"↓/j: down":          "↓/j : bas",
+		"/: filter":          "/ : filtrer",
+		"v: view full":       "v : tout afficher",
+		"f: format":          "f : formater",
+		"tab: expand":        "Tab : déplier",
+		"m/t: man/tldr":      "m/t : man/tldr",
+		"s: save":            "s : enregistrer",
+		"i: safety details":  "i : détails de sécurité",
+		"p: pipeline steps":  "p : étapes du pipeline",
+		"enter: select":      "entrée : choisir",
+		"esc: clear filter":  "échap : effacer le filtre",
+		"q: quit":            "q : quitter",
+		"←/→: switch model":  "←/→ : changer de modèle",
+		"e: expression only": "e : expression seule",
+		"↑/↓: scroll":        "↑/↓ : défiler",
+		"↑/↓: history":       "↑/↓ : historique",
+		"alt+enter: newline": "Alt+Entrée : nouvelle ligne",
+		"→: accept":          "→ : accepter",
+		"ctrl+r: search":     "Ctrl+R : rechercher",
+		"esc/q: back":        "échap/q : retour",
+		"esc: back":          "échap : retour",
+		"enter: confirm":     "Entrée : confirmer",
+		"esc: cancel":        "Échap : annuler",
+		"y: placeholders":    "y : variables",
+		"n: save as is":      "n : tel quel",
+
+		// Documentation pager
+		"Loading %s...":            "Chargement de %s...",
+		"Loading documentation...": "Chargement de la documentation...",
+		"Safety details":           "Détails de sécurité",
+		"Analyzing risks...":       "Analyse des risques...",
+		"Data at risk:":            "Données menacées :",
+		"Affected paths:":          "Chemins concernés :",
+		"Reversible: %s":           "Réversible : %s",
+		"Pipeline steps":           "Étapes du pipeline",
+		"Analyzing pipeline...":    "Analyse du pipeline...",
+		"In: %s":                   "Entrée : %s",
+		"Out: %s":                  "Sortie : %s",
+		"yes":                      "oui",
+		"partly":                   "en partie",
+		"no":                       "non",
+
+		// Screen reader announcements
+		"%d options. Up and down to move, enter to select, q to quit.":           "%d options. Haut et bas pour se déplacer, entrée pour choisir, q pour quitter.",
+		"%d options generated. Up and down to move, enter to select, q to quit.": "%d options générées. Haut et bas pour se déplacer, entrée pour choisir, q pour quitter.",
+		"Option %d of %d: %s. Command: %s":                                       "Option %d sur %d : %s. Commande : %s",
+		"%s risk: %s":                                                            "Risque %s : %s",
+		"Option %d: %s risk: %s":                                                 "Option %d : risque %s : %s",
+		"Option %d is a safer version of: %s":                                    "L'option %d est une version plus sûre de : %s",
+		"Critical risk: %s. Type %s to confirm, or escape to cancel.":            "Risque critique : %s. Saisissez %s pour confirmer, ou Échap pour annuler.",
+		"Safety check complete.":                                                 "Vérification de sécurité terminée.",
+		"Safety check unavailable.":                                              "Vérification de sécurité indisponible.",
+		"No options match the filter.":                                           "Aucune option ne correspond au filtre.",
+		"Back to the prompt.":                                                    "Retour à la saisie.",
+		"%d options match.":                                                      "%d options correspondent.",
+
+		// Output
+		"No option selected":            "Aucune option choisie",
+		"Selected command:":             "Commande choisie :",
+		"Copied to clipboard:":          "Copiée dans le presse-papiers :",
+		"Sent to Neovim:":               "Envoyée à Neovim :",
+		"Written to file:":              "Écrite dans le fichier :",
+		"Clipboard not available":       "Presse-papiers indisponible",
+		"Dry run, no output performed.": "Simulation, aucune sortie effectuée.",
+		"Would %s:":                     "Action prévue : %s :",
+		"Risk: %s":                      "Risque : %s",
+		"none detected":                 "aucun détecté",
+		"Risky part: %s":                "Partie risquée : %s",
+		"Safer: %s":                     "Plus sûr : %s",
+		"print the command for the shell function to insert into the prompt": "afficher la commande pour que la fonction shell l'insère dans l'invite",
+		"print the command to stdout":                                        "afficher la commande sur stdout",
+		"print the command as JSON to stdout":                                "afficher la commande en JSON sur stdout",
+		"copy the command to the clipboard (falling back to stdout)":         "copier la commande dans le presse-papiers (sinon l'afficher sur stdout)",
+		"insert the command into Neovim":                                     "insérer la commande dans Neovim",
+		"print the command as a markdown block to stdout":                    "afficher la commande en bloc Markdown sur stdout",
+		"write the command to a file as an executable script":                "écrire la commande dans un fichier comme script exécutable",
+
+		// Script builder
+		"Script":                               "Script",
+		"Select a command to add a step first": "Choisissez d'abord une commande à ajouter comme étape",
+		"Added step %d: %s":                    "Étape %d ajoutée : %s",
+		"No step added: %v":                    "Aucune étape ajoutée : %v",
+		"Step %d: %s":                          "Étape %d : %s",
+		"Step removed. %d steps.":              "Étape supprimée. %d étapes.",
+		"Script not saved":                     "Script non enregistré",
+		"K/J: reorder":                         "K/J : réordonner",
+		"d: remove":                            "d : supprimer",
+		"tab: back":                            "Tab : retour",
+		"tab: edit steps":                      "Tab : modifier les étapes",
+		"ctrl+s: save script":                  "Ctrl+S : enregistrer le script",
+		"Each command you select is added here as a step.": "Chaque commande choisie est ajoutée ici comme une étape.",
+
+		// Errors
+		"Couldn't copy to the clipboard": "Impossible de copier dans le presse-papiers",
+		"Couldn't write the file":        "Impossible d'écrire le fichier",
+		"Couldn't send to Neovim":        "Impossible d'envoyer à Neovim",
+		"Couldn't output the command":    "Impossible de sortir la commande",
+		"Trying again...":                "Nouvel essai...",
+		"r: retry":                       "r : réessayer",
+		"c: copy instead":                "c : copier plutôt",
+		"p: print instead":               "p : afficher plutôt",
+		"%s: %v. r to retry, p to print instead, escape to go back.": "%s : %v. r pour réessayer, p pour afficher, échap pour revenir.",
+		"Couldn't generate options":                                  "Impossible de générer des options",
+		"e: edit query":                                              "e : modifier la requête",
+		"Couldn't generate options: %v. r to retry, e to edit the query, q to quit.": "Impossible de générer des options : %v. r pour réessayer, e pour modifier la requête, q pour quitter.",
+
+		// Safety check timeouts
+		"safety check didn't finish (r: check again)":     "vérification de sécurité inachevée (r : vérifier à nouveau)",
+		"r: recheck safety":                               "r : revérifier",
+		"Checking safety again...":                        "Nouvelle vérification de sécurité...",
+		"unverified":                                      "non vérifié",
+		"Safety check timed out. Press r to check again.": "La vérification de sécurité a expiré. Appuyez sur r pour vérifier à nouveau.",
+
+		// Generation progress
+		"%ds elapsed":                       "%d s écoulées",
+		"Still generating options (%ds)...": "Génération des options toujours en cours (%ds)...",
+
+		// Local matches
+		"0 (local)":                         "0 (local)",
+		"0: use local match":                "0 : utiliser la correspondance locale",
+		"Local match: %s. 0 to use it now.": "Correspondance locale : %s. 0 pour l'utiliser tout de suite.",
+
+		// Large requests
+		"Large request": "Requête volumineuse",
+		"About %d tokens, including attached context.":                "Environ %d tokens, contexte joint compris.",
+		"About %d tokens, including attached context (~$%.2f on %s).": "Environ %d tokens, contexte joint compris (~%.2f $ avec %s).",
+		"y: send anyway": "y : envoyer quand même",
+		"Large request: about %d tokens. y to send it, e to edit the query, q to quit.": "Requête volumineuse : environ %d tokens. y pour l'envoyer, e pour modifier la requête, q pour quitter.",
+	},
+}
@@ -0,0 +1,85 @@
+// Package i18n translates 1lm's user-facing strings.
+//
+// Messages are looked up by their English text, so untranslated strings
+// and unsupported languages fall back to English without any extra work at
+// the call site. Model output (titles, descriptions, risk reasons) is
+// localized by the prompt instead; see Name.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// languageNames maps language codes to the English name used when asking
+// the model to write in that language.
+var languageNames = map[string]string{
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// base returns the lowercase primary subtag of a language code, so
+// "pt-BR", "pt_BR" and "pt_BR.UTF-8" all become "pt".
+func base(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// Public: Returns the English name of a language for use in prompts.
+//
+// lang - A code such as "es" or "pt-BR", or a name such as "Spanish"
+//
+// Returns the name (unknown values are returned unchanged), or "" for
+// English and empty codes since prompts are already in English.
+func Name(lang string) string {
+	b := base(lang)
+	if b == "" || b == "en" || b == "english" {
+		return ""
+	}
+	if name, ok := languageNames[b]; ok {
+		return name
+	}
+	return lang
+}
+
+// Public: Translates an English message into lang, falling back to the
+// message itself when there is no translation.
+func T(lang, msg string) string {
+	if translated, ok := catalogs[base(lang)][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Public: Translates an English format string and formats it with args.
+func Tf(lang, format string, args ...any) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// Public: Returns the language codes with a UI message catalog, English
+// first.
+func Supported() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return append([]string{"en"}, langs...)
+}
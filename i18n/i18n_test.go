@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{lang: "es", want: "Spanish"},
+		{lang: "pt-BR", want: "Portuguese"},
+		{lang: "de_DE.UTF-8", want: "German"},
+		{lang: "Klingon", want: "Klingon"},
+		{lang: "en", want: ""},
+		{lang: "en-GB", want: ""},
+		{lang: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			if got := Name(tt.lang); got != tt.want {
+				t.Errorf("Name(%q) = %q, want %q", tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		msg  string
+		want string
+	}{
+		{name: "translated", lang: "es", msg: "Select a command:", want: "Elige un comando:"},
+		{name: "region falls back to base", lang: "fr-CA", msg: "q: quit", want: "q : quitter"},
+		{name: "english", lang: "en", msg: "Select a command:", want: "Select a command:"},
+		{name: "unsupported language", lang: "ja", msg: "Select a command:", want: "Select a command:"},
+		{name: "unknown message", lang: "de", msg: "Not in catalog", want: "Not in catalog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := T(tt.lang, tt.msg); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.lang, tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTf(t *testing.T) {
+	got := Tf("de", "Saved snippet %q", "list-files")
+	if want := `Snippet "list-files" gespeichert`; got != want {
+		t.Errorf("Tf() = %q, want %q", got, want)
+	}
+}
+
+// verb matches fmt verbs, ignoring escaped percent signs.
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z]`)
+
+func TestCatalogsPreserveFormatVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			want := verb.FindAllString(msg, -1)
+			got := verb.FindAllString(translated, -1)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v", lang, translated, got, want)
+			}
+		}
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	reference := catalogs["es"]
+	for lang, catalog := range catalogs {
+		for msg := range reference {
+			if _, ok := catalog[msg]; !ok {
+				t.Errorf("%s catalog missing %q", lang, msg)
+			}
+		}
+		if len(catalog) != len(reference) {
+			t.Errorf("%s catalog has %d messages, want %d", lang, len(catalog), len(reference))
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	if got, want := Supported(), []string{"en", "de", "es", "fr"}; !slices.Equal(got, want) {
+		t.Errorf("Supported() = %v, want %v", got, want)
+	}
+}
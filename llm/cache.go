@@ -0,0 +1,274 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ErrCacheMiss is returned by CacheOnlyClient for queries with no cached
+// response.
+var ErrCacheMiss = errors.New("no cached response")
+
+// ResponseCache stores generated options on disk, one JSON file per query,
+// so they can be answered again without the network.
+type ResponseCache struct {
+	dir     string
+	ttl     time.Duration // 0 keeps entries forever
+	maxSize int64         // bytes; 0 for no limit
+}
+
+// CacheEntry is a cached response and where it came from.
+type CacheEntry struct {
+	Scope   string          `json:"scope"`           // model and prompt settings
+	Model   string          `json:"model,omitempty"` // the model that answered
+	Query   string          `json:"query"`
+	Created time.Time       `json:"created"`
+	Options []CommandOption `json:"options"`
+}
+
+// StoredEntry is an entry as found on disk, for listing and cleaning up
+// the cache.
+type StoredEntry struct {
+	CacheEntry
+	Size int64
+	path string
+}
+
+// Public: Creates a cache storing entries in dir, which is created on
+// first write.
+func NewResponseCache(dir string) *ResponseCache {
+	return &ResponseCache{dir: dir}
+}
+
+// Public: Sets how long entries are answered from, and how many bytes the
+// cache may take before GC removes the oldest entries. Zero means no
+// limit for either.
+func (c *ResponseCache) SetLimits(ttl time.Duration, maxSize int64) {
+	c.ttl, c.maxSize = ttl, maxSize
+}
+
+// Public: Returns the directory entries are stored in.
+func (c *ResponseCache) Dir() string {
+	return c.dir
+}
+
+// Public: Returns the cached options for query under scope, which names
+// the model and anything else that changes the answer, such as the target
+// shell.
+func (c *ResponseCache) Get(scope, query string) ([]CommandOption, bool) {
+	data, err := os.ReadFile(c.path(scope, query))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Scope != scope || entry.Query != query || c.expired(entry) {
+		return nil, false
+	}
+	return entry.Options, true
+}
+
+// Public: Stores options for query under scope, replacing any previous
+// entry.
+func (c *ResponseCache) Put(scope, query string, options []CommandOption) error {
+	return c.PutEntry(CacheEntry{Scope: scope, Query: query, Options: options})
+}
+
+// Public: Stores entry, recording when it was created unless it says,
+// and replacing any previous entry for its scope and query.
+func (c *ResponseCache) PutEntry(entry CacheEntry) error {
+	if entry.Created.IsZero() {
+		entry.Created = time.Now().UTC()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	path := c.path(entry.Scope, entry.Query)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *ResponseCache) path(scope, query string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + query))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// expired reports whether entry is older than the TTL.
+func (c *ResponseCache) expired(entry CacheEntry) bool {
+	return c.ttl > 0 && time.Since(entry.Created) > c.ttl
+}
+
+// Public: Returns every readable entry in the cache, oldest first. An
+// empty or missing cache has none.
+func (c *ResponseCache) List() ([]StoredEntry, error) {
+	entries, _, err := c.scan()
+	return entries, err
+}
+
+// scan reads the cache, returning its entries oldest first and the files
+// that aren't readable entries, such as those left by an interrupted
+// write an hour or more ago.
+func (c *ResponseCache) scan() ([]StoredEntry, []string, error) {
+	files, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var entries []StoredEntry
+	var junk []string
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		path := filepath.Join(c.dir, f.Name())
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		// A fresh temporary file may be a write in progress.
+		if filepath.Ext(path) != ".json" {
+			if time.Since(info.ModTime()) > time.Hour {
+				junk = append(junk, path)
+			}
+			continue
+		}
+		var entry CacheEntry
+		data, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(data, &entry) != nil {
+			junk = append(junk, path)
+			continue
+		}
+		entries = append(entries, StoredEntry{CacheEntry: entry, Size: info.Size(), path: path})
+	}
+	slices.SortStableFunc(entries, func(a, b StoredEntry) int { return a.Created.Compare(b.Created) })
+	return entries, junk, nil
+}
+
+// Public: Removes every entry.
+//
+// Returns how many were removed.
+func (c *ResponseCache) Clear() (int, error) {
+	entries, junk, err := c.scan()
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		junk = append(junk, e.path)
+	}
+	return removeAll(junk)
+}
+
+// Public: Removes expired entries and unreadable files, then the oldest
+// entries until the cache fits its size limit.
+//
+// Returns how many files were removed.
+func (c *ResponseCache) GC() (int, error) {
+	entries, remove, err := c.scan()
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	var kept []StoredEntry
+	for _, e := range entries {
+		if c.expired(e.CacheEntry) {
+			remove = append(remove, e.path)
+			continue
+		}
+		kept = append(kept, e)
+		size += e.Size
+	}
+	for len(kept) > 0 && c.maxSize > 0 && size > c.maxSize {
+		remove = append(remove, kept[0].path)
+		size -= kept[0].Size
+		kept = kept[1:]
+	}
+	return removeAll(remove)
+}
+
+// removeAll removes paths, stopping at the first failure.
+func removeAll(paths []string) (int, error) {
+	for i, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return i, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+	return len(paths), nil
+}
+
+// CachingClient saves every successful response to a cache, keeping the
+// cache within its limits. It never answers from the cache itself; see
+// CacheOnlyClient.
+type CachingClient struct {
+	client Client
+	cache  *ResponseCache
+	scope  string
+	model  string
+}
+
+// Public: Wraps client to write its responses to cache under scope.
+func NewCachingClient(client Client, cache *ResponseCache, scope string) *CachingClient {
+	return &CachingClient{client: client, cache: cache, scope: scope}
+}
+
+// Public: Sets the model recorded as answering, unless a fallback model
+// did.
+func (c *CachingClient) SetModel(model string) {
+	c.model = model
+}
+
+// Public: Generates options with the wrapped client and caches them.
+// Failing to cache doesn't fail the query.
+func (c *CachingClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
+	options, err := c.client.GenerateOptions(ctx, query)
+	if err == nil && len(options) > 0 {
+		model := c.model
+		if options[0].Fallback != "" {
+			model = options[0].Fallback
+		}
+		if c.cache.PutEntry(CacheEntry{Scope: c.scope, Model: model, Query: query, Options: options}) == nil {
+			_, _ = c.cache.GC()
+		}
+	}
+	return options, err
+}
+
+// CacheOnlyClient answers only from a cache, never the network.
+type CacheOnlyClient struct {
+	cache *ResponseCache
+	scope string
+}
+
+// Public: Creates a client answering from cache under scope.
+func NewCacheOnlyClient(cache *ResponseCache, scope string) *CacheOnlyClient {
+	return &CacheOnlyClient{cache: cache, scope: scope}
+}
+
+// Public: Returns the cached options for query, or ErrCacheMiss.
+func (c *CacheOnlyClient) GenerateOptions(_ context.Context, query string) ([]CommandOption, error) {
+	if options, ok := c.cache.Get(c.scope, query); ok {
+		return options, nil
+	}
+	return nil, ErrCacheMiss
+}
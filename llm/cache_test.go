@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	cache := NewResponseCache(t.TempDir())
+	options := []CommandOption{{Title: "List", Command: "ls -la"}}
+
+	if _, ok := cache.Get("sonnet", "list files"); ok {
+		t.Fatal("Get() on an empty cache hit")
+	}
+
+	if err := cache.Put("sonnet", "list files", options); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, ok := cache.Get("sonnet", "list files")
+	if !ok || len(got) != 1 || got[0].Command != "ls -la" {
+		t.Errorf("Get() = %v, %v; want the stored options", got, ok)
+	}
+
+	if _, ok := cache.Get("haiku", "list files"); ok {
+		t.Error("Get() hit for another scope")
+	}
+	if _, ok := cache.Get("sonnet", "list all files"); ok {
+		t.Error("Get() hit for another query")
+	}
+}
+
+func TestCachingAndCacheOnlyClients(t *testing.T) {
+	cache := NewResponseCache(t.TempDir())
+	ctx := context.Background()
+
+	failing := &MockClient{Err: errors.New("offline")}
+	if _, err := NewCachingClient(failing, cache, "scope").GenerateOptions(ctx, "q"); err == nil {
+		t.Fatal("CachingClient hid the wrapped error")
+	}
+
+	offline := NewCacheOnlyClient(cache, "scope")
+	if _, err := offline.GenerateOptions(ctx, "q"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("error = %v, want ErrCacheMiss; failures must not be cached", err)
+	}
+
+	online := NewCachingClient(NewMockClient(), cache, "scope")
+	want, err := online.GenerateOptions(ctx, "q")
+	if err != nil {
+		t.Fatalf("GenerateOptions() error = %v", err)
+	}
+
+	got, err := offline.GenerateOptions(ctx, "q")
+	if err != nil || len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("CacheOnlyClient = %v, %v; want the cached %v", got, err, want)
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	cache := NewResponseCache(t.TempDir())
+	cache.SetLimits(24*time.Hour, 0)
+
+	old := CacheEntry{Scope: "s", Query: "old", Created: time.Now().Add(-48 * time.Hour), Options: []CommandOption{{Command: "ls"}}}
+	if err := cache.PutEntry(old); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put("s", "new", []CommandOption{{Command: "ls"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.Get("s", "old"); ok {
+		t.Error("Get() answered from an expired entry")
+	}
+	if _, ok := cache.Get("s", "new"); !ok {
+		t.Error("Get() missed a fresh entry")
+	}
+
+	removed, err := cache.GC()
+	if err != nil || removed != 1 {
+		t.Fatalf("GC() = %d, %v; want the expired entry removed", removed, err)
+	}
+	if entries, _ := cache.List(); len(entries) != 1 || entries[0].Query != "new" {
+		t.Errorf("List() after GC() = %+v, want only the fresh entry", entries)
+	}
+}
+
+func TestResponseCacheGCSize(t *testing.T) {
+	cache := NewResponseCache(t.TempDir())
+	start := time.Now().Add(-time.Hour)
+	for i, query := range []string{"first", "second", "third"} {
+		entry := CacheEntry{Scope: "s", Query: query, Created: start.Add(time.Duration(i) * time.Minute), Options: []CommandOption{{Command: "ls"}}}
+		if err := cache.PutEntry(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := cache.List()
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("List() = %d entries, %v", len(entries), err)
+	}
+
+	// Room for two entries: the oldest goes.
+	cache.SetLimits(0, entries[1].Size+entries[2].Size)
+	if removed, err := cache.GC(); err != nil || removed != 1 {
+		t.Fatalf("GC() = %d, %v; want one entry removed", removed, err)
+	}
+	if _, ok := cache.Get("s", "first"); ok {
+		t.Error("GC() kept the oldest entry")
+	}
+	if _, ok := cache.Get("s", "third"); !ok {
+		t.Error("GC() removed the newest entry")
+	}
+
+	if removed, err := cache.Clear(); err != nil || removed != 2 {
+		t.Errorf("Clear() = %d, %v; want the two left removed", removed, err)
+	}
+	if entries, _ := cache.List(); len(entries) != 0 {
+		t.Errorf("List() after Clear() = %+v", entries)
+	}
+}
+
+func TestCachingClientRecordsModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []CommandOption
+		want    string
+	}{
+		{"primary", []CommandOption{{Title: "List", Command: "ls"}}, "sonnet"},
+		{"fallback", []CommandOption{{Title: "List", Command: "ls", Fallback: "haiku"}}, "haiku"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewResponseCache(t.TempDir())
+			client := NewCachingClient(&MockClient{Response: tt.options}, cache, "scope")
+			client.SetModel("sonnet")
+			if _, err := client.GenerateOptions(context.Background(), "q"); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := cache.List()
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("List() = %+v, %v", entries, err)
+			}
+			if got := entries[0]; got.Model != tt.want || got.Created.IsZero() || got.Size == 0 {
+				t.Errorf("entry = %+v, want model %q with its date and size", got, tt.want)
+			}
+		})
+	}
+}
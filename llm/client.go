@@ -13,4 +13,13 @@ type CommandOption struct {
 	Title       string `json:"title"`
 	Command     string `json:"command"`
 	Description string `json:"description"`
+	// Confidence is how sure the model is, from 0 to 100, that this is the
+	// command the user wants. Zero when the provider doesn't say.
+	Confidence int `json:"confidence,omitempty"`
+	// Fallback names the fallback model that answered, when the primary
+	// failed. Empty for the primary.
+	Fallback string `json:"fallback,omitempty"`
+	// Source lists the models that suggested the option when several are
+	// queried in parallel.
+	Source string `json:"source,omitempty"`
 }
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ModelClient is a client in a fallback chain, named for the UI.
+type ModelClient struct {
+	Name   string
+	Client Client
+}
+
+// FallbackClient tries a chain of clients in order, moving on when one is
+// unavailable (overloaded, rate limited, unknown model) so queries still
+// get answered during provider incidents.
+type FallbackClient struct {
+	chain []ModelClient
+}
+
+// Public: Creates a client that tries chain in order. The first entry is
+// the primary; options from any later entry have Fallback set to its name.
+func NewFallbackClient(chain ...ModelClient) *FallbackClient {
+	return &FallbackClient{chain: chain}
+}
+
+// Public: Generates options with the first client in the chain that
+// succeeds. Errors that another model wouldn't fix, like a bad API key,
+// are returned straight away.
+func (f *FallbackClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
+	var errs []error
+	for i, mc := range f.chain {
+		options, err := mc.Client.GenerateOptions(ctx, query)
+		if err == nil {
+			if i > 0 {
+				for j := range options {
+					options[j].Fallback = mc.Name
+				}
+			}
+			return options, nil
+		}
+
+		if !shouldFallBack(ctx, err) {
+			return nil, err
+		}
+		slog.Info("model unavailable; trying the next", "model", mc.Name, "err", err)
+		errs = append(errs, fmt.Errorf("%s: %w", mc.Name, err))
+	}
+
+	return nil, fmt.Errorf("all models failed: %w", errors.Join(errs...))
+}
+
+// shouldFallBack reports whether err is worth trying another model for.
+// Anthropic errors are judged by status and refusals never fall back;
+// anything else (plugins, network) does, since a different provider may
+// still work.
+func shouldFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	// Another model would judge the query the same way.
+	var refusal *Refusal
+	if errors.As(err, &refusal) {
+		return false
+	}
+
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound, // unknown or retired model
+		http.StatusTooManyRequests,
+		529: // overloaded
+		return true
+	}
+	return apiErr.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// statusError builds an API error for status, with the request and response
+// its Error method needs.
+func statusError(status int) error {
+	return fmt.Errorf("API call failed: %w", &anthropic.Error{
+		StatusCode: status,
+		Request:    httptest.NewRequest(http.MethodPost, "/v1/messages", nil),
+		Response:   &http.Response{StatusCode: status},
+	})
+}
+
+func TestFallbackClient(t *testing.T) {
+	overloaded := statusError(529)
+	unauthorized := statusError(http.StatusUnauthorized)
+	answer := []CommandOption{{Title: "List", Command: "ls"}}
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantFallback string
+		wantErr      string
+	}{
+		{
+			name: "primary answers",
+			errs: []error{nil, nil},
+		},
+		{
+			name:         "primary overloaded",
+			errs:         []error{overloaded, nil},
+			wantFallback: "haiku",
+		},
+		{
+			name:         "plugin failure falls back",
+			errs:         []error{errors.New("plugin exited"), nil},
+			wantFallback: "haiku",
+		},
+		{
+			name:    "auth error stops",
+			errs:    []error{unauthorized, nil},
+			wantErr: "401",
+		},
+		{
+			name:    "refusal stops",
+			errs:    []error{&Refusal{Reason: "poems are prose"}, nil},
+			wantErr: "not a shell task",
+		},
+		{
+			name:    "all fail",
+			errs:    []error{overloaded, overloaded},
+			wantErr: "all models failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{"sonnet", "haiku"}
+			var chain []ModelClient
+			for i, err := range tt.errs {
+				response := append([]CommandOption(nil), answer...)
+				chain = append(chain, ModelClient{Name: names[i], Client: &MockClient{Response: response, Err: err}})
+			}
+
+			options, err := NewFallbackClient(chain...).GenerateOptions(context.Background(), "list files")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GenerateOptions() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateOptions() error = %v", err)
+			}
+			if options[0].Fallback != tt.wantFallback {
+				t.Errorf("Fallback = %q, want %q", options[0].Fallback, tt.wantFallback)
+			}
+		})
+	}
+}
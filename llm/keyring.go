@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// KeyRotation says when a KeyRing moves on to its next key.
+type KeyRotation string
+
+const (
+	// KeyRotationRoundRobin starts each request on the next key in turn.
+	KeyRotationRoundRobin KeyRotation = "round-robin"
+	// KeyRotationOnRateLimit keeps using one key until it is rate limited.
+	KeyRotationOnRateLimit KeyRotation = "on-rate-limit"
+)
+
+// KeyRing spreads requests across several Anthropic API keys, such as one
+// per project, so their rate limits add up. A request that is rate limited
+// on one key is retried on the others before the limit is reported.
+//
+// A KeyRing is safe for concurrent use, and can be shared by every client
+// so the rotation covers all of 1lm's requests.
+type KeyRing struct {
+	keys     []string
+	rotation KeyRotation
+	next     atomic.Uint64
+}
+
+// Public: Creates a KeyRing over keys, which must not be empty. An unknown
+// rotation is treated as round-robin.
+func NewKeyRing(keys []string, rotation KeyRotation) *KeyRing {
+	return &KeyRing{keys: keys, rotation: rotation}
+}
+
+// Public: Returns the request option that sends each request with a key
+// from the ring. It replaces the key set with option.WithAPIKey.
+func (r *KeyRing) Option() option.RequestOption {
+	return option.WithMiddleware(r.middleware)
+}
+
+// middleware sends req with the key whose turn it is, moving on to the
+// next key after a 429 until every key has been tried.
+func (r *KeyRing) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	// The body has to be sent again for each key.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+
+	start := r.start()
+	var res *http.Response
+	var err error
+	for i := range len(r.keys) {
+		index := (start + i) % len(r.keys)
+		attempt := req.Clone(req.Context())
+		attempt.Header.Set("X-Api-Key", r.keys[index])
+		if body != nil {
+			attempt.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		res, err = next(attempt)
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		}
+		slog.Debug("API request", "path", req.URL.Path, "key", r.Label(index), "status", status)
+
+		if err != nil || status != http.StatusTooManyRequests || i == len(r.keys)-1 {
+			break
+		}
+		_ = res.Body.Close()
+		r.advance(index)
+	}
+	return res, err
+}
+
+// start returns the index of the key to try first.
+func (r *KeyRing) start() int {
+	if r.rotation == KeyRotationOnRateLimit {
+		return int(r.next.Load() % uint64(len(r.keys)))
+	}
+	return int((r.next.Add(1) - 1) % uint64(len(r.keys)))
+}
+
+// advance moves an on-rate-limit ring past the key at index, unless
+// another request already has.
+func (r *KeyRing) advance(index int) {
+	if r.rotation != KeyRotationOnRateLimit {
+		return
+	}
+	current := r.next.Load()
+	if int(current%uint64(len(r.keys))) == index {
+		r.next.CompareAndSwap(current, current+1)
+	}
+}
+
+// Public: Names the key at index for logs without revealing it, e.g.
+// "key 2 (...x7Qa)". Keys too short to be real aren't quoted at all.
+func (r *KeyRing) Label(index int) string {
+	key := r.keys[index]
+	if len(key) < 20 {
+		return fmt.Sprintf("key %d", index+1)
+	}
+	return fmt.Sprintf("key %d (...%s)", index+1, key[len(key)-4:])
+}
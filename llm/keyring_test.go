@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// keyServer answers requests in place of the API, rate limiting the keys
+// in limited, and records the key and body of each request.
+type keyServer struct {
+	limited map[string]bool
+	keys    []string
+	bodies  []string
+}
+
+func (s *keyServer) next(req *http.Request) (*http.Response, error) {
+	key := req.Header.Get("X-Api-Key")
+	body, _ := io.ReadAll(req.Body)
+	s.keys = append(s.keys, key)
+	s.bodies = append(s.bodies, string(body))
+
+	status := http.StatusOK
+	if s.limited[key] {
+		status = http.StatusTooManyRequests
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func TestKeyRing(t *testing.T) {
+	tests := []struct {
+		name       string
+		rotation   KeyRotation
+		limited    []string
+		requests   int
+		wantKeys   []string
+		wantStatus []int
+	}{
+		{
+			name:       "round-robin",
+			rotation:   KeyRotationRoundRobin,
+			requests:   4,
+			wantKeys:   []string{"a", "b", "c", "a"},
+			wantStatus: []int{200, 200, 200, 200},
+		},
+		{
+			name:       "round-robin skips rate-limited key",
+			rotation:   KeyRotationRoundRobin,
+			limited:    []string{"a"},
+			requests:   2,
+			wantKeys:   []string{"a", "b", "b"},
+			wantStatus: []int{200, 200},
+		},
+		{
+			name:       "on-rate-limit sticks to one key",
+			rotation:   KeyRotationOnRateLimit,
+			requests:   3,
+			wantKeys:   []string{"a", "a", "a"},
+			wantStatus: []int{200, 200, 200},
+		},
+		{
+			name:       "on-rate-limit moves on after a 429",
+			rotation:   KeyRotationOnRateLimit,
+			limited:    []string{"a"},
+			requests:   2,
+			wantKeys:   []string{"a", "b", "b"},
+			wantStatus: []int{200, 200},
+		},
+		{
+			name:       "every key rate limited",
+			rotation:   KeyRotationRoundRobin,
+			limited:    []string{"a", "b", "c"},
+			requests:   1,
+			wantKeys:   []string{"a", "b", "c"},
+			wantStatus: []int{429},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &keyServer{limited: map[string]bool{}}
+			for _, key := range tt.limited {
+				server.limited[key] = true
+			}
+			ring := NewKeyRing([]string{"a", "b", "c"}, tt.rotation)
+
+			var statuses []int
+			for range tt.requests {
+				req, _ := http.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", strings.NewReader(`{"model":"m"}`))
+				res, err := ring.middleware(req, server.next)
+				if err != nil {
+					t.Fatalf("middleware() error = %v", err)
+				}
+				statuses = append(statuses, res.StatusCode)
+			}
+
+			if !reflect.DeepEqual(server.keys, tt.wantKeys) {
+				t.Errorf("keys used = %v, want %v", server.keys, tt.wantKeys)
+			}
+			if !reflect.DeepEqual(statuses, tt.wantStatus) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantStatus)
+			}
+			for i, body := range server.bodies {
+				if body != `{"model":"m"}` {
+					t.Errorf("request %d body = %q, want the original body", i, body)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyRingLabel(t *testing.T) {
+	ring := NewKeyRing([]string{"sk-ant-REDACTED", "short"}, KeyRotationRoundRobin)
+
+	if got, want := ring.Label(0), "key 1 (...x7Qa)"; got != want {
+		t.Errorf("Label(0) = %q, want %q", got, want)
+	}
+	if got, want := ring.Label(1), "key 2"; got != want {
+		t.Errorf("Label(1) = %q, want %q", got, want)
+	}
+}
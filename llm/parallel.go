@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ParallelClient sends each query to several clients at once and merges
+// their options, for comparing models (say, a local one against Claude)
+// on real queries.
+type ParallelClient struct {
+	clients []ModelClient
+}
+
+// Public: Creates a client that queries every client concurrently. Options
+// are labeled with the models that suggested them in Source.
+func NewParallelClient(clients ...ModelClient) *ParallelClient {
+	return &ParallelClient{clients: clients}
+}
+
+// Public: Generates options from every client and merges them in client
+// order. Identical commands (ignoring spacing) appear once, labeled with
+// each model that suggested them. Fails only if every client fails.
+func (p *ParallelClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
+	results := make([][]CommandOption, len(p.clients))
+	errs := make([]error, len(p.clients))
+
+	var wg sync.WaitGroup
+	for i, mc := range p.clients {
+		wg.Go(func() {
+			results[i], errs[i] = mc.Client.GenerateOptions(ctx, query)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", mc.Name, errs[i])
+			}
+		})
+	}
+	wg.Wait()
+
+	var merged []CommandOption
+	seen := make(map[string]int)
+	for i, options := range results {
+		name := p.clients[i].Name
+		for _, opt := range options {
+			key := strings.Join(strings.Fields(opt.Command), " ")
+			if j, ok := seen[key]; ok {
+				merged[j].Source += ", " + name
+				continue
+			}
+			opt.Source = name
+			seen[key] = len(merged)
+			merged = append(merged, opt)
+		}
+	}
+
+	if len(merged) == 0 {
+		if err := errors.Join(errs...); err != nil {
+			return nil, fmt.Errorf("all models failed: %w", err)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParallelClient(t *testing.T) {
+	claude := &MockClient{Response: []CommandOption{
+		{Title: "List", Command: "ls -la"},
+		{Title: "Tree", Command: "tree"},
+	}}
+	local := &MockClient{Response: []CommandOption{
+		{Title: "List all", Command: "ls  -la"},
+		{Title: "Find", Command: "find ."},
+	}}
+	broken := &MockClient{Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name        string
+		clients     []ModelClient
+		wantSources []string
+		wantErr     bool
+	}{
+		{
+			name:        "merges and dedupes",
+			clients:     []ModelClient{{Name: "claude", Client: claude}, {Name: "local", Client: local}},
+			wantSources: []string{"claude, local", "claude", "local"},
+		},
+		{
+			name:        "one fails",
+			clients:     []ModelClient{{Name: "claude", Client: claude}, {Name: "broken", Client: broken}},
+			wantSources: []string{"claude", "claude"},
+		},
+		{
+			name:    "all fail",
+			clients: []ModelClient{{Name: "broken", Client: broken}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := NewParallelClient(tt.clients...).GenerateOptions(context.Background(), "list files")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(options) != len(tt.wantSources) {
+				t.Fatalf("got %d options, want %d", len(options), len(tt.wantSources))
+			}
+			for i, want := range tt.wantSources {
+				if options[i].Source != want {
+					t.Errorf("options[%d].Source = %q, want %q", i, options[i].Source, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PluginPrefix marks a provider setting that names a plugin executable, as
+// in provider = "plugin:my-llm".
+const PluginPrefix = "plugin:"
+
+// PluginProtocolVersion is sent with every request so plugins can reject
+// versions they don't understand.
+const PluginProtocolVersion = 1
+
+// PluginRequest is written as JSON to a plugin's stdin.
+type PluginRequest struct {
+	Version int    `json:"version"`
+	Query   string `json:"query"`
+	Model   string `json:"model,omitempty"`
+	Prompt  string `json:"prompt"` // The full prompt rendered for Query
+}
+
+// PluginResponse is read as JSON from a plugin's stdout. A non-empty Error
+// fails the request with that message, and a Refusal with no options says
+// the query isn't a shell task.
+type PluginResponse struct {
+	Options []CommandOption `json:"options"`
+	Refusal *Refusal        `json:"refusal,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// PluginClient implements Client by running an external executable once
+// per query. The executable reads a PluginRequest from stdin, writes a
+// PluginResponse to stdout and exits; anything on stderr is included in the
+// error if it exits non-zero.
+type PluginClient struct {
+	path   string
+	model  string
+	prompt *Prompt
+}
+
+// Public: Creates a client for the named plugin executable, found on PATH
+// unless name is a path.
+//
+// name   - Executable name or path
+// model  - Passed through to the plugin, which may ignore it
+// prompt - Renders the prompt sent with the query; nil for the default
+//
+// Returns the client, or an error if the executable can't be found.
+func NewPluginClient(name, model string, prompt *Prompt) (*PluginClient, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("provider plugin %q not found: %w", name, err)
+	}
+
+	if prompt == nil {
+		prompt = DefaultPrompt()
+	}
+
+	return &PluginClient{path: path, model: model, prompt: prompt}, nil
+}
+
+// Public: Generates command options by running the plugin.
+func (c *PluginClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
+	prompt, err := c.prompt.Render(query)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(PluginRequest{
+		Version: PluginProtocolVersion,
+		Query:   query,
+		Model:   c.model,
+		Prompt:  prompt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("provider plugin failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("provider plugin failed: %w", err)
+	}
+
+	var res PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin response: %w", err)
+	}
+	if res.Error != "" {
+		return nil, fmt.Errorf("provider plugin error: %s", res.Error)
+	}
+	if len(res.Options) == 0 && res.Refusal != nil {
+		return nil, res.Refusal
+	}
+	if len(res.Options) == 0 {
+		return nil, fmt.Errorf("no options returned")
+	}
+
+	return res.Options, nil
+}
+
+// Public: Creates the generation client for a provider setting.
+//
+// provider - "anthropic" (or empty), or "plugin:<executable>"
+// apiKey   - Anthropic API key, unused by plugins
+// model    - Model name passed to the provider
+// prompt   - Generation prompt; nil uses the default
+//
+// Returns the client, or an error for unknown providers.
+func NewProviderClient(provider, apiKey, model string, prompt *Prompt) (Client, error) {
+	if prompt == nil {
+		prompt = DefaultPrompt()
+	}
+
+	switch {
+	case provider == "" || provider == "anthropic":
+		if apiKey == "" {
+			return nil, fmt.Errorf("anthropic_api_key not set in config (~/.config/1lm/config.toml)")
+		}
+		return NewAnthropicClientWithPrompt(apiKey, model, prompt)
+	case strings.HasPrefix(provider, PluginPrefix):
+		return NewPluginClient(strings.TrimPrefix(provider, PluginPrefix), model, prompt)
+	}
+
+	return nil, fmt.Errorf("unknown provider %q", provider)
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePlugin creates an executable shell script plugin in a temp dir.
+func writePlugin(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test-plugin")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPluginClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+		want    int
+	}{
+		{
+			name: "options returned",
+			script: `cat > /dev/null
+echo '{"options":[{"title":"List","command":"ls -la","description":"List files"}]}'`,
+			want: 1,
+		},
+		{
+			name: "receives query",
+			script: `grep -q '"query":"find logs"' || { echo "bad request" >&2; exit 1; }
+echo '{"options":[{"title":"Find","command":"find . -name x.log","description":"Find logs"}]}'`,
+			want: 1,
+		},
+		{
+			name:    "plugin error field",
+			script:  `echo '{"error":"quota exceeded"}'`,
+			wantErr: "quota exceeded",
+		},
+		{
+			name:    "refusal",
+			script:  `echo '{"options":[],"refusal":{"reason":"poems are prose","suggestion":"count words in a file"}}'`,
+			wantErr: "not a shell task: poems are prose",
+		},
+		{
+			name:    "non-zero exit includes stderr",
+			script:  `echo "no credentials" >&2; exit 3`,
+			wantErr: "no credentials",
+		},
+		{
+			name:    "invalid json",
+			script:  `echo 'not json'`,
+			wantErr: "failed to parse plugin response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewPluginClient(writePlugin(t, tt.script), "test-model", nil)
+			if err != nil {
+				t.Fatalf("NewPluginClient() error = %v", err)
+			}
+
+			options, err := client.GenerateOptions(context.Background(), "find logs")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GenerateOptions() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateOptions() error = %v", err)
+			}
+			if len(options) != tt.want {
+				t.Errorf("GenerateOptions() returned %d options, want %d", len(options), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProviderClient(t *testing.T) {
+	plugin := writePlugin(t, "exit 0")
+
+	tests := []struct {
+		name     string
+		provider string
+		apiKey   string
+		wantErr  bool
+	}{
+		{name: "anthropic", provider: "anthropic", apiKey: "sk-test"},
+		{name: "empty defaults to anthropic", provider: "", apiKey: "sk-test"},
+		{name: "anthropic without key", provider: "anthropic", wantErr: true},
+		{name: "plugin", provider: PluginPrefix + plugin},
+		{name: "missing plugin", provider: PluginPrefix + "1lm-no-such-plugin", wantErr: true},
+		{name: "unknown", provider: "openai", apiKey: "sk-test", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProviderClient(tt.provider, tt.apiKey, "test-model", nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewProviderClient(%q) error = %v, wantErr %v", tt.provider, err, tt.wantErr)
+			}
+		})
+	}
+}
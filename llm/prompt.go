@@ -0,0 +1,197 @@
+package llm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// DefaultPromptTemplate is the generation prompt used when no
+// prompt_template is configured.
+const DefaultPromptTemplate = `Given this user request: "{{.Query}}"
+
+Generate exactly 3 different shell command options that accomplish the task.
+
+Requirements:
+- Provide exactly 3 different approaches when possible
+- Commands should be safe and practical
+- Prefer commonly available tools
+- Include relevant flags and options
+- Descriptions should explain the approach and any caveats
+- If no shell command can do what was asked (writing prose, answering a general question, summarizing a document the command line can't read), return no options and fill in refusal instead, written like the descriptions would be
+{{- if .Focus}}
+- {{.Focus}}
+{{- end}}
+{{- if .Script}}
+- A command may be a short script of several lines when the task can't be done in one line: start it with set -euo pipefail (or the target shell's equivalent), put a comment before each step, and keep it under 30 lines. Still give a one-liner when one will do
+{{- end}}
+{{- if .Target}}
+- The commands will be run on another machine, not this one: {{.Target}}. Use only the tools, flags and paths available there
+{{- end}}
+{{- if .TargetShell}}
+- Write every command for {{.TargetShell}}, using its syntax and built-in commands, even if the user is currently in a different shell
+{{- end}}
+{{- if .Language}}
+- Write titles and descriptions in {{.Language}}; keep commands, flags and paths as they are
+{{- end}}
+{{- if .PreferredTools}}
+- Prefer these tools where they fit the task: {{join .PreferredTools ", "}}
+{{- end}}
+{{- if .AvoidTools}}
+- Do not use these tools: {{join .AvoidTools ", "}}
+{{- end}}
+{{- range .Constraints}}
+- {{.}}
+{{- end}}
+{{- range .Packs}}
+- {{.}}
+{{- end}}
+{{- if .Examples}}
+
+Examples of commands this user prefers. Match their style (flags, tools, quoting):
+{{- range .Examples}}
+
+Request: "{{.Query}}"
+Command: {{.Command}}
+{{- end}}
+{{- end}}`
+
+// PromptData holds the variables available to prompt templates.
+type PromptData struct {
+	Query    string    // The user's request
+	OS       string    // runtime.GOOS, e.g. "darwin" or "linux"
+	Shell    string    // Base name of $SHELL, e.g. "zsh"
+	Tools    []string  // Optional tools found on PATH, e.g. "rg", "fd", "jq"
+	Examples []Example // Few-shot demonstrations of the user's preferred style
+
+	PreferredTools []string // Tools to use where they fit, e.g. "rg"
+	AvoidTools     []string // Tools not to use, e.g. "sudo"
+
+	// Constraints are instructions for what commands must not do, from
+	// flags such as --no-sudo, one sentence each.
+	Constraints []string
+
+	// Packs are the instructions of the context packs chosen with
+	// --context, steering the model toward a platform's tools.
+	Packs []string
+
+	Language string // Language name for titles and descriptions, e.g. "Spanish"
+
+	// Target describes the machine the commands will run on when it isn't
+	// this one, e.g. "ubuntu 20.04 arm64" or "busybox". OS, Shell and Tools
+	// are left empty then, since they describe this machine.
+	Target string
+
+	// TargetShell is set when the user explicitly asked for commands for a
+	// particular shell, e.g. "PowerShell", which may differ from Shell.
+	TargetShell string
+
+	// Focus holds extra instructions for a DSL mode, such as asking for a
+	// minimal jq invocation around a carefully written filter.
+	Focus string
+
+	// Script allows short multi-line scripts, for --script, when a task
+	// can't be done in one line.
+	Script bool
+}
+
+// Example pairs a request with the command the user would want for it.
+type Example struct {
+	Query   string
+	Command string
+}
+
+// knownTools are optional tools worth telling the model about when
+// installed, since it can't assume them otherwise.
+var knownTools = []string{
+	"rg", "fd", "fzf", "jq", "yq", "bat", "eza", "delta", "sd",
+	"gawk", "gsed", "parallel", "curl", "wget", "git", "docker",
+	"kubectl", "python3", "node",
+}
+
+// promptFuncs are available to templates in addition to the builtins.
+var promptFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Prompt renders the generation prompt from a template.
+type Prompt struct {
+	tmpl *template.Template
+
+	// Context supplies every variable except Query.
+	Context PromptData
+}
+
+// Public: Parses a prompt template, with Context detected from the
+// current environment.
+//
+// text - A text/template using PromptData fields and join, e.g. {{join .Tools ", "}}
+//
+// Returns the Prompt, or an error if the template doesn't parse.
+func NewPrompt(text string) (*Prompt, error) {
+	tmpl, err := template.New("prompt").Funcs(promptFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	return &Prompt{tmpl: tmpl, Context: DetectPromptContext()}, nil
+}
+
+// Public: Reads and parses a prompt template file.
+func LoadPrompt(path string) (*Prompt, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+
+	return NewPrompt(string(text))
+}
+
+// Public: Returns a Prompt using DefaultPromptTemplate.
+func DefaultPrompt() *Prompt {
+	p, err := NewPrompt(DefaultPromptTemplate)
+	if err != nil {
+		panic(err) // DefaultPromptTemplate is a constant
+	}
+	return p
+}
+
+// Public: Renders the prompt for a query.
+func (p *Prompt) Render(query string) (string, error) {
+	data := p.Context
+	data.Query = query
+
+	var b bytes.Buffer
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+
+	return b.String(), nil
+}
+
+// Public: Returns the OS, shell and installed tools of the current
+// environment, leaving Query empty.
+func DetectPromptContext() PromptData {
+	var tools []string
+	for _, tool := range knownTools {
+		if _, err := exec.LookPath(tool); err == nil {
+			tools = append(tools, tool)
+		}
+	}
+
+	var shell string
+	if s := os.Getenv("SHELL"); s != "" {
+		shell = filepath.Base(s)
+	}
+
+	return PromptData{
+		OS:    runtime.GOOS,
+		Shell: shell,
+		Tools: tools,
+	}
+}
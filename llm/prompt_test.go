@@ -0,0 +1,233 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		context PromptData
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "query",
+			text: `Request: {{.Query}}`,
+			want: "Request: list files",
+		},
+		{
+			name:    "context variables",
+			text:    `{{.Shell}} on {{.OS}} with {{join .Tools ", "}}`,
+			context: PromptData{OS: "linux", Shell: "zsh", Tools: []string{"rg", "fd"}},
+			want:    "zsh on linux with rg, fd",
+		},
+		{
+			name:    "conditional on tools",
+			text:    `{{if .Tools}}Installed: {{range .Tools}}{{.}} {{end}}{{else}}none{{end}}`,
+			context: PromptData{},
+			want:    "none",
+		},
+		{
+			name:    "unknown field",
+			text:    `{{.Nope}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPrompt(tt.text)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatalf("NewPrompt() error = %v", err)
+				}
+				return
+			}
+			p.Context = tt.context
+
+			got, err := p.Render("list files")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPromptIncludesQuery(t *testing.T) {
+	got, err := DefaultPrompt().Render("find large files")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, `"find large files"`) {
+		t.Errorf("default prompt missing query, got %q", got)
+	}
+}
+
+func TestDefaultPromptExamples(t *testing.T) {
+	p := DefaultPrompt()
+
+	without, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(without, "Examples") {
+		t.Errorf("prompt without examples mentions them: %q", without)
+	}
+
+	p.Context.Examples = []Example{
+		{Query: "find go files", Command: "fd --extension go"},
+		{Query: "search for TODO", Command: "rg --fixed-strings TODO"},
+	}
+	with, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Request: \"find go files\"\nCommand: fd --extension go",
+		"Request: \"search for TODO\"\nCommand: rg --fixed-strings TODO",
+	} {
+		if !strings.Contains(with, want) {
+			t.Errorf("prompt missing %q, got:\n%s", want, with)
+		}
+	}
+}
+
+func TestDefaultPromptToolPreferences(t *testing.T) {
+	p := DefaultPrompt()
+	p.Context.PreferredTools = []string{"rg", "fd"}
+	p.Context.AvoidTools = []string{"sudo"}
+
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"- Prefer these tools where they fit the task: rg, fd\n",
+		"- Do not use these tools: sudo",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestDefaultPromptConstraints(t *testing.T) {
+	p := DefaultPrompt()
+	p.Context.Constraints = []string{"Do not use sudo", "Do not use the network"}
+	p.Context.Packs = []string{"Prefer kubectl"}
+
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, "- Do not use sudo\n- Do not use the network\n- Prefer kubectl") {
+		t.Errorf("prompt missing constraints and packs, got:\n%s", got)
+	}
+}
+
+func TestDefaultPromptTarget(t *testing.T) {
+	p := DefaultPrompt()
+
+	local, _ := p.Render("q")
+	if strings.Contains(local, "another machine") {
+		t.Errorf("prompt without a target mentions one: %q", local)
+	}
+
+	p.Context.Target = "busybox"
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, "run on another machine, not this one: busybox.") {
+		t.Errorf("prompt missing target, got:\n%s", got)
+	}
+}
+
+func TestDefaultPromptLanguage(t *testing.T) {
+	p := DefaultPrompt()
+
+	english, _ := p.Render("q")
+	if strings.Contains(english, "Write titles and descriptions in") {
+		t.Errorf("prompt without a language asks for one: %q", english)
+	}
+
+	p.Context.Language = "Spanish"
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, "Write titles and descriptions in Spanish") {
+		t.Errorf("prompt missing language instruction, got:\n%s", got)
+	}
+}
+
+func TestDefaultPromptTargetShell(t *testing.T) {
+	p := DefaultPrompt()
+	p.Context.TargetShell = "PowerShell"
+
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, "Write every command for PowerShell") {
+		t.Errorf("prompt missing target shell, got:\n%s", got)
+	}
+}
+
+func TestDefaultPromptFocus(t *testing.T) {
+	p := DefaultPrompt()
+	p.Context.Focus = "Focus on the jq filter."
+
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, "- Focus on the jq filter.") {
+		t.Errorf("prompt missing focus, got:\n%s", got)
+	}
+}
+
+func TestDefaultPromptScript(t *testing.T) {
+	p := DefaultPrompt()
+	if got, _ := p.Render("q"); strings.Contains(got, "set -euo pipefail") {
+		t.Errorf("prompt allows scripts without Script, got:\n%s", got)
+	}
+
+	p.Context.Script = true
+	got, err := p.Render("q")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(got, "short script of several lines") || !strings.Contains(got, "set -euo pipefail") {
+		t.Errorf("prompt missing script instructions, got:\n%s", got)
+	}
+}
+
+func TestLoadPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	if err := os.WriteFile(path, []byte("Do: {{.Query}}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadPrompt(path)
+	if err != nil {
+		t.Fatalf("LoadPrompt() error = %v", err)
+	}
+	if got, _ := p.Render("x"); got != "Do: x" {
+		t.Errorf("Render() = %q, want %q", got, "Do: x")
+	}
+
+	if _, err := LoadPrompt(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadPrompt() of a missing file should fail")
+	}
+}
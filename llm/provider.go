@@ -13,6 +13,11 @@ import (
 type AnthropicClient struct {
 	client anthropic.Client
 	model  anthropic.Model
+	prompt *Prompt
+
+	thinking       ThinkingMode
+	thinkingBudget int64
+	structured     StructuredOutput
 }
 
 // optionsSchema defines the structured output format for command generation.
@@ -36,44 +41,75 @@ var optionsSchema = map[string]any{
 						"type":        "string",
 						"description": "Clear explanation of what this command does and any important details",
 					},
+					"confidence": map[string]any{
+						"type":        "integer",
+						"description": "How confident you are, from 0 to 100, that this is the command the user wants. Give the most canonical option the highest score",
+					},
 				},
-				"required":             []string{"title", "command", "description"},
+				"required":             []string{"title", "command", "description", "confidence"},
 				"additionalProperties": false,
 			},
 		},
+		"refusal": map[string]any{
+			"type":        "object",
+			"description": "Set, with no options, only when no shell command can do what was asked",
+			"properties": map[string]any{
+				"reason": map[string]any{
+					"type":        "string",
+					"description": "Why a shell command can't do this, in one sentence",
+				},
+				"suggestion": map[string]any{
+					"type":        "string",
+					"description": "A related task a shell one-liner could do instead",
+				},
+			},
+			"required":             []string{"reason", "suggestion"},
+			"additionalProperties": false,
+		},
 	},
 	"required":             []string{"options"},
 	"additionalProperties": false,
 }
 
-// Public: Creates a new Anthropic client for command generation.
+// Public: Creates a new Anthropic client for command generation using the
+// default prompt.
 func NewAnthropicClient(apiKey, model string) (Client, error) {
+	return NewAnthropicClientWithPrompt(apiKey, model, DefaultPrompt())
+}
+
+// Public: Creates a new Anthropic client for command generation that
+// renders its requests from prompt.
+func NewAnthropicClientWithPrompt(apiKey, model string, prompt *Prompt) (Client, error) {
 	return &AnthropicClient{
-		client: anthropic.NewClient(option.WithAPIKey(apiKey)),
+		client: anthropic.NewClient(option.WithAPIKey(apiKey), skipRateLimitRetries()),
 		model:  anthropic.Model(model),
+		prompt: prompt,
 	}, nil
 }
 
-// Public: Generates command options from a natural language query using
-// Anthropic's structured outputs API.
-func (c *AnthropicClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
-	promptText := fmt.Sprintf(`Given this user request: "%s"
+// Public: Sends requests with the keys in ring, in turn, instead of the
+// client's own key.
+func (c *AnthropicClient) SetKeyRing(ring *KeyRing) {
+	c.client = anthropic.NewClient(skipRateLimitRetries(), ring.Option())
+}
 
-Generate exactly 3 different shell command options that accomplish the task.
+// Public: Sets how JSON responses are requested. See StructuredOutputMode.
+func (c *AnthropicClient) SetStructuredOutputs(mode StructuredOutputMode) {
+	c.structured.Mode = mode
+}
 
-Requirements:
-- Provide exactly 3 different approaches when possible
-- Commands should be safe and practical
-- Prefer commonly available tools
-- Include relevant flags and options
-- Descriptions should explain the approach and any caveats`, query)
+// Public: Generates command options from a natural language query using
+// Anthropic's structured outputs API, or tool use where that's missing.
+// Returns a *Refusal error when the query isn't a shell task.
+func (c *AnthropicClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
+	promptText, err := c.prompt.Render(query)
+	if err != nil {
+		return nil, err
+	}
 
-	message, err := c.client.Beta.Messages.New(ctx, anthropic.BetaMessageNewParams{
+	params := anthropic.BetaMessageNewParams{
 		Model:     c.model,
 		MaxTokens: 2048,
-		Betas: []anthropic.AnthropicBeta{
-			"structured-outputs-2025-11-13",
-		},
 		Messages: []anthropic.BetaMessageParam{{
 			Content: []anthropic.BetaContentBlockParamUnion{{
 				OfText: &anthropic.BetaTextBlockParam{
@@ -82,32 +118,33 @@ Requirements:
 			}},
 			Role: anthropic.BetaMessageParamRoleUser,
 		}},
-		OutputFormat: anthropic.BetaJSONOutputFormatParam{
-			Schema: optionsSchema,
-		},
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("API call failed: %w", err)
 	}
 
-	if len(message.Content) == 0 {
-		return nil, fmt.Errorf("empty response from API")
+	// The thinking budget counts towards max_tokens, so the answer keeps
+	// its full allowance on top.
+	if budget := c.budgetFor(query); budget > 0 {
+		params.Thinking = anthropic.BetaThinkingConfigParamOfEnabled(budget)
+		params.MaxTokens += budget
 	}
 
-	textContent := message.Content[0].Text
-	if textContent == "" {
-		return nil, fmt.Errorf("no text content in response")
+	textContent, err := c.structured.New(ctx, &c.client, params, optionsSchema)
+	if err != nil {
+		return nil, err
 	}
 
 	var result struct {
 		Options []CommandOption `json:"options"`
+		Refusal *Refusal        `json:"refusal"`
 	}
 
 	if err := json.Unmarshal([]byte(textContent), &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response JSON: %w", err)
 	}
 
+	if len(result.Options) == 0 && result.Refusal != nil {
+		return nil, result.Refusal
+	}
+
 	if len(result.Options) == 0 {
 		return nil, fmt.Errorf("no options returned")
 	}
@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// DefaultMaxRateLimitWait caps the total time spent waiting out rate
+// limits for one query, so a long reset doesn't leave the user stuck.
+const DefaultMaxRateLimitWait = time.Minute
+
+// defaultRateLimitWait is used when a 429 carries no usable headers.
+const defaultRateLimitWait = 5 * time.Second
+
+// rateLimitResetHeaders report when each Anthropic limit next resets, as
+// RFC 3339 times.
+var rateLimitResetHeaders = []string{
+	"anthropic-ratelimit-requests-reset",
+	"anthropic-ratelimit-tokens-reset",
+	"anthropic-ratelimit-input-tokens-reset",
+	"anthropic-ratelimit-output-tokens-reset",
+}
+
+type retryNotifierKey struct{}
+
+// Public: Returns a context that has fn called with the wait before each
+// rate-limit retry, so a UI can show a countdown. fn must not block.
+func WithRetryNotifier(ctx context.Context, fn func(wait time.Duration)) context.Context {
+	return context.WithValue(ctx, retryNotifierKey{}, fn)
+}
+
+// RateLimitClient retries queries that hit a rate limit after the wait the
+// API asks for, up to a total cap.
+type RateLimitClient struct {
+	client  Client
+	maxWait time.Duration
+}
+
+// Public: Wraps client to wait out rate limits. A maxWait of zero uses
+// DefaultMaxRateLimitWait; a negative one never waits.
+func NewRateLimitClient(client Client, maxWait time.Duration) *RateLimitClient {
+	if maxWait == 0 {
+		maxWait = DefaultMaxRateLimitWait
+	}
+	return &RateLimitClient{client: client, maxWait: maxWait}
+}
+
+// Public: Generates options, waiting and retrying while rate limited.
+func (r *RateLimitClient) GenerateOptions(ctx context.Context, query string) ([]CommandOption, error) {
+	var waited time.Duration
+	for {
+		options, err := r.client.GenerateOptions(ctx, query)
+		wait, limited := RetryAfter(err)
+		if !limited {
+			return options, err
+		}
+		if waited+wait > r.maxWait {
+			return nil, fmt.Errorf("rate limited; not retrying, as the limit resets in %s: %w", wait.Round(time.Second), err)
+		}
+
+		if notify, ok := ctx.Value(retryNotifierKey{}).(func(time.Duration)); ok {
+			notify(wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		waited += wait
+	}
+}
+
+// Public: Reports whether err is a rate-limit (429) response and how long
+// to wait before retrying, from retry-after or the rate-limit reset
+// headers.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if apiErr.Response == nil {
+		return defaultRateLimitWait, true
+	}
+	return retryWait(apiErr.Response.Header, time.Now()), true
+}
+
+// retryWait reads the wait from rate-limit response headers. retry-after
+// is preferred; otherwise the latest reset time is used, since any
+// exhausted limit blocks the request.
+func retryWait(h http.Header, now time.Time) time.Duration {
+	if ms, err := strconv.ParseFloat(h.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if v := h.Get("retry-after"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+
+	var latest time.Time
+	for _, name := range rateLimitResetHeaders {
+		if t, err := time.Parse(time.RFC3339, h.Get(name)); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	if !latest.IsZero() {
+		return max(latest.Sub(now), 0)
+	}
+
+	return defaultRateLimitWait
+}
+
+// skipRateLimitRetries stops the SDK from silently retrying 429s itself,
+// leaving them to RateLimitClient, which can report the wait.
+func skipRateLimitRetries() option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		res, err := next(req)
+		if res != nil && res.StatusCode == http.StatusTooManyRequests {
+			res.Header.Set("x-should-retry", "false")
+		}
+		return res, err
+	})
+}
@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// rateLimitError builds a 429 API error carrying header.
+func rateLimitError(header http.Header) error {
+	return fmt.Errorf("API call failed: %w", &anthropic.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Request:    httptest.NewRequest(http.MethodPost, "/v1/messages", nil),
+		Response:   &http.Response{StatusCode: http.StatusTooManyRequests, Header: header},
+	})
+}
+
+// sequenceClient returns each error in turn, then answers.
+type sequenceClient struct {
+	errs  []error
+	calls int
+}
+
+func (s *sequenceClient) GenerateOptions(context.Context, string) ([]CommandOption, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return []CommandOption{{Title: "List", Command: "ls"}}, nil
+}
+
+func TestRetryWait(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{
+			name:   "retry-after-ms",
+			header: http.Header{"Retry-After-Ms": {"1500"}, "Retry-After": {"9"}},
+			want:   1500 * time.Millisecond,
+		},
+		{
+			name:   "retry-after seconds",
+			header: http.Header{"Retry-After": {"12"}},
+			want:   12 * time.Second,
+		},
+		{
+			name:   "retry-after date",
+			header: http.Header{"Retry-After": {now.Add(30 * time.Second).Format(http.TimeFormat)}},
+			want:   30 * time.Second,
+		},
+		{
+			name: "latest reset",
+			header: http.Header{
+				"Anthropic-Ratelimit-Requests-Reset": {now.Add(5 * time.Second).Format(time.RFC3339)},
+				"Anthropic-Ratelimit-Tokens-Reset":   {now.Add(20 * time.Second).Format(time.RFC3339)},
+			},
+			want: 20 * time.Second,
+		},
+		{
+			name:   "reset in the past",
+			header: http.Header{"Anthropic-Ratelimit-Requests-Reset": {now.Add(-time.Minute).Format(time.RFC3339)}},
+			want:   0,
+		},
+		{
+			name:   "no headers",
+			header: http.Header{},
+			want:   defaultRateLimitWait,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryWait(tt.header, now); got != tt.want {
+				t.Errorf("retryWait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterIgnoresOtherErrors(t *testing.T) {
+	for _, err := range []error{nil, fmt.Errorf("boom"), statusError(529)} {
+		if _, ok := RetryAfter(err); ok {
+			t.Errorf("RetryAfter(%v) reported a rate limit", err)
+		}
+	}
+}
+
+func TestRateLimitClient(t *testing.T) {
+	short := rateLimitError(http.Header{"Retry-After-Ms": {"10"}})
+	long := rateLimitError(http.Header{"Retry-After": {"120"}})
+
+	tests := []struct {
+		name      string
+		errs      []error
+		maxWait   time.Duration
+		wantCalls int
+		wantWaits int
+		wantErr   string
+	}{
+		{
+			name:      "retries after waiting",
+			errs:      []error{short, short},
+			maxWait:   time.Second,
+			wantCalls: 3,
+			wantWaits: 2,
+		},
+		{
+			name:      "gives up past the cap",
+			errs:      []error{long},
+			maxWait:   time.Second,
+			wantCalls: 1,
+			wantErr:   "rate limited",
+		},
+		{
+			name:      "never waits when disabled",
+			errs:      []error{short},
+			maxWait:   -1,
+			wantCalls: 1,
+			wantErr:   "rate limited",
+		},
+		{
+			name:      "other errors pass through",
+			errs:      []error{statusError(http.StatusUnauthorized)},
+			maxWait:   time.Second,
+			wantCalls: 1,
+			wantErr:   "401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &sequenceClient{errs: tt.errs}
+			var waits int
+			ctx := WithRetryNotifier(context.Background(), func(time.Duration) { waits++ })
+
+			_, err := NewRateLimitClient(inner, tt.maxWait).GenerateOptions(ctx, "list files")
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+			if inner.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", inner.calls, tt.wantCalls)
+			}
+			if waits != tt.wantWaits {
+				t.Errorf("waits = %d, want %d", waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestRateLimitClientStopsOnCancel(t *testing.T) {
+	inner := &sequenceClient{errs: []error{rateLimitError(http.Header{"Retry-After": {"30"}})}}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithRetryNotifier(ctx, func(time.Duration) { cancel() })
+
+	_, err := NewRateLimitClient(inner, time.Minute).GenerateOptions(ctx, "list files")
+	if err != context.Canceled {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
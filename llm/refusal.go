@@ -0,0 +1,15 @@
+package llm
+
+// Refusal is returned by GenerateOptions when the model judges that the
+// query isn't something a shell command can do, such as writing a poem.
+type Refusal struct {
+	// Reason says why no command fits the query.
+	Reason string `json:"reason"`
+	// Suggestion is a related task 1lm could help with instead.
+	Suggestion string `json:"suggestion"`
+}
+
+// Error describes the refusal.
+func (r *Refusal) Error() string {
+	return "not a shell task: " + r.Reason
+}
@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// StructuredOutputMode controls how JSON responses are requested, accepted
+// by the structured_outputs config key.
+type StructuredOutputMode string
+
+const (
+	// StructuredOutputsAuto uses the structured outputs beta and switches
+	// to tool use if the endpoint rejects it. The zero value behaves the
+	// same.
+	StructuredOutputsAuto StructuredOutputMode = "auto"
+	// StructuredOutputsOn always uses the beta, without a fallback.
+	StructuredOutputsOn StructuredOutputMode = "on"
+	// StructuredOutputsOff always uses tool use, for models, proxies and
+	// Bedrock endpoints known to lack the beta.
+	StructuredOutputsOff StructuredOutputMode = "off"
+)
+
+// structuredOutputsBeta is the beta header that enables output_format.
+const structuredOutputsBeta = "structured-outputs-2025-11-13"
+
+// respondTool is the tool the model is made to call in the fallback path.
+const respondTool = "respond"
+
+// StructuredOutput requests responses matching a JSON schema. Once an
+// endpoint has rejected the structured outputs beta, later requests go
+// straight to tool use. It is safe for concurrent use.
+type StructuredOutput struct {
+	Mode StructuredOutputMode
+
+	unsupported atomic.Bool
+}
+
+// Public: Sends params and returns the response JSON, which matches schema.
+// The caller leaves Betas, OutputFormat and tools unset.
+//
+// ctx    - Cancels the request
+// client - The Anthropic client to send with
+// params - The request, with model, messages and max tokens
+// schema - JSON schema for the response object
+//
+// Returns the JSON text of the response.
+func (s *StructuredOutput) New(ctx context.Context, client *anthropic.Client, params anthropic.BetaMessageNewParams, schema map[string]any) (string, error) {
+	if s.Mode != StructuredOutputsOff && !s.unsupported.Load() {
+		text, err := newWithOutputFormat(ctx, client, params, schema)
+		if err == nil || s.Mode == StructuredOutputsOn || !isBetaRejected(err) {
+			return text, err
+		}
+		s.unsupported.Store(true)
+	}
+
+	return newWithTool(ctx, client, params, schema)
+}
+
+func newWithOutputFormat(ctx context.Context, client *anthropic.Client, params anthropic.BetaMessageNewParams, schema map[string]any) (string, error) {
+	params.Betas = []anthropic.AnthropicBeta{structuredOutputsBeta}
+	params.OutputFormat = anthropic.BetaJSONOutputFormatParam{Schema: schema}
+
+	message, err := client.Beta.Messages.New(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("API call failed: %w", err)
+	}
+	recordUsage(message.Model, message.Usage)
+
+	return ResponseText(message.Content)
+}
+
+// newWithTool forces a call to a tool whose input schema is the response
+// schema, and reads the answer from the tool input. Forced tool calls
+// can't be combined with extended thinking, so thinking is turned off.
+func newWithTool(ctx context.Context, client *anthropic.Client, params anthropic.BetaMessageNewParams, schema map[string]any) (string, error) {
+	inputSchema := anthropic.BetaToolInputSchemaParam{
+		Properties:  schema["properties"],
+		ExtraFields: map[string]any{"additionalProperties": false},
+	}
+	if required, ok := schema["required"].([]string); ok {
+		inputSchema.Required = required
+	}
+
+	params.Thinking = anthropic.BetaThinkingConfigParamUnion{}
+	params.Tools = []anthropic.BetaToolUnionParam{{
+		OfTool: &anthropic.BetaToolParam{
+			Name:        respondTool,
+			Description: anthropic.String("Give the response."),
+			InputSchema: inputSchema,
+		},
+	}}
+	params.ToolChoice = anthropic.BetaToolChoiceParamOfTool(respondTool)
+
+	message, err := client.Beta.Messages.New(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("API call failed: %w", err)
+	}
+	recordUsage(message.Model, message.Usage)
+
+	for _, block := range message.Content {
+		if block.Type == "tool_use" && block.Name == respondTool {
+			return string(block.Input), nil
+		}
+	}
+
+	// Some proxies drop tools and answer in text; take any JSON object in
+	// it.
+	text, err := ResponseText(message.Content)
+	if err != nil {
+		return "", err
+	}
+	return ExtractJSON(text), nil
+}
+
+// isBetaRejected reports whether err is the API refusing the structured
+// outputs beta or its output_format parameter, rather than a failure that
+// would also break the fallback.
+func isBetaRejected(err error) bool {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode != http.StatusBadRequest && apiErr.StatusCode != http.StatusNotFound {
+		return false
+	}
+
+	body := strings.ToLower(apiErr.RawJSON())
+	for _, hint := range []string{"output_format", "structured", "beta"} {
+		if strings.Contains(body, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// Public: Returns the JSON object in text, ignoring code fences and prose
+// around it. Text without an object is returned unchanged, so the JSON
+// parser reports the error.
+func ExtractJSON(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return text
+	}
+
+	candidate := text[start : end+1]
+	if !json.Valid([]byte(candidate)) {
+		return text
+	}
+	return candidate
+}
@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "bare object",
+			text: `{"options":[]}`,
+			want: `{"options":[]}`,
+		},
+		{
+			name: "code fence",
+			text: "```json\n{\"options\":[]}\n```",
+			want: `{"options":[]}`,
+		},
+		{
+			name: "prose around",
+			text: "Here you go: {\"a\": {\"b\": 1}} Hope that helps!",
+			want: `{"a": {"b": 1}}`,
+		},
+		{
+			name: "no object",
+			text: "Sorry, I can't help.",
+			want: "Sorry, I can't help.",
+		},
+		{
+			name: "braces that aren't JSON",
+			text: "use {} and {x}",
+			want: "use {} and {x}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractJSON(tt.text); got != tt.want {
+				t.Errorf("ExtractJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBetaRejected(t *testing.T) {
+	apiError := func(status int, body string) error {
+		e := &anthropic.Error{StatusCode: status}
+		if err := e.UnmarshalJSON([]byte(body)); err != nil {
+			t.Fatalf("UnmarshalJSON() error = %v", err)
+		}
+		return fmt.Errorf("API call failed: %w", e)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "output_format rejected",
+			err:  apiError(http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"output_format: Extra inputs are not permitted"}}`),
+			want: true,
+		},
+		{
+			name: "unknown beta",
+			err:  apiError(http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"Unexpected value(s) for the anthropic-beta header"}}`),
+			want: true,
+		},
+		{
+			name: "other bad request",
+			err:  apiError(http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: too large"}}`),
+			want: false,
+		},
+		{
+			name: "overloaded",
+			err:  apiError(529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+			want: false,
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("connection refused"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBetaRejected(tt.err); got != tt.want {
+				t.Errorf("isBetaRejected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructuredOutputFallback(t *testing.T) {
+	var structuredCalls, toolCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.Contains(string(body), `"output_format"`) {
+			structuredCalls++
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"output_format: Extra inputs are not permitted"}}`)
+			return
+		}
+
+		toolCalls++
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","model":"m","stop_reason":"tool_use",
+			"content":[{"type":"tool_use","id":"tu_1","name":"respond","input":{"options":[]}}],
+			"usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	client := anthropic.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+	params := anthropic.BetaMessageNewParams{Model: "m", MaxTokens: 16}
+	schema := map[string]any{"type": "object", "properties": map[string]any{}}
+
+	var s StructuredOutput
+	for range 2 {
+		got, err := s.New(context.Background(), &client, params, schema)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if got != `{"options":[]}` {
+			t.Errorf("New() = %q, want the tool input", got)
+		}
+	}
+
+	// The rejection is remembered, so the second call goes straight to
+	// tool use.
+	if structuredCalls != 1 || toolCalls != 2 {
+		t.Errorf("got %d structured and %d tool calls, want 1 and 2", structuredCalls, toolCalls)
+	}
+
+	s = StructuredOutput{Mode: StructuredOutputsOn}
+	if _, err := s.New(context.Background(), &client, params, schema); err == nil {
+		t.Error("New() with mode on succeeded, want the rejection error")
+	}
+}
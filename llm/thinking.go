@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// ThinkingMode controls extended thinking, accepted by the thinking config
+// key.
+type ThinkingMode string
+
+const (
+	// ThinkingAuto thinks only for complex queries on models that support
+	// it. The zero value behaves the same.
+	ThinkingAuto ThinkingMode = "auto"
+	// ThinkingOn thinks for every query on models that support it.
+	ThinkingOn ThinkingMode = "on"
+	// ThinkingOff never thinks.
+	ThinkingOff ThinkingMode = "off"
+)
+
+// DefaultThinkingBudget is the thinking token budget used when none is
+// configured. The API's minimum is 1024.
+const DefaultThinkingBudget = 2048
+
+// minThinkingBudget is the smallest budget the API accepts.
+const minThinkingBudget = 1024
+
+// thinkingModels lists prefixes of model names that support extended
+// thinking.
+var thinkingModels = []string{
+	"claude-3-7-sonnet",
+	"claude-sonnet-4",
+	"claude-opus-4",
+	"claude-haiku-4-5",
+}
+
+// complexQueryWords is the length past which a query counts as complex.
+const complexQueryWords = 25
+
+// Public: Reports whether model supports extended thinking.
+func SupportsThinking(model string) bool {
+	for _, prefix := range thinkingModels {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Public: Sets when extended thinking is used and its token budget. A
+// budget below the API minimum uses DefaultThinkingBudget.
+func (c *AnthropicClient) SetThinking(mode ThinkingMode, budget int64) {
+	if budget < minThinkingBudget {
+		budget = DefaultThinkingBudget
+	}
+	c.thinking = mode
+	c.thinkingBudget = budget
+}
+
+// budgetFor returns the thinking budget for query, or 0 for no thinking.
+// Short one-line requests don't benefit enough to justify the latency.
+func (c *AnthropicClient) budgetFor(query string) int64 {
+	if c.thinking == ThinkingOff || !SupportsThinking(string(c.model)) {
+		return 0
+	}
+	if c.thinking != ThinkingOn && !isComplexQuery(query) {
+		return 0
+	}
+	if c.thinkingBudget == 0 {
+		return DefaultThinkingBudget
+	}
+	return c.thinkingBudget
+}
+
+// isComplexQuery guesses whether a query needs reasoning: long requests,
+// and anything multi-line such as attached error output.
+func isComplexQuery(query string) bool {
+	return strings.Contains(query, "\n") || len(strings.Fields(query)) > complexQueryWords
+}
+
+// Public: Returns the text of a response, skipping thinking blocks, which
+// come first when extended thinking is on.
+func ResponseText(content []anthropic.BetaContentBlockUnion) (string, error) {
+	if len(content) == 0 {
+		return "", fmt.Errorf("empty response from API")
+	}
+
+	for _, block := range content {
+		if block.Type == "text" && block.Text != "" {
+			return block.Text, nil
+		}
+	}
+
+	return "", fmt.Errorf("no text content in response")
+}
@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestSupportsThinking(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"claude-sonnet-4-5-20250929", true},
+		{"claude-opus-4-1-20250805", true},
+		{"claude-3-7-sonnet-latest", true},
+		{"claude-haiku-4-5", true},
+		{"claude-3-5-haiku-latest", false},
+		{"gpt-4o", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := SupportsThinking(tt.model); got != tt.want {
+				t.Errorf("SupportsThinking(%q) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetFor(t *testing.T) {
+	short := "list files"
+	long := strings.Repeat("word ", complexQueryWords+1)
+
+	tests := []struct {
+		name   string
+		model  string
+		mode   ThinkingMode
+		budget int64
+		query  string
+		want   int64
+	}{
+		{name: "auto skips short query", model: "claude-sonnet-4-5", query: short, want: 0},
+		{name: "auto thinks for long query", model: "claude-sonnet-4-5", query: long, want: DefaultThinkingBudget},
+		{name: "auto thinks for multi-line query", model: "claude-sonnet-4-5", query: "fix this\nerror: boom", want: DefaultThinkingBudget},
+		{name: "on thinks for short query", model: "claude-sonnet-4-5", mode: ThinkingOn, budget: 4096, query: short, want: 4096},
+		{name: "off never thinks", model: "claude-sonnet-4-5", mode: ThinkingOff, query: long, want: 0},
+		{name: "unsupported model", model: "claude-3-5-haiku-latest", mode: ThinkingOn, query: long, want: 0},
+		{name: "budget below minimum", model: "claude-sonnet-4-5", mode: ThinkingOn, budget: 100, query: short, want: DefaultThinkingBudget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AnthropicClient{model: anthropic.Model(tt.model)}
+			if tt.mode != "" || tt.budget != 0 {
+				c.SetThinking(tt.mode, tt.budget)
+			}
+			if got := c.budgetFor(tt.query); got != tt.want {
+				t.Errorf("budgetFor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []anthropic.BetaContentBlockUnion
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "text only",
+			content: []anthropic.BetaContentBlockUnion{{Type: "text", Text: `{"options":[]}`}},
+			want:    `{"options":[]}`,
+		},
+		{
+			name: "thinking first",
+			content: []anthropic.BetaContentBlockUnion{
+				{Type: "thinking", Thinking: "The user wants..."},
+				{Type: "redacted_thinking", Data: "abc"},
+				{Type: "text", Text: `{"options":[]}`},
+			},
+			want: `{"options":[]}`,
+		},
+		{
+			name:    "thinking only",
+			content: []anthropic.BetaContentBlockUnion{{Type: "thinking", Thinking: "hmm"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResponseText(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResponseText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResponseText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// Tokenizer counts the tokens a piece of text will consume for a model.
+type Tokenizer interface {
+	CountTokens(ctx context.Context, text string) (int, error)
+}
+
+// Public: Returns the most accurate tokenizer available for a provider.
+//
+// Anthropic models are counted with the API's count_tokens endpoint, with
+// results cached and the heuristic used if the endpoint is unreachable.
+// Unknown providers get the heuristic.
+func NewTokenizer(provider, apiKey, model string) Tokenizer {
+	switch provider {
+	case "anthropic":
+		return NewCachingTokenizer(&fallbackTokenizer{
+			primary:  NewAnthropicTokenizer(apiKey, model),
+			fallback: HeuristicTokenizer{},
+		})
+	default:
+		return HeuristicTokenizer{}
+	}
+}
+
+// HeuristicTokenizer estimates tokens from character count. It needs no
+// network access, so it is the fallback for every other tokenizer.
+type HeuristicTokenizer struct{}
+
+// charsPerToken is a conservative average for English prose and shell
+// syntax across current model families.
+const charsPerToken = 4
+
+// Public: Estimates the token count as one token per four characters,
+// rounded up.
+func (HeuristicTokenizer) CountTokens(_ context.Context, text string) (int, error) {
+	n := utf8.RuneCountInString(text)
+	return (n + charsPerToken - 1) / charsPerToken, nil
+}
+
+// AnthropicTokenizer counts tokens using Anthropic's count_tokens endpoint.
+type AnthropicTokenizer struct {
+	client anthropic.Client
+	model  anthropic.Model
+}
+
+// Public: Creates a tokenizer backed by Anthropic's count_tokens endpoint.
+func NewAnthropicTokenizer(apiKey, model string) *AnthropicTokenizer {
+	return &AnthropicTokenizer{
+		client: anthropic.NewClient(option.WithAPIKey(apiKey)),
+		model:  anthropic.Model(model),
+	}
+}
+
+// Public: Counts tokens for text sent as a single user message. The count
+// includes the few tokens of message framing the API adds.
+func (t *AnthropicTokenizer) CountTokens(ctx context.Context, text string) (int, error) {
+	res, err := t.client.Messages.CountTokens(ctx, anthropic.MessageCountTokensParams{
+		Model: t.model,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(text)),
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count tokens failed: %w", err)
+	}
+
+	return int(res.InputTokens), nil
+}
+
+// fallbackTokenizer uses primary and quietly switches to fallback when
+// primary fails, since budgeting should never block a request.
+type fallbackTokenizer struct {
+	primary  Tokenizer
+	fallback Tokenizer
+}
+
+func (t *fallbackTokenizer) CountTokens(ctx context.Context, text string) (int, error) {
+	if n, err := t.primary.CountTokens(ctx, text); err == nil {
+		return n, nil
+	}
+	return t.fallback.CountTokens(ctx, text)
+}
+
+// CachingTokenizer memoizes counts so static prompt blocks (instructions,
+// examples) are only counted once per process.
+type CachingTokenizer struct {
+	next   Tokenizer
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Public: Wraps a tokenizer with an in-memory cache of counts.
+func NewCachingTokenizer(next Tokenizer) *CachingTokenizer {
+	return &CachingTokenizer{
+		next:   next,
+		counts: make(map[string]int),
+	}
+}
+
+// Public: Returns the cached count for text, counting it on first use.
+// Errors are not cached so transient failures can be retried.
+func (t *CachingTokenizer) CountTokens(ctx context.Context, text string) (int, error) {
+	t.mu.Lock()
+	n, ok := t.counts[text]
+	t.mu.Unlock()
+	if ok {
+		return n, nil
+	}
+
+	n, err := t.next.CountTokens(ctx, text)
+	if err != nil {
+		return 0, err
+	}
+
+	t.mu.Lock()
+	t.counts[text] = n
+	t.mu.Unlock()
+
+	return n, nil
+}
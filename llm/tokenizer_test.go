@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// countingTokenizer records how many times it is called.
+type countingTokenizer struct {
+	calls int
+	count int
+	err   error
+}
+
+func (c *countingTokenizer) CountTokens(_ context.Context, _ string) (int, error) {
+	c.calls++
+	return c.count, c.err
+}
+
+func TestHeuristicTokenizer(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "empty", text: "", want: 0},
+		{name: "exact multiple", text: "abcdefgh", want: 2},
+		{name: "rounds up", text: "abcde", want: 2},
+		{name: "counts runes not bytes", text: "日本語の", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HeuristicTokenizer{}.CountTokens(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("CountTokens() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountTokens(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachingTokenizer(t *testing.T) {
+	inner := &countingTokenizer{count: 42}
+	tok := NewCachingTokenizer(inner)
+
+	for range 3 {
+		n, err := tok.CountTokens(context.Background(), "static block")
+		if err != nil {
+			t.Fatalf("CountTokens() error = %v", err)
+		}
+		if n != 42 {
+			t.Errorf("CountTokens() = %d, want 42", n)
+		}
+	}
+
+	if inner.calls != 1 {
+		t.Errorf("inner tokenizer called %d times, want 1", inner.calls)
+	}
+}
+
+func TestCachingTokenizerDoesNotCacheErrors(t *testing.T) {
+	inner := &countingTokenizer{err: errors.New("unavailable")}
+	tok := NewCachingTokenizer(inner)
+
+	_, _ = tok.CountTokens(context.Background(), "text")
+	_, _ = tok.CountTokens(context.Background(), "text")
+
+	if inner.calls != 2 {
+		t.Errorf("inner tokenizer called %d times, want 2", inner.calls)
+	}
+}
+
+func TestFallbackTokenizer(t *testing.T) {
+	tok := &fallbackTokenizer{
+		primary:  &countingTokenizer{err: errors.New("unavailable")},
+		fallback: &countingTokenizer{count: 7},
+	}
+
+	n, err := tok.CountTokens(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	if n != 7 {
+		t.Errorf("CountTokens() = %d, want 7", n)
+	}
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// Usage counts the tokens API calls used.
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// Price is what a model charges, in US dollars per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices are Anthropic's list prices, by model name prefix.
+var prices = map[string]Price{
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-haiku-4-5":  {Input: 1, Output: 5},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+}
+
+// Public: Returns the list price of model, matched by the longest known
+// prefix of its name, so dated and Vertex AI names ("@20250929") match
+// too. Returns false for models with no known price, such as plugins.
+func PriceFor(model string) (Price, bool) {
+	best := ""
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	price, ok := prices[best]
+	return price, ok
+}
+
+// Public: Returns the list price of the usage on model in US dollars, or
+// zero if the model's price isn't known.
+func (u Usage) Cost(model string) float64 {
+	price, _ := PriceFor(model)
+	return (float64(u.InputTokens)*price.Input + float64(u.OutputTokens)*price.Output) / 1e6
+}
+
+// usage totals the tokens used by this process, by model.
+var usage struct {
+	sync.Mutex
+	byModel map[string]Usage
+}
+
+// recordUsage adds a response's tokens to the process's total. Tokens
+// written to and read from the prompt cache count as input.
+func recordUsage(model anthropic.Model, u anthropic.BetaUsage) {
+	usage.Lock()
+	defer usage.Unlock()
+	if usage.byModel == nil {
+		usage.byModel = map[string]Usage{}
+	}
+	total := usage.byModel[string(model)]
+	total.InputTokens += u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	total.OutputTokens += u.OutputTokens
+	usage.byModel[string(model)] = total
+}
+
+// Public: Returns the tokens used by each model since the process started,
+// for generation and safety checks alike. Requests sent through a daemon
+// are counted in the daemon.
+func UsageByModel() map[string]Usage {
+	usage.Lock()
+	defer usage.Unlock()
+	return maps.Clone(usage.byModel)
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestPriceFor(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   Price
+		wantOK bool
+	}{
+		{model: "claude-sonnet-4-5-20250929", want: Price{Input: 3, Output: 15}, wantOK: true},
+		{model: "claude-sonnet-4-5@20250929", want: Price{Input: 3, Output: 15}, wantOK: true},
+		{model: "claude-opus-4-5", want: Price{Input: 5, Output: 25}, wantOK: true},
+		{model: "claude-opus-4-1", want: Price{Input: 15, Output: 75}, wantOK: true},
+		{model: "claude-haiku-4-5", want: Price{Input: 1, Output: 5}, wantOK: true},
+		{model: "plugin:ollama", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := PriceFor(tt.model)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("PriceFor() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestUsageCost(t *testing.T) {
+	u := Usage{InputTokens: 2000, OutputTokens: 500}
+	if got, want := u.Cost("claude-sonnet-4-5"), 0.0135; got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+	if got := u.Cost("plugin:ollama"); got != 0 {
+		t.Errorf("Cost() = %v, want 0 for an unpriced model", got)
+	}
+}
+
+func TestRecordUsage(t *testing.T) {
+	before := UsageByModel()["claude-test"]
+	recordUsage("claude-test", anthropic.BetaUsage{InputTokens: 100, CacheReadInputTokens: 50, OutputTokens: 20})
+	recordUsage("claude-test", anthropic.BetaUsage{InputTokens: 10, OutputTokens: 5})
+
+	got := UsageByModel()["claude-test"]
+	if got.InputTokens-before.InputTokens != 160 || got.OutputTokens-before.OutputTokens != 25 {
+		t.Errorf("UsageByModel() = %+v, want 160 input and 25 output tokens more than %+v", got, before)
+	}
+}
@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// DefaultVertexRegion is used when no region is configured.
+const DefaultVertexRegion = "us-east5"
+
+// googleScope is the OAuth scope Vertex AI requests need.
+const googleScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// vertexVersion is the anthropic_version Vertex AI expects in the body.
+const vertexVersion = "vertex-2023-10-16"
+
+// VertexConfig locates Claude on Google Vertex AI.
+type VertexConfig struct {
+	Project string // Google Cloud project; defaults to the credentials'
+	Region  string // e.g. "us-east5" or "global"; defaults to DefaultVertexRegion
+}
+
+// datedModel matches Anthropic model IDs ending in a date, which Vertex
+// names with an "@" instead: claude-sonnet-4-5@20250929.
+var datedModel = regexp.MustCompile(`-(\d{8})$`)
+
+// Public: Creates an Anthropic API client that sends requests to Vertex AI,
+// authenticated with Google Application Default Credentials. It can be
+// used wherever an API-key client can, such as for safety evaluation.
+func NewVertexAnthropicClient(cfg VertexConfig) (anthropic.Client, error) {
+	ctx := context.Background()
+	creds, err := google.FindDefaultCredentials(ctx, googleScope)
+	if err != nil {
+		return anthropic.Client{}, fmt.Errorf("no Google credentials found; run \"gcloud auth application-default login\" or set GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+	}
+
+	project := cmp.Or(cfg.Project, creds.ProjectID, os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	if project == "" {
+		return anthropic.Client{}, errors.New("vertex_project not set in config and not found in credentials")
+	}
+	region := cmp.Or(cfg.Region, DefaultVertexRegion)
+
+	baseURL := fmt.Sprintf("https://%s-aiplatform.googleapis.com/", region)
+	if region == "global" {
+		baseURL = "https://aiplatform.googleapis.com/"
+	}
+
+	return anthropic.NewClient(
+		option.WithBaseURL(baseURL),
+		option.WithHTTPClient(oauth2.NewClient(ctx, creds.TokenSource)),
+		option.WithMiddleware(vertexMiddleware(project, region)),
+		skipRateLimitRetries(),
+	), nil
+}
+
+// Public: Creates a command generation client for Claude on Vertex AI.
+// Model IDs may be given in either Anthropic's or Vertex's form.
+func NewVertexClient(cfg VertexConfig, model string, prompt *Prompt) (*AnthropicClient, error) {
+	client, err := NewVertexAnthropicClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if prompt == nil {
+		prompt = DefaultPrompt()
+	}
+
+	return &AnthropicClient{
+		client: client,
+		model:  anthropic.Model(VertexModel(model)),
+		prompt: prompt,
+	}, nil
+}
+
+// Public: Converts an Anthropic model ID to Vertex's form, e.g.
+// claude-sonnet-4-5-20250929 to claude-sonnet-4-5@20250929.
+func VertexModel(model string) string {
+	return datedModel.ReplaceAllString(model, "@$1")
+}
+
+// vertexMiddleware rewrites Messages API requests into Vertex rawPredict
+// calls: the model moves from the body into the URL and the body gains
+// anthropic_version. This is the SDK vertex package's rewrite, which can't
+// be used on its own: that package brings google.golang.org/api just for
+// the authenticated transport oauth2 already provides.
+func vertexMiddleware(project, region string) option.Middleware {
+	return func(r *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		if r.Body != nil && r.Method == http.MethodPost {
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			_ = r.Body.Close()
+
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(data, &body); err != nil {
+				return nil, fmt.Errorf("vertex: %w", err)
+			}
+			if _, ok := body["anthropic_version"]; !ok {
+				body["anthropic_version"], _ = json.Marshal(vertexVersion)
+			}
+
+			switch r.URL.Path {
+			case "/v1/messages":
+				var model string
+				_ = json.Unmarshal(body["model"], &model)
+				delete(body, "model")
+				r.URL.Path = fmt.Sprintf("/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:rawPredict", project, region, VertexModel(model))
+			case "/v1/messages/count_tokens":
+				r.URL.Path = fmt.Sprintf("/v1/projects/%s/locations/%s/publishers/anthropic/models/count-tokens:rawPredict", project, region)
+			}
+
+			if data, err = json.Marshal(body); err != nil {
+				return nil, fmt.Errorf("vertex: %w", err)
+			}
+			reader := bytes.NewReader(data)
+			r.Body = io.NopCloser(reader)
+			r.GetBody = func() (io.ReadCloser, error) {
+				_, err := reader.Seek(0, io.SeekStart)
+				return io.NopCloser(reader), err
+			}
+			r.ContentLength = int64(len(data))
+		}
+
+		// The transport authenticates with a Google access token instead.
+		r.Header.Del("X-Api-Key")
+		return next(r)
+	}
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVertexModel(t *testing.T) {
+	tests := map[string]string{
+		"claude-sonnet-4-5-20250929": "claude-sonnet-4-5@20250929",
+		"claude-sonnet-4-5@20250929": "claude-sonnet-4-5@20250929",
+		"claude-sonnet-4-5":          "claude-sonnet-4-5",
+	}
+	for model, want := range tests {
+		if got := VertexModel(model); got != want {
+			t.Errorf("VertexModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+func TestVertexMiddleware(t *testing.T) {
+	middleware := vertexMiddleware("my-project", "us-east5")
+
+	req := httptest.NewRequest(http.MethodPost, "https://us-east5-aiplatform.googleapis.com/v1/messages?beta=true",
+		strings.NewReader(`{"model":"claude-sonnet-4-5-20250929","max_tokens":1024}`))
+	req.Header.Set("X-Api-Key", "sk-ant-should-not-be-sent")
+
+	var sent *http.Request
+	var body map[string]any
+	_, err := middleware(req, func(r *http.Request) (*http.Response, error) {
+		sent = r
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Fatalf("body is not JSON: %v", err)
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	if err != nil {
+		t.Fatalf("middleware error = %v", err)
+	}
+
+	wantPath := "/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/claude-sonnet-4-5@20250929:rawPredict"
+	if sent.URL.Path != wantPath {
+		t.Errorf("path = %q, want %q", sent.URL.Path, wantPath)
+	}
+	if _, ok := body["model"]; ok {
+		t.Error("model left in body")
+	}
+	if body["anthropic_version"] != vertexVersion {
+		t.Errorf("anthropic_version = %v, want %s", body["anthropic_version"], vertexVersion)
+	}
+	if sent.Header.Get("X-Api-Key") != "" {
+		t.Error("API key header still set")
+	}
+}
+
+func TestNewVertexAnthropicClient(t *testing.T) {
+	write := func(t *testing.T, creds string) string {
+		path := filepath.Join(t.TempDir(), "creds.json")
+		if err := os.WriteFile(path, []byte(creds), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	serviceAccount := `{"type":"service_account","project_id":"from-creds","client_email":"bot@x","private_key":"unused","token_uri":"https://oauth2.googleapis.com/token"}`
+	authorizedUser := `{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh"}`
+
+	tests := []struct {
+		name    string
+		creds   string // empty for a missing file
+		project string
+		wantErr string
+	}{
+		{name: "project from credentials", creds: serviceAccount},
+		{name: "project from config", creds: authorizedUser, project: "from-config"},
+		{name: "no project", creds: authorizedUser, wantErr: "vertex_project not set"},
+		{name: "no credentials", wantErr: "no Google credentials found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+			path := filepath.Join(t.TempDir(), "missing.json")
+			if tt.creds != "" {
+				path = write(t, tt.creds)
+			}
+			t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+			_, err := NewVertexAnthropicClient(VertexConfig{Project: tt.project})
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("NewVertexAnthropicClient() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewVertexAnthropicClient() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
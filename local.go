@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/history"
+	"github.com/pixielabs/1lm/snippets"
+	"github.com/pixielabs/1lm/templates"
+)
+
+// localMatcher returns the Settings.LocalMatch hook, which answers a query
+// from history, saved snippets or the built-in templates while the model
+// is asked, or nil when local_match is off. Offline, the generator already
+// answers from these, so there is nothing to add.
+func localMatcher(cfg *config.Config, past *history.History) func(query string) *commands.Option {
+	if cfg.LocalMatch == "off" || *offline {
+		return nil
+	}
+	// Snippets are a convenience here, as with history, so a library that
+	// can't be read just isn't searched.
+	lib, _ := snippets.Load()
+
+	return func(query string) *commands.Option {
+		return localMatch(query, past, lib)
+	}
+}
+
+// localMatch finds a confident answer to query without the model: the
+// command chosen the last time it was asked, then the one snippet covering
+// all of its words, then the one built-in template that does.
+func localMatch(query string, past *history.History, lib *snippets.Library) *commands.Option {
+	if past != nil {
+		if entry, ok := past.Find(query); ok {
+			opt := entry.Option()
+			opt.Source = "your history"
+			return &opt
+		}
+	}
+
+	if lib != nil {
+		if s, ok := lib.BestMatch(query); ok {
+			opt := s.Option()
+			opt.Source = "snippet " + s.Name
+			return &opt
+		}
+	}
+
+	// Only a template covering the whole query, and the only one to, is
+	// confident enough to offer before the model answers.
+	found := templates.Search(query)
+	if len(found) == 0 || found[0].Score < 1 || len(found) > 1 && found[1].Score >= 1 {
+		return nil
+	}
+	return &commands.Option{
+		Title:       found[0].Title,
+		Command:     found[0].Command,
+		Description: found[0].Description,
+		Source:      "built-in template",
+	}
+}
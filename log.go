@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// logLevels maps --log-level names to slog levels.
+var logLevels = map[string]slog.Level{
+	"error": slog.LevelError,
+	"warn":  slog.LevelWarn,
+	"info":  slog.LevelInfo,
+	"debug": slog.LevelDebug,
+}
+
+// logOutput is where log records are written.
+var logOutput = &heldWriter{w: os.Stderr}
+
+// setupLogging sends slog records at the chosen level or above to stderr:
+// warnings by default, errors only with --quiet, and everything with
+// --verbose. An unknown --log-level is an error, but logging is still set
+// up, at the default level, to report it.
+func setupLogging() error {
+	name := *logLevel
+	switch {
+	case *verbose:
+		name = "debug"
+	case *quiet && name == "warn":
+		name = "error"
+	}
+
+	level, ok := logLevels[strings.ToLower(name)]
+	if !ok {
+		level = slog.LevelWarn
+	}
+	slog.SetDefault(slog.New(&cliHandler{w: logOutput, level: level}))
+
+	if !ok {
+		return fmt.Errorf("unknown --log-level %q (want error, warn, info or debug)", name)
+	}
+	return nil
+}
+
+// cliHandler writes records as short lines for people rather than log
+// processors: "Warning: safety check failed err=...".
+type cliHandler struct {
+	w     io.Writer
+	level slog.Level
+	attrs []slog.Attr
+}
+
+// levelNames label each record's line.
+var levelNames = map[slog.Level]string{
+	slog.LevelError: "Error",
+	slog.LevelWarn:  "Warning",
+	slog.LevelInfo:  "Info",
+	slog.LevelDebug: "Debug",
+}
+
+func (h *cliHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *cliHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s", levelNames[r.Level], r.Message)
+	for _, a := range h.attrs {
+		fmt.Fprintf(&b, " %s", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s", a)
+		return true
+	})
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+// WithGroup is unsupported; groups are flattened.
+func (h *cliHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// heldWriter writes to w, except while held, when writes are buffered
+// until release. Log records are held while the UI is on screen, since
+// writing to the terminal under it would tear the display.
+type heldWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	held bool
+	buf  bytes.Buffer
+}
+
+func (h *heldWriter) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.held {
+		return h.buf.Write(p)
+	}
+	return h.w.Write(p)
+}
+
+// hold buffers writes until release.
+func (h *heldWriter) hold() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.held = true
+}
+
+// release writes what was buffered while held, and stops holding.
+func (h *heldWriter) release() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.held = false
+	_, _ = h.buf.WriteTo(h.w)
+}
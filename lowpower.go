@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pixielabs/1lm/config"
+)
+
+// lowPower is set at startup when low_power is on, or detected in auto
+// mode. Besides pausing animation, it keeps requests small and avoids
+// network fetches that a cached copy can stand in for.
+var lowPower bool
+
+// lowPowerCacheAge is how old a cached include_url or policy_url may get
+// in low-power mode before it is fetched again, rather than the usual
+// hour. It still has a limit, so a changed policy reaches a machine that
+// stays on battery.
+const lowPowerCacheAge = 24 * time.Hour
+
+// cacheMaxAge returns how old a cached include_url or policy_url may be
+// before it is fetched again: lowPowerCacheAge in low-power mode, or zero
+// for the packages' own default.
+func cacheMaxAge() time.Duration {
+	if lowPower {
+		return lowPowerCacheAge
+	}
+	return 0
+}
+
+// lowPowerPromptTokens caps max_prompt_tokens in low-power mode, so
+// attached context is trimmed harder and requests stay small.
+const lowPowerPromptTokens = 2000
+
+// promptTokenLimit returns the token limit for a query and its attached
+// context: max_prompt_tokens, capped at lowPowerPromptTokens in low-power
+// mode even when the limit is off.
+func promptTokenLimit(cfg *config.Config) int {
+	if !lowPower {
+		return cfg.MaxPromptTokens
+	}
+	if cfg.MaxPromptTokens > 0 {
+		return min(cfg.MaxPromptTokens, lowPowerPromptTokens)
+	}
+	return lowPowerPromptTokens
+}
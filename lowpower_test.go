@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pixielabs/1lm/config"
+)
+
+func TestPromptTokenLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		lowPower bool
+		limit    int
+		want     int
+	}{
+		{name: "configured", limit: 4000, want: 4000},
+		{name: "default", limit: 0, want: 0},
+		{name: "low power caps the default", lowPower: true, limit: 0, want: lowPowerPromptTokens},
+		{name: "low power caps a raised limit", lowPower: true, limit: 16000, want: lowPowerPromptTokens},
+		{name: "low power caps no limit", lowPower: true, limit: -1, want: lowPowerPromptTokens},
+		{name: "low power keeps a smaller limit", lowPower: true, limit: 500, want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(saved bool) { lowPower = saved }(lowPower)
+			lowPower = tt.lowPower
+
+			if got := promptTokenLimit(&config.Config{MaxPromptTokens: tt.limit}); got != tt.want {
+				t.Errorf("promptTokenLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,123 +2,770 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/termenv"
 	"github.com/pixielabs/1lm/commands"
 	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/daemon"
+	"github.com/pixielabs/1lm/i18n"
 	"github.com/pixielabs/1lm/llm"
 	"github.com/pixielabs/1lm/output"
+	"github.com/pixielabs/1lm/replay"
+	"github.com/pixielabs/1lm/safety"
 	"github.com/pixielabs/1lm/ui"
 )
 
-var outputMode = flag.String("output", "clipboard", "Output mode: clipboard, shell-function, stdout")
+var (
+	outputMode    = flag.String("output", "clipboard", "Output mode: clipboard, shell-function, stdout, json, fzf (every option, one per line, without the selector), nvim (insert into the Neovim 1lm runs in), markdown (a block for runbooks and PRs)")
+	noColor       = flag.Bool("no-color", false, "Disable colors and text styling (also set by NO_COLOR)")
+	plain         = flag.Bool("plain", false, "Disable styling and replace emoji/symbols with ASCII")
+	accessible    = flag.Bool("accessible", false, "Screen-reader friendly mode: static status lines, no emoji")
+	altScreen     = flag.Bool("alt-screen", false, "Use the full-screen layout with a scrollable option list")
+	preview       = flag.Bool("preview", false, "Keep the option list compact and show the highlighted option in full in a side pane")
+	mouse         = flag.Bool("mouse", false, "Scroll with the mouse wheel, and click to pick options in the full-screen layout")
+	dryRun        = flag.Bool("dry-run", false, "Generate and evaluate, then report what would be output without doing it")
+	noDaemon      = flag.Bool("no-daemon", false, "Call the API directly even if a 1lm daemon is running")
+	targetShell   = flag.String("shell", "", "Generate commands for this shell instead of the current one: sh, bash, zsh, fish, powershell, nushell")
+	targetMachine = flag.String("target", "", `Generate commands for another machine instead of this one, e.g. "ubuntu 20.04 arm64" or busybox`)
+	dslMode       = flag.String("lang", "", "Focus on one tool's expression language: "+strings.Join(commands.DSLNames(), ", "))
+	fromClipboard = flag.Bool("from-clipboard", false, "Attach the clipboard contents (an error message, a snippet) to the query as context")
+	stdinContext  = flag.Bool("stdin-context", false, "Attach piped stdin (logs, a file) to the query as context")
+	compareModels = flag.String("models", "", "Comma-separated models for compare, e.g. claude-sonnet-4-5,claude-haiku-4-5")
+	first         = flag.Bool("first", false, "Output the first option that can be selected without showing the selector")
+	script        = flag.Bool("script", false, "Allow short multi-line scripts when the task can't be a one-liner, shown full-screen and printed rather than copied")
+	outputFile    = flag.String("file", "", "Write the selected command to this new file as an executable script")
+	printPrompt   = flag.Bool("show-prompt", false, "Print the prompt and context that would be sent for the query, without sending anything")
+	quiet         = flag.Bool("quiet", false, "Print only the command (nothing in clipboard mode): no banners, no animation")
+	batchJobs     = flag.Int("jobs", 1, "Queries to generate at once in batch and eval")
+	batchRate     = flag.Int("rate", 0, "Maximum queries started per minute in batch and eval (0 for no limit)")
+	recordPath    = flag.String("record", "", "Save API responses to this session file for --replay")
+	replayPath    = flag.String("replay", "", "Answer from a session file saved with --record instead of calling the API")
+	offline       = flag.Bool("offline", false, "Make no network calls: answer from cached responses and snippets, with local safety checks")
+	contextPacks  = flag.String("context", "", "Steer commands toward a platform's tools: "+strings.Join(commands.ContextPackNames(), ", ")+" (comma-separated for several)")
+	noSudo        = flag.Bool("no-sudo", false, "Only generate commands that run without sudo or root")
+	noNetwork     = flag.Bool("no-network", false, "Only generate commands that don't use the network")
+	noInstall     = flag.Bool("no-install", false, "Only generate commands that don't install packages")
+	checkJSON     = flag.Bool("json", false, "Print the result of check as JSON")
+	verbose       = flag.Bool("verbose", false, "Log everything, including which model and API key served each request (same as --log-level debug)")
+	logLevel      = flag.String("log-level", "warn", "Least severe messages to log to stderr: error, warn, info or debug")
+	printShellFn  = flag.String("print-shell-function", "", "Print the shell integration functions for this shell (bash, zsh, fish, powershell) and exit")
+)
+
+// recorder saves the session when --record is set.
+var recorder *replay.Recorder
+
+// keyRing spreads requests across the configured API keys, or is nil with
+// only one.
+var keyRing *llm.KeyRing
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	err := run()
+	if err == nil && recorder != nil {
+		err = recorder.Err()
+	}
+	var status exitStatus
+	if errors.As(err, &status) {
+		os.Exit(int(status))
+	}
+	if err != nil {
+		slog.Error(err.Error())
 		os.Exit(1)
 	}
 }
 
+// exitStatus is returned by subcommands that have already reported their
+// result and only need to exit with a particular status.
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
 func run() error {
 	// Re-order args so flags come first. Go's flag package stops at the
 	// first non-flag argument, so "1lm my query --output=shell-function"
-	// would leave --output unparsed without this.
+	// would leave --output unparsed without this. A lone "-" means "read
+	// the query from stdin" and stays with the query. A flag's value given
+	// as a separate argument ("--models a,b") moves with its flag.
 	var flagArgs, queryArgs []string
-	for _, arg := range os.Args[1:] {
-		if strings.HasPrefix(arg, "-") {
-			flagArgs = append(flagArgs, arg)
-		} else {
+	rest := os.Args[1:]
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
 			queryArgs = append(queryArgs, arg)
+			continue
+		}
+		flagArgs = append(flagArgs, arg)
+		if takesValue(arg) && i+1 < len(rest) {
+			i++
+			flagArgs = append(flagArgs, rest[i])
 		}
 	}
 	os.Args = append(
 		append([]string{os.Args[0]}, flagArgs...), queryArgs...,
 	)
 	flag.Parse()
+	if err := setupLogging(); err != nil {
+		return err
+	}
+	if *printShellFn != "" {
+		return printShellFunction(os.Stdout, *printShellFn)
+	}
 
-	cfg, err := config.Load()
+	cfg, warnings, err := config.LoadWithWarnings()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	lowPower = cfg.LowPowerEnabled()
+	warnings = append(warnings, includeConfig(cfg)...)
+	configWarnings = checkConfig(cfg, warnings)
+	// "1lm config validate" reports the problems itself.
+	if flag.Arg(0) != "config" || flag.Arg(1) != "validate" {
+		warnConfig()
+	}
 
-	if cfg.AnthropicAPIKey == "" {
-		return fmt.Errorf("anthropic_api_key not set in config (~/.config/1lm/config.toml)")
+	if activePolicy, err = loadPolicy(cfg); err != nil {
+		return err
 	}
+	keyRing = newKeyRing(cfg)
+	primarySelection = cfg.PrimarySelection == "on"
+	terminal = detectTerminal(cfg)
+
+	settings := newSettings(cfg)
+
+	if sub, args, ok := lookupSubcommand(flag.Args()); ok {
+		return sub(cfg, settings, args)
+	}
+
+	// "1lm jq …" is shorthand for "1lm --lang jq …".
+	args := flag.Args()
+	if len(args) > 1 && *dslMode == "" {
+		if _, ok := commands.LookupDSL(args[0]); ok {
+			*dslMode, args = args[0], args[1:]
+		}
+	}
+
+	query := strings.Join(args, " ")
+	if query == "-" {
+		if query, err = readStdinQuery(); err != nil {
+			return err
+		}
+	}
+
+	return runQuery(cfg, settings, query)
+}
+
+// takesValue reports whether arg is a non-boolean flag without an inline
+// "=value", so the following argument is its value.
+func takesValue(arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	if strings.Contains(name, "=") {
+		return false
+	}
+	f := flag.Lookup(name)
+	if f == nil {
+		return false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
 
-	client, err := llm.NewAnthropicClient(cfg.AnthropicAPIKey, cfg.Model)
+// runQuery generates options for query and outputs the one the user picks.
+// An empty query prompts for one first.
+func runQuery(cfg *config.Config, settings ui.Settings, query string) error {
+	if *printPrompt {
+		return showPrompt(os.Stdout, cfg, query)
+	}
+	if output.Mode(*outputMode) == output.ModeFZF {
+		return printOptions(cfg, query)
+	}
+	if *first {
+		return runFirst(cfg, settings, query)
+	}
+	if !hasTerminal() {
+		return runUnattended(cfg, settings, query)
+	}
+	detectPipedStdout(&settings)
+
+	generator, err := newGenerator(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create LLM client: %w", err)
+		return err
 	}
+	limitRequestSize(cfg, generator)
 
-	// Safety evaluation uses the raw Anthropic client (different API surface)
-	anthropicClient := anthropic.NewClient(
-		option.WithAPIKey(cfg.AnthropicAPIKey),
-	)
+	past := loadHistory(cfg)
 
-	generator := commands.NewGenerator(client, &anthropicClient, cfg.Model)
+	settings.OutputMode = string(selectedOutputMode())
+	settings.Audit = auditor(cfg)
+	settings.Deliver = deliverer(settings)
+	settings.LocalMatch = localMatcher(cfg, past)
 
 	var initialModel tea.Model
-	if args := flag.Args(); len(args) > 0 {
-		query := strings.Join(args, " ")
-		initialModel = ui.NewLoadingModel(generator, query)
+	if query != "" {
+		initialModel = ui.NewInputModel(generator, past, settings).Submit(query)
 	} else {
-		initialModel = ui.NewInputModel(generator)
+		initialModel = ui.NewInputModel(generator, past, settings)
+	}
+
+	finalModel, err := runUI(initialModel, settings)
+	if err != nil {
+		return err
 	}
 
-	// In shell-function mode, use /dev/tty so stdout stays clean for output
-	var p *tea.Program
-	if *outputMode == "shell-function" {
-		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if loadingModel, ok := finalModel.(ui.LoadingModel); ok {
+		err := loadingModel.Err()
+		// The loading view has already explained a refusal.
+		var refusal *llm.Refusal
+		if errors.As(err, &refusal) {
+			return nil
+		}
 		if err != nil {
-			return fmt.Errorf("failed to open /dev/tty: %w", err)
+			return fmt.Errorf("failed to generate options: %w", err)
 		}
-		defer func() { _ = tty.Close() }()
+	}
+
+	selectorModel, ok := finalModel.(ui.SelectorModel)
+	if !ok {
+		return nil
+	}
 
-		output := termenv.NewOutput(tty)
-		lipgloss.SetColorProfile(output.ColorProfile())
+	if err := selectorModel.Err(); err != nil {
+		return err
+	}
+	// A chosen command was released before it was delivered; quitting
+	// without one is recorded here.
+	if selectorModel.Selected() == nil {
+		if err := release(cfg, selectorModel.Query(), selectorModel.Options(), nil, nil); err != nil {
+			return err
+		}
+	}
+	recordHistory(past, selectorModel.Query(), selectorModel.Options(), selectorModel.Selected(), generator)
+	mode := selectedOutputMode()
+	if selectorModel.OutputMode() != "" {
+		mode = output.Mode(selectorModel.OutputMode())
+	}
+	return emitAs(selectorModel.Query(), selectorModel.Selected(), settings, mode, selectorModel.Delivered())
+}
 
-		p = tea.NewProgram(initialModel, tea.WithInput(tty), tea.WithOutput(tty))
-	} else {
-		p = tea.NewProgram(initialModel)
+// limitRequestSize makes generator ask before sending a request over
+// confirm_tokens. The selector asks; --first, --output=fzf and batch runs
+// can't, so they fail with the estimate instead. Offline and replayed
+// answers cost nothing, so they never ask.
+func limitRequestSize(cfg *config.Config, generator *commands.Generator) {
+	if !*offline && *replayPath == "" {
+		generator.SetConfirmLimit(cfg.ConfirmTokens, cfg.Model)
 	}
+}
 
-	finalModel, err := p.Run()
+// newGenerator sends queries through a running daemon when one is
+// listening, and otherwise calls the API directly.
+func newGenerator(cfg *config.Config) (*commands.Generator, error) {
+	generator, err := connectGenerator(cfg)
 	if err != nil {
-		return fmt.Errorf("error running UI: %w", err)
+		return nil, err
 	}
 
-	if loadingModel, ok := finalModel.(ui.LoadingModel); ok {
-		if err := loadingModel.Err(); err != nil {
-			return fmt.Errorf("failed to generate options: %w", err)
+	attachments, err := readContext()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configureGenerator(cfg, generator, attachments); err != nil {
+		return nil, err
+	}
+
+	return generator, nil
+}
+
+// configureGenerator applies the tool and command policies, DSL mode and attached
+// context that every generator in a run shares.
+func configureGenerator(cfg *config.Config, generator *commands.Generator, attachments []commands.Attachment) error {
+	generator.SetToolPolicy(commands.ToolPolicy{
+		Preferred: cfg.PreferredTools,
+		Avoid:     cfg.AvoidTools,
+	})
+
+	dsl, err := selectedDSL()
+	if err != nil {
+		return err
+	}
+	generator.SetDSL(dsl)
+
+	sh, err := selectedShell()
+	if err != nil {
+		return err
+	}
+	generator.SetShell(sh)
+
+	constraints, err := selectedConstraints(cfg)
+	if err != nil {
+		return err
+	}
+	generator.SetConstraints(constraints)
+
+	if cfg.ContextDetails == "on" {
+		packs, err := selectedContextPacks(cfg)
+		if err != nil {
+			return err
+		}
+		for _, pack := range packs {
+			generator.AddContext(pack.Name+" setup", pack.Environment(context.Background()))
 		}
 	}
 
-	selectorModel, ok := finalModel.(ui.SelectorModel)
+	generator.SetPolicy(activePolicy)
+	generator.SetKeepSecrets(cfg.RedactSecrets == "off")
+	generator.SetTokenLimit(newTokenizer(cfg), promptTokenLimit(cfg))
+	generator.SetSafetyTimeout(time.Duration(cfg.SafetyTimeout) * time.Second)
+
+	for _, a := range attachments {
+		generator.AddContext(a.Source, a.Text)
+	}
+
+	return nil
+}
+
+// selectedConstraints combines the constraints in the config with those
+// set by --no-sudo, --no-network and --no-install.
+func selectedConstraints(cfg *config.Config) (commands.Constraints, error) {
+	constraints, err := commands.ParseConstraints(cfg.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	if *noSudo {
+		constraints = constraints.With(commands.ConstraintNoSudo)
+	}
+	if *noNetwork {
+		constraints = constraints.With(commands.ConstraintNoNetwork)
+	}
+	if *noInstall {
+		constraints = constraints.With(commands.ConstraintNoInstall)
+	}
+	return constraints, nil
+}
+
+// selectedContextPacks returns the context packs in the config and those
+// chosen with --context, each once.
+func selectedContextPacks(cfg *config.Config) ([]commands.ContextPack, error) {
+	names := slices.Clone(cfg.ContextPacks)
+	if *contextPacks != "" {
+		names = append(names, strings.Split(*contextPacks, ",")...)
+	}
+
+	var packs []commands.ContextPack
+	for _, name := range names {
+		pack, ok := commands.LookupContextPack(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown context %q (want %s)", strings.TrimSpace(name), strings.Join(commands.ContextPackNames(), ", "))
+		}
+		if !slices.ContainsFunc(packs, func(p commands.ContextPack) bool { return p.Name == pack.Name }) {
+			packs = append(packs, pack)
+		}
+	}
+	return packs, nil
+}
+
+// newTokenizer counts with the provider's API when it can, and locally when
+// the query won't reach it.
+func newTokenizer(cfg *config.Config) llm.Tokenizer {
+	if *offline || *replayPath != "" {
+		return llm.HeuristicTokenizer{}
+	}
+	return llm.NewTokenizer(cfg.Provider, apiKey(cfg), cfg.Model)
+}
+
+// readContext reads the clipboard or piped stdin when asked to with
+// --from-clipboard or --stdin-context. Stdin can only be read once, so
+// callers building several generators share the result.
+func readContext() ([]commands.Attachment, error) {
+	var attachments []commands.Attachment
+
+	if *fromClipboard {
+		text, err := output.ReadClipboard()
+		if err != nil {
+			return nil, fmt.Errorf("--from-clipboard: %w", err)
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("--from-clipboard: clipboard is empty")
+		}
+		attachments = append(attachments, commands.Attachment{Source: "clipboard", Text: text})
+	}
+
+	if *stdinContext {
+		text, err := readStdin()
+		if err != nil {
+			return nil, fmt.Errorf("--stdin-context: %w", err)
+		}
+		attachments = append(attachments, commands.Attachment{Source: "standard input", Text: text})
+	}
+
+	return attachments, nil
+}
+
+// selectedShell returns the shell commands are generated for: the one
+// chosen with --shell, or else the user's $SHELL when it is a known one
+// and --target doesn't point elsewhere.
+func selectedShell() (commands.Shell, error) {
+	if *targetShell != "" {
+		return commands.ParseShell(*targetShell)
+	}
+	if *targetMachine != "" {
+		return "", nil
+	}
+	sh, _ := commands.ParseShell(filepath.Base(os.Getenv("SHELL")))
+	return sh, nil
+}
+
+// selectedDSL returns the DSL mode chosen with --lang, or nil.
+func selectedDSL() (*commands.DSL, error) {
+	if *dslMode == "" {
+		return nil, nil
+	}
+
+	dsl, ok := commands.LookupDSL(*dslMode)
 	if !ok {
+		return nil, fmt.Errorf("unknown --lang %q (want %s)", *dslMode, strings.Join(commands.DSLNames(), ", "))
+	}
+	return &dsl, nil
+}
+
+// connectGenerator picks a recorded session with --replay, or else the
+// daemon or a direct backend, recording responses with --record. The
+// daemon renders prompts from its own config, so per-query prompt settings
+// like --shell and --lang go direct.
+func connectGenerator(cfg *config.Config) (*commands.Generator, error) {
+	if *replayPath != "" {
+		if *recordPath != "" {
+			return nil, fmt.Errorf("--record and --replay can't be used together")
+		}
+		player, err := replay.Open(*replayPath)
+		if err != nil {
+			return nil, err
+		}
+		return commands.NewGeneratorWithEvaluator(player, player), nil
+	}
+
+	if *offline {
+		return newOfflineGenerator(cfg)
+	}
+
+	client, evaluator, err := connectBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache, err := responseCache(cfg); err == nil {
+		caching := llm.NewCachingClient(client, cache, cacheScope(cfg))
+		caching.SetModel(cfg.Model)
+		client = caching
+	}
+
+	if *recordPath != "" {
+		recorder = replay.NewRecorder(client, evaluator, *recordPath)
+		client, evaluator = recorder, recorder.Evaluator()
+	}
+
+	return commands.NewGeneratorWithEvaluator(client, evaluator), nil
+}
+
+// connectBackend returns the daemon when one is listening and usable for
+// this query, and otherwise a direct backend. The daemon renders prompts
+// from its own config, so it isn't used when flags change the prompt.
+func connectBackend(cfg *config.Config) (llm.Client, commands.RiskEvaluator, error) {
+	constrained := *noSudo || *noNetwork || *noInstall
+	if !*noDaemon && *targetShell == "" && *targetMachine == "" && *dslMode == "" && *contextPacks == "" && !*script && !constrained {
+		if path, err := daemon.SocketPath(); err == nil {
+			if client, err := daemon.Dial(path); err == nil {
+				return client, client, nil
+			}
+		}
+	}
+
+	return newBackend(cfg)
+}
+
+// newBackend creates the generation client for the configured provider and
+// the safety evaluator. Safety evaluation always uses Anthropic, so plugin
+// providers without an API key run without it.
+func newBackend(cfg *config.Config) (llm.Client, commands.RiskEvaluator, error) {
+	prompt, err := loadPrompt(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := newGenerationClient(cfg, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	return client, newEvaluator(cfg), nil
+}
+
+// newEvaluator creates the safety evaluator, or nil without an Anthropic
+// API key or, for Vertex AI, Google credentials.
+func newEvaluator(cfg *config.Config) commands.RiskEvaluator {
+	var anthropicClient anthropic.Client
+	model := cfg.Model
+
+	switch {
+	case cfg.Provider == "vertex":
+		client, err := llm.NewVertexAnthropicClient(vertexConfig(cfg))
+		if err != nil {
+			return nil
+		}
+		anthropicClient, model = client, llm.VertexModel(cfg.Model)
+	case apiKey(cfg) != "":
+		// Safety evaluation uses the raw Anthropic client (different API surface)
+		opts := []option.RequestOption{option.WithAPIKey(apiKey(cfg))}
+		if keyRing != nil {
+			opts = append(opts, keyRing.Option())
+		}
+		anthropicClient = anthropic.NewClient(opts...)
+	default:
 		return nil
 	}
 
-	selected := selectorModel.Selected()
-	if selected == nil {
-		if *outputMode != "shell-function" {
-			fmt.Println("No option selected")
+	evaluator := safety.NewEvaluator(&anthropicClient, model)
+	evaluator.SetLanguage(i18n.Name(cfg.Language))
+	if packs, err := selectedContextPacks(cfg); err == nil {
+		for _, pack := range packs {
+			evaluator.AddRiskNotes(pack.RiskNotes)
+		}
+	}
+	evaluator.SetStructuredOutputs(llm.StructuredOutputMode(cfg.StructuredOutputs))
+
+	return evaluator
+}
+
+// newGenerationClient creates the client for the configured provider and
+// model, chained with any fallback_models and queried alongside any
+// parallel_models.
+func newGenerationClient(cfg *config.Config, prompt *llm.Prompt) (llm.Client, error) {
+	client, err := newProviderClient(cfg, cfg.Provider, cfg.Model, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.FallbackModels) > 0 {
+		chain := []llm.ModelClient{{Name: cfg.Model, Client: client}}
+		for _, entry := range cfg.FallbackModels {
+			mc, err := newModelClient(cfg, entry, prompt)
+			if err != nil {
+				return nil, fmt.Errorf("fallback_models: %w", err)
+			}
+			chain = append(chain, mc)
+		}
+		client = llm.NewFallbackClient(chain...)
+	}
+
+	if len(cfg.ParallelModels) > 0 {
+		clients := []llm.ModelClient{{Name: cfg.Model, Client: client}}
+		for _, entry := range cfg.ParallelModels {
+			mc, err := newModelClient(cfg, entry, prompt)
+			if err != nil {
+				return nil, fmt.Errorf("parallel_models: %w", err)
+			}
+			clients = append(clients, mc)
 		}
+		client = llm.NewParallelClient(clients...)
+	}
+
+	return client, nil
+}
+
+// newModelClient creates the client for a fallback_models or
+// parallel_models entry: an Anthropic model (on Vertex AI with provider
+// "vertex"), or "plugin:name" for a plugin.
+func newModelClient(cfg *config.Config, entry string, prompt *llm.Prompt) (llm.ModelClient, error) {
+	provider, model := "anthropic", entry
+	if cfg.Provider == "vertex" {
+		provider = "vertex"
+	}
+	if strings.HasPrefix(entry, llm.PluginPrefix) {
+		provider, model = entry, cfg.Model
+	}
+
+	client, err := newProviderClient(cfg, provider, model, prompt)
+	if err != nil {
+		return llm.ModelClient{}, fmt.Errorf("%q: %w", entry, err)
+	}
+	return llm.ModelClient{Name: entry, Client: client}, nil
+}
+
+// newProviderClient creates one generation client with the configured
+// Anthropic request settings.
+func newProviderClient(cfg *config.Config, provider, model string, prompt *llm.Prompt) (llm.Client, error) {
+	var client llm.Client
+	var err error
+	if provider == "vertex" {
+		client, err = llm.NewVertexClient(vertexConfig(cfg), model, prompt)
+	} else {
+		client, err = llm.NewProviderClient(provider, apiKey(cfg), model, prompt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if anthropicClient, ok := client.(*llm.AnthropicClient); ok {
+		if keyRing != nil {
+			anthropicClient.SetKeyRing(keyRing)
+		}
+		anthropicClient.SetThinking(llm.ThinkingMode(cfg.Thinking), cfg.ThinkingBudget)
+		anthropicClient.SetStructuredOutputs(llm.StructuredOutputMode(cfg.StructuredOutputs))
+		return llm.NewRateLimitClient(client, time.Duration(cfg.RateLimitWait)*time.Second), nil
+	}
+
+	return client, nil
+}
+
+// apiKey returns the first configured Anthropic API key, or "".
+func apiKey(cfg *config.Config) string {
+	if keys := cfg.APIKeys(); len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// newKeyRing rotates requests across the API keys when several are
+// configured.
+func newKeyRing(cfg *config.Config) *llm.KeyRing {
+	keys := cfg.APIKeys()
+	if len(keys) < 2 {
 		return nil
 	}
+	return llm.NewKeyRing(keys, llm.KeyRotation(cfg.KeyRotation))
+}
 
-	handler := output.NewHandler(output.Mode(*outputMode))
-	if err := handler.Output(selected); err != nil {
-		return fmt.Errorf("failed to output command: %w", err)
+// vertexConfig returns the Vertex AI project and region from the config.
+func vertexConfig(cfg *config.Config) llm.VertexConfig {
+	return llm.VertexConfig{Project: cfg.VertexProject, Region: cfg.VertexRegion}
+}
+
+// loadPrompt reads the configured prompt template, or the default, and
+// adds the configured examples and tool preferences to its context.
+func loadPrompt(cfg *config.Config) (*llm.Prompt, error) {
+	prompt := llm.DefaultPrompt()
+	if cfg.PromptTemplate != "" {
+		path, err := config.ExpandPath(cfg.PromptTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand prompt_template path: %w", err)
+		}
+		if prompt, err = llm.LoadPrompt(path); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	for _, ex := range cfg.Examples {
+		prompt.Context.Examples = append(prompt.Context.Examples, llm.Example{
+			Query:   ex.Query,
+			Command: ex.Command,
+		})
+	}
+	prompt.Context.PreferredTools = cfg.PreferredTools
+	prompt.Context.AvoidTools = cfg.AvoidTools
+	if constraints, err := selectedConstraints(cfg); err == nil {
+		prompt.Context.Constraints = constraints.Instructions()
+	}
+	packs, err := selectedContextPacks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, pack := range packs {
+		prompt.Context.Packs = append(prompt.Context.Packs, pack.Instructions)
+	}
+	prompt.Context.Language = i18n.Name(cfg.Language)
+
+	if target := strings.TrimSpace(*targetMachine); target != "" {
+		prompt.Context.Target = target
+		prompt.Context.OS, prompt.Context.Shell, prompt.Context.Tools = "", "", nil
+	}
+
+	if *targetShell != "" {
+		sh, err := selectedShell()
+		if err != nil {
+			return nil, err
+		}
+		prompt.Context.TargetShell = sh.DisplayName()
+	}
+
+	dsl, err := selectedDSL()
+	if err != nil {
+		return nil, err
+	}
+	if dsl != nil {
+		prompt.Context.Focus = dsl.Instructions
+	}
+	prompt.Context.Script = *script
+
+	return prompt, nil
+}
+
+// subcommandFunc runs a subcommand with the remaining arguments.
+type subcommandFunc func(cfg *config.Config, settings ui.Settings, args []string) error
+
+// subcommand pairs a subcommand with a check on the arguments after its
+// name. Subcommand names are plausible first words of a query, so the
+// check keeps "1lm snippet of code" or "1lm fix ssh key permissions" a
+// query.
+type subcommand struct {
+	run     subcommandFunc
+	matches func(args []string) bool
+}
+
+var subcommands = map[string]subcommand{
+	"snippet": {run: runSnippet, matches: verbIn("save", "list", "use", "rm")},
+	"daemon":  {run: runDaemon, matches: noArgs},
+	"fix":     {run: runFix, matches: fixArgs},
+	"batch":   {run: runBatch, matches: fileArg},
+	"eval":    {run: runEval, matches: fileArg},
+	"compare": {run: runCompare, matches: compareArgs},
+	"check":   {run: runCheck, matches: checkArgs},
+	"config":  {run: runConfig, matches: verbIn("validate")},
+	"history": {run: runHistory, matches: noArgs},
+	"sync":    {run: runSync, matches: noArgs},
+	"stats":   {run: runStats, matches: noArgs},
+	"build":   {run: runBuild, matches: noArgs},
+	"context": {run: runContext, matches: noArgs},
+	"cache":   {run: runCache, matches: verbIn("stats", "clear", "gc")},
+}
+
+// verbIn matches when the first argument is one of verbs.
+func verbIn(verbs ...string) func([]string) bool {
+	return func(args []string) bool {
+		return len(args) > 0 && slices.Contains(verbs, args[0])
+	}
+}
+
+// noArgs matches a bare subcommand.
+func noArgs(args []string) bool {
+	return len(args) == 0
+}
+
+// lookupSubcommand checks whether args invoke a subcommand. Queries that
+// happen to start with a subcommand name can be quoted to avoid this.
+func lookupSubcommand(args []string) (subcommandFunc, []string, bool) {
+	if len(args) == 0 {
+		return nil, nil, false
+	}
+
+	sub, ok := subcommands[args[0]]
+	if !ok || !sub.matches(args[1:]) {
+		return nil, nil, false
+	}
+
+	return sub.run, args[1:], true
 }
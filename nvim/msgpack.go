@@ -0,0 +1,188 @@
+package nvim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// This is the subset of MessagePack that Neovim's RPC needs: requests are
+// arrays of integers and strings, and responses are decoded into nil,
+// bool, int64, float64, string, []any and map[any]any. Extension values,
+// such as buffer handles, decode to their raw bytes.
+
+// encode appends v's MessagePack encoding to b. v must be an int, a
+// string, or a []any of those.
+func encode(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case int:
+		if v < 0 || v > math.MaxUint32 {
+			return nil, fmt.Errorf("msgpack: %d out of range", v)
+		}
+		if v < 0x80 {
+			return append(b, byte(v)), nil
+		}
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v)), nil
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+		return append(b, v...), nil
+	case []any:
+		n := len(v)
+		if n < 16 {
+			b = append(b, 0x90|byte(n))
+		} else {
+			b = binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+		}
+		for _, item := range v {
+			var err error
+			if b, err = encode(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("msgpack: can't encode %T", v)
+}
+
+// errUnsupported is returned for MessagePack types Neovim never sends.
+var errUnsupported = errors.New("msgpack: unsupported type")
+
+// decode reads one MessagePack value from r.
+func decode(r *bufio.Reader) (any, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return decodeMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return decodeArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		return readString(r, int(c&0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readUint(r, 1<<(c-0xcc))
+		return int64(n), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := readUint(r, size)
+		// Sign-extend from the value's size.
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case 0xca:
+		n, err := readUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xd9, 0xda, 0xdb, 0xc4, 0xc5, 0xc6:
+		size := map[byte]int{0xd9: 1, 0xda: 2, 0xdb: 4, 0xc4: 1, 0xc5: 2, 0xc6: 4}[c]
+		n, err := readUint(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, int(n))
+	case 0xdc, 0xdd:
+		n, err := readUint(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(r, int(n))
+	case 0xde, 0xdf:
+		n, err := readUint(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(r, int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		// fixext: a type byte, then 1 to 16 bytes.
+		return readString(r, 1+1<<(c-0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readUint(r, 1<<(c-0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, 1+int(n))
+	}
+	return nil, errUnsupported
+}
+
+// readUint reads a big-endian unsigned integer of size bytes.
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, b := range buf {
+		n = n<<8 | uint64(b)
+	}
+	return n, nil
+}
+
+// readString reads n raw bytes.
+func readString(r *bufio.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+func decodeArray(r *bufio.Reader, n int) ([]any, error) {
+	items := make([]any, 0, n)
+	for range n {
+		item, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func decodeMap(r *bufio.Reader, n int) (map[any]any, error) {
+	m := make(map[any]any, n)
+	for range n {
+		key, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		// Keys that can't be map keys, such as arrays, are dropped.
+		switch key.(type) {
+		case []any, map[any]any:
+			continue
+		}
+		m[key] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,158 @@
+package nvim
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []any{
+		int64(5),
+		int64(300),
+		"hello",
+		strings.Repeat("x", 40),
+		strings.Repeat("y", 70000),
+		[]any{int64(0), int64(1), "nvim_exec_lua", []any{"print(...)", []any{"ls"}}},
+		[]any{nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+	}
+
+	for _, want := range tests {
+		in := want
+		if n, ok := want.(int64); ok {
+			in = int(n)
+		}
+		b, err := encode(nil, toEncodable(in))
+		if err != nil {
+			t.Fatalf("encode(%v) error = %v", want, err)
+		}
+		got, err := decode(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			t.Fatalf("decode() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("decode(encode(%.40v)) = %.40v", want, got)
+		}
+	}
+}
+
+// toEncodable turns int64s back into the ints encode takes.
+func toEncodable(v any) any {
+	switch v := v.(type) {
+	case int64:
+		return int(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = toEncodable(item)
+		}
+		return out
+	}
+	return v
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want any
+	}{
+		{name: "negative fixint", data: []byte{0xff}, want: int64(-1)},
+		{name: "int16", data: []byte{0xd1, 0xff, 0x38}, want: int64(-200)},
+		{name: "uint16", data: []byte{0xcd, 0x01, 0x00}, want: int64(256)},
+		{name: "bool", data: []byte{0xc3}, want: true},
+		{name: "float64", data: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, want: 1.5},
+		{name: "fixmap", data: []byte{0x81, 0xa1, 'k', 0x01}, want: map[any]any{"k": int64(1)}},
+		{name: "buffer handle", data: []byte{0xd4, 0x00, 0x01}, want: "\x00\x01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decode(bufio.NewReader(bytes.NewReader(tt.data)))
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode() = %#v, %v, want %#v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// fakeNvim serves one connection on a Unix socket, replying to each
+// request with reply, after a notification Insert must skip. It returns
+// the socket path and a channel with the requests received.
+func fakeNvim(t *testing.T, reply func(msgid int64) []any) (string, <-chan []any) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs Unix sockets")
+	}
+
+	addr := filepath.Join(t.TempDir(), "nvim.sock")
+	ln, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	requests := make(chan []any, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		msg, err := decode(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		req := msg.([]any)
+		requests <- req
+
+		notification, _ := encode(nil, []any{2, "nvim_buf_lines_event", []any{}})
+		response, _ := encode(nil, reply(req[1].(int64)))
+		_, _ = conn.Write(append(notification, response...))
+	}()
+	return addr, requests
+}
+
+func TestInsert(t *testing.T) {
+	addr, requests := fakeNvim(t, func(msgid int64) []any {
+		return []any{1, int(msgid), nil, nil}
+	})
+
+	if err := Insert(context.Background(), addr, "ls -la"); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	req := <-requests
+	if req[2] != "nvim_exec_lua" {
+		t.Errorf("method = %v, want nvim_exec_lua", req[2])
+	}
+	params := req[3].([]any)
+	if want := []any{"ls -la", int64(os.Getpid())}; !reflect.DeepEqual(params[1], want) {
+		t.Errorf("lua args = %v, want %v", params[1], want)
+	}
+}
+
+func TestInsertError(t *testing.T) {
+	addr, _ := fakeNvim(t, func(msgid int64) []any {
+		return []any{1, int(msgid), []any{1, "Buffer is not 'modifiable'"}, nil}
+	})
+
+	err := Insert(context.Background(), addr, "ls -la")
+	if err == nil || !strings.Contains(err.Error(), "not 'modifiable'") {
+		t.Errorf("Insert() error = %v, want Neovim's error", err)
+	}
+}
+
+func TestInsertNoNeovim(t *testing.T) {
+	if err := Insert(context.Background(), filepath.Join(t.TempDir(), "missing.sock"), "ls"); err == nil {
+		t.Error("Insert() error = nil, want a connection error")
+	}
+}
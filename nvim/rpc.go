@@ -0,0 +1,124 @@
+// Package nvim talks to a running Neovim over its msgpack-RPC socket, so
+// commands can be put where the user is editing.
+package nvim
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// dialTimeout bounds connecting to Neovim and each call.
+const dialTimeout = 5 * time.Second
+
+// Client is a connection to Neovim's RPC API.
+type Client struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	msgid int
+}
+
+// Public: Connects to Neovim at addr, a Unix socket path or a TCP
+// "host:port", as given in $NVIM or to --listen.
+func Dial(ctx context.Context, addr string) (*Client, error) {
+	network := "unix"
+	if !strings.ContainsAny(addr, `/\`) && strings.Contains(addr, ":") {
+		network = "tcp"
+	}
+
+	var d net.Dialer
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Neovim: %w", err)
+	}
+	return &Client{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// Public: Closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Public: Calls an API method, such as "nvim_exec_lua", and waits for its
+// result. Notifications Neovim sends in the meantime are skipped.
+func (c *Client) Call(method string, args ...any) (any, error) {
+	c.msgid++
+	req, err := encode(nil, []any{0, c.msgid, method, args})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = c.conn.SetDeadline(time.Now().Add(dialTimeout))
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	for {
+		msg, err := decode(c.r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the reply to %s: %w", method, err)
+		}
+		// A response is [1, msgid, error, result].
+		resp, ok := msg.([]any)
+		if !ok || len(resp) != 4 || resp[0] != int64(1) || resp[1] != int64(c.msgid) {
+			continue
+		}
+		if resp[2] != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, errorMessage(resp[2]))
+		}
+		return resp[3], nil
+	}
+}
+
+// errorMessage returns the message of an RPC error, which Neovim sends as
+// [type, message].
+func errorMessage(v any) string {
+	if e, ok := v.([]any); ok && len(e) == 2 {
+		if msg, ok := e[1].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// insertLua types text into the current buffer's terminal, or puts it
+// after the cursor in a normal buffer. When the terminal's job is 1lm
+// itself, as when a plugin runs it with :terminal, the window it was
+// opened from gets the text instead.
+const insertLua = `
+local text, pid = ...
+local buf = vim.api.nvim_get_current_buf()
+if vim.bo[buf].buftype == 'terminal' and vim.fn.jobpid(vim.bo[buf].channel) == pid then
+  local win = vim.fn.win_getid(vim.fn.winnr('#'))
+  if win ~= 0 then
+    vim.api.nvim_set_current_win(win)
+    buf = vim.api.nvim_get_current_buf()
+  end
+end
+if vim.bo[buf].buftype == 'terminal' then
+  vim.api.nvim_chan_send(vim.bo[buf].channel, text)
+else
+  vim.api.nvim_put(vim.split(text, '\n', { plain = true }), 'c', true, true)
+end
+`
+
+// Public: Inserts text into the Neovim at addr: typed at the prompt when
+// the current window is a terminal, so it can be edited before running,
+// and otherwise put after the cursor in the current buffer. When 1lm runs
+// as a terminal's job, the previous window is used.
+func Insert(ctx context.Context, addr, text string) error {
+	c, err := Dial(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	_, err = c.Call("nvim_exec_lua", insertLua, []any{text, os.Getpid()})
+	return err
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+	"github.com/pixielabs/1lm/snippets"
+	"github.com/pixielabs/1lm/templates"
+)
+
+// maxSnippetMatches caps the snippets offered for an offline query, and
+// maxTemplateMatches the built-in templates.
+const (
+	maxSnippetMatches  = 5
+	maxTemplateMatches = 3
+)
+
+// Cache limits when cache_ttl and cache_max_size aren't set.
+const (
+	defaultCacheTTL     = 30 // days
+	defaultCacheMaxSize = 50 // megabytes
+)
+
+// responseCache returns the cache every online response is saved to, for
+// answering the same queries with --offline, with the configured limits.
+func responseCache(cfg *config.Config) (*llm.ResponseCache, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	cache := llm.NewResponseCache(filepath.Join(dir, "responses"))
+
+	days, megabytes := cfg.CacheTTL, cfg.CacheMaxSize
+	if days == 0 {
+		days = defaultCacheTTL
+	}
+	if megabytes == 0 {
+		megabytes = defaultCacheMaxSize
+	}
+	cache.SetLimits(time.Duration(max(days, 0))*24*time.Hour, max(megabytes, 0)<<20)
+	return cache, nil
+}
+
+// cacheScope names what, besides the query, decides the response, so a
+// cached answer for one model, shell or set of constraints isn't reused
+// for another.
+func cacheScope(cfg *config.Config) string {
+	scope := fmt.Sprintf("%s:%s shell=%s lang=%s", cfg.Provider, cfg.Model, *targetShell, *dslMode)
+	if *targetMachine != "" {
+		scope += " target=" + *targetMachine
+	}
+	if constraints, err := selectedConstraints(cfg); err == nil && len(constraints) > 0 {
+		scope += " constraints=" + constraints.String()
+	}
+	if packs, err := selectedContextPacks(cfg); err == nil {
+		for _, pack := range packs {
+			scope += " context=" + pack.Name
+		}
+	}
+	return scope
+}
+
+// newOfflineGenerator answers from cached responses, the snippet library
+// and the built-in templates, and checks safety with local rules, so
+// nothing touches the network.
+func newOfflineGenerator(cfg *config.Config) (*commands.Generator, error) {
+	cache, err := responseCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lib, err := snippets.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &offlineClient{
+		cache:   llm.NewCacheOnlyClient(cache, cacheScope(cfg)),
+		library: lib,
+	}
+	return commands.NewGeneratorWithEvaluator(client, safety.NewHeuristicEvaluator()), nil
+}
+
+// offlineClient answers from the response cache, then saved snippets and
+// built-in templates.
+type offlineClient struct {
+	cache   llm.Client
+	library *snippets.Library
+}
+
+func (c *offlineClient) GenerateOptions(ctx context.Context, query string) ([]llm.CommandOption, error) {
+	options, err := c.cache.GenerateOptions(ctx, query)
+	if !errors.Is(err, llm.ErrCacheMiss) {
+		return options, err
+	}
+
+	matches := c.library.Search(query)
+	for _, s := range matches[:min(len(matches), maxSnippetMatches)] {
+		options = append(options, llm.CommandOption{
+			Title:       s.Title,
+			Command:     s.Command,
+			Description: s.Description,
+			Source:      "snippet " + s.Name,
+		})
+	}
+
+	found := templates.Search(query)
+	for _, t := range found[:min(len(found), maxTemplateMatches)] {
+		options = append(options, llm.CommandOption{
+			Title:       t.Title,
+			Command:     t.Command,
+			Description: t.Description,
+			Source:      "built-in template",
+		})
+	}
+
+	if len(options) == 0 {
+		return nil, errors.New("offline: no cached response, saved snippet or built-in template matches this query")
+	}
+	return options, nil
+}
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// jsonCheck is the machine-readable result of checking a command.
+type jsonCheck struct {
+	Command  string     `json:"command"`
+	Level    string     `json:"level"`
+	Message  string     `json:"message,omitempty"`
+	Fragment string     `json:"fragment,omitempty"`
+	Safer    *jsonSafer `json:"safer,omitempty"`
+	Blocked  string     `json:"blocked,omitempty"`
+}
+
+// jsonSafer mirrors safety.Alternative.
+type jsonSafer struct {
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+}
+
+// Public: Writes the result of a safety check on a command that wasn't
+// generated, for "1lm check". JSON mode writes one object with the risk
+// level ("none" when nothing was found); other modes write a short report.
+//
+// opt - The checked command, with its Risk and Blocked set
+//
+// Returns an error only if writing fails.
+func (h *Handler) WriteCheck(opt commands.Option) error {
+	level := safety.RiskNone
+	if opt.Risk != nil {
+		level = opt.Risk.Level
+	}
+
+	if h.mode == ModeJSON {
+		check := jsonCheck{
+			Command: opt.Command,
+			Level:   strings.ToLower(level.String()),
+			Blocked: opt.Blocked,
+		}
+		if opt.Risk != nil {
+			check.Message = opt.Risk.Message
+			check.Fragment = opt.Risk.Fragment
+			if opt.Risk.Safer != nil {
+				check.Safer = &jsonSafer{Command: opt.Risk.Safer.Command, Description: opt.Risk.Safer.Description}
+			}
+		}
+		return json.NewEncoder(h.writer()).Encode(check)
+	}
+
+	w := h.writer()
+	risk := i18n.T(h.language, "none detected")
+	if opt.Risk != nil && level != safety.RiskNone {
+		risk = fmt.Sprintf("%s - %s", i18n.T(h.language, level.String()), opt.Risk.Message)
+	}
+	fmt.Fprintln(w, i18n.Tf(h.language, "Risk: %s", risk))
+
+	if opt.Risk != nil {
+		if opt.Risk.Fragment != "" && opt.Risk.Fragment != opt.Command {
+			fmt.Fprintln(w, i18n.Tf(h.language, "Risky part: %s", opt.Risk.Fragment))
+		}
+		if safer := opt.Risk.Safer; safer != nil {
+			fmt.Fprintln(w, i18n.Tf(h.language, "Safer: %s", safer.Command))
+			if safer.Description != "" {
+				fmt.Fprintf(w, "  %s\n", safer.Description)
+			}
+		}
+	}
+	if opt.Blocked != "" {
+		fmt.Fprintln(w, h.status("⚠", i18n.Tf(h.language, "Blocked by policy: %s", opt.Blocked)))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestWriteCheck(t *testing.T) {
+	risky := commands.Option{
+		Command: "rm -rf build",
+		Risk: &safety.RiskInfo{
+			Level:    safety.RiskHigh,
+			Message:  "Deletes files",
+			Fragment: "-rf",
+			Safer:    &safety.Alternative{Command: "rm -rI build", Description: "Asks first"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		mode Mode
+		opt  commands.Option
+		want string
+	}{
+		{
+			name: "json",
+			mode: ModeJSON,
+			opt:  risky,
+			want: `{"command":"rm -rf build","level":"high","message":"Deletes files","fragment":"-rf","safer":{"command":"rm -rI build","description":"Asks first"}}` + "\n",
+		},
+		{
+			name: "json without risk",
+			mode: ModeJSON,
+			opt:  commands.Option{Command: "ls"},
+			want: `{"command":"ls","level":"none"}` + "\n",
+		},
+		{
+			name: "text",
+			mode: ModeStdout,
+			opt:  risky,
+			want: "Risk: High - Deletes files\nRisky part: -rf\nSafer: rm -rI build\n  Asks first\n",
+		},
+		{
+			name: "text without risk",
+			mode: ModeStdout,
+			opt:  commands.Option{Command: "ls"},
+			want: "Risk: none detected\n",
+		},
+		{
+			name: "blocked",
+			mode: ModeStdout,
+			opt:  commands.Option{Command: "cat /etc/shadow", Blocked: "touches /etc/shadow"},
+			want: "Risk: none detected\nBlocked by policy: touches /etc/shadow\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := NewHandlerWriter(&buf, tt.mode, DecorationPlain)
+			if err := handler.WriteCheck(tt.opt); err != nil {
+				t.Fatalf("WriteCheck() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteCheck() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+type clipboardCmd struct {
+	name string
+	args []string
+}
+
+// clipboardTools lists clipboard tools in order of preference by platform,
+// for when the clipboard library can't reach the clipboard itself.
+var clipboardTools = []clipboardCmd{
+	{name: "pbcopy"}, // macOS
+	{name: "xclip", args: []string{"-selection", "clipboard"}}, // Linux X11
+	{name: "xsel", args: []string{"--clipboard", "--input"}},   // Linux X11
+	{name: "wl-copy"}, // Wayland
+}
+
+// pasteTools lists clipboard readers in the same order as clipboardTools.
+var pasteTools = []clipboardCmd{
+	{name: "pbpaste"}, // macOS
+	{name: "xclip", args: []string{"-selection", "clipboard", "-o"}}, // Linux X11
+	{name: "xsel", args: []string{"--clipboard", "--output"}},        // Linux X11
+	{name: "wl-paste", args: []string{"--no-newline"}},               // Wayland
+}
+
+// primaryTools set the X11 primary selection, which a middle click pastes.
+// Wayland compositors offer it too.
+var primaryTools = []clipboardCmd{
+	{name: "xclip", args: []string{"-selection", "primary"}},
+	{name: "xsel", args: []string{"--primary", "--input"}},
+	{name: "wl-copy", args: []string{"--primary"}},
+}
+
+// ErrNoClipboard is returned when no clipboard tool could be run.
+var ErrNoClipboard = errors.New("clipboard not available (install xclip or wl-clipboard)")
+
+// Public: Writes text to the system clipboard, natively where the
+// clipboard library supports it (Windows), and otherwise with the first
+// clipboard tool that works.
+func WriteClipboard(text string) error {
+	if clipboard.WriteAll(text) == nil {
+		return nil
+	}
+	return runTools(clipboardTools, text)
+}
+
+// Public: Writes text to the primary selection, so a middle click pastes
+// it. Returns ErrNoClipboard where there is none, as on macOS.
+func WritePrimary(text string) error {
+	tools := primaryTools
+	// Under Wayland, xclip would only reach X11 programs.
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = []clipboardCmd{primaryTools[2], primaryTools[0], primaryTools[1]}
+	}
+	return runTools(tools, text)
+}
+
+// Public: Reads the system clipboard's text, natively where the clipboard
+// library supports it, and otherwise with the first clipboard tool that
+// works.
+func ReadClipboard() (string, error) {
+	if text, err := clipboard.ReadAll(); err == nil {
+		return text, nil
+	}
+
+	for _, tool := range pasteTools {
+		out, err := exec.Command(tool.name, tool.args...).Output()
+		if err == nil {
+			return string(out), nil
+		}
+	}
+
+	return "", ErrNoClipboard
+}
+
+// runTools gives text to the first of tools that runs successfully.
+func runTools(tools []clipboardCmd, text string) error {
+	for _, tool := range tools {
+		c := exec.Command(tool.name, tool.args...)
+		c.Stdin = strings.NewReader(text)
+		if c.Run() == nil {
+			return nil
+		}
+	}
+	return ErrNoClipboard
+}
@@ -0,0 +1,67 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+// fakeClipboard installs an xclip on the PATH that logs the selection it
+// is asked to set and the text given, and returns the log's path.
+func fakeClipboard(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	script := "#!/bin/sh\nfor arg; do [ \"$prev\" = -selection ] && sel=$arg; prev=$arg; done\n" +
+		"IFS= read -r text\necho \"$sel: $text\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("WAYLAND_DISPLAY", "")
+	return log
+}
+
+func TestWritePrimary(t *testing.T) {
+	log := fakeClipboard(t)
+
+	if err := WritePrimary("ls -la"); err != nil {
+		t.Fatalf("WritePrimary() error = %v", err)
+	}
+	got, _ := os.ReadFile(log)
+	if string(got) != "primary: ls -la\n" {
+		t.Errorf("xclip got %q, want the command as the primary selection", got)
+	}
+}
+
+func TestWritePrimaryUnavailable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if err := WritePrimary("ls -la"); err != ErrNoClipboard {
+		t.Errorf("WritePrimary() error = %v, want ErrNoClipboard", err)
+	}
+}
+
+func TestClipboardOutputSetsPrimary(t *testing.T) {
+	log := fakeClipboard(t)
+
+	handler := NewHandlerWriter(&strings.Builder{}, ModeClipboard, DecorationPlain)
+	handler.SetPrimarySelection(true)
+	if err := handler.Output(&commands.Option{Command: "ls -la"}); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	got, _ := os.ReadFile(log)
+	for _, want := range []string{"clipboard: ls -la", "primary: ls -la"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("xclip got %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package output
+
+import "github.com/pixielabs/1lm/commands"
+
+// Public: Does the part of Output that can fail for reasons the user can
+// fix, such as a missing clipboard tool or a file that already exists,
+// without printing anything. Interactive callers use it before the UI
+// exits so a failure can be shown there with a chance to retry, then call
+// SetDelivered so Output only prints its confirmation.
+//
+// Modes that print to stdout have nothing to deliver, and neither does a
+// clipboard only reachable through the terminal's escape sequences, which
+// can't be written while the UI owns the screen.
+//
+// cmd - The selected command
+//
+// Returns whether the command was delivered, or the error if it couldn't
+// be.
+func (h *Handler) Deliver(cmd *commands.Option) (bool, error) {
+	switch h.mode {
+	case ModeClipboard:
+		if err := WriteClipboard(cmd.Command); err != nil {
+			if h.terminal == TerminalITerm2 {
+				return false, nil
+			}
+			return false, err
+		}
+		if h.primary {
+			_ = WritePrimary(cmd.Command)
+		}
+	case ModeNvim:
+		if err := sendToNvim(cmd.Command); err != nil {
+			return false, err
+		}
+	case ModeFile:
+		if err := h.writeFile(cmd.Command); err != nil {
+			return false, err
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Public: Records that Deliver already did the output step, so Output
+// prints the confirmation without copying or writing again.
+func (h *Handler) SetDelivered(delivered bool) {
+	h.delivered = delivered
+}
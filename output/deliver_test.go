@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestDeliverFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	var buf bytes.Buffer
+	h := NewHandlerWriter(&buf, ModeFile, DecorationPlain)
+	h.SetFile(path, commands.ShellBash)
+	cmd := &commands.Option{Command: "echo hi"}
+
+	delivered, err := h.Deliver(cmd)
+	if err != nil || !delivered {
+		t.Fatalf("Deliver() = %v, %v, want delivered", delivered, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Deliver() printed %q, want nothing", buf.String())
+	}
+	if got, _ := os.ReadFile(path); string(got) != "#!/usr/bin/env bash\necho hi\n" {
+		t.Errorf("file = %q", got)
+	}
+
+	// Output only confirms, rather than failing on the file it wrote.
+	h.SetDelivered(true)
+	if err := h.Output(cmd); err != nil {
+		t.Fatalf("Output() after Deliver() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), path) {
+		t.Errorf("Output() printed %q, want the file's path", buf.String())
+	}
+}
+
+func TestDeliverFileExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(path, []byte("keep me"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerWriter(&bytes.Buffer{}, ModeFile, DecorationPlain)
+	h.SetFile(path, commands.ShellBash)
+	if delivered, err := h.Deliver(&commands.Option{Command: "echo hi"}); delivered || err == nil {
+		t.Errorf("Deliver() = %v, %v, want already exists", delivered, err)
+	}
+}
+
+func TestDeliverClipboard(t *testing.T) {
+	log := fakeClipboard(t)
+
+	var buf bytes.Buffer
+	h := NewHandlerWriter(&buf, ModeClipboard, DecorationPlain)
+	if delivered, err := h.Deliver(&commands.Option{Command: "ls -la"}); err != nil || !delivered {
+		t.Fatalf("Deliver() = %v, %v, want delivered", delivered, err)
+	}
+	if got, _ := os.ReadFile(log); string(got) != "clipboard: ls -la\n" {
+		t.Errorf("xclip got %q", got)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Deliver() printed %q, want nothing", buf.String())
+	}
+}
+
+func TestDeliverClipboardUnavailable(t *testing.T) {
+	fakeClipboard(t)
+	t.Setenv("PATH", t.TempDir())
+
+	h := NewHandlerWriter(&bytes.Buffer{}, ModeClipboard, DecorationPlain)
+	if delivered, err := h.Deliver(&commands.Option{Command: "ls -la"}); delivered || err == nil {
+		t.Errorf("Deliver() = %v, %v, want an error", delivered, err)
+	}
+
+	// iTerm2 can still copy once the UI has exited.
+	h.SetTerminal(TerminalITerm2)
+	if delivered, err := h.Deliver(&commands.Option{Command: "ls -la"}); delivered || err != nil {
+		t.Errorf("Deliver() in iTerm2 = %v, %v, want it left to Output", delivered, err)
+	}
+}
+
+func TestDeliverPrintingModes(t *testing.T) {
+	for _, mode := range []Mode{ModeStdout, ModeShellFunction, ModeJSON, ModeMarkdown} {
+		var buf bytes.Buffer
+		h := NewHandlerWriter(&buf, mode, DecorationPlain)
+		if delivered, err := h.Deliver(&commands.Option{Command: "ls"}); delivered || err != nil || buf.Len() != 0 {
+			t.Errorf("Deliver() in %s = %v, %v, printed %q; want nothing done", mode, delivered, err, buf.String())
+		}
+	}
+}
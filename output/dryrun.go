@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
+)
+
+// Public: Describes what Output would do with the command without doing it.
+//
+// Writes to w rather than stdout so that shell-function wrappers, which
+// capture stdout, don't paste the report into the prompt.
+//
+// w   - Destination for the report, typically os.Stderr
+// cmd - The selected command
+//
+// Returns an error only if writing the report fails.
+func (h *Handler) DryRun(w io.Writer, cmd *commands.Option) error {
+	var action string
+	switch h.mode {
+	case ModeShellFunction:
+		action = "print the command for the shell function to insert into the prompt"
+	case ModeStdout:
+		action = "print the command to stdout"
+	case ModeJSON:
+		action = "print the command as JSON to stdout"
+	case ModeNvim:
+		action = "insert the command into Neovim"
+	case ModeMarkdown:
+		action = "print the command as a markdown block to stdout"
+	case ModeFile:
+		action = "write the command to a file as an executable script"
+	default:
+		action = "copy the command to the clipboard (falling back to stdout)"
+	}
+
+	risk := i18n.T(h.language, "none detected")
+	if cmd.Risk != nil {
+		risk = fmt.Sprintf("%s - %s", i18n.T(h.language, cmd.Risk.Level.String()), cmd.Risk.Message)
+	}
+
+	_, err := fmt.Fprintf(w,
+		"\n%s\n%s\n%s\n%s\n",
+		h.status("○", "Dry run, no output performed."),
+		i18n.Tf(h.language, "Would %s:", i18n.T(h.language, action)),
+		cmd.Command,
+		i18n.Tf(h.language, "Risk: %s", risk),
+	)
+	return err
+}
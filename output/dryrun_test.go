@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		risk     *safety.RiskInfo
+		contains []string
+	}{
+		{
+			name:     "clipboard",
+			mode:     ModeClipboard,
+			contains: []string{"copy the command to the clipboard", "Risk: none detected"},
+		},
+		{
+			name:     "shell-function",
+			mode:     ModeShellFunction,
+			contains: []string{"shell function"},
+		},
+		{
+			name:     "nvim",
+			mode:     ModeNvim,
+			contains: []string{"insert the command into Neovim"},
+		},
+		{
+			name:     "with risk",
+			mode:     ModeStdout,
+			risk:     &safety.RiskInfo{Level: safety.RiskHigh, Message: "deletes files"},
+			contains: []string{"print the command to stdout", "Risk: High - deletes files"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mode, DecorationFull)
+			cmd := &commands.Option{Command: "rm -rf build", Risk: tt.risk}
+
+			var buf bytes.Buffer
+			stdout := captureOutput(func() {
+				if err := handler.DryRun(&buf, cmd); err != nil {
+					t.Errorf("DryRun() error = %v", err)
+				}
+			})
+
+			if stdout != "" {
+				t.Errorf("DryRun() wrote to stdout: %q", stdout)
+			}
+			for _, want := range append(tt.contains, "rm -rf build") {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("DryRun() missing %q, got %q", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+// outputFile writes the command to the file set with SetFile as an
+// executable script, adding a #! line for the shell unless it has one. An
+// existing file is left alone rather than overwritten.
+func (h *Handler) outputFile(cmd *commands.Option) error {
+	if !h.delivered {
+		if err := h.writeFile(cmd.Command); err != nil {
+			return err
+		}
+	}
+
+	if !h.quiet {
+		fmt.Fprintf(h.writer(), "\n%s %s\n", h.status("✓", "Written to file:"), h.file)
+	}
+	return nil
+}
+
+// writeFile writes command to the file as a script, failing if the file
+// exists.
+func (h *Handler) writeFile(command string) error {
+	if h.file == "" {
+		return errors.New("no file to write the command to")
+	}
+
+	script := command
+	if !strings.HasPrefix(script, "#!") {
+		script = "#!/usr/bin/env " + h.shell.Interpreter() + "\n" + script
+	}
+	if !strings.HasSuffix(script, "\n") {
+		script += "\n"
+	}
+
+	f, err := os.OpenFile(h.file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o755)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists", h.file)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(script); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestOutputFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   commands.Shell
+		command string
+		want    string
+	}{
+		{"adds shebang", "", "set -euo pipefail\necho hi", "#!/usr/bin/env bash\nset -euo pipefail\necho hi\n"},
+		{"target shell", commands.ShellZsh, "echo hi", "#!/usr/bin/env zsh\necho hi\n"},
+		{"keeps shebang", "", "#!/bin/sh\necho hi\n", "#!/bin/sh\necho hi\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "script.sh")
+			var buf bytes.Buffer
+			h := NewHandlerWriter(&buf, ModeFile, DecorationPlain)
+			h.SetFile(path, tt.shell)
+
+			if err := h.Output(&commands.Option{Command: tt.command}); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+			if info, _ := os.Stat(path); info.Mode().Perm()&0o100 == 0 {
+				t.Errorf("file mode = %v, want executable", info.Mode())
+			}
+			if !strings.Contains(buf.String(), path) {
+				t.Errorf("Output() printed %q, want the file's path", buf.String())
+			}
+		})
+	}
+}
+
+func TestOutputFileExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(path, []byte("keep me"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandlerWriter(&bytes.Buffer{}, ModeFile, DecorationPlain)
+	h.SetFile(path, commands.ShellBash)
+	if err := h.Output(&commands.Option{Command: "echo hi"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Output() error = %v, want already exists", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "keep me" {
+		t.Errorf("file = %q, want it left alone", got)
+	}
+}
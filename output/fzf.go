@@ -0,0 +1,29 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+// fzfField flattens tabs and newlines, which separate fields and lines in
+// fzf input, to spaces.
+var fzfField = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ")
+
+// Public: Writes options as tab-separated "command<TAB>title<TAB>
+// description" lines, one per option, for fzf and other line-based
+// pickers. Tabs and newlines within a field become spaces.
+func (h *Handler) WriteOptions(options []commands.Option) error {
+	for _, opt := range options {
+		if err := h.outputFZF(&opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) outputFZF(cmd *commands.Option) error {
+	_, err := fmt.Fprintf(h.writer(), "%s\t%s\t%s\n", fzfField.Replace(cmd.Command), fzfField.Replace(cmd.Title), fzfField.Replace(cmd.Description))
+	return err
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestWriteOptions(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeFZF, DecorationFull)
+	options := []commands.Option{
+		{Title: "List files", Command: "ls -la", Description: "Long listing\nwith hidden files"},
+		{Title: "Tab\tseparated", Command: "cut -f1\tfile", Description: ""},
+	}
+
+	if err := handler.WriteOptions(options); err != nil {
+		t.Fatalf("WriteOptions() error = %v", err)
+	}
+
+	want := "ls -la\tList files\tLong listing with hidden files\n" +
+		"cut -f1 file\tTab separated\t\n"
+	if buf.String() != want {
+		t.Errorf("WriteOptions() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFZFOutput(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeFZF, DecorationFull)
+
+	if err := handler.Output(&commands.Option{Title: "Disk usage", Command: "df -h"}); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if want := "df -h\tDisk usage\t\n"; buf.String() != want {
+		t.Errorf("Output() = %q, want %q", buf.String(), want)
+	}
+}
@@ -3,10 +3,12 @@ package output
 
 import (
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
 )
 
 // Mode represents the output mode.
@@ -19,16 +21,113 @@ const (
 	ModeShellFunction Mode = "shell-function"
 	// ModeStdout prints to stdout only.
 	ModeStdout Mode = "stdout"
+	// ModeJSON prints the command and its details as JSON, for scripts.
+	ModeJSON Mode = "json"
+	// ModeFZF prints every option as a tab-separated line for fzf, without
+	// the selector.
+	ModeFZF Mode = "fzf"
+	// ModeNvim inserts the command into the Neovim 1lm runs inside, over
+	// its RPC socket.
+	ModeNvim Mode = "nvim"
+	// ModeMarkdown prints a markdown block with the query, command,
+	// description and risk, for runbooks and PR descriptions.
+	ModeMarkdown Mode = "markdown"
+	// ModeFile writes the command to a file as an executable script.
+	ModeFile Mode = "file"
+)
+
+// Decoration controls how status messages around the command look.
+type Decoration int
+
+const (
+	// DecorationFull uses symbols such as ✓ and ⚠ in status messages.
+	DecorationFull Decoration = iota
+	// DecorationPlain uses text-only status messages.
+	DecorationPlain
 )
 
 // Handler manages command output.
 type Handler struct {
-	mode Mode
+	mode       Mode
+	decoration Decoration
+	out        io.Writer
+	language   string
+	quiet      bool
+	primary    bool
+	terminal   Terminal
+	tmux       bool
+	query      string
+	file       string
+	shell      commands.Shell
+	delivered  bool // Deliver already did the output step
+}
+
+// Public: Creates a new output handler for the given mode and decoration
+// that writes to stdout.
+func NewHandler(mode Mode, decoration Decoration) *Handler {
+	return &Handler{mode: mode, decoration: decoration}
+}
+
+// Public: Creates a new output handler that writes to w instead of stdout,
+// for programs embedding 1lm that capture output themselves.
+func NewHandlerWriter(w io.Writer, mode Mode, decoration Decoration) *Handler {
+	return &Handler{mode: mode, decoration: decoration, out: w}
+}
+
+// Public: Sets the language status messages are translated into, e.g.
+// "es". The command itself is never translated.
+func (h *Handler) SetLanguage(lang string) {
+	h.language = lang
+}
+
+// Public: Drops status banners, so stdout mode prints only the command and
+// clipboard mode prints nothing unless it has to fall back to stdout.
+func (h *Handler) SetQuiet(quiet bool) {
+	h.quiet = quiet
+}
+
+// Public: Sets whether clipboard mode also sets the X11 primary selection,
+// so a middle click pastes the command as well as Ctrl+Shift+V.
+func (h *Handler) SetPrimarySelection(primary bool) {
+	h.primary = primary
+}
+
+// Public: Sets the terminal whose own escape sequences are used, from
+// DetectTerminal. Inside tmux they are wrapped to pass through it.
+func (h *Handler) SetTerminal(t Terminal) {
+	h.terminal = t
+	h.tmux = os.Getenv("TMUX") != ""
 }
 
-// Public: Creates a new output handler for the given mode.
-func NewHandler(mode Mode) *Handler {
-	return &Handler{mode: mode}
+// Public: Sets the query the command was generated for, which markdown
+// mode writes as the block's heading.
+func (h *Handler) SetQuery(query string) {
+	h.query = query
+}
+
+// Public: Sets the file file mode writes the command to, and the shell
+// whose interpreter goes in its #! line.
+func (h *Handler) SetFile(path string, sh commands.Shell) {
+	h.file, h.shell = path, sh
+}
+
+// writer returns the handler's destination. Stdout is looked up at write
+// time rather than captured at construction so redirection is honored.
+func (h *Handler) writer() io.Writer {
+	if h.out == nil {
+		return os.Stdout
+	}
+	return h.out
+}
+
+// status formats a translated status message, prefixed with symbol unless
+// plain.
+func (h *Handler) status(symbol, msg string) string {
+	msg = i18n.T(h.language, msg)
+	if h.decoration == DecorationPlain {
+		return msg
+	}
+	return symbol + " " + msg
 }
 
 // Public: Outputs the selected command using the configured mode.
@@ -38,43 +137,57 @@ func (h *Handler) Output(cmd *commands.Option) error {
 		return h.outputShellFunction(cmd)
 	case ModeStdout:
 		return h.outputStdout(cmd)
+	case ModeJSON:
+		return h.outputJSON(cmd)
+	case ModeFZF:
+		return h.outputFZF(cmd)
+	case ModeNvim:
+		return h.outputNvim(cmd)
+	case ModeMarkdown:
+		return h.outputMarkdown(cmd)
+	case ModeFile:
+		return h.outputFile(cmd)
 	default:
 		return h.outputClipboard(cmd)
 	}
 }
 
 func (h *Handler) outputShellFunction(cmd *commands.Option) error {
-	fmt.Println(cmd.Command)
+	fmt.Fprintln(h.writer(), cmd.Command)
 	return nil
 }
 
 func (h *Handler) outputStdout(cmd *commands.Option) error {
-	fmt.Printf("\n✓ Selected command:\n%s\n", cmd.Command)
+	if h.quiet {
+		fmt.Fprintln(h.writer(), cmd.Command)
+		return nil
+	}
+	fmt.Fprintf(h.writer(), "\n%s\n%s%s\n", h.status("✓", "Selected command:"), h.annotation(cmd), cmd.Command)
 	return nil
 }
 
-type clipboardCmd struct {
-	name string
-	args []string
-}
-
-// clipboardTools lists clipboard tools in order of preference by platform.
-var clipboardTools = []clipboardCmd{
-	{name: "pbcopy"},                                    // macOS
-	{name: "xclip", args: []string{"-selection", "clipboard"}}, // Linux X11
-	{name: "wl-copy"},                                   // Wayland
-}
-
 func (h *Handler) outputClipboard(cmd *commands.Option) error {
-	for _, tool := range clipboardTools {
-		c := exec.Command(tool.name, tool.args...)
-		c.Stdin = strings.NewReader(cmd.Command)
-		if c.Run() == nil {
-			fmt.Printf("\n✓ Copied to clipboard: %s\n", cmd.Command)
+	if h.delivered || WriteClipboard(cmd.Command) == nil || h.terminalCopy(cmd.Command) {
+		// The primary selection is a convenience on top of the clipboard,
+		// so failing to set it isn't reported.
+		if h.primary && !h.delivered {
+			_ = WritePrimary(cmd.Command)
+		}
+		if h.quiet {
 			return nil
 		}
+		// Multi-line commands start on their own line so the first line's
+		// indentation isn't mangled by the banner.
+		if strings.Contains(cmd.Command, "\n") {
+			fmt.Fprintf(h.writer(), "\n%s\n%s\n", h.status("✓", "Copied to clipboard:"), cmd.Command)
+		} else {
+			fmt.Fprintf(h.writer(), "\n%s %s%s\n", h.status("✓", "Copied to clipboard:"), h.annotation(cmd), cmd.Command)
+		}
+		return nil
 	}
 
-	fmt.Printf("\n⚠ Clipboard not available\n")
+	if !h.quiet {
+		fmt.Fprintf(h.writer(), "\n%s\n", h.status("⚠", "Clipboard not available"))
+	}
 	return h.outputStdout(cmd)
 }
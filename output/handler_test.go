@@ -50,7 +50,7 @@ func TestNewHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := NewHandler(tt.mode)
+			handler := NewHandler(tt.mode, DecorationFull)
 			if handler.mode != tt.want {
 				t.Errorf("NewHandler() mode = %v, want %v", handler.mode, tt.want)
 			}
@@ -59,7 +59,7 @@ func TestNewHandler(t *testing.T) {
 }
 
 func TestShellFunctionOutput(t *testing.T) {
-	handler := NewHandler(ModeShellFunction)
+	handler := NewHandler(ModeShellFunction, DecorationFull)
 	cmd := &commands.Option{
 		Title:       "List files",
 		Command:     "ls -la",
@@ -80,7 +80,7 @@ func TestShellFunctionOutput(t *testing.T) {
 }
 
 func TestStdoutOutput(t *testing.T) {
-	handler := NewHandler(ModeStdout)
+	handler := NewHandler(ModeStdout, DecorationFull)
 	cmd := &commands.Option{
 		Title:       "List files",
 		Command:     "ls -la",
@@ -103,7 +103,7 @@ func TestStdoutOutput(t *testing.T) {
 }
 
 func TestClipboardFallback(t *testing.T) {
-	handler := NewHandler(ModeClipboard)
+	handler := NewHandler(ModeClipboard, DecorationFull)
 	cmd := &commands.Option{
 		Title:       "List files",
 		Command:     "ls -la",
@@ -147,7 +147,7 @@ func TestModeSelection(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := NewHandler(tt.mode)
+			handler := NewHandler(tt.mode, DecorationFull)
 			cmd := &commands.Option{
 				Title:       "List files",
 				Command:     "ls -la",
@@ -223,3 +223,101 @@ func TestDefaultModeIsClipboard(t *testing.T) {
 		t.Errorf("Output() with invalid mode missing command, got %q", output)
 	}
 }
+
+func TestShellFunctionPreservesMultiLine(t *testing.T) {
+	handler := NewHandler(ModeShellFunction, DecorationFull)
+	cmd := &commands.Option{
+		Command: "for f in *.log; do\n  gzip \"$f\"\ndone",
+	}
+
+	output := captureOutput(func() {
+		if err := handler.Output(cmd); err != nil {
+			t.Errorf("Output() error = %v", err)
+		}
+	})
+
+	if output != cmd.Command+"\n" {
+		t.Errorf("Output() = %q, want %q", output, cmd.Command+"\n")
+	}
+}
+
+func TestPlainDecoration(t *testing.T) {
+	handler := NewHandler(ModeStdout, DecorationPlain)
+	cmd := &commands.Option{
+		Command: "ls -la",
+	}
+
+	output := captureOutput(func() {
+		if err := handler.Output(cmd); err != nil {
+			t.Errorf("Output() error = %v", err)
+		}
+	})
+
+	if strings.Contains(output, "✓") {
+		t.Errorf("Output() with plain decoration contains symbol, got %q", output)
+	}
+	if !strings.Contains(output, "Selected command:") {
+		t.Errorf("Output() missing status message, got %q", output)
+	}
+}
+
+func TestNewHandlerWriter(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeShellFunction, DecorationFull)
+
+	output := captureOutput(func() {
+		if err := handler.Output(&commands.Option{Command: "ls -la"}); err != nil {
+			t.Errorf("Output() error = %v", err)
+		}
+	})
+
+	if output != "" {
+		t.Errorf("Output() wrote %q to stdout, want nothing", output)
+	}
+	if buf.String() != "ls -la\n" {
+		t.Errorf("Output() wrote %q to writer, want %q", buf.String(), "ls -la\n")
+	}
+}
+
+func TestHandlerLanguage(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeStdout, DecorationPlain)
+	handler.SetLanguage("es")
+
+	if err := handler.Output(&commands.Option{Command: "ls -la"}); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Comando elegido:\nls -la") {
+		t.Errorf("Output() = %q, want translated status and untranslated command", buf.String())
+	}
+}
+
+func TestQuietOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{name: "stdout prints only the command", mode: ModeStdout},
+		{name: "clipboard prints nothing or only the fallback command", mode: ModeClipboard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := NewHandlerWriter(&buf, tt.mode, DecorationFull)
+			handler.SetQuiet(true)
+
+			if err := handler.Output(&commands.Option{Command: "ls -la"}); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+
+			if got := buf.String(); got != "" && got != "ls -la\n" {
+				t.Errorf("Output() = %q, want only the command", got)
+			}
+			if tt.mode == ModeStdout && buf.String() != "ls -la\n" {
+				t.Errorf("Output() = %q, want %q", buf.String(), "ls -la\n")
+			}
+		})
+	}
+}
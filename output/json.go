@@ -0,0 +1,105 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
+)
+
+// jsonOption is the machine-readable form of a command option.
+type jsonOption struct {
+	Title       string    `json:"title"`
+	Command     string    `json:"command"`
+	Description string    `json:"description,omitempty"`
+	Confidence  int       `json:"confidence,omitempty"`
+	Recommended bool      `json:"recommended,omitempty"`
+	Risk        *jsonRisk `json:"risk,omitempty"`
+	Unverified  bool      `json:"unverified,omitempty"`
+	Fallback    string    `json:"fallback,omitempty"`
+	Source      string    `json:"source,omitempty"`
+}
+
+// jsonRisk mirrors safety.RiskInfo with the level as a string.
+type jsonRisk struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// jsonResult is one line of batch output.
+type jsonResult struct {
+	Query   string       `json:"query"`
+	Options []jsonOption `json:"options"`
+	Error   string       `json:"error,omitempty"`
+}
+
+func newJSONOption(opt commands.Option) jsonOption {
+	o := jsonOption{
+		Title:       opt.Title,
+		Command:     opt.Command,
+		Description: opt.Description,
+		Confidence:  opt.Confidence,
+		Recommended: opt.Recommended,
+		Unverified:  opt.Unverified,
+		Fallback:    opt.Fallback,
+		Source:      opt.Source,
+	}
+	if opt.Risk != nil {
+		o.Risk = &jsonRisk{
+			Level:   strings.ToLower(opt.Risk.Level.String()),
+			Message: opt.Risk.Message,
+		}
+	}
+	return o
+}
+
+func (h *Handler) outputJSON(cmd *commands.Option) error {
+	return json.NewEncoder(h.writer()).Encode(newJSONOption(*cmd))
+}
+
+// Public: Writes batch results. JSON mode writes one object per line
+// (JSON Lines) with the query, its options and any error; other modes
+// write a plain-text listing.
+func (h *Handler) WriteBatch(results []commands.BatchResult) error {
+	if h.mode == ModeJSON {
+		enc := json.NewEncoder(h.writer())
+		for _, r := range results {
+			line := jsonResult{Query: r.Query, Options: []jsonOption{}}
+			for _, opt := range r.Options {
+				line.Options = append(line.Options, newJSONOption(opt))
+			}
+			if r.Err != nil {
+				line.Error = r.Err.Error()
+			}
+			if err := enc.Encode(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := h.writer()
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, r.Query)
+		if r.Err != nil {
+			fmt.Fprintf(w, "  %s\n", h.status("⚠", r.Err.Error()))
+			continue
+		}
+		for j, opt := range r.Options {
+			fmt.Fprintf(w, "  %d. %s\n", j+1, opt.Title)
+			for _, line := range strings.Split(opt.Command, "\n") {
+				fmt.Fprintf(w, "     %s\n", line)
+			}
+			if opt.Risk != nil {
+				risk := fmt.Sprintf("%s - %s", i18n.T(h.language, opt.Risk.Level.String()), opt.Risk.Message)
+				fmt.Fprintf(w, "     %s\n", i18n.Tf(h.language, "Risk: %s", risk))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestJSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeJSON, DecorationFull)
+	cmd := &commands.Option{
+		Title:   "Remove build dir",
+		Command: "rm -rf build",
+		Risk:    &safety.RiskInfo{Level: safety.RiskHigh, Message: "Deletes files"},
+	}
+
+	if err := handler.Output(cmd); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	want := `{"title":"Remove build dir","command":"rm -rf build","risk":{"level":"high","message":"Deletes files"}}` + "\n"
+	if buf.String() != want {
+		t.Errorf("Output() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteBatch(t *testing.T) {
+	results := []commands.BatchResult{
+		{Query: "list files", Options: []commands.Option{{Title: "List", Command: "ls -la"}}},
+		{Query: "broken", Err: errors.New("API error")},
+	}
+
+	tests := []struct {
+		name string
+		mode Mode
+		want []string
+	}{
+		{
+			name: "json lines",
+			mode: ModeJSON,
+			want: []string{
+				`{"query":"list files","options":[{"title":"List","command":"ls -la"}]}` + "\n",
+				`{"query":"broken","options":[],"error":"API error"}` + "\n",
+			},
+		},
+		{
+			name: "text",
+			mode: ModeStdout,
+			want: []string{"list files\n  1. List\n     ls -la\n", "broken\n  ⚠ API error\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := NewHandlerWriter(&buf, tt.mode, DecorationFull)
+			if err := handler.WriteBatch(results); err != nil {
+				t.Fatalf("WriteBatch() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("WriteBatch() missing %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
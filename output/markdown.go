@@ -0,0 +1,82 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
+)
+
+// outputMarkdown writes a markdown block with the query, the command in a
+// fenced code block, its description and any risk notes, for pasting into
+// a runbook, PR description or wiki.
+func (h *Handler) outputMarkdown(cmd *commands.Option) error {
+	var b strings.Builder
+
+	heading := strings.Join(strings.Fields(h.query), " ")
+	if heading == "" {
+		heading = cmd.Title
+	}
+	fmt.Fprintf(&b, "### %s\n\n", heading)
+	if cmd.Title != "" && cmd.Title != heading {
+		fmt.Fprintf(&b, "**%s**\n\n", cmd.Title)
+	}
+
+	fence := codeFence(cmd.Command)
+	fmt.Fprintf(&b, "%ssh\n%s\n%s\n", fence, cmd.Command, fence)
+
+	if cmd.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", cmd.Description)
+	}
+
+	if cmd.Risk != nil {
+		risk := fmt.Sprintf("%s - %s", i18n.T(h.language, cmd.Risk.Level.String()), cmd.Risk.Message)
+		fmt.Fprintf(&b, "\n> **%s**", i18n.Tf(h.language, "Risk: %s", risk))
+		if cmd.Risk.Fragment != "" && cmd.Risk.Fragment != cmd.Command {
+			fmt.Fprintf(&b, " (%s)", inlineCode(cmd.Risk.Fragment))
+		}
+		b.WriteString("\n")
+		if safer := cmd.Risk.Safer; safer != nil {
+			fmt.Fprintf(&b, ">\n> %s", i18n.Tf(h.language, "Safer: %s", inlineCode(safer.Command)))
+			if safer.Description != "" {
+				fmt.Fprintf(&b, " - %s", safer.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := fmt.Fprint(h.writer(), b.String())
+	return err
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// s, so a command containing ``` can't close its own block.
+func codeFence(s string) string {
+	return strings.Repeat("`", max(3, longestRun(s, '`')+1))
+}
+
+// inlineCode wraps s in enough backticks to hold any it contains, padding
+// with spaces when s starts or ends with one.
+func inlineCode(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	ticks := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return ticks + s + ticks
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c rune) int {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
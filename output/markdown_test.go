@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestMarkdownOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		cmd   commands.Option
+		want  string
+	}{
+		{
+			name:  "with risk",
+			query: "delete the build dir",
+			cmd: commands.Option{
+				Title:       "Remove build dir",
+				Command:     "rm -rf build",
+				Description: "Deletes build recursively.",
+				Risk: &safety.RiskInfo{
+					Level:   safety.RiskHigh,
+					Message: "Deletes files",
+					Safer:   &safety.Alternative{Command: "rm -rI build", Description: "asks first"},
+				},
+			},
+			want: "### delete the build dir\n\n**Remove build dir**\n\n```sh\nrm -rf build\n```\n\n" +
+				"Deletes build recursively.\n\n> **Risk: High - Deletes files**\n>\n> Safer: `rm -rI build` - asks first\n",
+		},
+		{
+			name: "snippet without query",
+			cmd:  commands.Option{Title: "List files", Command: "ls -la"},
+			want: "### List files\n\n```sh\nls -la\n```\n",
+		},
+		{
+			name:  "command containing a fence",
+			query: "print a fence",
+			cmd:   commands.Option{Command: "echo '```'"},
+			want:  "### print a fence\n\n````sh\necho '```'\n````\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := NewHandlerWriter(&buf, ModeMarkdown, DecorationFull)
+			handler.SetQuery(tt.query)
+			if err := handler.Output(&tt.cmd); err != nil {
+				t.Fatalf("Output() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Output() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
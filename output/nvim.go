@@ -0,0 +1,35 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/nvim"
+)
+
+// outputNvim inserts the command into the Neovim whose RPC socket is in
+// $NVIM, which Neovim sets for programs in its terminal and jobs.
+func (h *Handler) outputNvim(cmd *commands.Option) error {
+	if !h.delivered {
+		if err := sendToNvim(cmd.Command); err != nil {
+			return err
+		}
+	}
+
+	if !h.quiet {
+		fmt.Fprintf(h.writer(), "\n%s %s\n", h.status("✓", "Sent to Neovim:"), cmd.Command)
+	}
+	return nil
+}
+
+// sendToNvim inserts text into the Neovim whose socket is in $NVIM.
+func sendToNvim(text string) error {
+	addr := os.Getenv("NVIM")
+	if addr == "" {
+		return errors.New("--output nvim only works inside Neovim ($NVIM isn't set)")
+	}
+	return nvim.Insert(context.Background(), addr, text)
+}
@@ -0,0 +1,19 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestNvimOutsideNeovim(t *testing.T) {
+	t.Setenv("NVIM", "")
+
+	var buf bytes.Buffer
+	err := NewHandlerWriter(&buf, ModeNvim, DecorationFull).Output(&commands.Option{Command: "ls"})
+	if err == nil || !strings.Contains(err.Error(), "$NVIM") {
+		t.Errorf("Output() error = %v, want one explaining $NVIM isn't set", err)
+	}
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
+)
+
+// Terminal is a terminal emulator whose own escape sequences output can
+// use.
+type Terminal int
+
+const (
+	// TerminalOther gets only standard output.
+	TerminalOther Terminal = iota
+	// TerminalITerm2 can copy to the Mac's clipboard from any host,
+	// including over SSH, and annotate the printed command with its
+	// description and risk.
+	TerminalITerm2
+	// TerminalWarp is recognized, but Warp has no public escape sequences
+	// for block metadata yet, so it gets standard output.
+	TerminalWarp
+)
+
+// Public: Detects the terminal from the environment. LC_TERMINAL is
+// checked as well as TERM_PROGRAM because OpenSSH passes LC_* variables
+// on by default, so iTerm2 is still recognized on a remote host.
+func DetectTerminal(getenv func(string) string) Terminal {
+	switch {
+	case getenv("TERM_PROGRAM") == "iTerm.app" || getenv("LC_TERMINAL") == "iTerm2":
+		return TerminalITerm2
+	case getenv("TERM_PROGRAM") == "WarpTerminal":
+		return TerminalWarp
+	}
+	return TerminalOther
+}
+
+// iterm2 returns an iTerm2 proprietary escape sequence, wrapped so tmux
+// passes it through to the terminal when running inside tmux.
+func (h *Handler) iterm2(payload string) string {
+	seq := "\x1b]1337;" + payload + "\a"
+	if h.tmux {
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// terminalCopy copies text through the terminal, for when no clipboard
+// tool works, such as over SSH. Reports whether the terminal supports it.
+func (h *Handler) terminalCopy(text string) bool {
+	if h.terminal != TerminalITerm2 {
+		return false
+	}
+	fmt.Fprint(h.writer(), h.iterm2("Copy=:"+base64.StdEncoding.EncodeToString([]byte(text))))
+	return true
+}
+
+// annotation returns the escape sequence that attaches cmd's description
+// and risk to the command printed straight after it, as an iTerm2
+// annotation shown on hover. Empty for other terminals and for commands
+// over several lines, which an annotation can't span.
+func (h *Handler) annotation(cmd *commands.Option) string {
+	if h.terminal != TerminalITerm2 || strings.Contains(cmd.Command, "\n") {
+		return ""
+	}
+
+	notes := []string{}
+	if cmd.Description != "" {
+		notes = append(notes, cmd.Description)
+	}
+	if cmd.Risk != nil {
+		risk := fmt.Sprintf("%s - %s", i18n.T(h.language, cmd.Risk.Level.String()), cmd.Risk.Message)
+		notes = append(notes, i18n.Tf(h.language, "Risk: %s", risk))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+
+	// The message can't contain the separator or control characters.
+	message := strings.NewReplacer("|", "/", "\a", "", "\x1b", "").Replace(strings.Join(notes, " "))
+	return h.iterm2(fmt.Sprintf("AddAnnotation=%d|%s", lipgloss.Width(cmd.Command), message))
+}
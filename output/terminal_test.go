@@ -0,0 +1,82 @@
+package output
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestDetectTerminal(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Terminal
+	}{
+		{name: "iTerm2", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: TerminalITerm2},
+		{name: "iTerm2 over ssh", env: map[string]string{"LC_TERMINAL": "iTerm2"}, want: TerminalITerm2},
+		{name: "Warp", env: map[string]string{"TERM_PROGRAM": "WarpTerminal"}, want: TerminalWarp},
+		{name: "other", env: map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, want: TerminalOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := DetectTerminal(getenv); got != tt.want {
+				t.Errorf("DetectTerminal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestITerm2Annotation(t *testing.T) {
+	t.Setenv("TMUX", "")
+	cmd := &commands.Option{
+		Command:     "rm -rf build",
+		Description: "Delete the build directory",
+		Risk:        &safety.RiskInfo{Level: safety.RiskHigh, Message: "Deletes files | recursively"},
+	}
+
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeStdout, DecorationPlain)
+	handler.SetTerminal(TerminalITerm2)
+	if err := handler.Output(cmd); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	want := "\x1b]1337;AddAnnotation=12|Delete the build directory Risk: High - Deletes files / recursively\arm -rf build\n"
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("Output() = %q, want the command annotated: %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	handler.SetTerminal(TerminalWarp)
+	_ = handler.Output(cmd)
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("Output() = %q, want no escape sequences outside iTerm2", buf.String())
+	}
+}
+
+func TestITerm2Copy(t *testing.T) {
+	// No clipboard tool can run, as over SSH.
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	var buf bytes.Buffer
+	handler := NewHandlerWriter(&buf, ModeClipboard, DecorationPlain)
+	handler.SetTerminal(TerminalITerm2)
+	if err := handler.Output(&commands.Option{Command: "ls -la"}); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	copySeq := "\x1bPtmux;\x1b\x1b]1337;Copy=:" + base64.StdEncoding.EncodeToString([]byte("ls -la")) + "\a\x1b\\"
+	if !strings.HasPrefix(buf.String(), copySeq) {
+		t.Errorf("Output() = %q, want it to start with the tmux-wrapped copy %q", buf.String(), copySeq)
+	}
+	if !strings.Contains(buf.String(), "Copied to clipboard: ls -la") {
+		t.Errorf("Output() = %q, want the copy reported", buf.String())
+	}
+}
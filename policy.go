@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/policy"
+)
+
+// activePolicy is the merged organization and local policy, or nil.
+var activePolicy *policy.Policy
+
+// loadPolicy reads the organization policy from policy_path or policy_url
+// and merges it with the config file's [policy] table. A configured policy
+// that can't be loaded or verified is an error, so 1lm never runs with its
+// rules silently dropped. An unsigned policy_path is trusted unless
+// policy_public_key is set; policy_url always needs the key.
+func loadPolicy(cfg *config.Config) (*policy.Policy, error) {
+	var org *policy.Policy
+
+	switch {
+	case cfg.PolicyPath != "":
+		path, err := config.ExpandPath(cfg.PolicyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand policy_path: %w", err)
+		}
+		if org, err = policy.Load(path, cfg.PolicyPublicKey); err != nil {
+			return nil, err
+		}
+
+	case cfg.PolicyURL != "":
+		dir, err := config.Dir()
+		if err != nil {
+			return nil, err
+		}
+		if *offline {
+			org, err = policy.LoadCached(cfg.PolicyURL, cfg.PolicyPublicKey, dir)
+		} else {
+			org, err = policy.Fetch(context.Background(), cfg.PolicyURL, cfg.PolicyPublicKey, dir, cacheMaxAge())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Policy != nil {
+		if err := cfg.Policy.Compile(); err != nil {
+			return nil, fmt.Errorf("config [policy]: %w", err)
+		}
+	}
+
+	return policy.Merge(org, cfg.Policy), nil
+}
+
+// checkPolicy refuses a selected command the policy forbids. The selector
+// already blocks these, but snippets, history picks and other paths reach
+// output too, some without a safety check, so with max_risk set a command
+// that was never checked is refused.
+func checkPolicy(selected *commands.Option) error {
+	if reason := activePolicy.Check(selected.Command); reason != "" {
+		return fmt.Errorf("blocked by policy: %s", reason)
+	}
+	if selected.Risk == nil && activePolicy.RequiresSafety() {
+		return errors.New("blocked by policy: max_risk requires a safety check, and this command wasn't checked")
+	}
+	if selected.Risk != nil && !activePolicy.AllowsRisk(selected.Risk.Level) {
+		return fmt.Errorf("blocked by policy: %s risk exceeds the allowed maximum", strings.ToLower(selected.Risk.Level.String()))
+	}
+	return nil
+}
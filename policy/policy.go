@@ -0,0 +1,148 @@
+// Package policy enforces organization-wide rules on which commands may be
+// selected: forbidden patterns, banned paths and a maximum risk level.
+package policy
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Policy is a set of rules commands must satisfy to be selected.
+type Policy struct {
+	// Forbidden lists patterns no selected command may match.
+	Forbidden []Rule `toml:"forbidden"`
+	// BannedPaths lists files and directories no command may touch.
+	BannedPaths []string `toml:"banned_paths"`
+	// MaxRisk is the highest risk level a command may be selected at:
+	// "none" or "low". When set, commands must have been safety checked.
+	MaxRisk string `toml:"max_risk"`
+}
+
+// Rule forbids commands matching a regular expression.
+type Rule struct {
+	Pattern string `toml:"pattern"`
+	Reason  string `toml:"reason"`
+
+	re *regexp.Regexp
+}
+
+// Public: Parses a TOML policy document and compiles its patterns.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := toml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if err := p.Compile(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Public: Checks the policy and compiles its patterns. Policies decoded
+// other than by Parse, such as the config file's [policy] table, must be
+// compiled before use.
+func (p *Policy) Compile() error {
+	switch p.MaxRisk {
+	case "", "none", "low":
+	default:
+		return fmt.Errorf("policy max_risk %q must be \"none\" or \"low\"", p.MaxRisk)
+	}
+
+	for i, rule := range p.Forbidden {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("policy pattern %q: %w", rule.Pattern, err)
+		}
+		p.Forbidden[i].re = re
+	}
+	return nil
+}
+
+// Public: Combines policies, keeping every rule and banned path and the
+// strictest risk threshold, so no policy can loosen another. Nil policies
+// are skipped; the result is nil if all are.
+func Merge(policies ...*Policy) *Policy {
+	var merged *Policy
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &Policy{}
+		}
+
+		merged.Forbidden = append(merged.Forbidden, p.Forbidden...)
+		for _, banned := range p.BannedPaths {
+			if !slices.Contains(merged.BannedPaths, banned) {
+				merged.BannedPaths = append(merged.BannedPaths, banned)
+			}
+		}
+		if p.MaxRisk != "" && (merged.MaxRisk == "" || p.maxLevel() < merged.maxLevel()) {
+			merged.MaxRisk = p.MaxRisk
+		}
+	}
+	return merged
+}
+
+// Public: Returns why the policy forbids command, or "" if it doesn't.
+// Risk is checked separately, with AllowsRisk.
+func (p *Policy) Check(command string) string {
+	if p == nil {
+		return ""
+	}
+
+	for _, rule := range p.Forbidden {
+		if rule.re != nil && rule.re.MatchString(command) {
+			if rule.Reason != "" {
+				return rule.Reason
+			}
+			return fmt.Sprintf("matches forbidden pattern %s", rule.Pattern)
+		}
+	}
+
+	for _, word := range pathWords(command) {
+		for _, banned := range p.BannedPaths {
+			if underPath(word, banned) {
+				return fmt.Sprintf("touches banned path %s", banned)
+			}
+		}
+	}
+
+	return ""
+}
+
+// Public: Reports whether commands must be safety checked before they can
+// be selected.
+func (p *Policy) RequiresSafety() bool {
+	return p != nil && p.MaxRisk != ""
+}
+
+// Public: Reports whether a command at level may be selected.
+func (p *Policy) AllowsRisk(level safety.RiskLevel) bool {
+	return !p.RequiresSafety() || level <= p.maxLevel()
+}
+
+func (p *Policy) maxLevel() safety.RiskLevel {
+	return safety.ParseRiskLevel(p.MaxRisk)
+}
+
+// pathWords splits a command into the words that might be paths, breaking
+// on whitespace, quotes, redirections and "=" (as in --file=/etc/x).
+func pathWords(command string) []string {
+	return strings.FieldsFunc(command, func(r rune) bool {
+		return strings.ContainsRune(" \t\n'\"<>=;|&()`", r)
+	})
+}
+
+// underPath reports whether word names banned or something inside it.
+func underPath(word, banned string) bool {
+	banned = strings.TrimSuffix(banned, "/")
+	word = path.Clean(word)
+	return word == banned || strings.HasPrefix(word, banned+"/")
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := Parse([]byte(`
+banned_paths = ["/etc/ssh/", "~/.aws"]
+
+[[forbidden]]
+pattern = '\bmkfs\b'
+reason = "formats disks"
+
+[[forbidden]]
+pattern = 'chmod\s+777'
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"ls -la", ""},
+		{"sudo mkfs.ext4 /dev/sdb1", "formats disks"},
+		{"chmod 777 file", `matches forbidden pattern chmod\s+777`},
+		{"cat /etc/ssh/sshd_config", "touches banned path /etc/ssh/"},
+		{"vim /etc/ssh", "touches banned path /etc/ssh/"},
+		{"cp x --target=/etc/ssh/keys", "touches banned path /etc/ssh/"},
+		{"cat ~/.aws/credentials", "touches banned path ~/.aws"},
+		{"cat /etc/sshd", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := p.Check(tt.command); got != tt.want {
+				t.Errorf("Check(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilPolicyAllowsEverything(t *testing.T) {
+	var p *Policy
+	if p.Check("rm -rf /") != "" || !p.AllowsRisk(safety.RiskHigh) || p.RequiresSafety() {
+		t.Error("nil policy should allow everything")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad risk":    `max_risk = "high"`,
+		"bad pattern": "[[forbidden]]\npattern = '('",
+		"bad toml":    `max_risk =`,
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(doc)); err == nil {
+				t.Errorf("Parse(%q) succeeded, want error", doc)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	org := &Policy{
+		Forbidden:   []Rule{{Pattern: "mkfs"}},
+		BannedPaths: []string{"/etc"},
+		MaxRisk:     "none",
+	}
+	local := &Policy{
+		Forbidden:   []Rule{{Pattern: "dd"}},
+		BannedPaths: []string{"/etc", "/boot"},
+		MaxRisk:     "low",
+	}
+	for _, p := range []*Policy{org, local} {
+		if err := p.Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	merged := Merge(org, nil, local)
+
+	if len(merged.Forbidden) != 2 {
+		t.Errorf("Forbidden = %v, want both rules", merged.Forbidden)
+	}
+	if got := strings.Join(merged.BannedPaths, ","); got != "/etc,/boot" {
+		t.Errorf("BannedPaths = %q, want /etc,/boot", got)
+	}
+	// A local "low" can't loosen the organization's "none".
+	if merged.MaxRisk != "none" {
+		t.Errorf("MaxRisk = %q, want none", merged.MaxRisk)
+	}
+	if merged.AllowsRisk(safety.RiskLow) {
+		t.Error("merged policy allows low risk")
+	}
+
+	if Merge(nil, nil) != nil {
+		t.Error("Merge of nil policies should be nil")
+	}
+}
@@ -0,0 +1,193 @@
+package policy
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// signatureSuffix names a policy's detached signature: policy.toml is
+// signed by policy.toml.sig, holding a base64 Ed25519 signature.
+const signatureSuffix = ".sig"
+
+// cacheTTL is how long a fetched policy is used before it is fetched
+// again, unless the caller allows longer. A stale copy is still used if
+// fetching fails.
+const cacheTTL = time.Hour
+
+// fetchTimeout bounds fetching a remote policy.
+const fetchTimeout = 10 * time.Second
+
+// maxPolicySize bounds a fetched policy or signature.
+const maxPolicySize = 1 << 20
+
+// Public: Reads the policy at path and, when publicKey is set, verifies it
+// against the signature at path.sig.
+//
+// Without a key a local policy is trusted as it is, like the config file
+// naming it: the file is on the user's own disk, so a signature only adds
+// something when the key is set by whoever distributes the policy.
+//
+// path      - The policy TOML file
+// publicKey - Base64 Ed25519 public key; empty accepts unsigned policies
+//
+// Returns the policy, or an error if it can't be read, verified or parsed.
+func Load(path, publicKey string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	if publicKey != "" {
+		sig, err := os.ReadFile(path + signatureSuffix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy signature: %w", err)
+		}
+		if err := Verify(data, sig, publicKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return Parse(data)
+}
+
+// Public: Fetches the policy at url and its signature at url.sig, verifies
+// it, and caches it in cacheDir. A cached copy younger than maxAge is used
+// without fetching, and a stale one if fetching fails, so rules stay
+// enforced offline.
+//
+// ctx       - Bounds the fetch
+// url       - Where the policy is published
+// publicKey - Base64 Ed25519 public key; required for remote policies
+// cacheDir  - Directory for the cached copy
+// maxAge    - How long a cached copy is used before fetching; zero is 1h
+//
+// Returns the policy, or an error if no verified copy is available.
+func Fetch(ctx context.Context, url, publicKey, cacheDir string, maxAge time.Duration) (*Policy, error) {
+	if publicKey == "" {
+		return nil, errors.New("policy_url requires policy_public_key")
+	}
+	if maxAge <= 0 {
+		maxAge = cacheTTL
+	}
+
+	cache := cachePath(url, cacheDir)
+	if info, err := os.Stat(cache); err == nil && time.Since(info.ModTime()) < maxAge {
+		if p, err := Load(cache, publicKey); err == nil {
+			return p, nil
+		}
+	}
+
+	data, sig, err := download(ctx, url)
+	if err == nil {
+		if err = Verify(data, sig, publicKey); err == nil {
+			writeCache(cache, data, sig)
+			return Parse(data)
+		}
+		err = fmt.Errorf("%s: %w", url, err)
+	}
+
+	// Fall back to the last verified copy rather than run unenforced.
+	if p, cacheErr := Load(cache, publicKey); cacheErr == nil {
+		return p, nil
+	}
+	return nil, err
+}
+
+// Public: Loads the verified copy of the policy fetched from url from
+// cacheDir, without the network, for offline use.
+func LoadCached(url, publicKey, cacheDir string) (*Policy, error) {
+	p, err := Load(cachePath(url, cacheDir), publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("no cached copy of policy_url available offline: %w", err)
+	}
+	return p, nil
+}
+
+// cachePath names the cached copy after a hash of url, so a changed
+// policy_url is fetched rather than answered from the old one's copy.
+func cachePath(url, cacheDir string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, "policy-"+hex.EncodeToString(sum[:8])+".toml")
+}
+
+// Public: Checks that sig, a base64 Ed25519 signature, signs data under
+// publicKey, a base64 Ed25519 public key.
+func Verify(data, sig []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("policy_public_key is not a base64 Ed25519 public key")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return errors.New("policy signature is not base64")
+	}
+
+	if !ed25519.Verify(key, data, signature) {
+		return errors.New("policy signature does not match")
+	}
+	return nil
+}
+
+// download fetches the policy and its signature.
+func download(ctx context.Context, url string) (data, sig []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	if data, err = get(ctx, url); err != nil {
+		return nil, nil, err
+	}
+	if sig, err = get(ctx, url+signatureSuffix); err != nil {
+		return nil, nil, err
+	}
+	return data, sig, nil
+}
+
+func get(ctx context.Context, url string) (data []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policy: %w", err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policy: %w", err)
+	}
+	defer func() {
+		if closeErr := res.Body.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to fetch policy: %w", closeErr))
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, res.Status)
+	}
+
+	data, err = io.ReadAll(io.LimitReader(res.Body, maxPolicySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policy: %w", err)
+	}
+	return data, nil
+}
+
+// writeCache saves a verified policy and its signature. Failing to cache
+// only costs a fetch next time, so errors are ignored.
+func writeCache(path string, data, sig []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	if os.WriteFile(path+signatureSuffix, sig, 0o644) == nil {
+		_ = os.WriteFile(path, data, 0o644)
+	}
+}
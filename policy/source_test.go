@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testPolicy = "max_risk = \"low\"\n"
+
+// signer returns a base64 public key and a function signing data with its
+// private key.
+func signer(t *testing.T) (string, func([]byte) []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(data []byte) []byte {
+		return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)))
+	}
+	return base64.StdEncoding.EncodeToString(pub), sign
+}
+
+func TestLoad(t *testing.T) {
+	key, sign := signer(t)
+	otherKey, _ := signer(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.toml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".sig", sign([]byte(testPolicy)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err := Load(path, key); err != nil || p.MaxRisk != "low" {
+		t.Errorf("Load() = %+v, %v; want the verified policy", p, err)
+	}
+	if _, err := Load(path, otherKey); err == nil {
+		t.Error("Load() with the wrong key succeeded")
+	}
+	if _, err := Load(path, "not a key"); err == nil {
+		t.Error("Load() with an invalid key succeeded")
+	}
+
+	unsigned := filepath.Join(dir, "unsigned.toml")
+	if err := os.WriteFile(unsigned, []byte(testPolicy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(unsigned, key); err == nil {
+		t.Error("Load() without a signature succeeded")
+	}
+	if _, err := Load(unsigned, ""); err != nil {
+		t.Errorf("Load() of an unsigned policy without a key = %v", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	key, sign := signer(t)
+	body := []byte(testPolicy)
+	sig := sign(body)
+
+	up := true
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if !up {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/policy.toml":
+			w.Write(body)
+		case "/policy.toml.sig":
+			w.Write(sig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	url := server.URL + "/policy.toml"
+
+	if _, err := Fetch(context.Background(), url, "", cacheDir, 0); err == nil {
+		t.Error("Fetch() without a public key succeeded")
+	}
+
+	p, err := Fetch(context.Background(), url, key, cacheDir, 0)
+	if err != nil || p.MaxRisk != "low" {
+		t.Fatalf("Fetch() = %+v, %v; want the verified policy", p, err)
+	}
+
+	// With the server down, the stale cached copy keeps the rules enforced.
+	up = false
+	stale := time.Now().Add(-2 * cacheTTL)
+	if err := os.Chtimes(cachePath(url, cacheDir), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := Fetch(context.Background(), url, key, cacheDir, 0); err != nil || p.MaxRisk != "low" {
+		t.Errorf("Fetch() while down = %+v, %v; want the cached policy", p, err)
+	}
+
+	if _, err := Fetch(context.Background(), url, key, t.TempDir(), 0); err == nil {
+		t.Error("Fetch() while down without a cache succeeded")
+	}
+	if p, err := LoadCached(url, key, cacheDir); err != nil || p.MaxRisk != "low" {
+		t.Errorf("LoadCached() = %+v, %v; want the cached policy", p, err)
+	}
+
+	// Another policy_url doesn't get this one's cached copy.
+	other := server.URL + "/other/policy.toml"
+	if _, err := Fetch(context.Background(), other, key, cacheDir, 0); err == nil {
+		t.Error("Fetch() of another URL while down used this one's cached copy")
+	}
+	if _, err := LoadCached(other, key, cacheDir); err == nil {
+		t.Error("LoadCached() of another URL used this one's cached copy")
+	}
+
+	// A longer maxAge keeps using the cached copy, up to that age.
+	up = true
+	fetches = 0
+	if _, err := Fetch(context.Background(), url, key, cacheDir, 24*time.Hour); err != nil || fetches != 0 {
+		t.Errorf("Fetch() within maxAge = %v after %d fetches, want the cached copy", err, fetches)
+	}
+	old := time.Now().Add(-25 * time.Hour)
+	if err := os.Chtimes(cachePath(url, cacheDir), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Fetch(context.Background(), url, key, cacheDir, 24*time.Hour); err != nil || fetches == 0 {
+		t.Errorf("Fetch() past maxAge = %v after %d fetches, want a fresh fetch", err, fetches)
+	}
+}
+
+func TestFetchRejectsTamperedPolicy(t *testing.T) {
+	key, sign := signer(t)
+	sig := sign([]byte(testPolicy))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/policy.toml.sig" {
+			w.Write(sig)
+			return
+		}
+		w.Write([]byte("max_risk = \"none\"\n# tampered\n"))
+	}))
+	defer server.Close()
+
+	if _, err := Fetch(context.Background(), server.URL+"/policy.toml", key, t.TempDir(), 0); err == nil {
+		t.Error("Fetch() accepted a policy that doesn't match its signature")
+	}
+}
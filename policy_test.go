@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/history"
+	"github.com/pixielabs/1lm/policy"
+	"github.com/pixielabs/1lm/safety"
+	"github.com/pixielabs/1lm/snippets"
+)
+
+func TestLoadPolicyPath(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(pub)
+	data := []byte("max_risk = \"low\"\n")
+
+	dir := t.TempDir()
+	unsigned := filepath.Join(dir, "unsigned.toml")
+	signed := filepath.Join(dir, "signed.toml")
+	for _, path := range []string{unsigned, signed} {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))
+	if err := os.WriteFile(signed+".sig", []byte(sig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{name: "unsigned without a key is trusted", cfg: config.Config{PolicyPath: unsigned}},
+		{name: "unsigned with a key is refused", cfg: config.Config{PolicyPath: unsigned, PolicyPublicKey: key}, wantErr: true},
+		{name: "signed with a key", cfg: config.Config{PolicyPath: signed, PolicyPublicKey: key}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := loadPolicy(&tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("loadPolicy() = %+v, want an error", p)
+				}
+				return
+			}
+			if err != nil || p == nil || p.MaxRisk != "low" {
+				t.Errorf("loadPolicy() = %+v, %v; want the policy", p, err)
+			}
+		})
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	maxLow, err := policy.Parse([]byte("max_risk = \"low\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		policy   *policy.Policy
+		selected commands.Option
+		wantErr  bool
+	}{
+		{name: "no policy", selected: commands.Option{Command: "ls"}},
+		{name: "checked and allowed", policy: maxLow, selected: commands.Option{Command: "ls", Risk: &safety.RiskInfo{Level: safety.RiskNone}}},
+		{name: "checked and over max_risk", policy: maxLow, selected: commands.Option{Command: "rm -rf build", Risk: &safety.RiskInfo{Level: safety.RiskHigh}}, wantErr: true},
+		{name: "unchecked", policy: maxLow, selected: commands.Option{Command: "ls"}, wantErr: true},
+		{name: "snippet without a risk", policy: maxLow, selected: snippets.Snippet{Name: "ls", Command: "ls"}.Option(), wantErr: true},
+		{name: "snippet saved with a low risk", policy: maxLow, selected: snippets.Snippet{Name: "ls", Command: "ls", RiskLevel: "low"}.Option()},
+		{name: "snippet saved with a high risk", policy: maxLow, selected: snippets.Snippet{Name: "rm", Command: "rm -rf build", RiskLevel: "high"}.Option(), wantErr: true},
+		{name: "history pick without a risk", policy: maxLow, selected: history.Entry{Query: "list files", Command: "ls"}.Option(), wantErr: true},
+		{name: "history pick with a low risk", policy: maxLow, selected: history.Entry{Query: "list files", Command: "ls", RiskLevel: "low"}.Option()},
+		{name: "history pick without a policy", selected: history.Entry{Query: "list files", Command: "ls"}.Option()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(saved *policy.Policy) { activePolicy = saved }(activePolicy)
+			activePolicy = tt.policy
+
+			if err := checkPolicy(&tt.selected); (err != nil) != tt.wantErr {
+				t.Errorf("checkPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitBackend keeps files in a git repository, committing each change. It
+// works on a clone in dir, which is brought up to date before the first
+// read.
+type gitBackend struct {
+	url    string
+	dir    string
+	pulled bool
+}
+
+func (g *gitBackend) Get(ctx context.Context, name string) ([]byte, error) {
+	if err := g.pull(ctx); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(g.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (g *gitBackend) Put(ctx context.Context, name string, data []byte) error {
+	if err := g.pull(ctx); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(g.dir, name), data, 0600); err != nil {
+		return err
+	}
+
+	if _, err := g.git(ctx, "add", name); err != nil {
+		return err
+	}
+	if _, err := g.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return nil // unchanged
+	}
+	if _, err := g.git(ctx, "commit", "--quiet", "--message", "Update "+name); err != nil {
+		return err
+	}
+
+	if _, err := g.push(ctx); err != nil {
+		return g.rejected(ctx, err)
+	}
+	return nil
+}
+
+// rejected explains a failed push. When another machine pushed first, the
+// clone is reset to match it and ErrConflict returned: every Put replaces
+// a whole file, so its commit can't be replayed on theirs, and the caller
+// merges the newer contents instead.
+func (g *gitBackend) rejected(ctx context.Context, pushErr error) error {
+	if _, err := g.git(ctx, "fetch", "--quiet", "origin"); err != nil {
+		return pushErr
+	}
+	upstream, err := g.upstream(ctx)
+	if err != nil {
+		return pushErr
+	}
+	if _, err := g.git(ctx, "merge-base", "--is-ancestor", upstream, "HEAD"); err == nil {
+		// Nothing new upstream, so the push failed for another reason.
+		return pushErr
+	}
+	if _, err := g.git(ctx, "reset", "--quiet", "--hard", upstream); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrConflict, pushErr)
+}
+
+// pull clones the repository, or brings the clone up to date, once. The
+// clone is reset to match the repository rather than merged with it:
+// every change is pushed as it is made, so it holds nothing that isn't
+// already there. A clone that can't be updated is made again, such as one
+// made while the repository was empty or one of another repository, left
+// from before sync_url changed.
+func (g *gitBackend) pull(ctx context.Context) error {
+	if g.pulled {
+		return nil
+	}
+
+	_, err := os.Stat(filepath.Join(g.dir, ".git"))
+	if err == nil {
+		if g.update(ctx) == nil {
+			g.pulled = true
+			return nil
+		}
+		if err := os.RemoveAll(g.dir); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.dir), 0755); err != nil {
+		return err
+	}
+	if _, err := run(ctx, nil, "git", "clone", "--quiet", g.url, g.dir); err != nil {
+		return err
+	}
+	g.pulled = true
+	return nil
+}
+
+// update resets an existing clone of g.url to the repository's copy of
+// its branch.
+func (g *gitBackend) update(ctx context.Context) error {
+	origin, err := g.git(ctx, "remote", "get-url", "origin")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(origin)) != g.url {
+		return errors.New("clone of another repository")
+	}
+	if _, err := g.git(ctx, "fetch", "--quiet", "origin"); err != nil {
+		return err
+	}
+	upstream, err := g.upstream(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = g.git(ctx, "reset", "--quiet", "--hard", upstream)
+	return err
+}
+
+// upstream names the repository's copy of the clone's branch, such as
+// origin/main. A clone with no commits yet has none.
+func (g *gitBackend) upstream(ctx context.Context) (string, error) {
+	branch, err := g.git(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	upstream := "origin/" + strings.TrimSpace(string(branch))
+	if _, err := g.git(ctx, "rev-parse", "--verify", "--quiet", upstream); err != nil {
+		return "", fmt.Errorf("the repository has no %s", upstream)
+	}
+	return upstream, nil
+}
+
+// push pushes the branch, tracking it so later pulls know where from.
+func (g *gitBackend) push(ctx context.Context) ([]byte, error) {
+	return g.git(ctx, "push", "--quiet", "--set-upstream", "origin", "HEAD")
+}
+
+// git runs a git command in the clone. Commits are made as 1lm, which
+// also works on machines with no git identity set up.
+func (g *gitBackend) git(ctx context.Context, args ...string) ([]byte, error) {
+	base := []string{"-C", g.dir, "-c", "user.name=1lm", "-c", "user.email=1lm@localhost"}
+	return run(ctx, nil, "git", append(base, args...)...)
+}
@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxFileSize limits how much of a file is read from a WebDAV server.
+const maxFileSize = 10 << 20
+
+// webdavBackend keeps files in a WebDAV collection, such as a Nextcloud
+// folder.
+type webdavBackend struct {
+	base     string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// newWebDAVBackend maps webdav:// and webdavs:// to HTTP and HTTPS, taking
+// the credentials from the URL.
+func newWebDAVBackend(u *url.URL) *webdavBackend {
+	b := &webdavBackend{client: &http.Client{Timeout: 30 * time.Second}}
+	if u.User != nil {
+		b.user = u.User.Username()
+		b.password, _ = u.User.Password()
+	}
+
+	base := *u
+	base.User = nil
+	base.Scheme = "https"
+	if u.Scheme == "webdav" {
+		base.Scheme = "http"
+	}
+	b.base = strings.TrimSuffix(base.String(), "/")
+	return b
+}
+
+func (w *webdavBackend) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := w.do(ctx, http.MethodGet, w.base+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to download %s: %s", name, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxFileSize))
+}
+
+func (w *webdavBackend) Put(ctx context.Context, name string, data []byte) error {
+	status, err := w.put(ctx, name, data)
+	if err != nil {
+		return err
+	}
+	// 409 Conflict means the collection doesn't exist yet.
+	if status == http.StatusConflict {
+		resp, err := w.do(ctx, "MKCOL", w.base+"/", nil)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if status, err = w.put(ctx, name, data); err != nil {
+			return err
+		}
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("failed to upload %s: %d %s", name, status, http.StatusText(status))
+	}
+	return nil
+}
+
+// put uploads data as name, returning the response status.
+func (w *webdavBackend) put(ctx context.Context, name string, data []byte) (int, error) {
+	resp, err := w.do(ctx, http.MethodPut, w.base+"/"+name, data)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// do sends an authenticated request.
+func (w *webdavBackend) do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the sync backend: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,78 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Player answers from a recorded session instead of calling the API. It
+// implements both llm.Client and commands.RiskEvaluator.
+//
+// Responses are matched by query and by command list. A query asked
+// several times replays its recordings in order, then repeats the last.
+type Player struct {
+	session *Session
+
+	mu              sync.Mutex
+	usedGenerations []bool
+	usedEvaluations []bool
+}
+
+// Public: Creates a Player for a loaded session.
+func NewPlayer(session *Session) *Player {
+	return &Player{
+		session:         session,
+		usedGenerations: make([]bool, len(session.Generations)),
+		usedEvaluations: make([]bool, len(session.Evaluations)),
+	}
+}
+
+// Public: Loads the session at path and creates a Player for it.
+func Open(path string) (*Player, error) {
+	session, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(session), nil
+}
+
+// Public: Returns the recorded options, or error, for query.
+func (p *Player) GenerateOptions(_ context.Context, query string) ([]llm.CommandOption, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := p.session.findGeneration(query, p.usedGenerations)
+	if i < 0 {
+		return nil, fmt.Errorf("no recorded response for query %q", query)
+	}
+	p.usedGenerations[i] = true
+
+	g := p.session.Generations[i]
+	if g.Error != "" {
+		return nil, errors.New(g.Error)
+	}
+	return g.Options, nil
+}
+
+// Public: Returns the recorded safety verdicts, or error, for commands.
+func (p *Player) Evaluate(_ context.Context, commands []string) ([]*safety.RiskInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := p.session.findEvaluation(commands, p.usedEvaluations)
+	if i < 0 {
+		return nil, errors.New("no recorded safety evaluation for these commands")
+	}
+	p.usedEvaluations[i] = true
+
+	e := p.session.Evaluations[i]
+	if e.Error != "" {
+		return nil, errors.New(e.Error)
+	}
+	return decodeRisks(e.Risks), nil
+}
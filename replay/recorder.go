@@ -0,0 +1,92 @@
+package replay
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Recorder passes calls through to a client and evaluator, saving each
+// response to a session file as it arrives. It implements both llm.Client
+// and commands.RiskEvaluator.
+type Recorder struct {
+	client    llm.Client
+	evaluator commands.RiskEvaluator
+	path      string
+
+	mu      sync.Mutex
+	session Session
+	err     error
+}
+
+// Public: Creates a Recorder writing to path.
+//
+// client    - Generates the options being recorded
+// evaluator - Evaluates their safety; may be nil
+// path      - Session file, created or replaced
+//
+// Returns the Recorder.
+func NewRecorder(client llm.Client, evaluator commands.RiskEvaluator, path string) *Recorder {
+	return &Recorder{client: client, evaluator: evaluator, path: path}
+}
+
+// Public: Returns the Recorder as a safety evaluator, or nil when it wraps
+// none, so safety evaluation stays disabled.
+func (r *Recorder) Evaluator() commands.RiskEvaluator {
+	if r.evaluator == nil {
+		return nil
+	}
+	return r
+}
+
+// Public: Generates options with the wrapped client and records them.
+func (r *Recorder) GenerateOptions(ctx context.Context, query string) ([]llm.CommandOption, error) {
+	options, err := r.client.GenerateOptions(ctx, query)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.Generations = append(r.session.Generations, Generation{
+		Query:   query,
+		Options: options,
+		Error:   errorString(err),
+	})
+	r.save()
+
+	return options, err
+}
+
+// Public: Evaluates commands with the wrapped evaluator and records the
+// verdicts.
+func (r *Recorder) Evaluate(ctx context.Context, commands []string) ([]*safety.RiskInfo, error) {
+	risks, err := r.evaluator.Evaluate(ctx, commands)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session.Evaluations = append(r.session.Evaluations, Evaluation{
+		Commands: commands,
+		Risks:    encodeRisks(risks),
+		Error:    errorString(err),
+	})
+	r.save()
+
+	return risks, err
+}
+
+// Public: Returns the first error saving the session, if any. Saving
+// failures don't interrupt the run, so callers check once at the end.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// save writes the session after every call, so it survives the process
+// being interrupted. r.mu must be held.
+func (r *Recorder) save() {
+	if err := r.session.Save(r.path); err != nil && r.err == nil {
+		r.err = err
+	}
+}
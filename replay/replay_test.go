@@ -0,0 +1,90 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+type stubEvaluator struct {
+	risks []*safety.RiskInfo
+}
+
+func (s stubEvaluator) Evaluate(context.Context, []string) ([]*safety.RiskInfo, error) {
+	return s.risks, nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	ctx := context.Background()
+
+	client := &llm.MockClient{Response: []llm.CommandOption{
+		{Title: "Remove", Command: "rm -rf build", Description: "Deletes the build directory"},
+	}}
+	evaluator := stubEvaluator{risks: []*safety.RiskInfo{{Level: safety.RiskHigh, Message: "Deletes files"}}}
+
+	recorder := NewRecorder(client, evaluator, path)
+	if _, err := recorder.GenerateOptions(ctx, "clean build"); err != nil {
+		t.Fatalf("GenerateOptions() error = %v", err)
+	}
+	if _, err := recorder.Evaluate(ctx, []string{"rm -rf build"}); err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	client.Err = errors.New("overloaded")
+	recorder.GenerateOptions(ctx, "broken")
+	if err := recorder.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	player, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	options, err := player.GenerateOptions(ctx, "clean build")
+	if err != nil || len(options) != 1 || options[0].Command != "rm -rf build" {
+		t.Errorf("GenerateOptions() = %v, %v; want the recorded option", options, err)
+	}
+
+	risks, err := player.Evaluate(ctx, []string{"rm -rf build"})
+	if err != nil || len(risks) != 1 || risks[0].Level != safety.RiskHigh || risks[0].Message != "Deletes files" {
+		t.Errorf("Evaluate() = %v, %v; want the recorded high risk", risks, err)
+	}
+
+	if _, err := player.GenerateOptions(ctx, "broken"); err == nil || err.Error() != "overloaded" {
+		t.Errorf("GenerateOptions(broken) error = %v, want the recorded error", err)
+	}
+
+	if _, err := player.GenerateOptions(ctx, "something else"); err == nil {
+		t.Error("GenerateOptions() of an unrecorded query should fail")
+	}
+}
+
+func TestPlayerReplaysRepeatsInOrder(t *testing.T) {
+	player := NewPlayer(&Session{Generations: []Generation{
+		{Query: "q", Options: []llm.CommandOption{{Command: "first"}}},
+		{Query: "other", Options: []llm.CommandOption{{Command: "other"}}},
+		{Query: "q", Options: []llm.CommandOption{{Command: "second"}}},
+	}})
+
+	for _, want := range []string{"first", "second", "second"} {
+		options, err := player.GenerateOptions(context.Background(), "q")
+		if err != nil {
+			t.Fatalf("GenerateOptions() error = %v", err)
+		}
+		if got := options[0].Command; got != want {
+			t.Errorf("GenerateOptions() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestRecorderWithoutEvaluator(t *testing.T) {
+	recorder := NewRecorder(llm.NewMockClient(), nil, filepath.Join(t.TempDir(), "session.json"))
+	if recorder.Evaluator() != nil {
+		t.Error("Evaluator() should be nil when no evaluator is wrapped")
+	}
+}
@@ -0,0 +1,153 @@
+// Package replay records API responses to a session file and plays them
+// back, for deterministic demos, offline TUI work and reproducing bugs.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Session is a recording of the generation and safety responses in a run.
+type Session struct {
+	Generations []Generation `json:"generations"`
+	Evaluations []Evaluation `json:"evaluations"`
+}
+
+// Generation is one recorded GenerateOptions call. The query includes any
+// attached context, as sent.
+type Generation struct {
+	Query   string              `json:"query"`
+	Options []llm.CommandOption `json:"options,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// Evaluation is one recorded safety evaluation. Risks has one entry per
+// command, null for commands with no detected risk.
+type Evaluation struct {
+	Commands []string `json:"commands"`
+	Risks    []*Risk  `json:"risks,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Risk is the recorded form of safety.RiskInfo.
+type Risk struct {
+	Level    string `json:"level"`
+	Message  string `json:"message"`
+	Fragment string `json:"fragment,omitempty"`
+
+	SaferCommand     string `json:"safer_command,omitempty"`
+	SaferDescription string `json:"safer_description,omitempty"`
+}
+
+// Public: Loads a session file.
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Public: Writes the session to path, replacing it atomically so an
+// interrupted run never leaves a truncated file.
+func (s *Session) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// findGeneration returns the index of the first unused generation for
+// query, or the last one once all are used; -1 if there are none.
+func (s *Session) findGeneration(query string, used []bool) int {
+	last := -1
+	for i, g := range s.Generations {
+		if g.Query != query {
+			continue
+		}
+		if !used[i] {
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+// findEvaluation returns the index of the first unused evaluation of
+// commands, or the last one once all are used; -1 if there are none.
+func (s *Session) findEvaluation(commands []string, used []bool) int {
+	last := -1
+	for i, e := range s.Evaluations {
+		if !slices.Equal(e.Commands, commands) {
+			continue
+		}
+		if !used[i] {
+			return i
+		}
+		last = i
+	}
+	return last
+}
+
+func encodeRisks(infos []*safety.RiskInfo) []*Risk {
+	risks := make([]*Risk, len(infos))
+	for i, info := range infos {
+		if info != nil {
+			risks[i] = &Risk{
+				Level:    strings.ToLower(info.Level.String()),
+				Message:  info.Message,
+				Fragment: info.Fragment,
+			}
+			if info.Safer != nil {
+				risks[i].SaferCommand = info.Safer.Command
+				risks[i].SaferDescription = info.Safer.Description
+			}
+		}
+	}
+	return risks
+}
+
+func decodeRisks(risks []*Risk) []*safety.RiskInfo {
+	infos := make([]*safety.RiskInfo, len(risks))
+	for i, r := range risks {
+		if r != nil {
+			infos[i] = &safety.RiskInfo{
+				Level:    safety.ParseRiskLevel(r.Level),
+				Message:  r.Message,
+				Fragment: r.Fragment,
+			}
+			if r.SaferCommand != "" {
+				infos[i].Safer = &safety.Alternative{Command: r.SaferCommand, Description: r.SaferDescription}
+			}
+		}
+	}
+	return infos
+}
+
+// errorString records err's message, or nothing for nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
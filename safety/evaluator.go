@@ -1,3 +1,5 @@
+// Package safety evaluates shell commands for destructive or risky
+// behavior before they are run.
 package safety
 
 import (
@@ -7,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/pixielabs/1lm/llm"
 )
 
 // RiskLevel represents the severity of detected risk.
@@ -19,18 +22,37 @@ const (
 	RiskLow
 	// RiskHigh indicates high-severity risk (e.g., destructive operations).
 	RiskHigh
+	// RiskCritical indicates irreversible destruction of a whole disk,
+	// filesystem, home directory or database.
+	RiskCritical
 )
 
 // RiskInfo contains details about a detected risk.
 type RiskInfo struct {
 	Level   RiskLevel
 	Message string
+	// Fragment is the part of the command that makes it risky, e.g. the
+	// "-rf /" in a longer pipeline. It is always a substring of the
+	// command, or empty.
+	Fragment string
+	// Safer is a mitigated variant of a high- or critical-risk command, if one exists.
+	Safer *Alternative
+}
+
+// Alternative is a safer way to do what a risky command does, such as
+// rm -rI instead of rm -rf, a dry run first, or moving files to the trash.
+type Alternative struct {
+	Command     string
+	Description string // what it does differently
 }
 
 // Evaluator uses an LLM to evaluate command safety.
 type Evaluator struct {
-	client *anthropic.Client
-	model  string
+	client     *anthropic.Client
+	model      string
+	language   string
+	riskNotes  []string
+	structured llm.StructuredOutput
 }
 
 // CommandRisk represents the safety evaluation for a single command.
@@ -38,6 +60,11 @@ type CommandRisk struct {
 	Command   string `json:"command"`
 	RiskLevel string `json:"risk_level"`
 	Reason    string `json:"reason"`
+	RiskyPart string `json:"risky_part"`
+	// SaferCommand and SaferDescription propose a mitigated variant of a
+	// high-risk command; both are empty otherwise.
+	SaferCommand     string `json:"safer_command"`
+	SaferDescription string `json:"safer_description"`
 }
 
 // SafetyResponse is the structured output from the safety LLM call.
@@ -53,6 +80,32 @@ func NewEvaluator(client *anthropic.Client, model string) *Evaluator {
 	}
 }
 
+// Public: Sets the language risk reasons are written in, by name (e.g.
+// "Spanish"). Empty means English.
+func (e *Evaluator) SetLanguage(language string) {
+	e.language = language
+}
+
+// Public: Adds platform-specific risks for the check to watch for, such as
+// what is dangerous to run against a Kubernetes cluster.
+func (e *Evaluator) AddRiskNotes(notes ...string) {
+	e.riskNotes = append(e.riskNotes, notes...)
+}
+
+// withRiskNotes appends the platform-specific risks to a system message.
+func (e *Evaluator) withRiskNotes(systemMessage string) string {
+	if len(e.riskNotes) == 0 {
+		return systemMessage
+	}
+	return systemMessage + "\n\nAlso watch for these risks:\n- " + strings.Join(e.riskNotes, "\n- ")
+}
+
+// Public: Sets how JSON responses are requested. See
+// llm.StructuredOutputMode.
+func (e *Evaluator) SetStructuredOutputs(mode llm.StructuredOutputMode) {
+	e.structured.Mode = mode
+}
+
 // safetySchema defines the structured output format for safety evaluation.
 var safetySchema = map[string]any{
 	"type": "object",
@@ -67,14 +120,26 @@ var safetySchema = map[string]any{
 					},
 					"risk_level": map[string]any{
 						"type": "string",
-						"enum": []string{"none", "low", "high"},
+						"enum": []string{"none", "low", "high", "critical"},
 					},
 					"reason": map[string]any{
 						"type":      "string",
 						"maxLength": 100,
 					},
+					"risky_part": map[string]any{
+						"type":        "string",
+						"description": "The exact substring of the command that makes it risky, copied character for character (e.g. \"rm -rf /\"); empty when there is no risk",
+					},
+					"safer_command": map[string]any{
+						"type":        "string",
+						"description": "Only for high risk: a variant that does the same job with a safeguard (an interactive prompt like rm -I, a dry run, moving to the trash, a backup first); empty otherwise or if there is none",
+					},
+					"safer_description": map[string]any{
+						"type":        "string",
+						"description": "What safer_command does differently, in one sentence; empty when safer_command is",
+					},
 				},
-				"required":             []string{"command", "risk_level", "reason"},
+				"required":             []string{"command", "risk_level", "reason", "risky_part", "safer_command", "safer_description"},
 				"additionalProperties": false,
 			},
 		},
@@ -99,18 +164,23 @@ func (e *Evaluator) Evaluate(ctx context.Context, commands []string) ([]*RiskInf
 	systemMessage := `You are a security expert evaluating shell commands for safety risks.
 
 Risk levels:
+- CRITICAL: Irreversible destruction of a whole disk, filesystem, home directory or database (rm -rf / or ~, dd to a device, mkfs, DROP DATABASE)
 - HIGH: Destructive operations that could cause data loss or system damage (rm -rf, dd, mkfs, formatting, permanent deletion)
 - LOW: Operations that interact with external systems or require careful attention (network operations, downloads, system scans, privilege changes)
 - NONE: Safe read-only operations (ls, grep, find, echo, cat, viewing files)
 
-Be practical and context-aware. Flag commands that users should think twice about before running.`
+Be practical and context-aware. Flag commands that users should think twice about before running.
+
+For HIGH and CRITICAL risk commands, propose a safer variant that still does the job where one exists: prompting before each deletion (rm -I), a dry run (--dry-run, -n), moving to the trash (trash-put) or taking a backup first.`
+
+	systemMessage = e.withRiskNotes(systemMessage)
+	if e.language != "" {
+		systemMessage += fmt.Sprintf("\n\nWrite each reason and safer description in %s.", e.language)
+	}
 
-	message, err := e.client.Beta.Messages.New(ctx, anthropic.BetaMessageNewParams{
+	textContent, err := e.structured.New(ctx, e.client, anthropic.BetaMessageNewParams{
 		Model:     anthropic.Model(e.model),
-		MaxTokens: 1024,
-		Betas: []anthropic.AnthropicBeta{
-			"structured-outputs-2025-11-13",
-		},
+		MaxTokens: 2048,
 		Messages: []anthropic.BetaMessageParam{{
 			Content: []anthropic.BetaContentBlockParamUnion{{
 				OfText: &anthropic.BetaTextBlockParam{
@@ -122,22 +192,9 @@ Be practical and context-aware. Flag commands that users should think twice abou
 		System: []anthropic.BetaTextBlockParam{{
 			Text: systemMessage,
 		}},
-		OutputFormat: anthropic.BetaJSONOutputFormatParam{
-			Schema: safetySchema,
-		},
-	})
-
+	}, safetySchema)
 	if err != nil {
-		return nil, fmt.Errorf("API call failed: %w", err)
-	}
-
-	if len(message.Content) == 0 {
-		return nil, fmt.Errorf("empty response from API")
-	}
-
-	textContent := message.Content[0].Text
-	if textContent == "" {
-		return nil, fmt.Errorf("no text content in response")
+		return nil, err
 	}
 
 	var response SafetyResponse
@@ -145,23 +202,81 @@ Be practical and context-aware. Flag commands that users should think twice abou
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(response.Evaluations) != len(commands) {
-		return nil, fmt.Errorf("expected %d evaluations, got %d", len(commands), len(response.Evaluations))
+	return matchEvaluations(commands, response.Evaluations)
+}
+
+// IncompleteError is returned by Evaluate when the response left some
+// commands out or gave them a malformed evaluation. The results for the
+// other commands are returned with it, so only the missing ones need
+// evaluating again.
+type IncompleteError struct {
+	Missing []int // indexes of the commands without an evaluation
+	Total   int   // how many commands were evaluated
+}
+
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("no evaluation for %d of %d commands", len(e.Missing), e.Total)
+}
+
+// matchEvaluations pairs evaluations with the commands they're for. The
+// model answers in order, but when it leaves commands out the rest are
+// matched by their command text instead. Commands with no evaluation, or
+// one with an unknown risk level, are reported in an *IncompleteError.
+func matchEvaluations(commands []string, evals []CommandRisk) ([]*RiskInfo, error) {
+	matched := make([]*CommandRisk, len(commands))
+	if len(evals) == len(commands) {
+		for i := range evals {
+			matched[i] = &evals[i]
+		}
+	} else {
+		for i := range evals {
+			for j, cmd := range commands {
+				if matched[j] == nil && evals[i].Command == cmd {
+					matched[j] = &evals[i]
+					break
+				}
+			}
+		}
 	}
 
 	results := make([]*RiskInfo, len(commands))
-	for i, eval := range response.Evaluations {
-		if level := parseRiskLevel(eval.RiskLevel); level != RiskNone {
+	var missing []int
+	for i, eval := range matched {
+		if eval == nil || !knownRiskLevel(eval.RiskLevel) {
+			missing = append(missing, i)
+			continue
+		}
+		if level := ParseRiskLevel(eval.RiskLevel); level != RiskNone {
 			results[i] = &RiskInfo{
 				Level:   level,
 				Message: eval.Reason,
 			}
+			// A fragment the model paraphrased can't be highlighted.
+			if eval.RiskyPart != "" && strings.Contains(commands[i], eval.RiskyPart) {
+				results[i].Fragment = eval.RiskyPart
+			}
+			if level >= RiskHigh && eval.SaferCommand != "" && eval.SaferCommand != commands[i] {
+				results[i].Safer = &Alternative{Command: eval.SaferCommand, Description: eval.SaferDescription}
+			}
 		}
 	}
 
+	if len(missing) > 0 {
+		return results, &IncompleteError{Missing: missing, Total: len(commands)}
+	}
 	return results, nil
 }
 
+// knownRiskLevel reports whether level is one the schema allows, rather
+// than a malformed answer that ParseRiskLevel would read as no risk.
+func knownRiskLevel(level string) bool {
+	switch level {
+	case "none", "low", "high", "critical":
+		return true
+	}
+	return false
+}
+
 // buildPrompt formats the list of commands into an evaluation prompt.
 func buildPrompt(commands []string) string {
 	var b strings.Builder
@@ -174,13 +289,17 @@ func buildPrompt(commands []string) string {
 	return b.String()
 }
 
-// parseRiskLevel converts a string risk level to a RiskLevel enum value.
-func parseRiskLevel(level string) RiskLevel {
+// Public: Converts a string risk level ("none", "low", "high",
+// "critical") to a
+// RiskLevel. Unknown values are treated as RiskNone.
+func ParseRiskLevel(level string) RiskLevel {
 	switch level {
 	case "low":
 		return RiskLow
 	case "high":
 		return RiskHigh
+	case "critical":
+		return RiskCritical
 	default:
 		return RiskNone
 	}
@@ -193,6 +312,8 @@ func (r RiskLevel) String() string {
 		return "Low"
 	case RiskHigh:
 		return "High"
+	case RiskCritical:
+		return "Critical"
 	default:
 		return "None"
 	}
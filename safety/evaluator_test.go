@@ -2,6 +2,8 @@ package safety
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -27,6 +29,11 @@ func TestParseRiskLevel(t *testing.T) {
 			level: "high",
 			want:  RiskHigh,
 		},
+		{
+			name:  "critical",
+			level: "critical",
+			want:  RiskCritical,
+		},
 		{
 			name:  "invalid",
 			level: "invalid",
@@ -41,9 +48,9 @@ func TestParseRiskLevel(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := parseRiskLevel(tt.level)
+			got := ParseRiskLevel(tt.level)
 			if got != tt.want {
-				t.Errorf("parseRiskLevel(%q) = %v, want %v", tt.level, got, tt.want)
+				t.Errorf("ParseRiskLevel(%q) = %v, want %v", tt.level, got, tt.want)
 			}
 		})
 	}
@@ -70,6 +77,11 @@ func TestRiskLevelString(t *testing.T) {
 			level: RiskHigh,
 			want:  "High",
 		},
+		{
+			name:  "critical",
+			level: RiskCritical,
+			want:  "Critical",
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,3 +143,76 @@ func TestEvaluateEmptyCommands(t *testing.T) {
 	}
 }
 
+func TestMatchEvaluations(t *testing.T) {
+	commands := []string{"ls", "rm -rf build", "curl example.com"}
+	none := func(cmd string) CommandRisk { return CommandRisk{Command: cmd, RiskLevel: "none"} }
+	high := CommandRisk{Command: "rm -rf build", RiskLevel: "high", Reason: "deletes build"}
+
+	tests := []struct {
+		name        string
+		evals       []CommandRisk
+		wantRisky   []int // indexes with a RiskInfo
+		wantMissing []int
+	}{
+		{
+			name:      "all in order",
+			evals:     []CommandRisk{none("ls"), high, none("curl example.com")},
+			wantRisky: []int{1},
+		},
+		{
+			name:        "one left out",
+			evals:       []CommandRisk{none("ls"), high},
+			wantRisky:   []int{1},
+			wantMissing: []int{2},
+		},
+		{
+			name:        "out of order and one left out",
+			evals:       []CommandRisk{high, none("curl example.com")},
+			wantRisky:   []int{1},
+			wantMissing: []int{0},
+		},
+		{
+			name:        "malformed risk level",
+			evals:       []CommandRisk{none("ls"), {Command: "rm -rf build", RiskLevel: "severe"}, none("curl example.com")},
+			wantMissing: []int{1},
+		},
+		{
+			name:        "nothing usable",
+			evals:       nil,
+			wantMissing: []int{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := matchEvaluations(commands, tt.evals)
+			if len(results) != len(commands) {
+				t.Fatalf("got %d results, want %d", len(results), len(commands))
+			}
+
+			var risky []int
+			for i, r := range results {
+				if r != nil {
+					risky = append(risky, i)
+				}
+			}
+			if !slices.Equal(risky, tt.wantRisky) {
+				t.Errorf("risky = %v, want %v", risky, tt.wantRisky)
+			}
+
+			var incomplete *IncompleteError
+			if tt.wantMissing == nil {
+				if err != nil {
+					t.Errorf("error = %v, want none", err)
+				}
+				return
+			}
+			if !errors.As(err, &incomplete) {
+				t.Fatalf("error = %v, want *IncompleteError", err)
+			}
+			if !slices.Equal(incomplete.Missing, tt.wantMissing) || incomplete.Total != len(commands) {
+				t.Errorf("missing = %v of %d, want %v of %d", incomplete.Missing, incomplete.Total, tt.wantMissing, len(commands))
+			}
+		})
+	}
+}
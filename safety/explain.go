@@ -0,0 +1,113 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// RiskDetail is an in-depth assessment of one command, for when the short
+// reason in RiskInfo isn't enough to decide whether to run it.
+type RiskDetail struct {
+	Level     RiskLevel
+	Reasoning string   // what the command does and why it is or isn't risky
+	DataLoss  string   // what data could be lost or changed; empty if none
+	Paths     []string // files, directories and devices it affects
+	// Reversible is "yes", "partly" or "no".
+	Reversible string
+	Recovery   string // how to undo it, or why it can't be undone
+}
+
+// explainSchema defines the structured output format for a detailed
+// assessment.
+var explainSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"risk_level": map[string]any{
+			"type": "string",
+			"enum": []string{"none", "low", "high", "critical"},
+		},
+		"reasoning": map[string]any{
+			"type":        "string",
+			"description": "What the command does step by step, and why it is or isn't risky",
+		},
+		"data_loss": map[string]any{
+			"type":        "string",
+			"description": "What data could be lost, overwritten or exposed; empty if none",
+		},
+		"affected_paths": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "Files, directories, devices or hosts the command reads from or changes",
+		},
+		"reversible": map[string]any{
+			"type": "string",
+			"enum": []string{"yes", "partly", "no"},
+		},
+		"recovery": map[string]any{
+			"type":        "string",
+			"description": "How to undo the command's effects, or why they can't be undone",
+		},
+	},
+	"required":             []string{"risk_level", "reasoning", "data_loss", "affected_paths", "reversible", "recovery"},
+	"additionalProperties": false,
+}
+
+// Public: Assesses a single command in depth: what it does, what data it
+// could lose, which paths it touches and whether it can be undone.
+func (e *Evaluator) Explain(ctx context.Context, command string) (*RiskDetail, error) {
+	if e.client == nil {
+		return nil, fmt.Errorf("evaluator client is nil")
+	}
+
+	systemMessage := `You are a security expert explaining the risks of a shell command to someone deciding whether to run it.
+
+Be concrete: name the paths, data and systems involved, assume the command is run in the current directory by the current user, and explain any flags that change how destructive it is. Risk levels are as for a quick check: CRITICAL for irreversible destruction of a whole disk, filesystem, home directory or database, HIGH for data loss or system damage, LOW for network access, privilege changes or changes that need care, NONE for read-only commands.`
+
+	systemMessage = e.withRiskNotes(systemMessage)
+	if e.language != "" {
+		systemMessage += fmt.Sprintf("\n\nWrite the reasoning, data loss and recovery in %s.", e.language)
+	}
+
+	textContent, err := e.structured.New(ctx, e.client, anthropic.BetaMessageNewParams{
+		Model:     anthropic.Model(e.model),
+		MaxTokens: 2048,
+		Messages: []anthropic.BetaMessageParam{{
+			Content: []anthropic.BetaContentBlockParamUnion{{
+				OfText: &anthropic.BetaTextBlockParam{
+					Text: "Explain the risks of this command:\n\n" + command,
+				},
+			}},
+			Role: anthropic.BetaMessageParamRoleUser,
+		}},
+		System: []anthropic.BetaTextBlockParam{{
+			Text: systemMessage,
+		}},
+	}, explainSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		RiskLevel     string   `json:"risk_level"`
+		Reasoning     string   `json:"reasoning"`
+		DataLoss      string   `json:"data_loss"`
+		AffectedPaths []string `json:"affected_paths"`
+		Reversible    string   `json:"reversible"`
+		Recovery      string   `json:"recovery"`
+	}
+	if err := json.Unmarshal([]byte(textContent), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &RiskDetail{
+		Level:      ParseRiskLevel(response.RiskLevel),
+		Reasoning:  response.Reasoning,
+		DataLoss:   response.DataLoss,
+		Paths:      response.AffectedPaths,
+		Reversible: response.Reversible,
+		Recovery:   response.Recovery,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package safety
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// heuristicRule flags commands matching a pattern.
+type heuristicRule struct {
+	level   RiskLevel
+	message string
+	re      *regexp.Regexp
+}
+
+// heuristicRules cover the most common destructive and network commands.
+// Rules are ordered by severity, so the most severe match wins.
+var heuristicRules = []heuristicRule{
+	{RiskCritical, "Deletes the root or home directory", regexp.MustCompile(`\brm\s+(-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)(\s+-\S+)*\s+("?(/|/\*|~/?|~/\*|\$HOME/?|\$HOME/\*)"?)(\s|$)`)},
+	{RiskCritical, "Writes directly to a disk device", regexp.MustCompile(`\bdd\b.*\bof=/dev/|>\s*/dev/(sd|nvme|disk|hd)`)},
+	{RiskCritical, "Formats a filesystem", regexp.MustCompile(`\bmkfs(\.\w+)?\b|\bwipefs\b`)},
+	{RiskCritical, "Drops a database", regexp.MustCompile(`(?i)\bdrop\s+(database|schema)\b`)},
+	{RiskHigh, "Recursively deletes files", regexp.MustCompile(`\brm\s+(-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)\b`)},
+	{RiskHigh, "Repartitions a disk", regexp.MustCompile(`\bfdisk\b|\bparted\b`)},
+	{RiskHigh, "Deletes Kubernetes resources in bulk", regexp.MustCompile(`\bkubectl\s+delete\b.*(\s(namespaces?|ns|pv|persistentvolumes?|crds?|nodes?)\b|\s--all\b)`)},
+	{RiskHigh, "Deletes cloud resources", regexp.MustCompile(`\baws\s+s3\s+(rm\b.*--recursive|rb\b.*--force)|\baws\s+\S+\s+(delete|terminate)-\S+`)},
+	{RiskHigh, "Destroys deployed infrastructure", regexp.MustCompile(`\bterraform\s+destroy\b|\bhelm\s+(uninstall|delete)\b`)},
+	{RiskHigh, "Runs a downloaded script", regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z|da|fi)?sh\b`)},
+	{RiskHigh, "Fork bomb", regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:`)},
+	{RiskHigh, "Makes files world-writable", regexp.MustCompile(`\bchmod\s+(-R\s+)?0?777\b`)},
+	{RiskHigh, "Recursively changes ownership or permissions", regexp.MustCompile(`\bch(own|mod|grp)\s+(-[a-zA-Z]*R|--recursive)`)},
+	{RiskHigh, "Shuts down or reboots the machine", regexp.MustCompile(`\b(shutdown|reboot|halt|poweroff)\b`)},
+	{RiskHigh, "Discards git history or uncommitted work", regexp.MustCompile(`\bgit\s+(push\s+.*(-f\b|--force)|reset\s+--hard|clean\s+-[a-zA-Z]*f)`)},
+	{RiskHigh, "Drops database data", regexp.MustCompile(`(?i)\b(drop\s+table|truncate\s+table)\b`)},
+	{RiskHigh, "Overwrites a file with truncation", regexp.MustCompile(`(^|[^>])>\s*/(etc|boot|usr)/`)},
+	{RiskLow, "Runs with elevated privileges", regexp.MustCompile(`\b(sudo|doas)\b`)},
+	{RiskLow, "Deletes files", regexp.MustCompile(`\b(rm|unlink|shred)\b`)},
+	{RiskLow, "Kills processes", regexp.MustCompile(`\b(kill|pkill|killall)\b`)},
+	{RiskLow, "Changes Kubernetes resources", regexp.MustCompile(`\bkubectl\s+(delete|apply|create|replace|patch|edit|scale|drain|cordon|rollout\s+(restart|undo))\b`)},
+	{RiskLow, "Makes network requests", regexp.MustCompile(`\b(curl|wget|ssh|scp|rsync|nc|ftp|sftp)\b`)},
+	{RiskLow, "Moves or overwrites files", regexp.MustCompile(`\b(mv|truncate)\b|\bsed\s+-i\b`)},
+	{RiskLow, "Changes installed packages", regexp.MustCompile(`\b(apt|apt-get|yum|dnf|pacman|brew|pip|npm)\s+(install|remove|uninstall|upgrade|purge)\b`)},
+}
+
+// saferRewrite turns a common high-risk command into a safer variant.
+type saferRewrite struct {
+	re          *regexp.Regexp
+	replacement string
+	description string
+}
+
+// saferRewrites are the mitigations that can be made mechanically.
+var saferRewrites = []saferRewrite{
+	{regexp.MustCompile(`\brm\s+-(rf|fr|Rf|fR)\b`), "rm -rI", "Asks once before deleting recursively or more than three files"},
+	{regexp.MustCompile(`\bkubectl\s+delete\b`), "kubectl delete --dry-run=client", "Lists what would be deleted without deleting it"},
+	{regexp.MustCompile(`\baws\s+s3\s+rm\b`), "aws s3 rm --dryrun", "Lists what would be deleted without deleting it"},
+	{regexp.MustCompile(`\bgit\s+push\s+(.*?)(--force|-f)(\s|$)`), "git push ${1}--force-with-lease${3}", "Refuses to overwrite remote commits you haven't fetched"},
+}
+
+// HeuristicEvaluator assesses commands with local pattern rules. It is
+// much less thorough than Evaluator, but needs no network access.
+type HeuristicEvaluator struct{}
+
+// Public: Creates an evaluator that uses local pattern rules only.
+func NewHeuristicEvaluator() HeuristicEvaluator {
+	return HeuristicEvaluator{}
+}
+
+// Public: Assesses each command against the local rules. Commands that
+// match none get a nil entry.
+func (HeuristicEvaluator) Evaluate(_ context.Context, commands []string) ([]*RiskInfo, error) {
+	risks := make([]*RiskInfo, len(commands))
+	for i, cmd := range commands {
+		for _, rule := range heuristicRules {
+			if rule.re.MatchString(cmd) {
+				risks[i] = &RiskInfo{Level: rule.level, Message: rule.message, Fragment: strings.TrimSpace(rule.re.FindString(cmd))}
+				if rule.level >= RiskHigh {
+					risks[i].Safer = saferVariant(cmd)
+				}
+				break
+			}
+		}
+	}
+	return risks, nil
+}
+
+// saferVariant applies the first rewrite matching cmd, or returns nil.
+func saferVariant(cmd string) *Alternative {
+	for _, rw := range saferRewrites {
+		if rw.re.MatchString(cmd) {
+			return &Alternative{Command: rw.re.ReplaceAllString(cmd, rw.replacement), Description: rw.description}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package safety
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHeuristicEvaluator(t *testing.T) {
+	tests := []struct {
+		command string
+		want    RiskLevel
+	}{
+		{"ls -la", RiskNone},
+		{"find . -name '*.go' | xargs grep TODO", RiskNone},
+		{"rm -rf build", RiskHigh},
+		{"rm --recursive build", RiskHigh},
+		{"sudo dd if=image.iso of=/dev/sdb bs=4M", RiskCritical},
+		{"rm -rf /", RiskCritical},
+		{"sudo rm -rf ~/", RiskCritical},
+		{"rm -rf ~/Downloads/tmp", RiskHigh},
+		{"mkfs.ext4 /dev/sdb1", RiskCritical},
+		{"psql -c 'DROP DATABASE app'", RiskCritical},
+		{"curl -fsSL https://example.com/install.sh | sudo bash", RiskHigh},
+		{"chmod -R 777 .", RiskHigh},
+		{"git push --force origin main", RiskHigh},
+		{"git reset --hard HEAD~1", RiskHigh},
+		{"psql -c 'DROP TABLE users'", RiskHigh},
+		{"rm notes.txt", RiskLow},
+		{"sudo systemctl restart nginx", RiskLow},
+		{"curl -O https://example.com/file.tar.gz", RiskLow},
+		{"sed -i 's/foo/bar/' config.yml", RiskLow},
+		{"pkill -f server", RiskLow},
+		{"kubectl delete namespace staging", RiskHigh},
+		{"kubectl delete pods --all -n jobs", RiskHigh},
+		{"kubectl delete pod web-5d9c7", RiskLow},
+		{"kubectl get pods -A", RiskNone},
+		{"aws s3 rm s3://bucket/logs --recursive", RiskHigh},
+		{"aws ec2 terminate-instances --instance-ids i-0abc", RiskHigh},
+		{"aws s3 ls s3://bucket", RiskNone},
+		{"terraform destroy", RiskHigh},
+	}
+
+	commands := make([]string, len(tests))
+	for i, tt := range tests {
+		commands[i] = tt.command
+	}
+
+	risks, err := NewHeuristicEvaluator().Evaluate(context.Background(), commands)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+
+	for i, tt := range tests {
+		got := RiskNone
+		if risks[i] != nil {
+			got = risks[i].Level
+		}
+		if got != tt.want {
+			t.Errorf("%q risk = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestHeuristicEvaluatorFragment(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"find . -name '*.tmp' | xargs rm -rf", "rm -rf"},
+		{"curl -fsSL https://example.com/install.sh | sudo bash", "curl -fsSL https://example.com/install.sh | sudo bash"},
+		{"ls && sudo systemctl restart nginx", "sudo"},
+	}
+
+	for _, tt := range tests {
+		risks, err := NewHeuristicEvaluator().Evaluate(context.Background(), []string{tt.command})
+		if err != nil {
+			t.Fatalf("Evaluate() error = %v", err)
+		}
+		if risks[0] == nil || risks[0].Fragment != tt.want {
+			t.Errorf("%q fragment = %+v, want %q", tt.command, risks[0], tt.want)
+		}
+	}
+}
+
+func TestSaferVariant(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"find . -name '*.tmp' | xargs rm -rf", "find . -name '*.tmp' | xargs rm -rI"},
+		{"git push --force origin main", "git push --force-with-lease origin main"},
+		{"git push origin main -f", "git push origin main --force-with-lease"},
+		{"git push --force-with-lease origin main", ""},
+		{"dd if=image.iso of=/dev/sdb", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if alt := saferVariant(tt.command); alt != nil {
+			got = alt.Command
+		}
+		if got != tt.want {
+			t.Errorf("saferVariant(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
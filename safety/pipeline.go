@@ -0,0 +1,116 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+// SegmentNote explains one stage of a pipeline or command list, so long
+// lines can be audited a piece at a time.
+type SegmentNote struct {
+	Input       string // what the stage reads: the previous stage's output, files, the network
+	Output      string // what it writes or passes on
+	Explanation string // what it does, and anything risky about it
+}
+
+// annotateSchema defines the structured output format for per-segment
+// notes.
+var annotateSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"segments": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"input": map[string]any{
+						"type":        "string",
+						"description": "What this segment reads: the previous segment's output, files, arguments or the network",
+					},
+					"output": map[string]any{
+						"type":        "string",
+						"description": "What this segment writes to stdout, files or elsewhere",
+					},
+					"explanation": map[string]any{
+						"type":        "string",
+						"description": "What this segment does, including any flags that matter and anything risky",
+					},
+				},
+				"required":             []string{"input", "output", "explanation"},
+				"additionalProperties": false,
+			},
+		},
+	},
+	"required":             []string{"segments"},
+	"additionalProperties": false,
+}
+
+// Public: Explains each segment of a command separately: what goes in,
+// what comes out and what it does.
+//
+// command  - The full command line, for context
+// segments - The pieces it was split into at |, &&, || and ;
+//
+// Returns one note per segment, in order.
+func (e *Evaluator) Annotate(ctx context.Context, command string, segments []string) ([]SegmentNote, error) {
+	if e.client == nil {
+		return nil, fmt.Errorf("evaluator client is nil")
+	}
+
+	systemMessage := `You are a shell expert annotating a command line one segment at a time for someone auditing it before running it.
+
+For each segment, in order, say what it reads, what it produces and what it does. Segments joined by | read the previous segment's output; segments joined by && or ; run afterwards. Be concrete about files, paths and hosts, and keep each field to a sentence or two.`
+
+	if e.language != "" {
+		systemMessage += fmt.Sprintf("\n\nWrite every field in %s.", e.language)
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Command:\n" + command + "\n\nSegments:\n")
+	for i, segment := range segments {
+		fmt.Fprintf(&prompt, "%d. %s\n", i+1, segment)
+	}
+
+	textContent, err := e.structured.New(ctx, e.client, anthropic.BetaMessageNewParams{
+		Model:     anthropic.Model(e.model),
+		MaxTokens: 2048,
+		Messages: []anthropic.BetaMessageParam{{
+			Content: []anthropic.BetaContentBlockParamUnion{{
+				OfText: &anthropic.BetaTextBlockParam{
+					Text: prompt.String(),
+				},
+			}},
+			Role: anthropic.BetaMessageParamRoleUser,
+		}},
+		System: []anthropic.BetaTextBlockParam{{
+			Text: systemMessage,
+		}},
+	}, annotateSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Segments []struct {
+			Input       string `json:"input"`
+			Output      string `json:"output"`
+			Explanation string `json:"explanation"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(textContent), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(response.Segments) != len(segments) {
+		return nil, fmt.Errorf("expected %d segment notes, got %d", len(segments), len(response.Segments))
+	}
+
+	notes := make([]SegmentNote, len(segments))
+	for i, s := range response.Segments {
+		notes[i] = SegmentNote{Input: s.Input, Output: s.Output, Explanation: s.Explanation}
+	}
+	return notes, nil
+}
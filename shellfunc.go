@@ -0,0 +1,65 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+// shellFunctions holds the wrapper functions for each shell, built into
+// the binary so they always match its output contract.
+//
+//go:embed shellfunc
+var shellFunctions embed.FS
+
+// shellFunctionFiles names each supported shell's wrapper in
+// shellFunctions.
+var shellFunctionFiles = map[commands.Shell]string{
+	commands.ShellBash:       "shellfunc/bash.sh",
+	commands.ShellZsh:        "shellfunc/zsh.zsh",
+	commands.ShellFish:       "shellfunc/fish.fish",
+	commands.ShellPowerShell: "shellfunc/powershell.ps1",
+}
+
+// printShellFunction writes the wrapper functions for the named shell to
+// w, calling this binary by its full path so they work whatever PATH
+// holds.
+func printShellFunction(w io.Writer, name string) error {
+	sh, err := commands.ParseShell(name)
+	if err != nil {
+		return err
+	}
+	file, ok := shellFunctionFiles[sh]
+	if !ok {
+		return fmt.Errorf("no shell function for %s (want bash, zsh, fish or powershell)", sh.DisplayName())
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the 1lm binary: %w", err)
+	}
+
+	tmpl, err := template.ParseFS(shellFunctions, file)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, struct{ Binary string }{quoteForShell(sh, binary)})
+}
+
+// quoteForShell quotes path as a single word for sh. PowerShell's wrapper
+// calls it with &, which takes a quoted string.
+func quoteForShell(sh commands.Shell, path string) string {
+	switch sh {
+	case commands.ShellPowerShell:
+		return "'" + strings.ReplaceAll(path, "'", "''") + "'"
+	case commands.ShellFish:
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(path) + "'"
+	default:
+		return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+	}
+}
@@ -0,0 +1,23 @@
+# 1lm shell integration for bash, from 1lm --print-shell-function bash.
+# Load it in ~/.bashrc with eval "$(1lm --print-shell-function bash)" so it
+# always matches the installed binary.
+
+1lm() {
+    local output
+    output=$({{.Binary}} "$@" --output=shell-function)
+
+    if [[ -n "$output" ]]; then
+        READLINE_LINE="$output"
+        READLINE_POINT=${#output}
+    fi
+}
+
+fix() {
+    local ret=$? last output
+    last=$(fc -ln -2 -2)
+    output=$({{.Binary}} fix "$ret" "$last" --output=shell-function)
+    if [[ -n "$output" ]]; then
+        READLINE_LINE="$output"
+        READLINE_POINT=${#output}
+    fi
+}
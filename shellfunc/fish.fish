@@ -0,0 +1,21 @@
+# 1lm shell integration for fish, from 1lm --print-shell-function fish.
+# Load it in ~/.config/fish/config.fish with
+# 1lm --print-shell-function fish | source
+# so it always matches the installed binary.
+
+function 1lm
+    # string collect keeps multi-line commands intact
+    set -l output ({{.Binary}} $argv --output=shell-function | string collect)
+
+    if test -n "$output"
+        commandline -r "$output"
+    end
+end
+
+function fix
+    set -l last_status $status
+    set -l output ({{.Binary}} fix $last_status $history[1] --output=shell-function | string collect)
+    if test -n "$output"
+        commandline -r "$output"
+    end
+end
@@ -0,0 +1,17 @@
+# 1lm shell integration for PowerShell, from
+# 1lm --print-shell-function powershell. Load it in $PROFILE with
+# Invoke-Expression (& 1lm --print-shell-function powershell | Out-String)
+# so it always matches the installed binary.
+
+# Type the query at the prompt and press Ctrl+g to replace it with the
+# chosen command.
+Set-PSReadLineKeyHandler -Chord 'Ctrl+g' -ScriptBlock {
+    $line = $null
+    $cursor = $null
+    [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$line, [ref]$cursor)
+
+    $output = (& {{.Binary}} $line --output=shell-function | Out-String).TrimEnd()
+    if ($output) {
+        [Microsoft.PowerShell.PSConsoleReadLine]::Replace(0, $line.Length, $output)
+    }
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/snippets"
+	"github.com/pixielabs/1lm/ui"
+)
+
+const snippetUsage = `usage:
+  1lm snippet save <title> <command> [description]
+  1lm snippet list
+  1lm snippet use [name] [placeholder=value ...]
+  1lm snippet rm <name>`
+
+// runSnippet manages the local snippet library. None of the verbs call the
+// API; "use" emits through the normal output handlers.
+func runSnippet(cfg *config.Config, settings ui.Settings, args []string) error {
+	lib, err := snippets.Load()
+	if err != nil {
+		return err
+	}
+
+	verb, args := args[0], args[1:]
+	switch verb {
+	case "save":
+		if len(args) < 2 {
+			return fmt.Errorf("%s", snippetUsage)
+		}
+		opt := commands.Option{Title: args[0], Command: args[1]}
+		if len(args) > 2 {
+			opt.Description = strings.Join(args[2:], " ")
+		}
+		saved := lib.Add(opt)
+		if err := lib.Save(); err != nil {
+			return fmt.Errorf("failed to save snippet: %w", err)
+		}
+		fmt.Printf("Saved snippet %q\n", saved.Name)
+		return nil
+
+	case "list":
+		if len(lib.Snippets) == 0 {
+			fmt.Println("No snippets saved yet. Press s in the selector to save one.")
+			return nil
+		}
+		for _, s := range lib.Snippets {
+			fmt.Printf("%s\n  %s\n  %s\n", s.Name, s.Title, s.Command)
+		}
+		return nil
+
+	case "use":
+		if len(args) == 0 {
+			return browseSnippets(cfg, lib, settings)
+		}
+		s, ok := lib.Find(args[0])
+		if !ok {
+			return fmt.Errorf("no snippet named %q", args[0])
+		}
+		opt := s.Option()
+		opt.Command = s.Fill(placeholderValues(args[1:]))
+		return release(cfg, "", []commands.Option{opt}, &opt, func() error {
+			return emit("", &opt, settings)
+		})
+
+	case "rm":
+		if len(args) == 0 {
+			return fmt.Errorf("%s", snippetUsage)
+		}
+		if !lib.Remove(args[0]) {
+			return fmt.Errorf("no snippet named %q", args[0])
+		}
+		return lib.Save()
+	}
+
+	return fmt.Errorf("%s", snippetUsage)
+}
+
+// browseSnippets shows the library in the selector, without safety
+// evaluation since each snippet keeps the risk it was saved with.
+func browseSnippets(cfg *config.Config, lib *snippets.Library, settings ui.Settings) error {
+	if len(lib.Snippets) == 0 {
+		return fmt.Errorf("no snippets saved yet")
+	}
+
+	options := make([]commands.Option, len(lib.Snippets))
+	for i, s := range lib.Snippets {
+		options[i] = s.Option()
+	}
+
+	finalModel, err := runUI(ui.NewSelector(options, nil, settings), settings)
+	if err != nil {
+		return err
+	}
+
+	selector, ok := finalModel.(ui.SelectorModel)
+	if !ok {
+		return nil
+	}
+	return release(cfg, "", options, selector.Selected(), func() error {
+		return emit("", selector.Selected(), settings)
+	})
+}
+
+// placeholderValues parses name=value arguments.
+func placeholderValues(args []string) map[string]string {
+	values := make(map[string]string, len(args))
+	for _, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			values[name] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,305 @@
+// Package snippets manages a local library of saved commands that can be
+// reused without calling the API.
+package snippets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// Snippet is a saved command with the context it was generated in.
+type Snippet struct {
+	Name        string    `toml:"name"`
+	Title       string    `toml:"title"`
+	Command     string    `toml:"command"`
+	Description string    `toml:"description,omitempty"`
+	RiskLevel   string    `toml:"risk_level,omitempty"`
+	RiskReason  string    `toml:"risk_reason,omitempty"`
+	Created     time.Time `toml:"created"`
+}
+
+// Library is the set of saved snippets backed by a TOML file.
+type Library struct {
+	path     string
+	Snippets []Snippet `toml:"snippets"`
+}
+
+// Public: Returns the path of the snippet library file.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snippets.toml"), nil
+}
+
+// Public: Loads the snippet library from its default location.
+func Load() (*Library, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFrom(path)
+}
+
+// Public: Loads a snippet library from path. A missing file is an empty
+// library, not an error.
+func LoadFrom(path string) (*Library, error) {
+	lib := &Library{path: path}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return lib, nil
+	}
+
+	if _, err := toml.DecodeFile(path, lib); err != nil {
+		return nil, fmt.Errorf("failed to read snippets: %w", err)
+	}
+
+	return lib, nil
+}
+
+// Public: Writes the library back to the file it was loaded from.
+func (l *Library) Save() (err error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	return toml.NewEncoder(file).Encode(l)
+}
+
+// Public: Adds a snippet built from a command option. The name is derived
+// from the title and made unique within the library.
+//
+// Returns the saved snippet.
+func (l *Library) Add(opt commands.Option) Snippet {
+	s := Snippet{
+		Name:        l.uniqueName(slugify(opt.Title)),
+		Title:       opt.Title,
+		Command:     opt.Command,
+		Description: opt.Description,
+		Created:     time.Now().UTC(),
+	}
+	if opt.Risk != nil {
+		s.RiskLevel = strings.ToLower(opt.Risk.Level.String())
+		s.RiskReason = opt.Risk.Message
+	}
+
+	l.Snippets = append(l.Snippets, s)
+	return s
+}
+
+// Public: Finds a snippet by name.
+func (l *Library) Find(name string) (Snippet, bool) {
+	for _, s := range l.Snippets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Snippet{}, false
+}
+
+// Public: Removes a snippet by name, reporting whether it existed.
+func (l *Library) Remove(name string) bool {
+	for i, s := range l.Snippets {
+		if s.Name == name {
+			l.Snippets = append(l.Snippets[:i], l.Snippets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Public: Merges another machine's library, in the same TOML format, into
+// this one. base is the library as it was last synced, which tells a
+// snippet one side removed from one the other side added: snippets added
+// on either side are kept, and those removed on either side are dropped.
+// Where both sides have a snippet of the same name, this library's wins.
+// Either file may be empty.
+func (l *Library) Merge(remote, base []byte) error {
+	var theirs, synced Library
+	if _, err := toml.Decode(string(remote), &theirs); err != nil {
+		return fmt.Errorf("failed to read synced snippets: %w", err)
+	}
+	if _, err := toml.Decode(string(base), &synced); err != nil {
+		return fmt.Errorf("failed to read synced snippets: %w", err)
+	}
+
+	var merged []Snippet
+	for _, s := range l.Snippets {
+		_, inRemote := theirs.Find(s.Name)
+		_, wasSynced := synced.Find(s.Name)
+		if inRemote || !wasSynced {
+			merged = append(merged, s)
+		}
+	}
+	for _, s := range theirs.Snippets {
+		_, inLocal := l.Find(s.Name)
+		_, wasSynced := synced.Find(s.Name)
+		if !inLocal && !wasSynced {
+			merged = append(merged, s)
+		}
+	}
+
+	l.Snippets = merged
+	return nil
+}
+
+// uniqueName appends a numeric suffix when base is already taken.
+func (l *Library) uniqueName(base string) string {
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := l.Find(name); !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a title into a short, shell-friendly name.
+func slugify(title string) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if slug == "" {
+		return "snippet"
+	}
+	return slug
+}
+
+// Public: Converts the snippet back to a command option for the selector
+// and output handlers, restoring its saved risk.
+func (s Snippet) Option() commands.Option {
+	opt := commands.Option{
+		Title:       s.Title,
+		Command:     s.Command,
+		Description: s.Description,
+	}
+	if level := safety.ParseRiskLevel(s.RiskLevel); level != safety.RiskNone {
+		opt.Risk = &safety.RiskInfo{Level: level, Message: s.RiskReason}
+	}
+	return opt
+}
+
+// placeholderPattern matches tldr-style {{name}} placeholders.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// Public: Returns the names of the {{placeholders}} in the command, in
+// order and without duplicates.
+func (s Snippet) Placeholders() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(s.Command, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Public: Substitutes placeholder values into the command. Placeholders
+// without a value are left in place for the user to edit.
+func (s Snippet) Fill(values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s.Command, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return match
+	})
+}
+
+// searchWord splits queries and snippets into words for Search.
+var searchWord = regexp.MustCompile(`[a-z0-9]+`)
+
+// Public: Returns the snippets sharing words with query, best match first.
+// A snippet must share at least half of the query's words to be returned.
+func (l *Library) Search(query string) []Snippet {
+	words := uniqueWords(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	type match struct {
+		snippet Snippet
+		score   int
+	}
+	var matches []match
+	for _, s := range l.Snippets {
+		score := s.shared(words)
+		if score > 0 && score*2 >= len(words) {
+			matches = append(matches, match{s, score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int { return b.score - a.score })
+
+	results := make([]Snippet, len(matches))
+	for i, m := range matches {
+		results[i] = m.snippet
+	}
+	return results
+}
+
+// Public: Returns the one snippet sharing every word of query, for
+// offering it without asking the model. When several do, none is
+// returned, since there's no telling which was meant.
+func (l *Library) BestMatch(query string) (Snippet, bool) {
+	words := uniqueWords(query)
+	if len(words) == 0 {
+		return Snippet{}, false
+	}
+
+	var best []Snippet
+	for _, s := range l.Snippets {
+		if s.shared(words) == len(words) {
+			best = append(best, s)
+		}
+	}
+	if len(best) != 1 {
+		return Snippet{}, false
+	}
+	return best[0], true
+}
+
+// shared counts the words the snippet's name, title, description and
+// command have in common with words.
+func (s Snippet) shared(words map[string]bool) int {
+	text := uniqueWords(strings.Join([]string{s.Name, s.Title, s.Description, s.Command}, " "))
+	count := 0
+	for w := range words {
+		if text[w] {
+			count++
+		}
+	}
+	return count
+}
+
+// uniqueWords returns the lowercase words in s.
+func uniqueWords(s string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range searchWord.FindAllString(strings.ToLower(s), -1) {
+		words[w] = true
+	}
+	return words
+}
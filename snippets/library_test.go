@@ -0,0 +1,207 @@
+package snippets
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestLibraryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snippets.toml")
+
+	lib, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error = %v", err)
+	}
+
+	saved := lib.Add(commands.Option{
+		Title:       "Delete build dir",
+		Command:     "rm -rf build",
+		Description: "Removes build output",
+		Risk:        &safety.RiskInfo{Level: safety.RiskHigh, Message: "deletes files"},
+	})
+	if saved.Name != "delete-build-dir" {
+		t.Errorf("Add() name = %q, want %q", saved.Name, "delete-build-dir")
+	}
+
+	if err := lib.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	got, ok := reloaded.Find("delete-build-dir")
+	if !ok {
+		t.Fatal("Find() did not find saved snippet")
+	}
+
+	opt := got.Option()
+	if opt.Command != "rm -rf build" {
+		t.Errorf("Option().Command = %q, want %q", opt.Command, "rm -rf build")
+	}
+	if opt.Risk == nil || opt.Risk.Level != safety.RiskHigh {
+		t.Errorf("Option().Risk = %v, want high risk", opt.Risk)
+	}
+}
+
+func TestAddUniqueNames(t *testing.T) {
+	lib := &Library{}
+	first := lib.Add(commands.Option{Title: "List files"})
+	second := lib.Add(commands.Option{Title: "List files"})
+	third := lib.Add(commands.Option{Title: "!!!"})
+
+	if first.Name != "list-files" || second.Name != "list-files-2" || third.Name != "snippet" {
+		t.Errorf("names = %q, %q, %q", first.Name, second.Name, third.Name)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	lib := &Library{}
+	lib.Add(commands.Option{Title: "a"})
+
+	if !lib.Remove("a") {
+		t.Error("Remove() existing = false, want true")
+	}
+	if lib.Remove("a") {
+		t.Error("Remove() missing = true, want false")
+	}
+}
+
+func TestLibraryMerge(t *testing.T) {
+	lib := &Library{Snippets: []Snippet{
+		{Name: "kept", Command: "ls"},
+		{Name: "shared", Command: "ls -la"},
+		{Name: "removed-there", Command: "rm -rf build"},
+		{Name: "added-here", Command: "df -h"},
+	}}
+	remote := `
+[[snippets]]
+name = "kept"
+command = "ls"
+
+[[snippets]]
+name = "shared"
+command = "ls -l"
+
+[[snippets]]
+name = "added-there"
+command = "du -sh ."
+
+[[snippets]]
+name = "removed-here"
+command = "git clean -fdx"
+`
+	base := `
+[[snippets]]
+name = "kept"
+command = "ls"
+
+[[snippets]]
+name = "removed-there"
+command = "rm -rf build"
+
+[[snippets]]
+name = "removed-here"
+command = "git clean -fdx"
+`
+
+	if err := lib.Merge([]byte(remote), []byte(base)); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	var names []string
+	for _, s := range lib.Snippets {
+		names = append(names, s.Name)
+	}
+	if want := []string{"kept", "shared", "added-here", "added-there"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("merged names = %v, want %v", names, want)
+	}
+	if s, _ := lib.Find("shared"); s.Command != "ls -la" {
+		t.Errorf("shared command = %q, want the local one", s.Command)
+	}
+}
+
+func TestMergeInvalid(t *testing.T) {
+	lib := &Library{}
+	if err := lib.Merge([]byte("snippets = "), nil); err == nil {
+		t.Error("Merge() error = nil, want a parse error")
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	s := Snippet{Command: "tar czf {{archive}} {{ dir }} && ls {{archive}}"}
+
+	if got, want := s.Placeholders(), []string{"archive", "dir"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Placeholders() = %v, want %v", got, want)
+	}
+
+	got := s.Fill(map[string]string{"archive": "out.tgz"})
+	want := "tar czf out.tgz {{ dir }} && ls out.tgz"
+	if got != want {
+		t.Errorf("Fill() = %q, want %q", got, want)
+	}
+}
+
+func TestLibrarySearch(t *testing.T) {
+	lib := &Library{Snippets: []Snippet{
+		{Name: "big-files", Title: "Find large files", Command: "find . -size +100M"},
+		{Name: "disk-usage", Title: "Disk usage by directory", Command: "du -sh * | sort -h"},
+		{Name: "large-logs", Title: "Large log files", Command: "find /var/log -size +10M -name '*.log'"},
+	}}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"find large files", []string{"big-files", "large-logs"}},
+		{"disk usage", []string{"disk-usage"}},
+		{"restart nginx", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []string
+			for _, s := range lib.Search(tt.query) {
+				got = append(got, s.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLibraryBestMatch(t *testing.T) {
+	lib := &Library{Snippets: []Snippet{
+		{Name: "big-files", Title: "Find large files", Command: "find . -size +100M"},
+		{Name: "disk-usage", Title: "Disk usage by directory", Command: "du -sh * | sort -h"},
+		{Name: "large-logs", Title: "Large log files", Command: "find /var/log -size +10M -name '*.log'"},
+	}}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Disk usage", "disk-usage"},
+		{"large log files", "large-logs"},
+		{"find large files", ""}, // both find snippets cover it
+		{"disk usage of home", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			s, ok := lib.BestMatch(tt.query)
+			if ok != (tt.want != "") || s.Name != tt.want {
+				t.Errorf("BestMatch(%q) = %q, %v, want %q", tt.query, s.Name, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package snippets
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Literal is a value in a command, such as a path, hostname or port, that
+// is likely specific to the day it was run and better as a placeholder.
+type Literal struct {
+	Text string // the value as written, e.g. "/var/log/app.log"
+	Kind string // "path", "host", "port" or "url"
+
+	start, end int // byte offsets of Text in the command
+}
+
+var (
+	urlPattern      = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://\S+$`)
+	ipv4Pattern     = regexp.MustCompile(`^\d{1,3}(\.\d{1,3}){3}$`)
+	hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9-]+\.)+[a-zA-Z]{2,}$`)
+	filenamePattern = regexp.MustCompile(`^[\w.-]*[\w-]\.[a-zA-Z0-9]{1,5}$`)
+	portPattern     = regexp.MustCompile(`^\d{2,5}$`)
+	// hostPortPattern matches host:port, as in "localhost:8080".
+	hostPortPattern = regexp.MustCompile(`^([\w.-]+):(\d{2,5})$`)
+	// userHostPattern matches user@host, optionally followed by scp's
+	// :path.
+	userHostPattern = regexp.MustCompile(`^[\w.-]+@([\w.-]+)(?::(.+))?$`)
+)
+
+// hostCommands take bare hostnames as arguments, so a dotted word given to
+// them is a host even where it could be a file name.
+var hostCommands = []string{"ssh", "sftp", "ping", "dig", "host", "nslookup", "nc", "ncat", "telnet", "traceroute", "mtr", "whois", "curl", "wget"}
+
+// portFlags are flags whose value is a port.
+var portFlags = []string{"-p", "-P", "--port"}
+
+// genericPaths are paths that mean the same thing on any machine, so
+// aren't worth a placeholder.
+var genericPaths = []string{"/", ".", "..", "~", "/dev/null", "/dev/stdin", "/dev/stdout", "/dev/stderr", "/dev/tty", "/tmp"}
+
+// Public: Finds the paths, hostnames, ports and URLs among a command's
+// arguments, in order. Each distinct value is listed once. Flags, globs,
+// existing {{placeholders}} and generic paths like /dev/null are skipped.
+// Commands that don't parse have no literals.
+func Literals(command string) []Literal {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil
+	}
+
+	var found []Literal
+	add := func(lit Literal) {
+		if lit.Text == "" || slices.ContainsFunc(found, func(l Literal) bool { return l.Text == lit.Text }) {
+			return
+		}
+		found = append(found, lit)
+	}
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok || len(call.Args) < 2 {
+			return true
+		}
+		bin := path.Base(call.Args[0].Lit())
+		for i, arg := range call.Args[1:] {
+			text, start, ok := wordValue(arg)
+			if !ok {
+				continue
+			}
+			prev, _, _ := wordValue(call.Args[i])
+			for _, lit := range classify(text, bin, prev) {
+				lit.start += start
+				lit.end += start
+				add(lit)
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// wordValue returns the value of a word that is a single literal, quoted
+// or not, and its offset in the command. Words with expansions or several
+// parts aren't literals.
+func wordValue(word *syntax.Word) (string, int, bool) {
+	if len(word.Parts) != 1 {
+		return "", 0, false
+	}
+	switch part := word.Parts[0].(type) {
+	case *syntax.Lit:
+		if strings.Contains(part.Value, `\`) {
+			return "", 0, false
+		}
+		return part.Value, int(part.Pos().Offset()), true
+	case *syntax.SglQuoted:
+		if part.Dollar {
+			return "", 0, false
+		}
+		return part.Value, int(part.Left.Offset()) + 1, true
+	case *syntax.DblQuoted:
+		if len(part.Parts) != 1 {
+			return "", 0, false
+		}
+		if lit, ok := part.Parts[0].(*syntax.Lit); ok && !strings.Contains(lit.Value, `\`) {
+			return lit.Value, int(lit.Pos().Offset()), true
+		}
+	}
+	return "", 0, false
+}
+
+// classify returns the literals in one argument, text, passed to bin after
+// the argument prev. Offsets are relative to text.
+func classify(text, bin, prev string) []Literal {
+	whole := func(kind string) []Literal {
+		return []Literal{{Text: text, Kind: kind, start: 0, end: len(text)}}
+	}
+
+	if strings.Contains(text, "{{") || strings.ContainsAny(text, "*?[") {
+		return nil
+	}
+
+	// --port=8080, --output=/tmp/x.csv
+	if name, value, ok := strings.Cut(text, "="); ok && strings.HasPrefix(name, "-") {
+		lits := classify(value, bin, name)
+		for i := range lits {
+			lits[i].start += len(name) + 1
+			lits[i].end += len(name) + 1
+		}
+		return lits
+	}
+	if strings.HasPrefix(text, "-") {
+		return nil
+	}
+
+	switch {
+	case slices.Contains(portFlags, prev) && portPattern.MatchString(text):
+		return whole("port")
+	case urlPattern.MatchString(text):
+		return whole("url")
+	case userHostPattern.MatchString(text):
+		m := userHostPattern.FindStringSubmatchIndex(text)
+		lits := []Literal{{Text: text[m[2]:m[3]], Kind: "host", start: m[2], end: m[3]}}
+		if m[4] >= 0 && !slices.Contains(genericPaths, text[m[4]:m[5]]) {
+			lits = append(lits, Literal{Text: text[m[4]:m[5]], Kind: "path", start: m[4], end: m[5]})
+		}
+		return lits
+	case hostPortPattern.MatchString(text):
+		m := hostPortPattern.FindStringSubmatchIndex(text)
+		return []Literal{
+			{Text: text[m[2]:m[3]], Kind: "host", start: m[2], end: m[3]},
+			{Text: text[m[4]:m[5]], Kind: "port", start: m[4], end: m[5]},
+		}
+	case ipv4Pattern.MatchString(text):
+		return whole("host")
+	case slices.Contains(genericPaths, text):
+		return nil
+	case strings.Contains(text, "/") || strings.HasPrefix(text, "~"):
+		return whole("path")
+	case hostnamePattern.MatchString(text) && slices.Contains(hostCommands, bin):
+		return whole("host")
+	case filenamePattern.MatchString(text):
+		return whole("path")
+	}
+	return nil
+}
+
+// Public: Replaces each literal in command with a {{placeholder}} named
+// after its kind: path, host, port or url, numbered from the second of a
+// kind (path2). Every occurrence of a literal's value as a whole argument
+// is replaced, so a path used twice becomes one placeholder.
+//
+// command  - The command the literals were found in
+// literals - Literals from Literals(command), or a subset of them
+//
+// Returns the command with placeholders.
+func Parameterize(command string, literals []Literal) string {
+	names := map[string]string{} // literal text to placeholder name
+	counts := map[string]int{}
+	for _, lit := range literals {
+		counts[lit.Kind]++
+		name := lit.Kind
+		if counts[lit.Kind] > 1 {
+			name = fmt.Sprintf("%s%d", lit.Kind, counts[lit.Kind])
+		}
+		names[lit.Text] = name
+	}
+
+	// Every occurrence, not only the first one found, is replaced.
+	var spans []Literal
+	for _, lit := range Literals(command) {
+		if _, ok := names[lit.Text]; ok {
+			spans = append(spans, lit)
+		}
+	}
+	spans = append(spans, repeats(command, spans)...)
+	slices.SortFunc(spans, func(a, b Literal) int { return b.start - a.start })
+
+	for _, span := range spans {
+		command = command[:span.start] + "{{" + names[span.Text] + "}}" + command[span.end:]
+	}
+	return command
+}
+
+// repeats finds later occurrences of the literals as whole arguments,
+// which Literals lists only once.
+func repeats(command string, literals []Literal) []Literal {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil
+	}
+
+	var found []Literal
+	syntax.Walk(file, func(node syntax.Node) bool {
+		word, ok := node.(*syntax.Word)
+		if !ok {
+			return true
+		}
+		text, start, ok := wordValue(word)
+		if !ok {
+			return true
+		}
+		for _, lit := range literals {
+			if lit.Text == text && lit.start != start {
+				found = append(found, Literal{Text: text, Kind: lit.Kind, start: start, end: start + len(text)})
+			}
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,84 @@
+package snippets
+
+import (
+	"testing"
+)
+
+func TestLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string // kind:text
+	}{
+		{"file path", "tail -f /var/log/app.log", []string{"path:/var/log/app.log"}},
+		{"file name", "wc -l report-2024.csv", []string{"path:report-2024.csv"}},
+		{"ssh host", "ssh deploy@web1.example.com", []string{"host:web1.example.com"}},
+		{"scp host and path", "scp build.tgz admin@10.0.0.5:/srv/app", []string{"path:build.tgz", "host:10.0.0.5", "path:/srv/app"}},
+		{"port flag", "ssh -p 2222 bastion.example.com", []string{"port:2222", "host:bastion.example.com"}},
+		{"port in flag value", "python3 -m http.server --port=8000", []string{"port:8000"}},
+		{"host and port", "nc -zv localhost:5432", []string{"host:localhost", "port:5432"}},
+		{"url", "curl -sS https://api.example.com/v1/health", []string{"url:https://api.example.com/v1/health"}},
+		{"quoted path", `grep -r "TODO" 'src/main app'`, []string{"path:src/main app"}},
+		{"listed once", "diff a/x.txt a/x.txt", []string{"path:a/x.txt"}},
+		{"generic paths skipped", "find / -name core 2>/dev/null", nil},
+		{"globs skipped", "rm -f *.tmp", nil},
+		{"placeholders skipped", "tail -f {{file}}", nil},
+		{"expansions skipped", `cp "$src" ~/backup/`, []string{"path:~/backup/"}},
+		{"plain words", "git log --oneline", nil},
+		{"invalid syntax", "echo 'unterminated /tmp/x.txt", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, lit := range Literals(tt.command) {
+				got = append(got, lit.Kind+":"+lit.Text)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Literals(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Literals(%q) = %q, want %q", tt.command, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParameterize(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"path", "tail -f /var/log/app.log", "tail -f {{path}}"},
+		{"numbered", "cp old.txt backups/new.txt", "cp {{path}} {{path2}}"},
+		{"repeated", "cp app.conf app.conf.bak && vim app.conf", "cp {{path}} {{path2}} && vim {{path}}"},
+		{"parts of a word", "scp dump.sql root@db.internal:/tmp/dump.sql", "scp {{path}} root@{{host}}:{{path2}}"},
+		{"flag value", "serve --port=8080 --root=./public", "serve --port={{port}} --root={{path}}"},
+		{"quoted", `du -sh "My Documents/photos"`, `du -sh "{{path}}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parameterize(tt.command, Literals(tt.command)); got != tt.want {
+				t.Errorf("Parameterize(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParameterizeSubset(t *testing.T) {
+	command := "ssh -p 2222 bastion.example.com"
+	var hosts []Literal
+	for _, lit := range Literals(command) {
+		if lit.Kind == "host" {
+			hosts = append(hosts, lit)
+		}
+	}
+
+	want := "ssh -p 2222 {{host}}"
+	if got := Parameterize(command, hosts); got != want {
+		t.Errorf("Parameterize() = %q, want %q", got, want)
+	}
+}
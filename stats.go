@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/history"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// statsTop is how many topics and tools "1lm stats" lists.
+const statsTop = 5
+
+// runStats runs "1lm stats", which summarizes usage from the local
+// history: what is asked about, which tools are chosen, how often each
+// option position is picked, how long answers take and what they cost.
+func runStats(cfg *config.Config, _ ui.Settings, _ []string) error {
+	if cfg.History == "off" {
+		fmt.Println(`Stats come from the history, which is turned off (history = "off" in the config).`)
+		return nil
+	}
+
+	past, err := history.Load()
+	if err != nil {
+		return err
+	}
+	if len(past.Entries) == 0 {
+		fmt.Println("No history yet.")
+		return nil
+	}
+
+	s := past.Stats(statsTop)
+	fmt.Printf("Queries: %d, with a command chosen for %d (%.0f%%)\n", s.Queries, s.Chosen, percent(s.Chosen, s.Queries))
+	if s.Latency > 0 {
+		fmt.Printf("Average time to answer: %s\n", s.Latency.Round(100*time.Millisecond))
+	}
+
+	printCounts("Top topics", s.Topics)
+	printCounts("Top tools", s.Tools)
+
+	if len(s.Positions) > 0 {
+		fmt.Println("\nChosen by position:")
+		for _, a := range s.Positions {
+			fmt.Printf("  %d  %3.0f%% of %d\n", a.Position, 100*a.Rate(), a.Offered)
+		}
+	}
+
+	fmt.Println("\nBy month:")
+	for _, m := range s.Months {
+		fmt.Printf("  %s  %4d queries  %9d tokens  $%.2f\n", m.Month, m.Queries, m.InputTokens+m.OutputTokens, m.Cost)
+	}
+	return nil
+}
+
+// printCounts prints a titled list of counts, if there are any.
+func printCounts(title string, counts []history.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for _, c := range counts {
+		fmt.Printf("  %-16s %d\n", c.Name, c.Count)
+	}
+}
+
+// percent returns n as a percentage of total.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxStdin bounds how much piped input is read.
+const maxStdin = 1 << 20
+
+// readStdin reads piped input, such as error output or a query.
+func readStdin() (string, error) {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdin))
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return string(data), nil
+}
+
+// readStdinQuery reads the query for "1lm -". Programs driving 1lm can
+// pipe it in without worrying about shell quoting.
+func readStdinQuery() (string, error) {
+	if *stdinContext {
+		return "", fmt.Errorf("stdin can't be both the query (-) and --stdin-context")
+	}
+
+	query, err := readStdin()
+	if err != nil {
+		return "", err
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("no query on stdin")
+	}
+	return query, nil
+}
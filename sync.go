@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/history"
+	"github.com/pixielabs/1lm/remote"
+	"github.com/pixielabs/1lm/snippets"
+	"github.com/pixielabs/1lm/ui"
+)
+
+// syncTimeout bounds a whole sync, clone included.
+const syncTimeout = 2 * time.Minute
+
+// maxSyncAttempts bounds how many times a file is merged again because
+// another machine uploaded it while this one was merging.
+const maxSyncAttempts = 3
+
+// runSync runs "1lm sync", which merges the history and snippets in the
+// sync_url backend with the local ones and uploads the result. The local
+// files are always merged into, never replaced, so a sync can't lose
+// anything this machine has.
+func runSync(cfg *config.Config, _ ui.Settings, _ []string) error {
+	if cfg.SyncURL == "" {
+		return errors.New("nothing to sync with: set sync_url in the config")
+	}
+	if *offline {
+		return errors.New("can't sync with --offline")
+	}
+
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(cacheDir, "sync")
+	backend, err := remote.Open(cfg.SyncURL, filepath.Join(dir, "repo"))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	entries := 0
+	if cfg.History != "off" {
+		if entries, err = syncHistory(ctx, backend, cfg.RedactSecrets == "off"); err != nil {
+			return err
+		}
+	}
+	count, err := syncSnippets(ctx, backend, dir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Synced %d history entries and %d snippets.\n", entries, count)
+	return nil
+}
+
+// syncHistory merges the backend's history into the local one and uploads
+// the result, returning how many entries it holds. Secrets in the uploaded
+// copy are replaced with placeholders unless keepSecrets is set; the local
+// history keeps them.
+func syncHistory(ctx context.Context, backend remote.Backend, keepSecrets bool) (int, error) {
+	past, err := history.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	err = retryConflicts(func() error {
+		theirs, err := download(ctx, backend, "history.jsonl")
+		if err != nil {
+			return err
+		}
+		if err := past.Merge(theirs); err != nil {
+			return err
+		}
+		shared, err := past.Export(keepSecrets)
+		if err != nil {
+			return err
+		}
+		return put(ctx, backend, "history.jsonl", shared)
+	})
+	return len(past.Entries), err
+}
+
+// retryConflicts runs sync, a download, merge and upload, again when the
+// upload finds another machine uploaded first, so their changes are
+// merged rather than overwritten.
+func retryConflicts(sync func() error) error {
+	var err error
+	for range maxSyncAttempts {
+		if err = sync(); !errors.Is(err, remote.ErrConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// syncSnippets merges the backend's snippets into the local library and
+// uploads the result, returning how many snippets it holds. The library
+// as last synced is kept in dir, so snippets removed on one machine stay
+// removed rather than coming back from the other.
+func syncSnippets(ctx context.Context, backend remote.Backend, dir string) (int, error) {
+	lib, err := snippets.Load()
+	if err != nil {
+		return 0, err
+	}
+	basePath := filepath.Join(dir, "snippets.toml")
+	base, err := os.ReadFile(basePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	path, err := snippets.Path()
+	if err != nil {
+		return 0, err
+	}
+
+	err = retryConflicts(func() error {
+		theirs, err := download(ctx, backend, "snippets.toml")
+		if err != nil {
+			return err
+		}
+		if err := lib.Merge(theirs, base); err != nil {
+			return err
+		}
+		if err := lib.Save(); err != nil {
+			return fmt.Errorf("failed to save snippets: %w", err)
+		}
+		return upload(ctx, backend, path, "snippets.toml")
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return len(lib.Snippets), os.WriteFile(basePath, data, 0600)
+}
+
+// download returns the backend's copy of name, or nothing if it has none
+// yet.
+func download(ctx context.Context, backend remote.Backend, name string) ([]byte, error) {
+	data, err := backend.Get(ctx, name)
+	if errors.Is(err, remote.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// upload replaces the backend's copy of name with the file at path.
+func upload(ctx context.Context, backend remote.Backend, path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return put(ctx, backend, name, data)
+}
+
+// put replaces the backend's copy of name with data.
+func put(ctx context.Context, backend remote.Backend, name string, data []byte) error {
+	if err := backend.Put(ctx, name, data); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	return nil
+}
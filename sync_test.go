@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pixielabs/1lm/remote"
+)
+
+func TestRetryConflicts(t *testing.T) {
+	failed := errors.New("boom")
+
+	tests := []struct {
+		name  string
+		errs  []error
+		calls int
+		want  error
+	}{
+		{name: "succeeds", errs: []error{nil}, calls: 1},
+		{name: "merges again after a conflict", errs: []error{remote.ErrConflict, nil}, calls: 2},
+		{name: "stops at other errors", errs: []error{failed}, calls: 1, want: failed},
+		{
+			name:  "gives up after repeated conflicts",
+			errs:  []error{remote.ErrConflict, remote.ErrConflict, remote.ErrConflict, nil},
+			calls: maxSyncAttempts,
+			want:  remote.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryConflicts(func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("retryConflicts() = %v, want %v", err, tt.want)
+			}
+			if calls != tt.calls {
+				t.Errorf("sync ran %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
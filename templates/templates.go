@@ -0,0 +1,127 @@
+// Package templates holds a built-in set of common one-liners, such as
+// find, grep, tar, ffmpeg and git patterns, matched against a query by
+// keyword so there's something to offer without the network.
+package templates
+
+import (
+	_ "embed"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed templates.toml
+var data []byte
+
+// Template is a one-liner with example values to change, and the words a
+// query for it is likely to use.
+type Template struct {
+	Title       string   `toml:"title"`
+	Command     string   `toml:"command"`
+	Description string   `toml:"description"`
+	Keywords    []string `toml:"keywords"`
+}
+
+// Match is a template found for a query, with Score the share of the
+// query's words it covers, from 0 to 1.
+type Match struct {
+	Template
+	Score float64
+}
+
+// MinScore is the share of a query's words a template must cover to be
+// offered at all.
+const MinScore = 0.5
+
+// load parses the embedded templates once. The file is part of the
+// binary and checked by the tests, so it can't fail to parse.
+var load = sync.OnceValue(func() []Template {
+	var file struct {
+		Templates []Template `toml:"templates"`
+	}
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		panic("templates: invalid templates.toml: " + err.Error())
+	}
+	return file.Templates
+})
+
+// Public: Returns every built-in template.
+func All() []Template {
+	return slices.Clone(load())
+}
+
+// Public: Finds the templates covering at least MinScore of the query's
+// words, matched against their keywords and titles.
+//
+// Returns the matches, best first.
+func Search(query string) []Match {
+	words := significantWords(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	for _, t := range load() {
+		vocabulary := significantWords(strings.Join(t.Keywords, " ") + " " + t.Title)
+		covered := 0
+		for _, w := range words {
+			if slices.Contains(vocabulary, w) {
+				covered++
+			}
+		}
+		if score := float64(covered) / float64(len(words)); covered > 0 && score >= MinScore {
+			matches = append(matches, Match{Template: t, Score: score})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return matches
+}
+
+var word = regexp.MustCompile(`[a-z0-9]+`)
+
+// stopWords are too common in queries to say which template is wanted.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "in": true, "of": true, "to": true,
+	"for": true, "and": true, "or": true, "with": true, "on": true,
+	"by": true, "from": true, "into": true, "that": true, "than": true,
+	"this": true, "my": true, "me": true, "i": true, "do": true, "is": true,
+	"are": true, "it": true, "its": true, "some": true, "please": true,
+	"can": true, "you": true,
+}
+
+// significantWords returns the distinct words in s, lowercased and
+// singular, without stop words.
+func significantWords(s string) []string {
+	var words []string
+	for _, w := range word.FindAllString(strings.ToLower(s), -1) {
+		if stopWords[w] {
+			continue
+		}
+		w = singular(w)
+		if !slices.Contains(words, w) {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// singular drops a plural "s", so "files" matches "file". It is only
+// compared with itself, so "process" becoming "proces" is harmless.
+func singular(w string) string {
+	if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+		return w[:len(w)-1]
+	}
+	return w
+}
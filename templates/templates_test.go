@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+func TestTemplatesAreComplete(t *testing.T) {
+	all := All()
+	if len(all) < 30 {
+		t.Fatalf("got %d templates, want the full set", len(all))
+	}
+
+	parser := syntax.NewParser(syntax.Variant(syntax.LangBash))
+	titles := map[string]bool{}
+	for _, tmpl := range all {
+		if tmpl.Title == "" || tmpl.Command == "" || tmpl.Description == "" || len(tmpl.Keywords) == 0 {
+			t.Errorf("incomplete template: %+v", tmpl)
+		}
+		if titles[tmpl.Title] {
+			t.Errorf("duplicate title %q", tmpl.Title)
+		}
+		titles[tmpl.Title] = true
+		if _, err := parser.Parse(strings.NewReader(tmpl.Command), ""); err != nil {
+			t.Errorf("%q doesn't parse: %v", tmpl.Command, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // title of the best match; empty for none
+	}{
+		{"find large files", "Find large files"},
+		{"find files larger than 500MB", "Find large files"},
+		{"extract a tar.gz archive", "Extract a tar.gz archive"},
+		{"Extract the audio from a video", "Extract audio from a video"},
+		{"undo my last git commit", "Undo the last commit, keeping changes"},
+		{"what is listening on port 3000", "What's listening on a port"},
+		{"search git history for a string", "Search git history for a string"},
+		{"write a haiku about autumn", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := Search(tt.query)
+			if tt.want == "" {
+				if len(matches) != 0 {
+					t.Errorf("Search() = %q first, want no matches", matches[0].Title)
+				}
+				return
+			}
+			if len(matches) == 0 {
+				t.Fatalf("Search() found nothing, want %q", tt.want)
+			}
+			if matches[0].Title != tt.want {
+				t.Errorf("best match = %q, want %q", matches[0].Title, tt.want)
+			}
+			for i, m := range matches {
+				if m.Score < MinScore || m.Score > 1 || (i > 0 && m.Score > matches[i-1].Score) {
+					t.Errorf("match %d %q has score %v out of order or range", i, m.Title, m.Score)
+				}
+			}
+		})
+	}
+}
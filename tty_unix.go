@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// openTTY opens the terminal directly, to draw the UI and read keys when
+// stdout is kept for the command. The same file serves both.
+func openTTY() (in, out *os.File, err error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tty, tty, nil
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// openTTY opens the console directly, to draw the UI and read keys when
+// stdout is kept for the command. Windows has no /dev/tty: the console's
+// input and screen buffer are opened separately, as CONIN$ and CONOUT$.
+func openTTY() (in, out *os.File, err error) {
+	in, err = os.OpenFile("CONIN$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err = os.OpenFile("CONOUT$", os.O_RDWR, 0)
+	if err != nil {
+		_ = in.Close()
+		return nil, nil, err
+	}
+	return in, out, nil
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/config"
+	"github.com/pixielabs/1lm/i18n"
+	"github.com/pixielabs/1lm/output"
+	"github.com/pixielabs/1lm/ui"
+	"golang.org/x/term"
+)
+
+// newSettings builds the UI settings from flags and config.
+func newSettings(cfg *config.Config) ui.Settings {
+	// Accessible mode implies plain, static output: animations and emoji
+	// are noise to a screen reader.
+	// Quiet mode is for scripts, so it skips animation as well.
+	isAccessible := *accessible || cfg.Accessible
+	return ui.Settings{
+		Static:     lowPower || isAccessible || *quiet,
+		Plain:      *plain || isAccessible || cfg.ASCIISymbolsEnabled(),
+		Accessible: isAccessible,
+		AltScreen:  *altScreen || cfg.AltScreen || *script,
+		Preview:    *preview || cfg.Preview,
+		Mouse:      *mouse || cfg.Mouse,
+		Quiet:      *quiet,
+		Language:   cfg.Language,
+		// Scripts are too long to judge folded.
+		FullCommands: *script,
+	}
+}
+
+// selectedOutputMode returns the mode set with --output, except that
+// --file writes to a file, and --script prints rather than copying, since
+// a script is saved or piped rather than pasted into the prompt. Without a
+// terminal, or with stdout piped, the command is printed too, since it's
+// going to a program rather than being pasted.
+func selectedOutputMode() output.Mode {
+	mode := output.Mode(*outputMode)
+	switch {
+	case *outputFile != "":
+		return output.ModeFile
+	case (*script || unattended || pipedStdout) && mode == output.ModeClipboard:
+		return output.ModeStdout
+	}
+	return mode
+}
+
+// pipedStdout is set when stdout is a pipe or file but there's a terminal
+// for the UI, as in "1lm … | xargs": the UI is drawn on the terminal,
+// opened with openTTY, and stdout gets only the bare command.
+var pipedStdout bool
+
+// hasTerminal reports whether there's a terminal to show the UI on. The
+// UI draws on stdout, or the terminal itself when stdout is piped or in
+// shell-function mode, and reads keys from stdin, or the terminal when
+// stdin is piped.
+func hasTerminal() bool {
+	if *outputMode != "shell-function" && term.IsTerminal(int(os.Stdout.Fd())) && term.IsTerminal(int(os.Stdin.Fd())) {
+		return true
+	}
+	in, out, err := openTTY()
+	if err != nil {
+		return false
+	}
+	closeTTY(in, out)
+	return true
+}
+
+// closeTTY closes what openTTY opened.
+func closeTTY(in, out *os.File) {
+	_ = in.Close()
+	if out != in {
+		_ = out.Close()
+	}
+}
+
+// detectPipedStdout sets pipedStdout and, with it, quiet output, so the
+// pipe gets only the command. Shell-function mode already keeps stdout
+// for the command alone.
+func detectPipedStdout(settings *ui.Settings) {
+	if *outputMode == "shell-function" || term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+	pipedStdout = true
+	settings.Quiet = true
+}
+
+// runUI runs a bubbletea program with the terminal setup shared by every
+// interactive flow, and returns the model it finished on.
+func runUI(initial tea.Model, settings ui.Settings) (tea.Model, error) {
+	// Focus reporting lets the UI pause animations while the terminal is
+	// in the background.
+	opts := []tea.ProgramOption{tea.WithReportFocus()}
+	if settings.AltScreen {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if settings.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+
+	// In shell-function mode, or when stdout is piped, use the terminal
+	// directly (/dev/tty, or the console on Windows) so stdout stays clean
+	// for output
+	if *outputMode == "shell-function" || pipedStdout {
+		in, out, err := openTTY()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the terminal: %w", err)
+		}
+		defer closeTTY(in, out)
+
+		// EnvColorProfile rather than ColorProfile so NO_COLOR is honored.
+		output := termenv.NewOutput(out)
+		lipgloss.SetColorProfile(output.EnvColorProfile())
+		lipgloss.SetHasDarkBackground(output.HasDarkBackground())
+
+		opts = append(opts, tea.WithInput(in), tea.WithOutput(out))
+	} else if !term.IsTerminal(int(os.Stdin.Fd())) {
+		// Stdin was piped in (error output, a query), so keys come from
+		// the terminal instead.
+		opts = append(opts, tea.WithInputTTY())
+	}
+	if *noColor || *plain {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	logOutput.hold()
+	defer logOutput.release()
+
+	finalModel, err := tea.NewProgram(initial, opts...).Run()
+	if err != nil {
+		return nil, fmt.Errorf("error running UI: %w", err)
+	}
+	return finalModel, nil
+}
+
+// primarySelection is whether clipboard output also sets the X11 primary
+// selection (primary_selection = "on").
+var primarySelection bool
+
+// terminal is the terminal whose own escape sequences output uses.
+var terminal output.Terminal
+
+// detectTerminal picks the terminal for output: the one detected, unless
+// terminal_features turns its escape sequences off or on regardless.
+// Escape sequences are only written to a terminal, never into a pipe.
+func detectTerminal(cfg *config.Config) output.Terminal {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return output.TerminalOther
+	}
+	switch cfg.TerminalFeatures {
+	case "off":
+		return output.TerminalOther
+	case "on":
+		return output.TerminalITerm2
+	}
+	return output.DetectTerminal(os.Getenv)
+}
+
+// release is the one way commands leave 1lm. It checks what is output
+// against the policy, records the query, the options and the selection in
+// the audit log, and only then calls deliver, so nothing refused or
+// unrecorded is output.
+//
+// selected is nil when nothing was chosen, or when every option is output
+// at once, as with --fzf; then each option is checked. deliver is nil when
+// the output happens afterwards, as when the selector delivers the command
+// itself or batch writes every query's results together.
+func release(cfg *config.Config, query string, options []commands.Option, selected *commands.Option, deliver func() error) error {
+	switch {
+	case selected != nil:
+		if err := checkPolicy(selected); err != nil {
+			return err
+		}
+	case deliver != nil:
+		for i := range options {
+			if err := checkPolicy(&options[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeAudit(cfg, query, options, selected); err != nil {
+		return err
+	}
+	if deliver == nil {
+		return nil
+	}
+	return deliver()
+}
+
+// emit sends the selected command, generated for query, through the
+// configured output handler, or reports what would happen in dry-run mode.
+func emit(query string, selected *commands.Option, settings ui.Settings) error {
+	return emitAs(query, selected, settings, selectedOutputMode(), false)
+}
+
+// emitAs is emit in the given output mode, for a command the UI may
+// already have delivered (copied, written to a file) with the hook from
+// deliverer, in which case only the confirmation is printed.
+func emitAs(query string, selected *commands.Option, settings ui.Settings, mode output.Mode, delivered bool) error {
+	if selected == nil {
+		if *outputMode != "shell-function" && !settings.Quiet {
+			fmt.Println(i18n.T(settings.Language, "No option selected"))
+		}
+		return nil
+	}
+
+	if err := checkPolicy(selected); err != nil {
+		return err
+	}
+
+	handler, err := newHandler(query, settings, mode)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return handler.DryRun(os.Stderr, selected)
+	}
+
+	handler.SetDelivered(delivered)
+	if err := handler.Output(selected); err != nil {
+		return fmt.Errorf("failed to output command: %w", err)
+	}
+
+	return nil
+}
+
+// newHandler builds the output handler for mode from flags, config and
+// settings.
+func newHandler(query string, settings ui.Settings, mode output.Mode) (*output.Handler, error) {
+	decoration := output.DecorationFull
+	if settings.Plain {
+		decoration = output.DecorationPlain
+	}
+
+	sh, err := selectedShell()
+	if err != nil {
+		return nil, err
+	}
+
+	handler := output.NewHandler(mode, decoration)
+	handler.SetLanguage(settings.Language)
+	handler.SetQuiet(settings.Quiet)
+	handler.SetPrimarySelection(primarySelection)
+	handler.SetTerminal(terminal)
+	handler.SetQuery(query)
+	handler.SetFile(*outputFile, sh)
+	return handler, nil
+}
+
+// auditor returns the hook the selector releases a chosen command with
+// before delivering it, so a command the policy refuses, or that can't be
+// recorded in audit_log, never goes out.
+func auditor(cfg *config.Config) func(string, []commands.Option, commands.Option) error {
+	return func(query string, options []commands.Option, selected commands.Option) error {
+		return release(cfg, query, options, &selected, nil)
+	}
+}
+
+// deliverer returns the hook the selector uses to output a chosen command
+// before the UI exits, so clipboard, file and Neovim failures are shown
+// there with a chance to retry. Dry runs and commands policy blocks are
+// left to emit, which reports them.
+func deliverer(settings ui.Settings) func(commands.Option, string) (bool, error) {
+	return func(opt commands.Option, mode string) (bool, error) {
+		if *dryRun || checkPolicy(&opt) != nil {
+			return false, nil
+		}
+		handler, err := newHandler("", settings, output.Mode(mode))
+		if err != nil {
+			return false, err
+		}
+		return handler.Deliver(&opt)
+	}
+}
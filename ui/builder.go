@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/history"
+)
+
+// builderMinWidth is the narrowest terminal the steps pane fits beside the
+// prompt in; on narrower ones it goes underneath.
+const builderMinWidth = 90
+
+// BuildStep is one command added to the script in the builder.
+type BuildStep struct {
+	Query   string            // the query the command was generated for
+	Option  commands.Option   // the selected option
+	Options []commands.Option // every option offered, for history and the audit log
+}
+
+// BuilderModel strings queries together into a script. Each command
+// selected is added as a step, shown in a pane beside the prompt for the
+// next query, where steps can be reordered or removed before the script
+// is written out with ctrl+s.
+type BuilderModel struct {
+	child     tea.Model // the prompt, spinner or selector for the next step
+	generator *commands.Generator
+	past      *history.History
+	settings  Settings
+	steps     []BuildStep
+	cursor    int  // highlighted step in the pane
+	editing   bool // keys go to the steps pane rather than the prompt
+	done      bool // ctrl+s was pressed: write the script out
+	width     int
+	height    int
+	status    string
+}
+
+// NewBuilder creates a script builder that starts at the query prompt.
+// past supplies the queries that up and down recall; nil disables recall.
+func NewBuilder(generator *commands.Generator, past *history.History, settings Settings) BuilderModel {
+	return BuilderModel{
+		child:     NewInputModel(generator, past, settings),
+		generator: generator,
+		past:      past,
+		settings:  settings,
+		width:     80,
+		height:    24,
+	}
+}
+
+// Init starts the prompt.
+func (m BuilderModel) Init() tea.Cmd {
+	return m.child.Init()
+}
+
+// Update handles the builder's own keys and passes everything else to the
+// prompt, spinner or selector for the next step.
+func (m BuilderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m.forward(m.childSize())
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "ctrl+s":
+			if len(m.steps) == 0 {
+				m.status = m.settings.t("Select a command to add a step first")
+				return m, m.settings.announce("%s", m.status)
+			}
+			m.done = true
+			return m, tea.Quit
+		}
+
+		if m.editing {
+			return m.updateSteps(msg)
+		}
+		switch m.child.(type) {
+		case InputModel:
+			if msg.String() == "tab" && len(m.steps) > 0 {
+				m.editing = true
+				m.cursor = len(m.steps) - 1
+				return m, m.announceStep()
+			}
+		case LoadingModel:
+			// q abandons this step rather than the whole script.
+			if msg.String() == "q" {
+				return m, m.nextStep()
+			}
+		}
+	}
+
+	return m.forward(msg)
+}
+
+// forward passes msg to the current step's model, and moves on to the
+// next step once it has a command, or the user backed out of it.
+func (m BuilderModel) forward(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.child.Update(msg)
+	m.child = next
+
+	// Each model quits the program when it is done; the builder carries on
+	// with the next step instead.
+	switch child := next.(type) {
+	case SelectorModel:
+		if child.selected != nil {
+			m.steps = append(m.steps, BuildStep{Query: child.query, Option: *child.selected, Options: child.options})
+			m.status = ""
+			return m, tea.Batch(m.nextStep(), m.settings.announce("Added step %d: %s", len(m.steps), child.selected.Command))
+		}
+		if child.quitting {
+			return m, m.nextStep()
+		}
+	case LoadingModel:
+		if child.err != nil {
+			m.status = m.settings.tf("No step added: %v", child.err)
+			return m, tea.Batch(m.nextStep(), m.settings.announce("%s", m.status))
+		}
+	}
+	return m, cmd
+}
+
+// nextStep starts a fresh prompt for the next query.
+func (m *BuilderModel) nextStep() tea.Cmd {
+	input := NewInputModel(m.generator, m.past, m.settings)
+	m.child, _ = input.Update(m.childSize())
+	return m.child.Init()
+}
+
+// updateSteps handles keys while the steps pane has focus: moving,
+// reordering and removing steps.
+func (m BuilderModel) updateSteps(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "tab", "esc":
+		m.editing = false
+		return m, nil
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			return m, m.announceStep()
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.steps)-1 {
+			m.cursor++
+			return m, m.announceStep()
+		}
+
+	case "shift+up", "K":
+		if m.cursor > 0 {
+			m.steps = slices.Clone(m.steps)
+			m.steps[m.cursor-1], m.steps[m.cursor] = m.steps[m.cursor], m.steps[m.cursor-1]
+			m.cursor--
+			return m, m.announceStep()
+		}
+
+	case "shift+down", "J":
+		if m.cursor < len(m.steps)-1 {
+			m.steps = slices.Clone(m.steps)
+			m.steps[m.cursor+1], m.steps[m.cursor] = m.steps[m.cursor], m.steps[m.cursor+1]
+			m.cursor++
+			return m, m.announceStep()
+		}
+
+	case "d", "delete", "backspace":
+		m.steps = slices.Delete(slices.Clone(m.steps), m.cursor, m.cursor+1)
+		m.cursor = min(m.cursor, len(m.steps)-1)
+		if len(m.steps) == 0 {
+			m.editing = false
+			m.cursor = 0
+		}
+		return m, m.settings.announce("Step removed. %d steps.", len(m.steps))
+	}
+	return m, nil
+}
+
+// announceStep describes the highlighted step for screen readers.
+func (m BuilderModel) announceStep() tea.Cmd {
+	step := m.steps[m.cursor]
+	return m.settings.announce("Step %d: %s", m.cursor+1, step.Option.Command)
+}
+
+// beside reports whether the steps pane is laid out to the right of the
+// prompt rather than below it.
+func (m BuilderModel) beside() bool {
+	return m.width >= builderMinWidth
+}
+
+// childSize is the window size the current step's model sees: the space
+// left beside the steps pane, or the whole window when the pane is below.
+func (m BuilderModel) childSize() tea.WindowSizeMsg {
+	if m.beside() {
+		return tea.WindowSizeMsg{Width: m.width - m.paneWidth(), Height: m.height}
+	}
+	return tea.WindowSizeMsg{Width: m.width, Height: m.height}
+}
+
+// paneWidth is the width of the steps pane, border included.
+func (m BuilderModel) paneWidth() int {
+	if m.beside() {
+		return max(m.width/3, 30)
+	}
+	return m.width
+}
+
+// View renders the current step's model with the steps pane beside or
+// below it.
+func (m BuilderModel) View() string {
+	main := strings.TrimSuffix(m.child.View(), "\n")
+	if m.status != "" {
+		main += "\n" + HelpStyle.Render(m.status)
+	}
+
+	if m.beside() {
+		main = lipgloss.NewStyle().Width(m.width - m.paneWidth()).Render(main)
+		return lipgloss.JoinHorizontal(lipgloss.Top, main, m.stepsView()) + "\n"
+	}
+	return main + "\n" + m.stepsView() + "\n"
+}
+
+// stepsView renders the script so far, one step per entry, with the
+// builder's keys underneath.
+func (m BuilderModel) stepsView() string {
+	beside := m.beside()
+	contentWidth := max(m.paneWidth()-4, 20)
+	glyphs := m.settings.glyphs()
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(m.settings.t("Script")) + "\n")
+	if len(m.steps) == 0 {
+		b.WriteString(HelpStyle.Width(contentWidth).Render(m.settings.t("Each command you select is added here as a step.")) + "\n")
+	}
+	for i, step := range m.steps {
+		cursor := " "
+		title := fmt.Sprintf("%d. %s", i+1, step.Option.Title)
+		if m.editing && i == m.cursor {
+			cursor = SelectedStyle.Render(glyphs.Cursor)
+			title = SelectedStyle.Render(title)
+		}
+		command, _ := collapse(step.Option.Command, contentWidth-4, glyphs.Ellipsis)
+		fmt.Fprintf(&b, "%s %s\n", cursor, title)
+		b.WriteString(indent(CommandStyle.Render(command), 4) + "\n")
+	}
+
+	b.WriteString("\n")
+	if m.editing {
+		b.WriteString(m.settings.help("↑/↓: move", "K/J: reorder", "d: remove", "tab: back"))
+	} else {
+		hints := []string{"ctrl+s: save script"}
+		if len(m.steps) > 0 {
+			hints = append([]string{"tab: edit steps"}, hints...)
+		}
+		b.WriteString(m.settings.help(hints...))
+	}
+
+	border := lipgloss.NormalBorder()
+	if m.settings.Plain {
+		border = lipgloss.ASCIIBorder()
+	}
+	return lipgloss.NewStyle().
+		Border(border, !beside, false, false, beside).
+		BorderForeground(subtleColor).
+		Padding(0, 1).
+		Width(contentWidth + 2).
+		Render(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// Public: Reports whether the user asked for the script to be written out,
+// rather than quitting without it.
+func (m BuilderModel) Done() bool {
+	return m.done
+}
+
+// Public: Returns the steps added so far, in script order.
+func (m BuilderModel) Steps() []BuildStep {
+	return m.steps
+}
+
+// Public: Returns the steps as a script: each step's command after a
+// comment with its number and query.
+func (m BuilderModel) Script() string {
+	var b strings.Builder
+	for i, step := range m.steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		query := strings.Join(strings.Fields(step.Query), " ")
+		fmt.Fprintf(&b, "# Step %d: %s\n%s\n", i+1, query, step.Option.Command)
+	}
+	return b.String()
+}
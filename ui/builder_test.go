@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+)
+
+// addStep selects the first of options in the builder, as if it had been
+// generated for query.
+func addStep(t *testing.T, m BuilderModel, query string, options ...commands.Option) BuilderModel {
+	t.Helper()
+	selector := NewSelector(options, nil, m.settings)
+	selector.query = query
+	m.child = selector
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(BuilderModel)
+	if _, ok := m.child.(InputModel); !ok {
+		t.Fatalf("after selecting, the builder shows %T, want the prompt", m.child)
+	}
+	return m
+}
+
+func TestBuilderAddsSelectedCommands(t *testing.T) {
+	m := NewBuilder(nil, nil, Settings{Static: true})
+	m = addStep(t, m, "find log files", commands.Option{Title: "Find", Command: "find . -name '*.log'"})
+	m = addStep(t, m, "compress them", commands.Option{Title: "Compress", Command: "gzip *.log"}, commands.Option{Title: "Other", Command: "xz *.log"})
+
+	if steps := m.Steps(); len(steps) != 2 || len(steps[1].Options) != 2 {
+		t.Fatalf("Steps() = %+v, want two steps", steps)
+	}
+	if view := m.View(); !strings.Contains(view, "1. Find") || !strings.Contains(view, "2. Compress") {
+		t.Errorf("View() doesn't list the steps:\n%s", view)
+	}
+
+	want := "# Step 1: find log files\nfind . -name '*.log'\n\n# Step 2: compress them\ngzip *.log\n"
+	if got := m.Script(); got != want {
+		t.Errorf("Script() = %q, want %q", got, want)
+	}
+}
+
+func TestBuilderReordersAndRemovesSteps(t *testing.T) {
+	m := NewBuilder(nil, nil, Settings{Static: true})
+	m = addStep(t, m, "one", commands.Option{Title: "One", Command: "echo 1"})
+	m = addStep(t, m, "two", commands.Option{Title: "Two", Command: "echo 2"})
+	m = addStep(t, m, "three", commands.Option{Title: "Three", Command: "echo 3"})
+
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			next, _ := m.Update(key)
+			m = next.(BuilderModel)
+		}
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	// tab focuses the last step; K moves it up, then d removes "two".
+	press(tea.KeyMsg{Type: tea.KeyTab}, runes("K"), runes("j"), runes("d"))
+
+	var got []string
+	for _, step := range m.Steps() {
+		got = append(got, step.Option.Command)
+	}
+	if strings.Join(got, ",") != "echo 1,echo 3" {
+		t.Errorf("steps = %q, want echo 1, echo 3", got)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if !m.Done() {
+		t.Error("ctrl+s didn't finish the script")
+	}
+}
+
+func TestBuilderNeedsAStepToSave(t *testing.T) {
+	m := NewBuilder(nil, nil, Settings{Static: true})
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m = next.(BuilderModel); m.Done() || m.status == "" {
+		t.Errorf("ctrl+s with no steps: Done() = %v, status = %q", m.Done(), m.status)
+	}
+}
+
+func TestBuilderCarriesOnAfterAFailedStep(t *testing.T) {
+	m := NewBuilder(nil, nil, Settings{Static: true})
+	m.child = NewLoadingModel(nil, "q", m.settings)
+
+	next, _ := m.Update(optionsMsg{err: errors.New("model unavailable")})
+	m = next.(BuilderModel)
+	if _, ok := m.child.(InputModel); !ok {
+		t.Fatalf("after a failed step, the builder shows %T, want the prompt", m.child)
+	}
+	if !strings.Contains(m.View(), "model unavailable") {
+		t.Errorf("View() doesn't explain the failure:\n%s", m.View())
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// maxCollapsedLines is how many lines of a multi-line command are shown
+// before the rest is folded behind the "view full" toggle.
+const maxCollapsedLines = 5
+
+// commandLines splits a command into its lines, ignoring a trailing newline
+// so "echo hi\n" still counts as a one-liner.
+func commandLines(command string) []string {
+	return strings.Split(strings.TrimRight(command, "\n"), "\n")
+}
+
+// isTruncated reports whether renderCommand would fold part of the command.
+func isTruncated(command string) bool {
+	return len(commandLines(command)) > maxCollapsedLines
+}
+
+// renderCommand renders a command block to fit width. Single-line commands
+// wrap as before, with words in distinct highlighted; multi-line commands
+// (heredocs, loops) keep each line's indentation and get a line-number
+// gutter, with long scripts folded unless full is set. The part of the
+// command risk flags is shown in its warning color where it falls within
+// one line.
+func renderCommand(command string, width int, full bool, settings Settings, distinct map[string]bool, risk *safety.RiskInfo) string {
+	lines := commandLines(command)
+	glyphs := settings.glyphs()
+	if len(lines) == 1 {
+		return CommandStyle.Width(width).Render(highlightRisk(lines[0], risk, distinct))
+	}
+
+	shown := lines
+	if !full && len(lines) > maxCollapsedLines {
+		shown = lines[:maxCollapsedLines]
+	}
+
+	digits := len(strconv.Itoa(len(lines)))
+	gutterWidth := digits + 3 // number, space, bar, space
+	codeWidth := max(width-gutterWidth, 1)
+
+	rows := make([]string, 0, len(shown)+1)
+	for i, line := range shown {
+		gutter := LineNumberStyle.Render(fmt.Sprintf("%*d %s ", digits, i+1, glyphs.Gutter))
+		// Rendering each line separately keeps wrapped continuation rows
+		// aligned next to their own line number.
+		code := CommandStyle.Width(codeWidth).Render(highlightRisk(line, risk, nil))
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, gutter, code))
+	}
+
+	if hidden := len(lines) - len(shown); hidden > 0 {
+		rows = append(rows, HelpStyle.Render(
+			settings.tf("%s %d more lines (v: view full)", glyphs.Ellipsis, hidden),
+		))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// renderContinued renders a command split over lines with \ continuations
+// by Reflow. Unlike a script it is still one command, so its lines get no
+// gutter.
+func renderContinued(command string, width int, distinct map[string]bool, risk *safety.RiskInfo) string {
+	lines := commandLines(command)
+	rows := make([]string, len(lines))
+	for i, line := range lines {
+		rows[i] = CommandStyle.Width(width).Render(highlightRisk(line, risk, distinct))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// indent prefixes every line of s with n spaces so multi-line blocks stay
+// aligned under their option title.
+func indent(s string, n int) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCommandSingleLine(t *testing.T) {
+	got := renderCommand("ls -la\n", 40, false, Settings{}, nil, nil)
+	if strings.Contains(got, "│") {
+		t.Errorf("renderCommand() added a gutter to a one-liner: %q", got)
+	}
+	if !strings.Contains(got, "ls -la") {
+		t.Errorf("renderCommand() missing command, got %q", got)
+	}
+}
+
+func TestRenderCommandMultiLine(t *testing.T) {
+	command := "for f in *.png; do\n  optipng \"$f\"\ndone"
+
+	got := renderCommand(command, 60, false, Settings{}, nil, nil)
+
+	for _, want := range []string{"1 │", "2 │", "3 │", "  optipng", "done"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderCommand() missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderCommandFolding(t *testing.T) {
+	lines := make([]string, maxCollapsedLines+3)
+	for i := range lines {
+		lines[i] = "echo line"
+	}
+	command := strings.Join(lines, "\n")
+
+	collapsed := renderCommand(command, 60, false, Settings{}, nil, nil)
+	if !strings.Contains(collapsed, "… 3 more lines") {
+		t.Errorf("collapsed render missing fold marker, got:\n%s", collapsed)
+	}
+
+	full := renderCommand(command, 60, true, Settings{}, nil, nil)
+	if strings.Contains(full, "more lines") {
+		t.Errorf("full render should not fold, got:\n%s", full)
+	}
+	if !isTruncated(command) {
+		t.Error("isTruncated() = false, want true")
+	}
+}
+
+func TestIndent(t *testing.T) {
+	if got := indent("a\nb", 2); got != "  a\n  b" {
+		t.Errorf("indent() = %q, want %q", got, "  a\n  b")
+	}
+}
+
+func TestRenderCommandPlainGlyphs(t *testing.T) {
+	got := renderCommand("a\nb", 40, false, Settings{Plain: true}, nil, nil)
+	if strings.Contains(got, "│") {
+		t.Errorf("plain render contains box-drawing gutter, got:\n%s", got)
+	}
+	if !strings.Contains(got, "1 | ") {
+		t.Errorf("plain render missing ASCII gutter, got:\n%s", got)
+	}
+}
@@ -0,0 +1,310 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+	"golang.org/x/term"
+)
+
+// CompareColumn is one model in a side-by-side comparison.
+type CompareColumn struct {
+	Name      string
+	Generator *commands.Generator
+}
+
+// compareColumn holds a column's progress and results.
+type compareColumn struct {
+	CompareColumn
+	options    []commands.Option
+	err        error
+	done       bool
+	safetyDone bool
+}
+
+// compareResultMsg carries one column's generated options.
+type compareResultMsg struct {
+	column  int
+	options []commands.Option
+	err     error
+}
+
+// compareRiskMsg carries one column's safety results.
+type compareRiskMsg struct {
+	column  int
+	options []commands.Option
+	err     error
+}
+
+// CompareModel generates options for one query from several models and
+// shows them in columns, to help decide which model is good enough.
+type CompareModel struct {
+	columns  []compareColumn
+	query    string
+	settings Settings
+	spinner  spinner.Model
+	column   int // column holding the cursor
+	cursor   int
+	status   string
+	selected *commands.Option
+	quitting bool
+	width    int
+	blurred  bool
+}
+
+// NewCompareModel creates a comparison of columns for query.
+func NewCompareModel(columns []CompareColumn, query string, settings Settings) CompareModel {
+	width := 80
+	if w, _, err := term.GetSize(0); err == nil && w > 0 {
+		width = w
+	}
+
+	m := CompareModel{
+		query:    query,
+		settings: settings,
+		spinner:  settings.newSpinner(TitleStyle),
+		width:    width,
+	}
+	for _, c := range columns {
+		m.columns = append(m.columns, compareColumn{CompareColumn: c})
+	}
+	return m
+}
+
+// Init starts generation for every column.
+func (m CompareModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{
+		m.settings.spinnerTick(m.spinner),
+		m.settings.announce("Generating options..."),
+	}
+	for i, c := range m.columns {
+		cmds = append(cmds, func() tea.Msg {
+			options, err := c.Generator.Generate(context.Background(), m.query)
+			return compareResultMsg{column: i, options: options, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// pending reports whether any column is still generating or being checked.
+func (m CompareModel) pending() bool {
+	for _, c := range m.columns {
+		if !c.done || (c.err == nil && !c.safetyDone) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update handles results, navigation between and within columns, and
+// selection.
+func (m CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "left", "h", "shift+tab":
+			m.column = (m.column + len(m.columns) - 1) % len(m.columns)
+			m.clampCursor()
+			return m, m.announceCurrent()
+
+		case "right", "l", "tab":
+			m.column = (m.column + 1) % len(m.columns)
+			m.clampCursor()
+			return m, m.announceCurrent()
+
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+				return m, m.announceCurrent()
+			}
+
+		case "down", "j":
+			if m.cursor < len(m.columns[m.column].options)-1 {
+				m.cursor++
+				return m, m.announceCurrent()
+			}
+
+		case "enter":
+			c := m.columns[m.column]
+			if len(c.options) > 0 {
+				if reason := refusal(c.options[m.cursor], c.Generator, c.safetyDone, m.settings); reason != "" {
+					m.status = reason
+					return m, m.settings.announce("%s", reason)
+				}
+				if opt := c.options[m.cursor]; needsConfirmation(opt) {
+					confirm := newConfirmModel(m, opt, m.settings, m.width)
+					return confirm, confirm.Init()
+				}
+				return m.choose(c.options[m.cursor])
+			}
+		}
+
+	case compareResultMsg:
+		c := &m.columns[msg.column]
+		c.done, c.options, c.err = true, msg.options, msg.err
+		if c.err != nil {
+			return m, m.settings.announce("%s: %v", c.Name, c.err)
+		}
+		generator := c.Generator
+		options := c.options
+		return m, tea.Batch(
+			m.settings.announce("%s: %d options.", c.Name, len(c.options)),
+			func() tea.Msg {
+				evaluated, err := generator.EvaluateSafety(context.Background(), options)
+				return compareRiskMsg{column: msg.column, options: evaluated, err: err}
+			},
+		)
+
+	case compareRiskMsg:
+		c := &m.columns[msg.column]
+		c.safetyDone = true
+		if msg.options != nil {
+			c.options = msg.options
+		}
+		m.clampCursor()
+		return m, nil
+
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, nil
+
+	case tea.FocusMsg:
+		m.blurred = false
+		if m.pending() {
+			return m, m.settings.spinnerTick(m.spinner)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.pending() && !m.blurred {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+
+	return m, nil
+}
+
+// clampCursor keeps the cursor on an option after switching columns.
+func (m *CompareModel) clampCursor() {
+	m.cursor = max(min(m.cursor, len(m.columns[m.column].options)-1), 0)
+}
+
+// announceCurrent describes the highlighted option in accessible mode.
+func (m CompareModel) announceCurrent() tea.Cmd {
+	c := m.columns[m.column]
+	if len(c.options) == 0 {
+		return m.settings.announce("%s", c.Name)
+	}
+	opt := c.options[m.cursor]
+	return m.settings.announce("%s. %s", c.Name, m.settings.tf("Option %d of %d: %s. Command: %s", m.cursor+1, len(c.options), opt.Title, opt.Command))
+}
+
+// View renders the columns side by side.
+func (m CompareModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	const gap = 2
+	width := max((m.width-gap*(len(m.columns)-1))/max(len(m.columns), 1), 20)
+
+	rendered := make([]string, len(m.columns))
+	for i := range m.columns {
+		style := lipgloss.NewStyle().Width(width)
+		if i < len(m.columns)-1 {
+			style = style.MarginRight(gap)
+		}
+		rendered[i] = style.Render(m.renderColumn(i, width))
+	}
+
+	help := m.settings.help("←/→: switch model", "↑/↓: move", "enter: select", "q: quit")
+	var status string
+	if m.status != "" {
+		status = HelpStyle.Render(m.status) + "\n"
+	}
+	return "\n" + lipgloss.JoinHorizontal(lipgloss.Top, rendered...) + "\n" + status + help + "\n"
+}
+
+// renderColumn renders one model's header and options.
+func (m CompareModel) renderColumn(i, width int) string {
+	c := m.columns[i]
+	glyphs := m.settings.glyphs()
+
+	var b strings.Builder
+	header := TitleStyle.Render(c.Name)
+	if i == m.column {
+		header = SelectedStyle.Render(c.Name)
+	}
+	b.WriteString(header + "\n\n")
+
+	switch {
+	case !c.done:
+		b.WriteString(fmt.Sprintf("%s %s\n", m.settings.spinnerView(m.spinner), m.settings.t("Generating options...")))
+		return b.String()
+	case c.err != nil:
+		b.WriteString(WarningHighStyle.Width(width).Render(c.err.Error()) + "\n")
+		return b.String()
+	}
+
+	contentWidth := max(width-2, 1)
+	for j, opt := range c.options {
+		isSelected := i == m.column && j == m.cursor
+		cursor, title := " ", TitleStyle.Render(opt.Title)
+		if isSelected {
+			cursor, title = SelectedStyle.Render(glyphs.Cursor), SelectedStyle.Render(opt.Title)
+		}
+
+		b.WriteString(fmt.Sprintf("%s %s\n", cursor, title))
+		b.WriteString(indent(renderCommand(opt.Command, contentWidth, false, m.settings, nil, opt.Risk), 2) + "\n")
+		if opt.SaferFor != "" {
+			b.WriteString(indent(HelpStyle.Width(contentWidth).Render(m.settings.tf("Safer version of: %s", opt.SaferFor)), 2) + "\n")
+		}
+		if opt.Blocked != "" {
+			b.WriteString(indent(WarningHighStyle.Width(contentWidth).Render(m.settings.tf("Blocked by policy: %s", opt.Blocked)), 2) + "\n")
+		}
+		if opt.Risk != nil {
+			b.WriteString(indent(formatRiskWarning(opt.Risk, isSelected, glyphs), 2) + "\n")
+		} else if !c.safetyDone {
+			b.WriteString("  " + m.settings.spinnerView(m.spinner) + CheckingStyle.Render(" "+m.settings.t("checking safety...")) + "\n")
+		}
+		b.WriteString(indent(DescriptionStyle.Width(contentWidth).Render(opt.Description), 2) + "\n\n")
+	}
+
+	return b.String()
+}
+
+// choose selects opt and quits.
+func (m CompareModel) choose(opt commands.Option) (tea.Model, tea.Cmd) {
+	m.selected = &opt
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// Selected returns the chosen option, or nil if the user quit.
+func (m CompareModel) Selected() *commands.Option {
+	return m.selected
+}
+
+// Options returns every column's options, in column order.
+func (m CompareModel) Options() []commands.Option {
+	var options []commands.Option
+	for _, col := range m.columns {
+		options = append(options, col.options...)
+	}
+	return options
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestCompareModel(t *testing.T) {
+	m := NewCompareModel([]CompareColumn{{Name: "sonnet"}, {Name: "haiku"}}, "list files", Settings{Static: true})
+	m.width = 120
+
+	next, _ := m.Update(compareResultMsg{column: 0, options: []commands.Option{{Title: "List", Command: "ls -la"}}})
+	next, _ = next.Update(compareResultMsg{column: 1, err: errors.New("overloaded")})
+	m = next.(CompareModel)
+
+	view := m.View()
+	for _, want := range []string{"sonnet", "haiku", "ls -la", "overloaded"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q:\n%s", want, view)
+		}
+	}
+
+	// Enter does nothing in a column without options.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if next.(CompareModel).Selected() != nil {
+		t.Fatal("Selected() set from a failed column")
+	}
+
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := next.(CompareModel).Selected(); got == nil || got.Command != "ls -la" {
+		t.Errorf("Selected() = %+v, want ls -la", got)
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// chooser is a model an option can be picked from once it is confirmed.
+type chooser interface {
+	tea.Model
+	choose(opt commands.Option) (tea.Model, tea.Cmd)
+}
+
+// ConfirmModel asks the user to type a short token, such as the path a
+// command destroys, before a critical-risk option is selected. Like the
+// confirmation prompts of cloud CLIs, it makes the user read what they
+// are about to run. Esc returns to the model it was opened from.
+type ConfirmModel struct {
+	parent   chooser
+	option   commands.Option
+	token    string
+	input    textinput.Model
+	settings Settings
+	width    int
+	status   string
+}
+
+// needsConfirmation reports whether opt must be confirmed by typing before
+// it is selected.
+func needsConfirmation(opt commands.Option) bool {
+	return opt.Risk != nil && opt.Risk.Level == safety.RiskCritical
+}
+
+// newConfirmModel opens a typed confirmation for opt on top of parent.
+func newConfirmModel(parent chooser, opt commands.Option, settings Settings, width int) ConfirmModel {
+	token := confirmationToken(opt.Command, opt.Risk)
+
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Focus()
+
+	return ConfirmModel{
+		parent:   parent,
+		option:   opt,
+		token:    token,
+		input:    input,
+		settings: settings,
+		width:    width,
+	}
+}
+
+// confirmationToken picks what the user must type to confirm command: the
+// path or device it destroys where one can be found after the risky part,
+// or "DELETE" otherwise.
+func confirmationToken(command string, risk *safety.RiskInfo) string {
+	rest := command
+	if risk != nil && risk.Fragment != "" {
+		if i := strings.Index(command, risk.Fragment); i >= 0 {
+			rest = command[i:]
+		}
+	}
+
+	for _, field := range strings.Fields(rest) {
+		if field == "|" || field == "||" || field == "&&" || field == ";" {
+			break
+		}
+		if target, ok := strings.CutPrefix(field, "of="); ok {
+			return strings.Trim(target, `"'`)
+		}
+		field = strings.Trim(strings.TrimLeft(field, ">"), `"'`)
+		if strings.HasPrefix(field, "/") || strings.HasPrefix(field, "~") || strings.HasPrefix(field, "$HOME") {
+			return field
+		}
+	}
+	return "DELETE"
+}
+
+// Init announces what must be typed.
+func (m ConfirmModel) Init() tea.Cmd {
+	return tea.Batch(
+		textinput.Blink,
+		m.settings.announce("Critical risk: %s. Type %s to confirm, or escape to cancel.", m.option.Risk.Message, m.token),
+	)
+}
+
+// Update selects the option once the token is typed and returns to the
+// parent on Esc. Other messages are forwarded to the parent so background
+// results aren't lost while the prompt is open.
+func (m ConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m.parent.Update(msg)
+		case "esc":
+			return m.parent, nil
+		case "enter":
+			if strings.TrimSpace(m.input.Value()) == m.token {
+				return m.parent.choose(m.option)
+			}
+			m.status = m.settings.t("Confirmation doesn't match")
+			return m, m.settings.announce("%s", m.status)
+		}
+
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+
+	case tea.MouseMsg:
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+
+	parent, cmd := m.parent.Update(msg)
+	if p, ok := parent.(chooser); ok {
+		m.parent = p
+	}
+	return m, cmd
+}
+
+// View renders the warning, the command and the confirmation input.
+func (m ConfirmModel) View() string {
+	glyphs := m.settings.glyphs()
+
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(formatRiskWarning(m.option.Risk, true, glyphs) + "\n\n")
+	b.WriteString(indent(renderCommand(m.option.Command, m.width-4, true, m.settings, nil, m.option.Risk), 2) + "\n\n")
+	b.WriteString(m.settings.tf("Type %s to confirm:", m.token) + "\n")
+	b.WriteString(m.input.View() + "\n\n")
+	if m.status != "" {
+		b.WriteString(WarningHighStyle.Render(m.status) + "\n")
+	}
+	b.WriteString(m.settings.help("enter: confirm", "esc: cancel") + "\n")
+	return b.String()
+}
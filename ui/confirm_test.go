@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestConfirmationToken(t *testing.T) {
+	tests := []struct {
+		command  string
+		fragment string
+		want     string
+	}{
+		{"rm -rf / --no-preserve-root", "rm -rf /", "/"},
+		{"cd /tmp && sudo rm -rf ~/", "rm -rf ~/", "~/"},
+		{"sudo dd if=/tmp/image.iso of=/dev/sdb bs=4M", "dd if=/tmp/image.iso of=/dev/", "/dev/sdb"},
+		{"sudo mkfs.ext4 /dev/sdb1", "mkfs.ext4", "/dev/sdb1"},
+		{"cat image > /dev/sda", "> /dev/sd", "/dev/sda"},
+		{"psql -c 'DROP DATABASE app'", "DROP DATABASE", "DELETE"},
+		{"mkfs.ext4 disk.img | tee /tmp/log", "mkfs.ext4", "DELETE"},
+	}
+
+	for _, tt := range tests {
+		risk := &safety.RiskInfo{Level: safety.RiskCritical, Fragment: tt.fragment}
+		if got := confirmationToken(tt.command, risk); got != tt.want {
+			t.Errorf("confirmationToken(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorConfirmsCriticalOptions(t *testing.T) {
+	options := []commands.Option{{
+		Title:   "Wipe disk",
+		Command: "sudo wipefs -a /dev/sdb",
+		Risk:    &safety.RiskInfo{Level: safety.RiskCritical, Message: "Formats a filesystem", Fragment: "wipefs"},
+	}}
+	m := NewSelector(options, nil, Settings{Static: true})
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := next.(ConfirmModel); !ok {
+		t.Fatalf("enter on a critical option opened %T, want ConfirmModel", next)
+	}
+
+	// A wrong token keeps the prompt open.
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/dev/sda")})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := next.(ConfirmModel); !ok {
+		t.Fatalf("wrong token returned %T, want ConfirmModel", next)
+	}
+
+	// Esc goes back without selecting.
+	back, _ := next.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if sm, ok := back.(SelectorModel); !ok || sm.Selected() != nil {
+		t.Fatalf("esc returned %T with a selection", back)
+	}
+
+	next, _ = back.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/dev/sdb")})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	sm, ok := next.(SelectorModel)
+	if !ok {
+		t.Fatalf("right token returned %T, want SelectorModel", next)
+	}
+	if got := sm.Selected(); got == nil || got.Command != options[0].Command {
+		t.Errorf("Selected() = %+v, want %q", got, options[0].Command)
+	}
+}
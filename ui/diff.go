@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+// segmentPattern splits a command into alternating word and whitespace
+// runs, so it can be re-rendered without changing its spacing.
+var segmentPattern = regexp.MustCompile(`\S+|\s+`)
+
+// distinctTokens finds, for each command, the words that aren't shared by
+// every other command. These are what distinguish one approach from
+// another (e.g. -S vs -G), while the common words (git, log, the search
+// term) are noise when comparing.
+//
+// Returns one set per command; sets are empty when there is nothing to
+// compare against.
+func distinctTokens(commands []string) []map[string]bool {
+	result := make([]map[string]bool, len(commands))
+	if len(commands) < 2 {
+		for i := range result {
+			result[i] = map[string]bool{}
+		}
+		return result
+	}
+
+	tokenSets := make([]map[string]bool, len(commands))
+	for i, cmd := range commands {
+		tokenSets[i] = map[string]bool{}
+		for _, tok := range strings.Fields(cmd) {
+			tokenSets[i][tok] = true
+		}
+	}
+
+	for i := range commands {
+		result[i] = map[string]bool{}
+		for tok := range tokenSets[i] {
+			for j := range commands {
+				if j != i && !tokenSets[j][tok] {
+					result[i][tok] = true
+					break
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// highlightTokens renders a single-line command with distinct words in
+// DiffStyle. Every segment carries the command background explicitly,
+// because a nested style's reset would otherwise clear it mid-line.
+func highlightTokens(command string, distinct map[string]bool) string {
+	var b strings.Builder
+	base := CommandStyle.UnsetPadding()
+
+	for _, seg := range segmentPattern.FindAllString(command, -1) {
+		if distinct[seg] {
+			b.WriteString(DiffStyle.Render(seg))
+		} else {
+			b.WriteString(base.Render(seg))
+		}
+	}
+
+	return b.String()
+}
+
+// highlightRisk renders a line with the first occurrence of the risky
+// fragment in DangerStyle (or CautionStyle for low risk) and the rest as
+// highlightTokens would. Lines with neither are returned unstyled.
+func highlightRisk(line string, risk *safety.RiskInfo, distinct map[string]bool) string {
+	i := -1
+	if risk != nil && risk.Fragment != "" {
+		i = strings.Index(line, risk.Fragment)
+	}
+	if i < 0 {
+		if len(distinct) == 0 {
+			return line
+		}
+		return highlightTokens(line, distinct)
+	}
+
+	style := DangerStyle
+	if risk.Level == safety.RiskLow {
+		style = CautionStyle
+	}
+	end := i + len(risk.Fragment)
+	return highlightTokens(line[:i], distinct) + style.Render(line[i:end]) + highlightTokens(line[end:], distinct)
+}
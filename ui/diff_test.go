@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/pixielabs/1lm/safety"
+)
+
+func TestDistinctTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		want     [][]string
+	}{
+		{
+			name:     "single command has nothing to compare",
+			commands: []string{"git log -S foo"},
+			want:     [][]string{{}},
+		},
+		{
+			name: "flags that differ",
+			commands: []string{
+				"git log -p -S foo",
+				"git log -G foo",
+				"git log --all -p -S foo",
+			},
+			want: [][]string{
+				{"-S", "-p"},
+				{"-G"},
+				{"--all", "-S", "-p"},
+			},
+		},
+		{
+			name:     "identical commands",
+			commands: []string{"ls -la", "ls -la"},
+			want:     [][]string{{}, {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distinctTokens(tt.commands)
+			for i, set := range got {
+				keys := make([]string, 0, len(set))
+				for k := range set {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				if !reflect.DeepEqual(keys, tt.want[i]) {
+					t.Errorf("distinctTokens()[%d] = %v, want %v", i, keys, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHighlightTokensPreservesText(t *testing.T) {
+	command := `grep  -r "two  spaces" .`
+	got := highlightTokens(command, map[string]bool{"-r": true})
+
+	// Tests run without a color profile, so rendering is plain text.
+	if got != command {
+		t.Errorf("highlightTokens() = %q, want %q", got, command)
+	}
+}
+
+func TestHighlightRiskPreservesText(t *testing.T) {
+	command := "find . -name '*.tmp' | xargs rm -rf"
+	tests := []struct {
+		name string
+		risk *safety.RiskInfo
+	}{
+		{"no risk", nil},
+		{"fragment", &safety.RiskInfo{Level: safety.RiskHigh, Fragment: "rm -rf"}},
+		{"low risk fragment", &safety.RiskInfo{Level: safety.RiskLow, Fragment: "xargs"}},
+		{"fragment not in line", &safety.RiskInfo{Level: safety.RiskHigh, Fragment: "dd of=/dev/sda"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightRisk(command, tt.risk, map[string]bool{"-name": true}); got != command {
+				t.Errorf("highlightRisk() = %q, want %q", got, command)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/docs"
+)
+
+// docMsg carries the result of a documentation lookup.
+type docMsg struct {
+	text string
+	err  error
+}
+
+// DocModel is a pager showing local documentation (man pages, tldr) or a
+// detailed safety assessment for the highlighted command, so it can be
+// checked before trusting it. Esc or q returns to the selector it was
+// opened from.
+type DocModel struct {
+	parent   SelectorModel
+	title    string
+	loading  string // shown until fetch returns
+	fetch    func() (string, error)
+	viewport viewport.Model
+	loaded   bool
+	err      error
+}
+
+// newDocModel opens a pager on top of the selector. fetch runs in the
+// background and its text becomes the pager content.
+func newDocModel(parent SelectorModel, title string, fetch func() (string, error)) DocModel {
+	// Leave room for the title and help lines.
+	vp := viewport.New(parent.width, max(parent.height-5, 3))
+
+	return DocModel{
+		parent:   parent,
+		title:    title,
+		loading:  "Loading documentation...",
+		fetch:    fetch,
+		viewport: vp,
+	}
+}
+
+// newManPageModel shows the man page excerpt for a command's flags.
+func newManPageModel(parent SelectorModel, command string) DocModel {
+	binary := commands.PrimaryBinary(command)
+	return newDocModel(parent, "man "+binary, func() (string, error) {
+		page, err := docs.Lookup(context.Background(), binary)
+		if err != nil {
+			return "", err
+		}
+		return docs.Excerpt(page, commands.Flags(command)), nil
+	})
+}
+
+// newTLDRModel shows the cached tldr page for a command's binary, with
+// its flags cross-checked against the page's examples.
+func newTLDRModel(parent SelectorModel, command string) DocModel {
+	binary := commands.PrimaryBinary(command)
+	return newDocModel(parent, "tldr "+binary, func() (string, error) {
+		page, err := docs.TLDR(binary)
+		if err != nil {
+			return "", err
+		}
+		return docs.FormatTLDR(page, commands.Flags(command)), nil
+	})
+}
+
+// Init starts the documentation lookup.
+func (m DocModel) Init() tea.Cmd {
+	return tea.Batch(
+		m.parent.settings.announce("Loading %s...", m.title),
+		m.lookup,
+	)
+}
+
+func (m DocModel) lookup() tea.Msg {
+	text, err := m.fetch()
+	return docMsg{text: text, err: err}
+}
+
+// Update scrolls the pager and returns to the selector on Esc/q. Other
+// messages are forwarded to the selector so background safety results
+// aren't lost while the pager is open.
+func (m DocModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			m.parent.quitting = true
+			return m.parent, tea.Quit
+		case "esc", "q":
+			return m.parent, nil
+		}
+
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case docMsg:
+		m.loaded = true
+		m.err = msg.err
+		m.viewport.SetContent(msg.text)
+		if msg.err != nil {
+			return m, m.parent.settings.announce("%v", msg.err)
+		}
+		return m, m.parent.settings.announce("%s", msg.text)
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(msg.Height-5, 3)
+	}
+
+	parent, cmd := m.parent.Update(msg)
+	if sm, ok := parent.(SelectorModel); ok {
+		m.parent = sm
+	}
+	return m, cmd
+}
+
+// View renders the documentation excerpt with a title and help line.
+func (m DocModel) View() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(TitleStyle.Render(m.title))
+	b.WriteString("\n\n")
+
+	switch {
+	case !m.loaded:
+		b.WriteString(HelpStyle.Render(m.parent.settings.t(m.loading)))
+		b.WriteString("\n")
+	case m.err != nil:
+		b.WriteString(WarningLowStyle.Render(m.err.Error()))
+		b.WriteString("\n")
+	default:
+		b.WriteString(m.viewport.View())
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.parent.settings.help("↑/↓: scroll", "esc/q: back"))
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+// filterOptions returns the indices of options whose title or command
+// fuzzy-matches the query. An empty query matches every option.
+func filterOptions(options []commands.Option, query string) []int {
+	indices := make([]int, 0, len(options))
+	for i, opt := range options {
+		if fuzzyMatch(query, opt.Title+" "+opt.Command) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// fuzzyMatch reports whether every character of pattern appears in text in
+// order, ignoring case. Substrings are a special case of this, so "log -S"
+// and "lgS" both match "git log -p -S".
+func fuzzyMatch(pattern, text string) bool {
+	pattern = strings.ToLower(pattern)
+	text = strings.ToLower(text)
+
+	for _, r := range pattern {
+		idx := strings.IndexRune(text, r)
+		if idx < 0 {
+			return false
+		}
+		text = text[idx+len(string(r)):]
+	}
+	return true
+}
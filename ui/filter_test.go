@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		want    bool
+	}{
+		{name: "empty pattern", pattern: "", text: "git log", want: true},
+		{name: "substring", pattern: "log -S", text: "git log -S foo", want: true},
+		{name: "subsequence", pattern: "glS", text: "git log -S foo", want: true},
+		{name: "case insensitive", pattern: "GIT", text: "git log", want: true},
+		{name: "out of order", pattern: "lg", text: "git", want: false},
+		{name: "missing character", pattern: "xyz", text: "git log", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuzzyMatch(tt.pattern, tt.text); got != tt.want {
+				t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterOptions(t *testing.T) {
+	options := []commands.Option{
+		{Title: "Git log with pickaxe", Command: "git log -p -S myFunction"},
+		{Title: "Git log with regex", Command: "git log -G myFunction"},
+		{Title: "Grep working tree", Command: "rg myFunction"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "empty query", query: "", want: []int{0, 1, 2}},
+		{name: "matches title", query: "grep", want: []int{2}},
+		{name: "matches command", query: "-G", want: []int{1}},
+		{name: "no match", query: "docker", want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterOptions(options, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterOptions(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/history"
+)
+
+// historySearchRows is how many matches are listed at once.
+const historySearchRows = 10
+
+// historyItem is a past query, or a command chosen for one, that can be
+// picked from the history search.
+type historyItem struct {
+	query   string
+	command string // empty for a query
+}
+
+// text is what the item shows and is matched on.
+func (it historyItem) text() string {
+	if it.command != "" {
+		return it.command
+	}
+	return it.query
+}
+
+// HistorySearchModel is a fuzzy finder over past queries and the commands
+// chosen for them, opened with ctrl+r from the prompt like a shell's
+// reverse search. Picking a query puts it back in the prompt; picking a
+// command selects it without generating anything. Esc returns to the
+// prompt unchanged.
+type HistorySearchModel struct {
+	parent   InputModel
+	items    []historyItem
+	chosen   map[historyItem]history.Entry // latest entry for each command
+	visible  []int
+	cursor   int
+	filter   textinput.Model
+	settings Settings
+	width    int
+}
+
+// newHistorySearchModel opens a search over entries, most recent first, on
+// top of the prompt. Each query and command is listed once.
+func newHistorySearchModel(parent InputModel, entries []history.Entry, settings Settings, width int) HistorySearchModel {
+	var items []historyItem
+	chosen := map[historyItem]history.Entry{}
+	seen := map[historyItem]bool{}
+	add := func(it historyItem) {
+		if !seen[it] {
+			seen[it] = true
+			items = append(items, it)
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		add(historyItem{query: entry.Query})
+		if entry.Command != "" {
+			it := historyItem{query: entry.Query, command: entry.Command}
+			if _, ok := chosen[it]; !ok {
+				chosen[it] = entry
+			}
+			add(it)
+		}
+	}
+
+	fi := textinput.New()
+	fi.Prompt = "> "
+	fi.Placeholder = settings.t("filter")
+	fi.SetValue(parent.input.Value())
+	fi.Focus()
+
+	m := HistorySearchModel{
+		parent:   parent,
+		items:    items,
+		chosen:   chosen,
+		filter:   fi,
+		settings: settings,
+		width:    width,
+	}
+	m.applyFilter()
+	return m
+}
+
+// applyFilter narrows the list to the items matching the search.
+func (m *HistorySearchModel) applyFilter() {
+	var visible []int
+	for i, it := range m.items {
+		if fuzzyMatch(m.filter.Value(), it.text()) {
+			visible = append(visible, i)
+		}
+	}
+	m.visible = visible
+	m.cursor = 0
+}
+
+// Init starts the cursor blinking.
+func (m HistorySearchModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update moves through and filters the matches, and picks one on Enter.
+func (m HistorySearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			return m, tea.Quit
+		case tea.KeyEsc:
+			return m.parent, nil
+		case tea.KeyUp, tea.KeyCtrlP:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case tea.KeyDown, tea.KeyCtrlN, tea.KeyCtrlR:
+			if m.cursor < len(m.visible)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case tea.KeyEnter:
+			if len(m.visible) == 0 {
+				return m, nil
+			}
+			return m.pick(m.items[m.visible[m.cursor]])
+		}
+
+		var cmd tea.Cmd
+		before := m.filter.Value()
+		m.filter, cmd = m.filter.Update(msg)
+		if m.filter.Value() != before {
+			m.applyFilter()
+		}
+		return m, cmd
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		parent, _ := m.parent.Update(msg)
+		m.parent = parent.(InputModel)
+	}
+	return m, nil
+}
+
+// pick returns to the prompt with a query filled in, or selects a command
+// for the query it was chosen for.
+func (m HistorySearchModel) pick(it historyItem) (tea.Model, tea.Cmd) {
+	if it.command == "" {
+		m.parent.setQuery(it.query)
+		m.parent.recalled = -1
+		return m.parent, nil
+	}
+
+	opt := m.chosen[it].Option()
+	selector := NewSelector([]commands.Option{opt}, nil, m.settings)
+	selector.query = it.query
+	return selector.choose(opt)
+}
+
+// View renders the search input and the matches around the cursor.
+func (m HistorySearchModel) View() string {
+	glyphs := m.settings.glyphs()
+
+	var b strings.Builder
+	b.WriteString("\n" + TitleStyle.Render(m.settings.t("Search history")) + "\n\n")
+	b.WriteString(m.filter.View() + "\n\n")
+
+	if len(m.visible) == 0 {
+		b.WriteString(HelpStyle.Render(m.settings.t("No history matches")) + "\n")
+	}
+
+	start := max(0, min(m.cursor-historySearchRows/2, len(m.visible)-historySearchRows))
+	end := min(start+historySearchRows, len(m.visible))
+	for i := start; i < end; i++ {
+		it := m.items[m.visible[i]]
+
+		// Commands are marked like a shell prompt, queries like a question.
+		marker, style := "?", TitleStyle
+		if it.command != "" {
+			marker, style = "$", CommandStyle
+		}
+		text, _ := collapse(strings.SplitN(it.text(), "\n", 2)[0], m.width-8, glyphs.Ellipsis)
+
+		cursor := " "
+		if i == m.cursor {
+			cursor = SelectedStyle.Render(glyphs.Cursor)
+			style = SelectedStyle
+		}
+		line := style.Render(text)
+		if risk := m.chosen[it].Option().Risk; risk != nil {
+			line += " " + riskStyle(risk.Level).Render(riskGlyph(risk.Level, glyphs))
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, HelpStyle.Render(marker), line)
+	}
+
+	b.WriteString("\n" + m.settings.help("↑/↓: move", "enter: select", "esc: cancel") + "\n")
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/history"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// openHistorySearch returns a prompt with some history and ctrl+r pressed.
+func openHistorySearch(t *testing.T) tea.Model {
+	t.Helper()
+	past, err := history.LoadFrom(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past.Entries = []history.Entry{
+		{Query: "find large files", Command: "find . -size +100M"},
+		{Query: "show disk usage"},
+		{Query: "find large files", Command: "find . -size +100M"},
+	}
+
+	var m tea.Model = NewInputModel(nil, past, Settings{Static: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if _, ok := m.(HistorySearchModel); !ok {
+		t.Fatalf("ctrl+r gave %T, want HistorySearchModel", m)
+	}
+	return m
+}
+
+func TestHistorySearchListsEachItemOnce(t *testing.T) {
+	m := openHistorySearch(t).(HistorySearchModel)
+
+	want := []historyItem{
+		{query: "find large files"},
+		{query: "find large files", command: "find . -size +100M"},
+		{query: "show disk usage"},
+	}
+	if len(m.items) != len(want) {
+		t.Fatalf("items = %v, want %v", m.items, want)
+	}
+	for i := range want {
+		if m.items[i] != want[i] {
+			t.Errorf("items[%d] = %v, want %v", i, m.items[i], want[i])
+		}
+	}
+}
+
+func TestHistorySearchPicksQuery(t *testing.T) {
+	m := openHistorySearch(t)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("dsk")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	input, ok := m.(InputModel)
+	if !ok {
+		t.Fatalf("picking a query gave %T, want InputModel", m)
+	}
+	if got := input.input.Value(); got != "show disk usage" {
+		t.Errorf("prompt = %q, want the picked query", got)
+	}
+}
+
+func TestHistorySearchPicksCommand(t *testing.T) {
+	m := openHistorySearch(t)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("size")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	selector, ok := m.(SelectorModel)
+	if !ok {
+		t.Fatalf("picking a command gave %T, want SelectorModel", m)
+	}
+	if selector.Selected() == nil || selector.Selected().Command != "find . -size +100M" {
+		t.Errorf("selected = %v, want the past command", selector.Selected())
+	}
+	if selector.Query() != "find large files" {
+		t.Errorf("query = %q, want the query it was chosen for", selector.Query())
+	}
+}
+
+func TestHistorySearchKeepsRisk(t *testing.T) {
+	past, err := history.LoadFrom(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past.Entries = []history.Entry{
+		{Query: "clean build", Command: "rm -rf build", RiskLevel: "high", RiskReason: "deletes build"},
+	}
+
+	var m tea.Model = NewInputModel(nil, past, Settings{Static: true, Plain: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rm")})
+	if !strings.Contains(m.View(), "rm -rf build "+plainGlyphs.RiskHigh) {
+		t.Errorf("View() = %q, want the command marked high risk", m.View())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	selected := m.(SelectorModel).Selected()
+	if selected == nil || selected.Risk == nil || selected.Risk.Level != safety.RiskHigh {
+		t.Errorf("selected = %+v, want the saved high risk", selected)
+	}
+}
+
+func TestHistorySearchEscReturnsToPrompt(t *testing.T) {
+	m := openHistorySearch(t)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("xyz")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	input, ok := m.(InputModel)
+	if !ok {
+		t.Fatalf("esc gave %T, want InputModel", m)
+	}
+	if got := input.input.Value(); got != "" {
+		t.Errorf("prompt = %q, want it unchanged", got)
+	}
+}
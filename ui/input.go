@@ -2,37 +2,77 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/history"
 )
 
-// InputModel is the initial prompt where users type their query.
+// maxInputHeight is how many lines the prompt grows to before scrolling.
+const maxInputHeight = 8
+
+// InputModel is the initial prompt where users type their query. It is a
+// textarea so pasted error messages and code keep their line breaks.
 type InputModel struct {
-	textInput textinput.Model
+	input     textarea.Model
 	generator *commands.Generator
+	settings  Settings
+	width     int
 	submitted bool
 	query     string
+
+	// Up and down step through past queries, most recent first, like
+	// shell history. recalled is -1 while editing the draft.
+	past     []string
+	recalled int
+	draft    string
+
+	// entries are searched with ctrl+r.
+	entries []history.Entry
+
+	// snippetTitles are suggested, after past queries, as the query is
+	// typed.
+	snippetTitles []string
 }
 
-// NewInputModel creates a text input prompt for entering queries.
-func NewInputModel(generator *commands.Generator) InputModel {
-	ti := textinput.New()
-	ti.Placeholder = "e.g., search git history for myFunction"
-	ti.Focus()
-	ti.CharLimit = 200
-	ti.Width = 80
+// NewInputModel creates a text input prompt for entering queries. Enter
+// submits; alt+enter or ctrl+j starts a new line. past supplies the queries
+// that up and down recall; nil disables recall.
+func NewInputModel(generator *commands.Generator, past *history.History, settings Settings) InputModel {
+	ta := textarea.New()
+	ta.Placeholder = settings.t("e.g., search git history for myFunction")
+	ta.Prompt = "> "
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.SetWidth(80)
+	ta.SetHeight(1)
+	ta.Focus()
 
-	return InputModel{
-		textInput: ti,
+	m := InputModel{
+		input:     ta,
 		generator: generator,
+		settings:  settings,
+		width:     80,
+		recalled:  -1,
 	}
+	if past != nil {
+		m.past = past.Queries()
+		m.entries = past.Entries
+	}
+	return m
 }
 
-// Init starts the cursor blinking.
+// Init starts the cursor blinking and loads the snippet titles used for
+// suggestions.
 func (m InputModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textarea.Blink, loadSnippetTitles)
 }
 
 // Update transitions to LoadingModel on Enter, or quits on Esc/Ctrl+C.
@@ -41,38 +81,119 @@ func (m InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyEnter:
-			m.query = m.textInput.Value()
+		switch {
+		case msg.Type == tea.KeyEnter && !msg.Alt && !msg.Paste:
+			m.query = strings.TrimSpace(m.input.Value())
 			if m.query != "" {
+				loadingModel := m.Submit(m.query)
 				m.submitted = true
-				loadingModel := NewLoadingModel(m.generator, m.query)
 				return loadingModel, loadingModel.Init()
 			}
 			return m, nil
 
-		case tea.KeyCtrlC, tea.KeyEsc:
+		case msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc:
 			return m, tea.Quit
+
+		case msg.Type == tea.KeyRight && m.acceptSuggestion():
+			return m, nil
+
+		case msg.Type == tea.KeyCtrlR && len(m.entries) > 0:
+			search := newHistorySearchModel(m, m.entries, m.settings, m.width)
+			return search, search.Init()
+
+		// Up and down move between lines of a multi-line query, and only
+		// recall history from its first and last line.
+		case msg.Type == tea.KeyUp && m.input.Line() == 0:
+			if m.recalled < len(m.past)-1 {
+				if m.recalled == -1 {
+					m.draft = m.input.Value()
+				}
+				m.recalled++
+				m.setQuery(m.past[m.recalled])
+			}
+			return m, nil
+
+		case msg.Type == tea.KeyDown && m.input.Line() == m.input.LineCount()-1:
+			if m.recalled >= 0 {
+				m.recalled--
+				if m.recalled == -1 {
+					m.setQuery(m.draft)
+				} else {
+					m.setQuery(m.past[m.recalled])
+				}
+			}
+			return m, nil
 		}
 
 	case tea.WindowSizeMsg:
-		m.textInput.Width = msg.Width - 4
+		m.width = msg.Width
+		m.input.SetWidth(msg.Width - 4)
+
+	case snippetTitlesMsg:
+		m.snippetTitles = msg
+		return m, nil
 	}
 
-	m.textInput, cmd = m.textInput.Update(msg)
+	m.input, cmd = m.input.Update(msg)
+	m.fit()
 	return m, cmd
 }
 
+// fit grows the prompt to show every line of the query, up to
+// maxInputHeight.
+func (m *InputModel) fit() {
+	m.input.SetHeight(min(m.input.LineCount(), maxInputHeight))
+}
+
+// hints lists the prompt's keys, mentioning recall only when there is
+// history to recall and → only while there is a suggestion to accept.
+func (m InputModel) hints() []string {
+	hints := []string{"Enter to submit"}
+	if m.suggestion() != "" {
+		hints = append(hints, "→: accept")
+	}
+	hints = append(hints, "alt+enter: newline")
+	if len(m.past) > 0 {
+		hints = append(hints, "↑/↓: history", "ctrl+r: search")
+	}
+	return append(hints, "Esc/Ctrl+C to quit")
+}
+
+// Public: Starts generating options for query, keeping the prompt with
+// query in it for esc in the selector to return to.
+//
+// query - The request to generate commands for
+//
+// Returns the LoadingModel to run.
+func (m InputModel) Submit(query string) LoadingModel {
+	m.setQuery(query)
+	m.query = query
+	loading := NewLoadingModel(m.generator, query, m.settings)
+	loading.back = &m
+	return loading
+}
+
+// setQuery replaces the input's text, leaving the cursor at the end.
+func (m *InputModel) setQuery(query string) {
+	m.input.SetValue(query)
+	m.fit()
+}
+
 // View renders the query prompt with help text.
 func (m InputModel) View() string {
 	if m.submitted {
 		return ""
 	}
 
+	input := m.input.View()
+	if rest := m.suggestion(); rest != "" {
+		input = m.suggestionView(rest)
+	}
+
 	return fmt.Sprintf(
 		"\n%s\n\n%s\n\n%s\n",
-		TitleStyle.Render("What command do you need?"),
-		m.textInput.View(),
-		HelpStyle.Render("Enter to submit • Esc/Ctrl+C to quit"),
+		TitleStyle.Render(m.settings.t("What command do you need?")),
+		input,
+		m.settings.help(m.hints()...),
 	)
 }
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/history"
+)
+
+func TestInputRecallsHistory(t *testing.T) {
+	past, err := history.LoadFrom(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past.Entries = []history.Entry{{Query: "older query"}, {Query: "newer query"}}
+
+	var m tea.Model = NewInputModel(nil, past, Settings{Static: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("draft")})
+
+	steps := []struct {
+		key  tea.KeyType
+		want string
+	}{
+		{tea.KeyUp, "newer query"},
+		{tea.KeyUp, "older query"},
+		{tea.KeyUp, "older query"}, // stays on the oldest
+		{tea.KeyDown, "newer query"},
+		{tea.KeyDown, "draft"}, // back to what was being typed
+		{tea.KeyDown, "draft"},
+	}
+	for i, step := range steps {
+		m, _ = m.Update(tea.KeyMsg{Type: step.key})
+		if got := m.(InputModel).input.Value(); got != step.want {
+			t.Errorf("step %d: value = %q, want %q", i, got, step.want)
+		}
+	}
+}
+
+func TestInputKeepsNewlines(t *testing.T) {
+	var m tea.Model = NewInputModel(nil, nil, Settings{Static: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("why does this fail:"), Paste: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter, Alt: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("error: line one\nerror: line two"), Paste: true})
+
+	want := "why does this fail:\nerror: line one\nerror: line two"
+	if got := m.(InputModel).input.Value(); got != want {
+		t.Fatalf("value = %q, want %q", got, want)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	loading, ok := m.(LoadingModel)
+	if !ok {
+		t.Fatalf("enter gave %T, want LoadingModel", m)
+	}
+	if loading.query != want {
+		t.Errorf("query = %q, want %q", loading.query, want)
+	}
+}
+
+func TestInputMovesBetweenLinesBeforeRecalling(t *testing.T) {
+	past, err := history.LoadFrom(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past.Entries = []history.Entry{{Query: "older query"}}
+
+	var m tea.Model = NewInputModel(nil, past, Settings{Static: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("first\nsecond"), Paste: true})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.(InputModel).input.Value(); got != "first\nsecond" {
+		t.Fatalf("up on the last line recalled %q", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.(InputModel).input.Value(); got != "older query" {
+		t.Errorf("up on the first line = %q, want the past query", got)
+	}
+}
@@ -2,19 +2,35 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
 )
 
-// LoadingModel shows a spinner while generating command options.
+// LoadingModel shows a spinner while generating command options, with any
+// local match for the query to pick straight away. When generation fails
+// it shows the error with keys to retry, edit the query or quit, rather
+// than exiting, and a request too large to send without asking waits for
+// confirmation.
 type LoadingModel struct {
 	spinner   spinner.Model
 	generator *commands.Generator
+	settings  Settings
 	query     string
 	err       error
+	blurred   bool
+	waits     chan time.Time // rate-limit retry times, closed when done
+	retryAt   time.Time
+	back      *InputModel // the prompt the query came from, for the selector
+	width     int
+	started   time.Time
+	local     *commands.Option // found by Settings.LocalMatch, shown as option 0
 }
 
 // optionsMsg is sent when the generation API call completes.
@@ -23,53 +39,182 @@ type optionsMsg struct {
 	err     error
 }
 
-// NewLoadingModel creates a loading model that generates options for the query.
-func NewLoadingModel(generator *commands.Generator, query string) LoadingModel {
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = TitleStyle
+// rateLimitMsg is sent when generation is rate limited and will retry at
+// the given time.
+type rateLimitMsg struct {
+	retryAt time.Time
+}
+
+// countdownMsg redraws the rate-limit countdown.
+type countdownMsg struct{}
+
+// elapsedMsg redraws the time spent generating, which the spinner's own
+// redraws don't cover when animations are off.
+type elapsedMsg struct{}
+
+// elapsedAfter is how long generation runs before the time spent is
+// shown, so quick answers don't flash a counter.
+const elapsedAfter = 2 * time.Second
+
+// announceEvery is how often accessible mode says generation is still
+// going, since the redrawn counter isn't read out.
+const announceEvery = 10
 
-	return LoadingModel{
-		spinner:   s,
+// NewLoadingModel creates a loading model that generates options for the query.
+func NewLoadingModel(generator *commands.Generator, query string, settings Settings) LoadingModel {
+	m := LoadingModel{
+		spinner:   settings.newSpinner(TitleStyle),
 		generator: generator,
+		settings:  settings,
 		query:     query,
+		waits:     make(chan time.Time, 1),
+		width:     80,
+		started:   time.Now(),
+	}
+	if settings.LocalMatch != nil {
+		m.local = settings.LocalMatch(query)
 	}
+	return m
 }
 
 // Init starts the spinner and kicks off the API call.
 func (m LoadingModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, m.loadOptions)
+	var announceLocal tea.Cmd
+	if m.local != nil {
+		announceLocal = m.settings.announce("Local match: %s. 0 to use it now.", m.local.Command)
+	}
+	return tea.Batch(
+		m.settings.spinnerTick(m.spinner),
+		tea.Sequence(m.settings.announce("Generating options..."), announceLocal),
+		m.loadOptions,
+		m.waitForRateLimit,
+		elapsedTick(),
+	)
 }
 
 func (m LoadingModel) loadOptions() tea.Msg {
-	options, err := m.generator.Generate(context.Background(), m.query)
+	defer close(m.waits)
+
+	ctx := llm.WithRetryNotifier(context.Background(), func(wait time.Duration) {
+		select {
+		case m.waits <- time.Now().Add(wait):
+		default:
+		}
+	})
+
+	options, err := m.generator.Generate(ctx, m.query)
 	return optionsMsg{options: options, err: err}
 }
 
-// Update handles spinner ticks, API responses, and quit keys.
+// waitForRateLimit reports the next rate-limit wait, if any, so the view
+// can count down to the retry.
+func (m LoadingModel) waitForRateLimit() tea.Msg {
+	retryAt, ok := <-m.waits
+	if !ok {
+		return nil
+	}
+	return rateLimitMsg{retryAt: retryAt}
+}
+
+func countdownTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return countdownMsg{} })
+}
+
+func elapsedTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return elapsedMsg{} })
+}
+
+// elapsedSeconds is the whole number of seconds spent generating.
+func (m LoadingModel) elapsedSeconds() int {
+	return int(time.Since(m.started).Seconds())
+}
+
+// Update handles spinner ticks, API responses, quit keys and the error
+// screen's keys.
 func (m LoadingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.failed() {
+			return m.updateFailed(msg)
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
+		case "0":
+			if m.local != nil {
+				return m.useLocal()
+			}
 		}
 
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		if m.back != nil {
+			back, _ := m.back.Update(msg)
+			input := back.(InputModel)
+			m.back = &input
+		}
+		return m, nil
+
 	case optionsMsg:
+		if msg.err == nil && len(msg.options) == 0 {
+			msg.err = fmt.Errorf("no options generated")
+		}
 		if msg.err != nil {
 			m.err = msg.err
-			return m, tea.Quit
+			// The refusal view says what to ask instead; there is nothing
+			// to retry.
+			var refusal *llm.Refusal
+			if errors.As(m.err, &refusal) {
+				return m, tea.Quit
+			}
+			if large := m.largeRequest(); large != nil {
+				return m, m.settings.announce("Large request: about %d tokens. y to send it, e to edit the query, q to quit.", large.Tokens)
+			}
+			return m, m.settings.announce("Couldn't generate options: %v. r to retry, e to edit the query, q to quit.", m.err)
 		}
 
-		if len(msg.options) == 0 {
-			m.err = fmt.Errorf("no options generated")
-			return m, tea.Quit
+		selector := NewSelector(m.withLocal(msg.options), m.generator, m.settings)
+		selector.query = m.query
+		selector.back = m.back
+		return selector, selector.Init()
+
+	case rateLimitMsg:
+		m.retryAt = msg.retryAt
+		return m, tea.Batch(
+			m.waitForRateLimit,
+			countdownTick(),
+			m.settings.announce("Rate limited, retrying in %ds...", m.retrySeconds()),
+		)
+
+	case countdownMsg:
+		if time.Now().Before(m.retryAt) {
+			return m, countdownTick()
 		}
+		return m, nil
 
-		selector := NewSelector(msg.options, m.generator)
-		return selector, selector.Init()
+	case elapsedMsg:
+		if m.err != nil {
+			return m, nil
+		}
+		var announce tea.Cmd
+		if secs := m.elapsedSeconds(); secs > 0 && secs%announceEvery == 0 {
+			announce = m.settings.announce("Still generating options (%ds)...", secs)
+		}
+		return m, tea.Batch(elapsedTick(), announce)
+
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, nil
+
+	case tea.FocusMsg:
+		m.blurred = false
+		return m, m.settings.spinnerTick(m.spinner)
 
 	default:
+		// The spinner stops while blurred or showing an error.
+		if m.blurred || m.err != nil {
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
@@ -78,13 +223,182 @@ func (m LoadingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// View renders the spinner with a "Generating options..." message.
+// failed reports whether generation failed with an error that can be
+// retried, as opposed to a refusal.
+func (m LoadingModel) failed() bool {
+	var refusal *llm.Refusal
+	return m.err != nil && !errors.As(m.err, &refusal)
+}
+
+// largeRequest returns the estimate when generation is waiting for a
+// large request to be confirmed.
+func (m LoadingModel) largeRequest() *commands.LargeRequestError {
+	var large *commands.LargeRequestError
+	if errors.As(m.err, &large) {
+		return large
+	}
+	return nil
+}
+
+// updateFailed handles the error screen's keys: r tries the same query
+// again (y sends a large request), e goes back to the prompt to edit it,
+// and q quits.
+func (m LoadingModel) updateFailed(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "y":
+		if m.largeRequest() != nil {
+			m.generator.ConfirmLargeRequests()
+			return m.retry()
+		}
+
+	case "r", "enter":
+		// A large request would only be refused again.
+		if m.largeRequest() == nil {
+			return m.retry()
+		}
+
+	case "e", "esc":
+		if m.back != nil {
+			return *m.back, tea.Batch(m.back.Init(), m.settings.announce("Back to the prompt."))
+		}
+
+	case "0":
+		if m.local != nil {
+			return m.useLocal()
+		}
+	}
+	return m, nil
+}
+
+// retry starts generating for the same query again.
+func (m LoadingModel) retry() (tea.Model, tea.Cmd) {
+	retry := NewLoadingModel(m.generator, m.query, m.settings)
+	retry.back, retry.width = m.back, m.width
+	return retry, retry.Init()
+}
+
+// useLocal goes straight to the selector with just the local match,
+// which is safety-checked there like any other option. The model's
+// options are no longer waited for.
+func (m LoadingModel) useLocal() (tea.Model, tea.Cmd) {
+	selector := NewSelector([]commands.Option{*m.local}, m.generator, m.settings)
+	selector.query = m.query
+	selector.back = m.back
+	return selector, selector.Init()
+}
+
+// withLocal puts the local match, if any, before the model's options,
+// unless the model suggested the same command.
+func (m LoadingModel) withLocal(options []commands.Option) []commands.Option {
+	if m.local == nil || slices.ContainsFunc(options, func(opt commands.Option) bool {
+		return opt.Command == m.local.Command
+	}) {
+		return options
+	}
+	return append([]commands.Option{*m.local}, options...)
+}
+
+// View renders the spinner with a "Generating options..." message and
+// the time spent so far, what to try instead when the query isn't a shell
+// task, or why generation failed.
 func (m LoadingModel) View() string {
+	var refusal *llm.Refusal
+	if errors.As(m.err, &refusal) {
+		return m.refusalView(refusal)
+	}
+	if large := m.largeRequest(); large != nil {
+		return m.largeRequestView(large)
+	}
 	if m.err != nil {
+		return m.errorView()
+	}
+
+	status := m.settings.t("Generating options...")
+	if time.Now().Before(m.retryAt) {
+		status = m.rateLimitStatus()
+	} else if time.Since(m.started) >= elapsedAfter {
+		status += " " + HelpStyle.Render(m.settings.tf("%ds elapsed", m.elapsedSeconds()))
+	}
+
+	return fmt.Sprintf("\n%s %s\n", m.settings.spinnerView(m.spinner), status) + m.localView()
+}
+
+// localView shows the local match as option 0, to take without waiting
+// for the model.
+func (m LoadingModel) localView() string {
+	if m.local == nil {
 		return ""
 	}
+	view := "\n" + TitleStyle.Render(m.settings.t("0 (local)")) + " " + m.local.Title + "\n"
+	view += "  " + CommandStyle.Render(m.local.Command) + "\n"
+	if m.local.Source != "" {
+		view += "  " + HelpStyle.Render(m.settings.tf("From %s", m.local.Source)) + "\n"
+	}
+	return view + "\n" + m.settings.help("0: use local match", "q: quit") + "\n"
+}
+
+// refusalView explains why no commands were generated and suggests a
+// shell task to ask for instead.
+func (m LoadingModel) refusalView(refusal *llm.Refusal) string {
+	view := "\n" + WarningLowStyle.Render(m.settings.tf("Not a shell task: %s", refusal.Reason)) + "\n"
+	if refusal.Suggestion != "" {
+		view += DescriptionStyle.Render(m.settings.tf("Try instead: %s", refusal.Suggestion)) + "\n"
+	}
+	return view
+}
+
+// errorView explains why generation failed and what can be done next.
+func (m LoadingModel) errorView() string {
+	hints := []string{"r: retry"}
+	if m.back != nil {
+		hints = append(hints, "e: edit query")
+	}
+	if m.local != nil {
+		hints = append(hints, "0: use local match")
+	}
+	hints = append(hints, "q: quit")
+
+	return fmt.Sprintf(
+		"\n%s\n%s\n\n%s\n",
+		WarningHighStyle.Render(m.settings.t("Couldn't generate options")),
+		DescriptionStyle.Width(max(m.width-2, 20)).Render(m.err.Error()),
+		m.settings.help(hints...),
+	)
+}
+
+// largeRequestView shows the estimated size and cost of a large request
+// and asks before sending it.
+func (m LoadingModel) largeRequestView(large *commands.LargeRequestError) string {
+	size := m.settings.tf("About %d tokens, including attached context.", large.Tokens)
+	if large.Cost > 0 {
+		size = m.settings.tf("About %d tokens, including attached context (~$%.2f on %s).", large.Tokens, large.Cost, large.Model)
+	}
+
+	hints := []string{"y: send anyway"}
+	if m.back != nil {
+		hints = append(hints, "e: edit query")
+	}
+	hints = append(hints, "q: quit")
+
+	return fmt.Sprintf(
+		"\n%s\n%s\n\n%s\n",
+		WarningLowStyle.Render(m.settings.t("Large request")),
+		DescriptionStyle.Width(max(m.width-2, 20)).Render(size),
+		m.settings.help(hints...),
+	)
+}
+
+// rateLimitStatus counts down to the next retry after a rate limit.
+func (m LoadingModel) rateLimitStatus() string {
+	return m.settings.tf("Rate limited, retrying in %ds...", m.retrySeconds())
+}
 
-	return fmt.Sprintf("\n%s Generating options...\n", m.spinner.View())
+// retrySeconds is the whole number of seconds left until the retry.
+func (m LoadingModel) retrySeconds() int {
+	return max(int(time.Until(m.retryAt).Round(time.Second).Seconds()), 1)
 }
 
 // Err returns any error encountered during loading.
@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
+)
+
+// failedLoading submits query at a prompt and fails its generation.
+func failedLoading(t *testing.T, query string) tea.Model {
+	t.Helper()
+	var m tea.Model = NewInputModel(nil, nil, Settings{Static: true}).Submit(query)
+	m, cmd := m.Update(optionsMsg{err: errors.New("429 Too Many Requests")})
+	if cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Fatal("a failed generation quit")
+		}
+	}
+	return m
+}
+
+func TestLoadingShowsErrors(t *testing.T) {
+	m := failedLoading(t, "list open ports")
+	view := m.View()
+	for _, want := range []string{"Couldn't generate options", "429 Too Many Requests", "r: retry", "e: edit query", "q: quit"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestLoadingErrorRetry(t *testing.T) {
+	m := failedLoading(t, "list open ports")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+
+	loading, ok := m.(LoadingModel)
+	if !ok || loading.Err() != nil || loading.query != "list open ports" || cmd == nil {
+		t.Fatalf("r gave %T (err %v), want generation started again", m, loading.Err())
+	}
+	if loading.back == nil {
+		t.Error("retry lost the prompt to go back to")
+	}
+}
+
+func TestLoadingErrorEditQuery(t *testing.T) {
+	m := failedLoading(t, "list open ports")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+
+	input, ok := m.(InputModel)
+	if !ok {
+		t.Fatalf("e gave %T, want InputModel", m)
+	}
+	if got := input.input.Value(); got != "list open ports" {
+		t.Errorf("query = %q, want it kept for editing", got)
+	}
+}
+
+func TestLoadingErrorQuit(t *testing.T) {
+	m := failedLoading(t, "list open ports")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("q didn't quit")
+	}
+	if m.(LoadingModel).Err() == nil {
+		t.Error("quitting lost the error for the caller to report")
+	}
+}
+
+func TestLoadingRefusalQuits(t *testing.T) {
+	m := NewLoadingModel(nil, "write me a poem", Settings{Static: true})
+	_, cmd := m.Update(optionsMsg{err: &llm.Refusal{Reason: "not a shell task"}})
+	if cmd == nil {
+		t.Fatal("a refusal didn't quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("a refusal didn't quit")
+	}
+}
+
+func TestLoadingShowsElapsedTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    string
+		wantNot string
+	}{
+		{name: "just started", elapsed: 0, wantNot: "elapsed"},
+		{name: "slow model", elapsed: 12 * time.Second, want: "12s elapsed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewLoadingModel(nil, "list open ports", Settings{Static: true})
+			m.started = time.Now().Add(-tt.elapsed)
+			view := m.View()
+			if tt.want != "" && !strings.Contains(view, tt.want) {
+				t.Errorf("view missing %q:\n%s", tt.want, view)
+			}
+			if tt.wantNot != "" && strings.Contains(view, tt.wantNot) {
+				t.Errorf("view has %q:\n%s", tt.wantNot, view)
+			}
+		})
+	}
+}
+
+func TestLoadingElapsedTickStopsOnError(t *testing.T) {
+	m := NewLoadingModel(nil, "list open ports", Settings{Static: true})
+	if _, cmd := m.Update(elapsedMsg{}); cmd == nil {
+		t.Error("elapsed time stopped counting while generating")
+	}
+
+	failed := failedLoading(t, "list open ports")
+	if _, cmd := failed.Update(elapsedMsg{}); cmd != nil {
+		t.Error("elapsed time kept counting after generation failed")
+	}
+	if strings.Contains(failed.View(), "elapsed") {
+		t.Errorf("error view shows elapsed time:\n%s", failed.View())
+	}
+}
+
+// localSettings offers the same local match for every query.
+func localSettings(command string) Settings {
+	return Settings{Static: true, LocalMatch: func(query string) *commands.Option {
+		return &commands.Option{Title: query, Command: command, Source: "your history"}
+	}}
+}
+
+func TestLoadingShowsLocalMatch(t *testing.T) {
+	m := NewInputModel(nil, nil, localSettings("ss -tlnp")).Submit("list open ports")
+	view := m.View()
+	for _, want := range []string{"Generating options...", "0 (local)", "ss -tlnp", "From your history", "0: use local match"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	if view := NewLoadingModel(nil, "list open ports", Settings{Static: true}).View(); strings.Contains(view, "local") {
+		t.Errorf("view without a local match mentions one:\n%s", view)
+	}
+}
+
+func TestLoadingUseLocalMatch(t *testing.T) {
+	var m tea.Model = NewInputModel(nil, nil, localSettings("ss -tlnp")).Submit("list open ports")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+
+	selector, ok := m.(SelectorModel)
+	if !ok {
+		t.Fatalf("0 gave %T, want SelectorModel", m)
+	}
+	if len(selector.options) != 1 || selector.options[0].Command != "ss -tlnp" {
+		t.Errorf("options = %+v, want just the local match", selector.options)
+	}
+	if selector.Query() != "list open ports" || selector.back == nil {
+		t.Error("the selector lost the query or the prompt to go back to")
+	}
+}
+
+func TestLoadingLocalMatchComesFirst(t *testing.T) {
+	tests := []struct {
+		name      string
+		generated []commands.Option
+		want      []string
+	}{
+		{
+			name:      "new command",
+			generated: []commands.Option{{Command: "lsof -i -P"}, {Command: "netstat -tln"}},
+			want:      []string{"ss -tlnp", "lsof -i -P", "netstat -tln"},
+		},
+		{
+			name:      "also generated",
+			generated: []commands.Option{{Command: "lsof -i -P"}, {Command: "ss -tlnp"}},
+			want:      []string{"lsof -i -P", "ss -tlnp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewLoadingModel(nil, "list open ports", localSettings("ss -tlnp"))
+			next, _ := m.Update(optionsMsg{options: tt.generated})
+
+			var got []string
+			for _, opt := range next.(SelectorModel).options {
+				got = append(got, opt.Command)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("options = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadingErrorOffersLocalMatch(t *testing.T) {
+	m := NewLoadingModel(nil, "list open ports", localSettings("ss -tlnp"))
+	failed, _ := m.Update(optionsMsg{err: errors.New("connection refused")})
+	if view := failed.View(); !strings.Contains(view, "0: use local match") {
+		t.Errorf("error view doesn't offer the local match:\n%s", view)
+	}
+
+	next, _ := failed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+	if _, ok := next.(SelectorModel); !ok {
+		t.Errorf("0 gave %T, want SelectorModel", next)
+	}
+}
+
+func TestLoadingConfirmsLargeRequests(t *testing.T) {
+	mock := &llm.MockClient{Response: []llm.CommandOption{{Title: "Disk usage", Command: "df -h"}}}
+	generator := commands.NewGeneratorWithEvaluator(mock, nil)
+	generator.AddContext("standard input", strings.Repeat("starting up\n", 300))
+	generator.SetConfirmLimit(100, "claude-sonnet-4-5")
+
+	var m tea.Model = NewInputModel(generator, nil, Settings{Static: true}).Submit("why is the disk full")
+	m, _ = m.Update(m.(LoadingModel).loadOptions())
+	if mock.LastQuery != "" {
+		t.Fatal("a large request was sent without asking")
+	}
+	view := m.View()
+	for _, want := range []string{"Large request", "About 950 tokens", "on claude-sonnet-4-5", "y: send anyway", "e: edit query"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	if next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); next.(LoadingModel).largeRequest() == nil {
+		t.Error("r retried a large request without confirming it")
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	loading, ok := m.(LoadingModel)
+	if !ok || loading.Err() != nil || cmd == nil {
+		t.Fatalf("y gave %T (err %v), want generation started again", m, loading.Err())
+	}
+	if m, _ = m.Update(loading.loadOptions()); mock.LastQuery == "" {
+		t.Error("the confirmed request wasn't sent")
+	}
+	if _, ok := m.(SelectorModel); !ok {
+		t.Errorf("confirmed request gave %T, want SelectorModel", m)
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doubleClickInterval is how soon a second click on the same option
+// selects it.
+const doubleClickInterval = 400 * time.Millisecond
+
+// updateMouse moves the cursor with the scroll wheel, highlights the
+// clicked option and selects it on a double-click.
+func (m SelectorModel) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if m.cursor > 0 {
+			m.cursor--
+			return m, m.announceCurrent()
+		}
+
+	case tea.MouseButtonWheelDown:
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+			return m, m.announceCurrent()
+		}
+
+	case tea.MouseButtonLeft:
+		i, ok := m.optionAt(msg.Y)
+		if !ok || msg.X >= m.listWidth() {
+			return m, nil
+		}
+		double := i == m.cursor && time.Since(m.lastClick) < doubleClickInterval
+		m.cursor, m.lastClick = i, time.Now()
+		if double {
+			return m.selectCurrent()
+		}
+		return m, m.announceCurrent()
+	}
+
+	return m, nil
+}
+
+// optionAt returns the index into visible of the option drawn on screen
+// row y. Rows are only known in alt-screen mode; inline, the selector's
+// position in the terminal depends on what was printed before it.
+func (m SelectorModel) optionAt(y int) (int, bool) {
+	if !m.settings.AltScreen || len(m.visible) == 0 {
+		return 0, false
+	}
+
+	row := y - (lipgloss.Height(m.header()) - 1)
+	if row < 0 || row >= m.viewport.Height {
+		return 0, false
+	}
+
+	line := row + m.viewport.YOffset
+	_, starts := m.renderOptions()
+	for i := range m.visible {
+		if line >= starts[i] && line < starts[i+1] {
+			return i, true
+		}
+	}
+	return 0, false
+}
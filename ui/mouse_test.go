@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestSelectorMouse(t *testing.T) {
+	options := []commands.Option{
+		{Title: "List", Command: "ls"},
+		{Title: "List all", Command: "ls -la"},
+		{Title: "Tree", Command: "tree"},
+	}
+	next, _ := NewSelector(options, nil, Settings{Static: true, AltScreen: true}).Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	m := next.(SelectorModel)
+
+	next, _ = m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	if got := next.(SelectorModel).cursor; got != 1 {
+		t.Errorf("cursor after wheel down = %d, want 1", got)
+	}
+
+	// Click on the third option's title row.
+	_, starts := m.renderOptions()
+	y := lipgloss.Height(m.header()) - 1 + starts[2]
+	click := tea.MouseMsg{Button: tea.MouseButtonLeft, Action: tea.MouseActionPress, Y: y}
+
+	next, _ = next.Update(click)
+	sm := next.(SelectorModel)
+	if sm.cursor != 2 || sm.Selected() != nil {
+		t.Fatalf("single click: cursor = %d, selected = %v; want 2, nil", sm.cursor, sm.Selected())
+	}
+
+	next, _ = next.Update(click)
+	if got := next.(SelectorModel).Selected(); got == nil || got.Command != "tree" {
+		t.Errorf("double click selected %+v, want tree", got)
+	}
+}
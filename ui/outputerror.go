@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+)
+
+// deliveredMsg is sent when Settings.Deliver has finished with a chosen
+// option.
+type deliveredMsg struct {
+	option    commands.Option
+	mode      string // the output mode it was delivered in
+	delivered bool   // false when the mode left the output to after the UI
+	err       error
+}
+
+// deliver runs Settings.Deliver for opt in the background.
+func deliver(settings Settings, opt commands.Option, mode string) tea.Cmd {
+	return func() tea.Msg {
+		delivered, err := settings.Deliver(opt, mode)
+		return deliveredMsg{option: opt, mode: mode, delivered: delivered, err: err}
+	}
+}
+
+// OutputErrorModel is shown when the chosen command couldn't be output,
+// for example because no clipboard tool is installed or the file already
+// exists. The command can be retried, printed or copied instead, or esc
+// goes back to the list, rather than the UI exiting with an error that
+// scrolls away.
+type OutputErrorModel struct {
+	parent   SelectorModel
+	option   commands.Option
+	mode     string
+	err      error
+	settings Settings
+	retrying bool
+}
+
+// newOutputErrorModel reports a failed delivery on top of the selector it
+// was chosen in.
+func newOutputErrorModel(parent SelectorModel, msg deliveredMsg) OutputErrorModel {
+	return OutputErrorModel{
+		parent:   parent,
+		option:   msg.option,
+		mode:     msg.mode,
+		err:      msg.err,
+		settings: parent.settings,
+	}
+}
+
+// Init announces the failure and what can be done about it.
+func (m OutputErrorModel) Init() tea.Cmd {
+	return m.settings.announce("%s: %v. r to retry, p to print instead, escape to go back.", m.settings.t(m.title()), m.err)
+}
+
+// Update retries, switches mode, goes back to the list or quits.
+func (m OutputErrorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.retrying {
+			if msg.String() == "ctrl+c" {
+				return m.quit()
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m.quit()
+		case "esc":
+			m.parent.status = ""
+			return m.parent, nil
+		case "r", "enter":
+			m.retrying = true
+			return m, deliver(m.settings, m.option, m.mode)
+		case "c":
+			if m.mode != "clipboard" {
+				m.retrying = true
+				return m, deliver(m.settings, m.option, "clipboard")
+			}
+		case "p":
+			// Printing happens once the UI has exited, so it can't fail
+			// here.
+			return m.parent.Update(deliveredMsg{option: m.option, mode: "stdout"})
+		}
+
+	case deliveredMsg:
+		if msg.err != nil {
+			m.mode, m.err, m.retrying = msg.mode, msg.err, false
+			return m, m.Init()
+		}
+		return m.parent.Update(msg)
+
+	case tea.WindowSizeMsg:
+		parent, _ := m.parent.Update(msg)
+		m.parent = parent.(SelectorModel)
+	}
+	return m, nil
+}
+
+// quit exits without outputting anything.
+func (m OutputErrorModel) quit() (tea.Model, tea.Cmd) {
+	m.parent.quitting = true
+	return m.parent, tea.Quit
+}
+
+// title says what couldn't be done, for the mode that failed.
+func (m OutputErrorModel) title() string {
+	switch m.mode {
+	case "clipboard":
+		return "Couldn't copy to the clipboard"
+	case "file":
+		return "Couldn't write the file"
+	case "nvim":
+		return "Couldn't send to Neovim"
+	}
+	return "Couldn't output the command"
+}
+
+// View renders the error, the command and the ways forward.
+func (m OutputErrorModel) View() string {
+	width := m.parent.width
+
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(WarningHighStyle.Render(m.settings.t(m.title())) + "\n")
+	b.WriteString(DescriptionStyle.Width(max(width-2, 20)).Render(m.err.Error()) + "\n\n")
+	b.WriteString(indent(renderCommand(m.option.Command, width-4, true, m.settings, nil, m.option.Risk), 2) + "\n\n")
+
+	if m.retrying {
+		b.WriteString(HelpStyle.Render(m.settings.t("Trying again...")) + "\n")
+		return b.String()
+	}
+	hints := []string{"r: retry"}
+	if m.mode != "clipboard" {
+		hints = append(hints, "c: copy instead")
+	}
+	hints = append(hints, "p: print instead", "esc: back", "q: quit")
+	b.WriteString(m.settings.help(hints...) + "\n")
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+)
+
+// failingDeliver fails the first failures calls, then delivers, recording
+// the mode of every call.
+func failingDeliver(failures int, modes *[]string) func(commands.Option, string) (bool, error) {
+	return func(opt commands.Option, mode string) (bool, error) {
+		*modes = append(*modes, mode)
+		if len(*modes) <= failures {
+			return false, errors.New("clipboard not available (install xclip or wl-clipboard)")
+		}
+		return true, nil
+	}
+}
+
+// press sends a key and runs the command it returns, if any, feeding the
+// result back in, as the program would.
+func press(t *testing.T, m tea.Model, key tea.KeyMsg) tea.Model {
+	t.Helper()
+	m, cmd := m.Update(key)
+	if cmd == nil {
+		return m
+	}
+	if msg, ok := cmd().(deliveredMsg); ok {
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func newDeliveringSelector(mode string, deliver func(commands.Option, string) (bool, error)) SelectorModel {
+	settings := Settings{Static: true, OutputMode: mode, Deliver: deliver}
+	return NewSelector([]commands.Option{{Title: "List", Command: "ls -la"}}, nil, settings)
+}
+
+func TestSelectorDeliversBeforeQuitting(t *testing.T) {
+	var modes []string
+	m := press(t, newDeliveringSelector("clipboard", failingDeliver(0, &modes)), tea.KeyMsg{Type: tea.KeyEnter})
+
+	s, ok := m.(SelectorModel)
+	if !ok || s.Selected() == nil || !s.Delivered() || !s.quitting {
+		t.Fatalf("after enter got %T %+v, want a delivered selection", m, m)
+	}
+	if s.OutputMode() != "clipboard" || len(modes) != 1 {
+		t.Errorf("delivered in %v, output mode %q; want clipboard once", modes, s.OutputMode())
+	}
+}
+
+func TestOutputErrorRetry(t *testing.T) {
+	var modes []string
+	m := press(t, newDeliveringSelector("clipboard", failingDeliver(1, &modes)), tea.KeyMsg{Type: tea.KeyEnter})
+
+	failed, ok := m.(OutputErrorModel)
+	if !ok {
+		t.Fatalf("failed delivery gave %T, want OutputErrorModel", m)
+	}
+	view := failed.View()
+	for _, want := range []string{"Couldn't copy to the clipboard", "install xclip", "ls -la", "r: retry", "p: print instead"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "c: copy instead") {
+		t.Errorf("view offers copying when copying failed:\n%s", view)
+	}
+
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	s, ok := m.(SelectorModel)
+	if !ok || s.Selected() == nil || !s.Delivered() {
+		t.Fatalf("retry gave %T, want a delivered selection", m)
+	}
+}
+
+func TestOutputErrorPrintInstead(t *testing.T) {
+	var modes []string
+	m := press(t, newDeliveringSelector("clipboard", failingDeliver(1, &modes)), tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+
+	s, ok := m.(SelectorModel)
+	if !ok || s.Selected() == nil || s.Delivered() || !s.quitting {
+		t.Fatalf("print instead gave %T, want a selection left to print", m)
+	}
+	if s.OutputMode() != "stdout" {
+		t.Errorf("OutputMode() = %q, want stdout", s.OutputMode())
+	}
+}
+
+func TestOutputErrorCopyInstead(t *testing.T) {
+	var modes []string
+	m := press(t, newDeliveringSelector("file", failingDeliver(1, &modes)), tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(m.View(), "Couldn't write the file") {
+		t.Fatalf("view doesn't explain the failure:\n%s", m.View())
+	}
+
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	s, ok := m.(SelectorModel)
+	if !ok || !s.Delivered() || s.OutputMode() != "clipboard" {
+		t.Fatalf("copy instead gave %T, want a selection delivered to the clipboard", m)
+	}
+	if len(modes) != 2 || modes[1] != "clipboard" {
+		t.Errorf("delivered in %v, want file then clipboard", modes)
+	}
+}
+
+func TestOutputErrorBackAndQuit(t *testing.T) {
+	var modes []string
+	failed := press(t, newDeliveringSelector("clipboard", failingDeliver(10, &modes)), tea.KeyMsg{Type: tea.KeyEnter})
+
+	m := press(t, failed, tea.KeyMsg{Type: tea.KeyEsc})
+	if s, ok := m.(SelectorModel); !ok || s.Selected() != nil || s.quitting {
+		t.Errorf("esc gave %T, want the list back with nothing selected", m)
+	}
+
+	m, cmd := failed.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if s, ok := m.(SelectorModel); !ok || s.Selected() != nil || cmd == nil {
+		t.Errorf("q gave %T, want to quit with nothing selected", m)
+	}
+}
+
+func TestSelectorAuditsBeforeDelivering(t *testing.T) {
+	tests := []struct {
+		name         string
+		auditErr     error
+		wantDelivery bool
+	}{
+		{name: "recorded", wantDelivery: true},
+		{name: "audit log fails", auditErr: errors.New("audit log: permission denied")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var modes []string
+			var audited []string
+			s := newDeliveringSelector("clipboard", failingDeliver(0, &modes))
+			s.settings.Audit = func(_ string, _ []commands.Option, selected commands.Option) error {
+				if len(modes) > 0 {
+					t.Error("Audit ran after Deliver")
+				}
+				audited = append(audited, selected.Command)
+				return tt.auditErr
+			}
+
+			m := press(t, s, tea.KeyMsg{Type: tea.KeyEnter})
+			got, ok := m.(SelectorModel)
+			if !ok || !got.quitting {
+				t.Fatalf("after enter got %T, want the selector quitting", m)
+			}
+			if len(audited) != 1 || audited[0] != "ls -la" {
+				t.Errorf("audited %v, want the chosen command once", audited)
+			}
+			if delivered := len(modes) > 0; delivered != tt.wantDelivery {
+				t.Errorf("delivered = %v, want %v", delivered, tt.wantDelivery)
+			}
+			if !tt.wantDelivery && (got.Selected() != nil || !errors.Is(got.Err(), tt.auditErr)) {
+				t.Errorf("Selected() = %v, Err() = %v; want nothing selected and the audit error", got.Selected(), got.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+)
+
+// newPipelineModel explains a command one segment at a time, with what
+// each segment reads and produces, so long pipelines can be audited.
+func newPipelineModel(parent SelectorModel, command string) DocModel {
+	settings, width := parent.settings, max(parent.width-2, 20)
+	m := newDocModel(parent, settings.t("Pipeline steps"), func() (string, error) {
+		segments, err := parent.generator.AnnotatePipeline(context.Background(), command)
+		if err != nil {
+			return "", err
+		}
+		return formatSegments(segments, settings, width), nil
+	})
+	m.loading = "Analyzing pipeline..."
+	return m
+}
+
+// formatSegments lays out annotated segments as plain text, numbered and
+// prefixed with the operator joining them to the previous one.
+func formatSegments(segments []commands.Segment, settings Settings, width int) string {
+	wrap := lipgloss.NewStyle().Width(width - 3)
+
+	var b strings.Builder
+	for i, segment := range segments {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		line := segment.Command
+		if segment.Operator != "" {
+			line = segment.Operator + " " + line
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, line)
+		b.WriteString(indent(wrap.Render(settings.tf("In: %s", segment.Note.Input)), 3) + "\n")
+		b.WriteString(indent(wrap.Render(settings.tf("Out: %s", segment.Note.Output)), 3) + "\n")
+		b.WriteString(indent(wrap.Render(segment.Note.Explanation), 3) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// previewMinWidth is the narrowest terminal the preview pane fits beside
+// the option list in; on narrower ones it goes underneath.
+const previewMinWidth = 100
+
+// previewBeside reports whether the preview pane is laid out to the right
+// of the list rather than below it.
+func (m SelectorModel) previewBeside() bool {
+	return m.width >= previewMinWidth
+}
+
+// listWidth is the width the option list is rendered at.
+func (m SelectorModel) listWidth() int {
+	if m.settings.Preview && m.previewBeside() {
+		return m.width * 2 / 5
+	}
+	return m.width
+}
+
+// renderCompactOptions renders each visible option as its title and the
+// first line of its command, leaving the detail to the preview pane. Like
+// renderOptions, it returns the line each option starts on.
+func (m SelectorModel) renderCompactOptions() (string, []int) {
+	var b strings.Builder
+	starts := make([]int, 0, len(m.visible)+1)
+
+	contentWidth := m.listWidth() - 4
+	glyphs := m.settings.glyphs()
+
+	if len(m.visible) == 0 {
+		b.WriteString(HelpStyle.Render(m.settings.t("No options match the filter")))
+		b.WriteString("\n")
+	}
+
+	for i, idx := range m.visible {
+		option := m.options[idx]
+
+		cursor := " "
+		title := TitleStyle.Render(option.Title)
+		if m.cursor == i {
+			cursor = SelectedStyle.Render(glyphs.Cursor)
+			title = SelectedStyle.Render(option.Title)
+		}
+
+		switch {
+		case option.Risk != nil && option.Risk.Level != safety.RiskNone:
+			title += " " + riskStyle(option.Risk.Level).Render(riskGlyph(option.Risk.Level, glyphs))
+		case option.Risk == nil && m.checking(idx):
+			title += " " + m.settings.spinnerView(m.spinner)
+		case option.Risk == nil && m.isUnverified(idx):
+			title += " " + WarningLowStyle.Render("["+m.settings.t("unverified")+"]")
+		}
+
+		command, _ := collapse(strings.SplitN(option.Command, "\n", 2)[0], contentWidth-2, glyphs.Ellipsis)
+		if strings.Contains(option.Command, "\n") && !strings.HasSuffix(command, glyphs.Ellipsis) {
+			command += " " + glyphs.Ellipsis
+		}
+
+		starts = append(starts, i*2)
+		fmt.Fprintf(&b, "%s %s\n", cursor, title)
+		b.WriteString(indent(CommandStyle.Render(command), 2) + "\n")
+	}
+
+	starts = append(starts, len(m.visible)*2)
+	return b.String(), starts
+}
+
+// renderPreview renders everything about the highlighted option: title and
+// badges, the full wrapped command, its risk, notes and description.
+// maxHeight clips the pane in alt-screen mode; 0 means no limit.
+func (m SelectorModel) renderPreview(maxHeight int) string {
+	if len(m.visible) == 0 {
+		return ""
+	}
+
+	beside := m.previewBeside()
+	width := m.width
+	if beside {
+		width = m.width - m.listWidth()
+	}
+	// Leave room for the border and padding.
+	contentWidth := max(width-4, 20)
+
+	idx := m.visible[m.cursor]
+	option := m.options[idx]
+	glyphs := m.settings.glyphs()
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(option.Title))
+	if badges := formatBadges(option.Badges(), m.settings); badges != "" {
+		b.WriteString("  " + badges)
+	}
+	b.WriteString("\n")
+	b.WriteString(m.renderOptionCommand(option, contentWidth, true, m.distinct()[idx]) + "\n")
+	if option.Risk != nil {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(formatRiskWarning(option.Risk, true, glyphs)) + "\n")
+	} else if m.checking(idx) {
+		b.WriteString(m.settings.spinnerView(m.spinner) + CheckingStyle.Render(" "+m.settings.t("checking safety...")) + "\n")
+	} else if m.isUnverified(idx) {
+		b.WriteString(lipgloss.NewStyle().Width(contentWidth).Render(unverifiedWarning(m.settings)) + "\n")
+	}
+	for _, note := range m.notes(option) {
+		b.WriteString(note + "\n")
+	}
+	b.WriteString(DescriptionStyle.Width(contentWidth).Render(option.Description))
+
+	border := lipgloss.NormalBorder()
+	if m.settings.Plain {
+		border = lipgloss.ASCIIBorder()
+	}
+	style := lipgloss.NewStyle().
+		Border(border, !beside, false, false, beside).
+		BorderForeground(subtleColor).
+		Padding(0, 1).
+		MaxHeight(maxHeight)
+	if maxHeight <= 0 {
+		style = style.UnsetMaxHeight()
+	}
+	return style.Render(b.String())
+}
+
+// withPreview lays the preview pane out beside or below the rendered list.
+func (m SelectorModel) withPreview(list string, maxHeight int) string {
+	list = strings.TrimSuffix(list, "\n")
+	if m.previewBeside() {
+		list = lipgloss.NewStyle().Width(m.listWidth()).Render(list)
+		return lipgloss.JoinHorizontal(lipgloss.Top, list, m.renderPreview(maxHeight))
+	}
+	return list + "\n" + m.renderPreview(0)
+}
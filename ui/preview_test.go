@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestSelectorPreview(t *testing.T) {
+	options := []commands.Option{
+		{Title: "Find logs", Command: "find . -name '*.log'", Description: "Lists every log file FIRST"},
+		{Title: "Count logs", Command: "find . -name '*.log' | wc -l", Description: "Counts the log files SECOND"},
+	}
+
+	for _, width := range []int{120, 60} {
+		m := NewSelector(options, nil, Settings{Static: true, Preview: true})
+		m.width = width
+
+		view := m.View()
+		if !strings.Contains(view, "FIRST") || strings.Contains(view, "SECOND") {
+			t.Errorf("width %d: preview should show only the highlighted description:\n%s", width, view)
+		}
+
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		view = next.View()
+		if strings.Contains(view, "FIRST") || !strings.Contains(view, "SECOND") {
+			t.Errorf("width %d: preview didn't follow the cursor:\n%s", width, view)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// newSafetyDetailModel shows an in-depth safety assessment of a command:
+// the full reasoning, what data it could lose, the paths it touches and
+// whether it can be undone.
+func newSafetyDetailModel(parent SelectorModel, command string) DocModel {
+	settings, width := parent.settings, max(parent.width-2, 20)
+	m := newDocModel(parent, settings.t("Safety details"), func() (string, error) {
+		detail, err := parent.generator.ExplainRisk(context.Background(), command)
+		if err != nil {
+			return "", err
+		}
+		return formatRiskDetail(command, detail, settings, width), nil
+	})
+	m.loading = "Analyzing risks..."
+	return m
+}
+
+// formatRiskDetail lays out an assessment as plain text, wrapped to width,
+// so it reads the same in the pager and when announced.
+func formatRiskDetail(command string, detail *safety.RiskDetail, settings Settings, width int) string {
+	wrap := lipgloss.NewStyle().Width(width)
+
+	level := settings.t("none detected")
+	if detail.Level != safety.RiskNone {
+		level = settings.t(detail.Level.String())
+	}
+
+	var b strings.Builder
+	b.WriteString(command + "\n\n")
+	b.WriteString(settings.tf("Risk: %s", level) + "\n\n")
+	b.WriteString(wrap.Render(detail.Reasoning) + "\n\n")
+
+	b.WriteString(settings.t("Data at risk:") + "\n")
+	dataLoss := detail.DataLoss
+	if dataLoss == "" {
+		dataLoss = settings.t("none detected")
+	}
+	b.WriteString(wrap.Render(dataLoss) + "\n\n")
+
+	if len(detail.Paths) > 0 {
+		b.WriteString(settings.t("Affected paths:") + "\n")
+		for _, path := range detail.Paths {
+			fmt.Fprintf(&b, "  - %s\n", path)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(settings.tf("Reversible: %s", settings.t(detail.Reversible)) + "\n")
+	b.WriteString(wrap.Render(detail.Recovery) + "\n")
+
+	return b.String()
+}
@@ -2,68 +2,516 @@ package ui
 
 import (
 	"context"
-	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/pixielabs/1lm/commands"
-	"github.com/pixielabs/1lm/safety"
+	"github.com/pixielabs/1lm/snippets"
 	"golang.org/x/term"
 )
 
-// riskResultMsg is sent when background safety evaluation completes.
+// safetyDeadlineMsg is sent when the safety checks started in run have had
+// the generator's SafetyTimeout to finish.
+type safetyDeadlineMsg struct {
+	run int
+}
+
+// riskResultMsg is sent when the background safety check of one option
+// completes.
 type riskResultMsg struct {
-	options []commands.Option
+	id      int               // the checked option's ID
+	options []commands.Option // the option, followed by any safer version
 	err     error
 }
 
 // SelectorModel lets the user pick from generated command options.
 type SelectorModel struct {
+	query      string
 	options    []commands.Option
 	cursor     int
 	selected   *commands.Option
 	quitting   bool
 	width      int
+	height     int
+	viewport   viewport.Model // only used in alt-screen mode
 	generator  *commands.Generator
-	safetyDone bool
+	settings   Settings
+	ids        []int        // each option's ID, stable as safer versions are inserted
+	nextID     int          // the ID the next inserted option gets
+	riskChecks map[int]bool // IDs of options whose safety check is running
+	safetyErr  error        // the first safety check that failed
+	unverified map[int]bool // IDs of options whose safety check failed or timed out
+	safetyRun  int          // counts rechecks, so a stale deadline is ignored
 	spinner    spinner.Model
+	filter     textinput.Model
+	filtering  bool
+	visible    []int           // indices into options that match the filter
+	blurred    bool            // terminal lost focus; animations are paused
+	showFull   bool            // expand multi-line commands beyond maxCollapsedLines
+	reflow     bool            // break long pipelines across lines
+	expanded   map[string]bool // commands whose full description is shown
+	status     string
+	lastClick  time.Time // for detecting double-clicks
+
+	// delivering is the chosen option while Settings.Deliver outputs it.
+	delivering *commands.Option
+	delivered  bool   // Deliver did the output step
+	outputMode string // the mode to output in, when not the configured one
+	err        error  // why Audit couldn't record the chosen option
+
+	// back is the prompt the query was typed at, which esc returns to so
+	// the query can be reworded. Nil when there is no prompt to go back to,
+	// as for saved snippets.
+	back *InputModel
+
+	// pendingSave is the option waiting on the user to choose whether its
+	// literals become placeholders before it is saved as a snippet.
+	pendingSave *commands.Option
+	literals    []snippets.Literal
+}
+
+// snippetSavedMsg is sent when the highlighted option has been saved.
+type snippetSavedMsg struct {
+	name string
+	err  error
 }
 
-// NewSelector creates a new option selector with background safety evaluation.
-func NewSelector(options []commands.Option, generator *commands.Generator) SelectorModel {
-	width := 80
-	if w, _, err := term.GetSize(0); err == nil && w > 0 {
-		width = w
+// NewSelector creates a new option selector with background safety
+// evaluation. A nil generator skips evaluation, for options that already
+// carry their risk (e.g. saved snippets) and must not call the API.
+func NewSelector(options []commands.Option, generator *commands.Generator, settings Settings) SelectorModel {
+	width, height := 80, 24
+	if w, h, err := term.GetSize(0); err == nil && w > 0 {
+		width, height = w, h
 	}
 
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = CheckingStyle
+	fi := textinput.New()
+	fi.Prompt = "/"
+	fi.Placeholder = settings.t("filter")
 
-	return SelectorModel{
+	m := SelectorModel{
 		options:   options,
 		width:     width,
+		height:    height,
+		viewport:  viewport.New(width, height),
 		generator: generator,
-		spinner:   s,
+		settings:  settings,
+		spinner:   settings.newSpinner(CheckingStyle),
+		filter:    fi,
+		visible:   filterOptions(options, ""),
+		showFull:  settings.FullCommands,
+		expanded:  map[string]bool{},
+	}
+	// Options can share a command, e.g. when parallel models agree, so
+	// safety results are matched to them by ID rather than by command.
+	m.ids = make([]int, len(options))
+	for i := range options {
+		m.ids[i] = i
+	}
+	m.nextID = len(options)
+	// Without a generator there is nothing to wait for.
+	if generator != nil {
+		m.riskChecks = make(map[int]bool, len(options))
+		for _, id := range m.ids {
+			m.riskChecks[id] = true
+		}
 	}
+	if settings.AltScreen {
+		m.syncViewport()
+	}
+	return m
 }
 
 // Init starts background safety evaluation and the spinner animation.
 func (m SelectorModel) Init() tea.Cmd {
-	return tea.Batch(m.evaluateSafety, m.spinner.Tick)
+	if m.safetyDone() {
+		return tea.Sequence(
+			m.settings.announce("%d options. Up and down to move, enter to select, q to quit.", len(m.options)),
+			m.announceCurrent(),
+		)
+	}
+
+	var announceFallback, announceRedacted, announceTrimmed, announceSuspicious tea.Cmd
+	if fallback := m.fallback(); fallback != "" {
+		announceFallback = m.settings.announce("Answered by fallback model %s", fallback)
+	}
+	if redacted := m.redacted(); len(redacted) > 0 {
+		announceRedacted = m.settings.announce("Redacted before sending: %s", strings.Join(redacted, ", "))
+	}
+	if trimmed := m.trimmed(); len(trimmed) > 0 {
+		announceTrimmed = m.settings.announce("Context trimmed for token limit: %s", strings.Join(trimmed, ", "))
+	}
+	if suspicious := m.suspicious(); len(suspicious) > 0 {
+		announceSuspicious = m.settings.announce("Possible prompt injection in: %s", strings.Join(suspicious, ", "))
+	}
+
+	return tea.Batch(
+		m.evaluateSafety(),
+		m.safetyDeadline(),
+		m.settings.spinnerTick(m.spinner),
+		tea.Sequence(
+			announceFallback,
+			announceRedacted,
+			announceTrimmed,
+			announceSuspicious,
+			m.settings.announce("%d options generated. Up and down to move, enter to select, q to quit.", len(m.options)),
+			m.announceCurrent(),
+		),
+	)
+}
+
+// fallback returns the fallback model that answered, or "" if the primary
+// did.
+func (m SelectorModel) fallback() string {
+	if len(m.options) == 0 {
+		return ""
+	}
+	return m.options[0].Fallback
+}
+
+// redacted returns the translated kinds of secret removed from the query
+// before it was sent.
+func (m SelectorModel) redacted() []string {
+	if len(m.options) == 0 {
+		return nil
+	}
+
+	labels := make([]string, len(m.options[0].Redacted))
+	for i, label := range m.options[0].Redacted {
+		labels[i] = m.settings.t(label)
+	}
+	return labels
+}
+
+// trimmed returns the translated sources of attached context that were
+// cut to fit the token limit.
+func (m SelectorModel) trimmed() []string {
+	if len(m.options) == 0 {
+		return nil
+	}
+
+	sources := make([]string, len(m.options[0].Trimmed))
+	for i, source := range m.options[0].Trimmed {
+		sources[i] = m.settings.t(source)
+	}
+	return sources
+}
+
+// suspicious returns the translated sources of attached context that
+// contain instruction-like text.
+func (m SelectorModel) suspicious() []string {
+	if len(m.options) == 0 {
+		return nil
+	}
+
+	sources := make([]string, len(m.options[0].Suspicious))
+	for i, source := range m.options[0].Suspicious {
+		sources[i] = m.settings.t(source)
+	}
+	return sources
+}
+
+// announceCurrent describes the highlighted option in accessible mode.
+func (m SelectorModel) announceCurrent() tea.Cmd {
+	if len(m.visible) == 0 {
+		return m.settings.announce("No options match the filter.")
+	}
+
+	opt := m.options[m.visible[m.cursor]]
+	msg := m.settings.tf("Option %d of %d: %s. Command: %s", m.cursor+1, len(m.visible), opt.Title, opt.Command)
+	if opt.Risk != nil {
+		msg += ". " + m.settings.tf("%s risk: %s", m.settings.t(opt.Risk.Level.String()), opt.Risk.Message)
+	}
+	if badges := opt.Badges(); len(badges) > 0 {
+		labels := make([]string, len(badges))
+		for i, badge := range badges {
+			labels[i] = m.settings.t(string(badge))
+		}
+		msg += ". " + strings.Join(labels, ", ")
+	}
+	if opt.ShellError != "" {
+		msg += ". " + m.settings.tf("Invalid shell syntax: %s", opt.ShellError)
+	}
+	if opt.ShellFix != "" {
+		msg += ". " + m.settings.tf("Fixed: %s", opt.ShellFix)
+	}
+	if len(opt.Unquoted) > 0 {
+		msg += ". " + m.settings.tf("May split on spaces: %s", strings.Join(opt.Unquoted, " "))
+	}
+	if opt.SyntaxError != "" {
+		msg += ". " + m.settings.tf("Syntax error: %s", opt.SyntaxError)
+	}
+	if len(opt.AvoidedTools) > 0 {
+		msg += ". " + m.settings.tf("Uses avoided tool: %s", strings.Join(opt.AvoidedTools, ", "))
+	}
+	if len(opt.Violations) > 0 {
+		msg += ". " + m.settings.tf("Breaks constraint: %s", opt.Violations)
+	}
+	if opt.Source != "" {
+		msg += ". " + m.settings.tf("From %s", opt.Source)
+	}
+	return m.settings.announce("%s", msg)
+}
+
+// announceSafety summarizes safety results in accessible mode.
+func (m SelectorModel) announceSafety(checked []commands.Option) tea.Cmd {
+	var cmds []tea.Cmd
+	for i, opt := range m.options {
+		if !slices.ContainsFunc(checked, func(c commands.Option) bool { return c.Command == opt.Command }) {
+			continue
+		}
+		if opt.SaferFor != "" {
+			cmds = append(cmds, m.settings.announce("Option %d is a safer version of: %s", i+1, opt.SaferFor))
+		}
+		if opt.Risk != nil {
+			cmds = append(cmds, m.settings.announce("Option %d: %s risk: %s", i+1, m.settings.t(opt.Risk.Level.String()), opt.Risk.Message))
+		}
+	}
+
+	if m.safetyDone() {
+		if m.safetyErr != nil {
+			cmds = append(cmds, m.settings.announce("Safety check unavailable."))
+		} else {
+			cmds = append(cmds, m.settings.announce("Safety check complete."))
+		}
+	}
+	return tea.Sequence(cmds...)
+}
+
+// saveSnippet adds an option to the snippet library in the background.
+func saveSnippet(opt commands.Option) tea.Cmd {
+	return func() tea.Msg {
+		lib, err := snippets.Load()
+		if err != nil {
+			return snippetSavedMsg{err: err}
+		}
+
+		saved := lib.Add(opt)
+		if err := lib.Save(); err != nil {
+			return snippetSavedMsg{err: err}
+		}
+		return snippetSavedMsg{name: saved.Name}
+	}
+}
+
+// startSave saves opt as a snippet, first asking whether to replace any
+// paths, hostnames or ports in it with placeholders so the snippet isn't
+// tied to the values it was generated for.
+func (m SelectorModel) startSave(opt commands.Option) (tea.Model, tea.Cmd) {
+	literals := snippets.Literals(opt.Command)
+	if len(literals) == 0 {
+		return m, saveSnippet(opt)
+	}
+
+	texts := make([]string, len(literals))
+	for i, lit := range literals {
+		texts[i] = lit.Text
+	}
+	m.pendingSave = &opt
+	m.literals = literals
+	m.status = m.settings.tf("Replace with placeholders: %s", strings.Join(texts, ", "))
+	return m, m.settings.announce("%s", m.status)
+}
+
+// updatePendingSave handles the answer to startSave's question: y saves
+// with placeholders, n saves the command as it is, and esc saves nothing.
+func (m SelectorModel) updatePendingSave(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	opt := *m.pendingSave
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+
+	case "y", "enter":
+		opt.Command = snippets.Parameterize(opt.Command, m.literals)
+
+	case "n":
+
+	case "esc":
+		m.pendingSave, m.literals, m.status = nil, nil, ""
+		return m, nil
+
+	default:
+		return m, nil
+	}
+
+	m.pendingSave, m.literals, m.status = nil, nil, ""
+	return m, saveSnippet(opt)
+}
+
+// evaluateSafety checks each option separately and concurrently, so each
+// row's risk shows as soon as it is known rather than when the slowest
+// check finishes.
+func (m SelectorModel) evaluateSafety() tea.Cmd {
+	var cmds []tea.Cmd
+	timeout := m.generator.SafetyTimeout()
+	for i, opt := range m.options {
+		id := m.ids[i]
+		if !m.riskChecks[id] {
+			continue
+		}
+		cmds = append(cmds, func() tea.Msg {
+			ctx := context.Background()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			options, err := m.generator.EvaluateSafety(ctx, []commands.Option{opt})
+			return riskResultMsg{id: id, options: options, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// safetyDeadline marks checks still running after the generator's
+// SafetyTimeout as unverified, in case a check doesn't give up by itself.
+func (m SelectorModel) safetyDeadline() tea.Cmd {
+	timeout := m.generator.SafetyTimeout()
+	if timeout <= 0 {
+		return nil
+	}
+	run := m.safetyRun
+	return tea.Tick(timeout, func(time.Time) tea.Msg { return safetyDeadlineMsg{run: run} })
+}
+
+// expireSafety gives up on the checks still running, showing their
+// options as unverified. Results that arrive later are still applied.
+func (m *SelectorModel) expireSafety() {
+	if len(m.riskChecks) == 0 {
+		return
+	}
+	m.unverified = maps.Clone(m.unverified)
+	if m.unverified == nil {
+		m.unverified = map[int]bool{}
+	}
+	for id := range m.riskChecks {
+		m.unverified[id] = true
+	}
+	m.riskChecks = nil
+}
+
+// recheckSafety starts the safety check again for every unverified
+// option.
+func (m SelectorModel) recheckSafety() (tea.Model, tea.Cmd) {
+	if len(m.unverified) == 0 || m.generator == nil {
+		return m, nil
+	}
+	m.riskChecks = maps.Clone(m.unverified)
+	m.unverified = nil
+	m.safetyErr = nil
+	m.safetyRun++
+	return m, tea.Batch(
+		m.evaluateSafety(),
+		m.safetyDeadline(),
+		m.settings.spinnerTick(m.spinner),
+		m.settings.announce("Checking safety again..."),
+	)
+}
+
+// safetyDone reports whether every option's safety check has finished.
+func (m SelectorModel) safetyDone() bool {
+	return len(m.riskChecks) == 0
+}
+
+// checking reports whether the safety check for the option at idx is
+// running.
+func (m SelectorModel) checking(idx int) bool {
+	return m.riskChecks[m.ids[idx]]
 }
 
-func (m SelectorModel) evaluateSafety() tea.Msg {
-	options, err := m.generator.EvaluateSafety(context.Background(), m.options)
-	return riskResultMsg{options: options, err: err}
+// isUnverified reports whether the safety check for the option at idx
+// failed or timed out.
+func (m SelectorModel) isUnverified(idx int) bool {
+	return m.unverified[m.ids[idx]]
+}
+
+// applyRisk replaces the option a safety check was for with its result,
+// followed by any safer version the check proposed.
+func (m *SelectorModel) applyRisk(msg riskResultMsg) {
+	if !m.riskChecks[msg.id] && !m.unverified[msg.id] {
+		// Already answered, by an earlier run of a check that was retried.
+		return
+	}
+	m.riskChecks = maps.Clone(m.riskChecks)
+	delete(m.riskChecks, msg.id)
+	m.unverified = maps.Clone(m.unverified)
+	delete(m.unverified, msg.id)
+
+	i := slices.Index(m.ids, msg.id)
+	if i < 0 {
+		return
+	}
+	command := m.options[i].Command
+
+	// A check can succeed without a verdict for the command, when the
+	// evaluator left it out even on its own.
+	answered := slices.ContainsFunc(msg.options, func(o commands.Option) bool {
+		return o.Command == command && !o.Unverified
+	})
+	if msg.err != nil && m.safetyErr == nil {
+		m.safetyErr = msg.err
+	}
+	if msg.err != nil || !answered {
+		if m.unverified == nil {
+			m.unverified = map[int]bool{}
+		}
+		m.unverified[msg.id] = true
+	}
+
+	// Failed checks return options only when the policy blocked them.
+	if msg.options == nil {
+		return
+	}
+	ids := make([]int, len(msg.options))
+	ids[0] = msg.id
+	for j := 1; j < len(ids); j++ {
+		ids[j] = m.nextID
+		m.nextID++
+	}
+	m.setOptions(slices.Concat(m.options[:i], msg.options, m.options[i+1:]), slices.Concat(m.ids[:i], ids, m.ids[i+1:]))
 }
 
 // Update handles key presses, safety results, and spinner ticks.
 func (m SelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	// Any message can change what's rendered (cursor, spinner frame, risk
+	// results), so the viewport is resynced after every update.
+	if sm, ok := next.(SelectorModel); ok && sm.settings.AltScreen {
+		sm.syncViewport()
+		return sm, cmd
+	}
+	return next, cmd
+}
+
+func (m SelectorModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case deliveredMsg:
+		return m.finishDelivery(msg)
+
 	case tea.KeyMsg:
+		// Keys wait while the chosen option is being output.
+		if m.delivering != nil && msg.String() != "ctrl+c" {
+			return m, nil
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.pendingSave != nil {
+			return m.updatePendingSave(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quitting = true
@@ -72,109 +520,332 @@ func (m SelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
+				return m, m.announceCurrent()
 			}
 
 		case "down", "j":
-			if m.cursor < len(m.options)-1 {
+			if m.cursor < len(m.visible)-1 {
 				m.cursor++
+				return m, m.announceCurrent()
+			}
+
+		case "v":
+			m.showFull = !m.showFull
+
+		case "f":
+			m.reflow = !m.reflow
+
+		case "tab", " ":
+			if len(m.visible) > 0 {
+				command := m.options[m.visible[m.cursor]].Command
+				m.expanded[command] = !m.expanded[command]
+			}
+
+		case "m":
+			if len(m.visible) > 0 {
+				pager := newManPageModel(m, m.options[m.visible[m.cursor]].Command)
+				return pager, pager.Init()
+			}
+
+		case "s":
+			if len(m.visible) > 0 {
+				return m.startSave(m.options[m.visible[m.cursor]])
+			}
+
+		case "r":
+			return m.recheckSafety()
+
+		case "i":
+			if len(m.visible) > 0 && m.generator != nil {
+				pager := newSafetyDetailModel(m, m.options[m.visible[m.cursor]].Command)
+				return pager, pager.Init()
+			}
+
+		case "p":
+			if len(m.visible) > 0 && m.generator != nil && len(commands.SplitPipeline(m.options[m.visible[m.cursor]].Command)) > 1 {
+				pager := newPipelineModel(m, m.options[m.visible[m.cursor]].Command)
+				return pager, pager.Init()
+			}
+
+		case "t":
+			if len(m.visible) > 0 {
+				pager := newTLDRModel(m, m.options[m.visible[m.cursor]].Command)
+				return pager, pager.Init()
+			}
+
+		case "/":
+			m.filtering = true
+			return m, m.filter.Focus()
+
+		// esc clears a filter first, then goes back to the prompt.
+		case "esc":
+			if m.filter.Value() != "" {
+				m.clearFilter()
+			} else if m.back != nil {
+				return m.goBack()
+			}
+
+		case "e":
+			if len(m.visible) > 0 && m.options[m.visible[m.cursor]].Expression != "" {
+				return m.selectExpression()
 			}
 
 		case "enter":
-			m.selected = &m.options[m.cursor]
-			m.quitting = true
-			return m, tea.Quit
+			return m.selectCurrent()
+		}
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
+	case snippetSavedMsg:
+		if msg.err != nil {
+			m.status = m.settings.tf("Could not save snippet: %v", msg.err)
+		} else {
+			m.status = m.settings.tf("Saved snippet %q", msg.name)
 		}
+		return m, m.settings.announce("%s", m.status)
 
 	case riskResultMsg:
-		m.safetyDone = true
-		if msg.err == nil {
-			m.options = msg.options
+		m.applyRisk(msg)
+		return m, m.announceSafety(msg.options)
+
+	case safetyDeadlineMsg:
+		if msg.run != m.safetyRun || m.safetyDone() {
+			return m, nil
+		}
+		m.expireSafety()
+		return m, m.settings.announce("Safety check timed out. Press r to check again.")
+
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, nil
+
+	case tea.FocusMsg:
+		m.blurred = false
+		if !m.safetyDone() {
+			return m, m.settings.spinnerTick(m.spinner)
 		}
 		return m, nil
 
 	case spinner.TickMsg:
-		if !m.safetyDone {
+		// Dropping the tick while blurred ends the animation loop; FocusMsg
+		// restarts it so we don't redraw for a window nobody is looking at.
+		if !m.safetyDone() && !m.blurred {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
+
+	default:
+		if m.filtering {
+			var cmd tea.Cmd
+			m.filter, cmd = m.filter.Update(msg)
+			return m, cmd
+		}
 	}
 
 	return m, nil
 }
 
-// View renders the option list with safety indicators.
-func (m SelectorModel) View() string {
-	if m.quitting && m.selected == nil {
-		return ""
-	}
+// updateFilter handles key presses while the filter input is focused.
+// Navigation keys still move the cursor so users can filter and pick
+// without leaving the input.
+func (m SelectorModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+
+	case "esc":
+		m.clearFilter()
+		return m, nil
 
-	var b strings.Builder
+	case "enter":
+		m.filtering = false
+		m.filter.Blur()
+		return m.selectCurrent()
 
-	b.WriteString("\n")
-	b.WriteString("Select a command:\n\n")
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, m.announceCurrent()
 
-	contentWidth := m.width - 4
+	case "down":
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+		}
+		return m, m.announceCurrent()
+	}
 
-	for i, option := range m.options {
-		isSelected := m.cursor == i
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	before := len(m.visible)
+	m.visible = filterOptions(m.options, m.filter.Value())
+	if m.cursor >= len(m.visible) {
+		m.cursor = max(len(m.visible)-1, 0)
+	}
+	if len(m.visible) != before {
+		cmd = tea.Batch(cmd, m.settings.announce("%d options match.", len(m.visible)))
+	}
+	return m, cmd
+}
+
+// setOptions replaces the options and their IDs after a safety check,
+// which may have added safer versions, keeping the cursor on the same
+// option.
+func (m *SelectorModel) setOptions(options []commands.Option, ids []int) {
+	current := -1
+	if len(m.visible) > 0 {
+		current = m.ids[m.visible[m.cursor]]
+	}
 
-		cursor := " "
-		title := TitleStyle.Render(option.Title)
-		if isSelected {
-			cursor = SelectedStyle.Render("▸")
-			title = SelectedStyle.Render(option.Title)
+	m.options, m.ids = options, ids
+	m.visible = filterOptions(m.options, m.filter.Value())
+	m.cursor = min(m.cursor, max(len(m.visible)-1, 0))
+	for i, idx := range m.visible {
+		if m.ids[idx] == current {
+			m.cursor = i
+			break
 		}
+	}
+}
 
-		command := CommandStyle.Width(contentWidth).Render(option.Command)
+// clearFilter leaves filter mode and restores the full option list.
+func (m *SelectorModel) clearFilter() {
+	m.filtering = false
+	m.filter.Blur()
+	m.filter.SetValue("")
+	m.visible = filterOptions(m.options, "")
+	m.cursor = 0
+}
 
-		var riskWarning string
-		if option.Risk != nil {
-			riskWarning = formatRiskWarning(option.Risk, isSelected)
-		} else if !m.safetyDone {
-			riskWarning = m.spinner.View() + CheckingStyle.Render(" checking safety...")
-		}
+// selectCurrent picks the option under the cursor, if any are visible.
+func (m SelectorModel) selectCurrent() (tea.Model, tea.Cmd) {
+	if len(m.visible) == 0 {
+		return m, nil
+	}
 
-		description := DescriptionStyle.Width(contentWidth).Render(option.Description)
+	idx := m.visible[m.cursor]
+	opt := m.options[idx]
+	if reason := refusal(opt, m.generator, !m.checking(idx) && !m.isUnverified(idx), m.settings); reason != "" {
+		m.status = reason
+		return m, m.settings.announce("%s", reason)
+	}
 
-		b.WriteString(fmt.Sprintf("%s %s\n", cursor, title))
-		b.WriteString(fmt.Sprintf("  %s\n", command))
-		if riskWarning != "" {
-			b.WriteString(fmt.Sprintf("  %s\n", riskWarning))
-		}
-		b.WriteString(fmt.Sprintf("  %s\n\n", description))
+	if needsConfirmation(opt) {
+		confirm := newConfirmModel(m, opt, m.settings, m.width)
+		return confirm, confirm.Init()
 	}
+	return m.choose(opt)
+}
 
-	if m.selected == nil {
-		b.WriteString(HelpStyle.Render("↑/k: up • ↓/j: down • enter: select • q: quit"))
-		b.WriteString("\n")
+// selectExpression picks just the DSL expression of the option under the
+// cursor, for pasting into a script or another tool.
+func (m SelectorModel) selectExpression() (tea.Model, tea.Cmd) {
+	idx := m.visible[m.cursor]
+	opt := m.options[idx]
+	if reason := refusal(opt, m.generator, !m.checking(idx) && !m.isUnverified(idx), m.settings); reason != "" {
+		m.status = reason
+		return m, m.settings.announce("%s", reason)
 	}
 
-	return b.String()
+	opt.Command = opt.Expression
+	if needsConfirmation(opt) {
+		confirm := newConfirmModel(m, opt, m.settings, m.width)
+		return confirm, confirm.Init()
+	}
+	return m.choose(opt)
 }
 
-// formatRiskWarning returns a styled warning string for the given risk level.
-func formatRiskWarning(risk *safety.RiskInfo, selected bool) string {
-	var icon string
-	var style lipgloss.Style
+// goBack returns to the prompt with the query still in it, so it can be
+// reworded and sent again without starting over.
+func (m SelectorModel) goBack() (tea.Model, tea.Cmd) {
+	input, cmd := m.back.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	return input, tea.Batch(cmd, input.Init(), m.settings.announce("Back to the prompt."))
+}
 
-	switch risk.Level {
-	case safety.RiskLow:
-		icon, style = "⚠️", WarningLowStyle
-	case safety.RiskHigh:
-		icon, style = "🚨", WarningHighStyle
-	default:
-		return ""
+// choose selects opt and quits, first recording it with Settings.Audit and
+// outputting it with Settings.Deliver when they are set, so a delivery
+// failure can be shown before the UI exits. A command that can't be
+// recorded is never delivered.
+func (m SelectorModel) choose(opt commands.Option) (tea.Model, tea.Cmd) {
+	if m.settings.Audit != nil {
+		if err := m.settings.Audit(m.query, m.options, opt); err != nil {
+			m.err = err
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	if m.settings.Deliver != nil {
+		m.delivering = &opt
+		return m, deliver(m.settings, opt, m.settings.OutputMode)
 	}
+	m.selected = &opt
+	m.quitting = true
+	return m, tea.Quit
+}
 
-	if selected {
-		style = style.Bold(true)
+// finishDelivery selects the option once it has been output, or reports
+// why it couldn't be.
+func (m SelectorModel) finishDelivery(msg deliveredMsg) (tea.Model, tea.Cmd) {
+	m.delivering = nil
+	if msg.err != nil {
+		failed := newOutputErrorModel(m, msg)
+		return failed, failed.Init()
 	}
+	m.selected = &msg.option
+	m.delivered = msg.delivered
+	m.outputMode = msg.mode
+	m.quitting = true
+	return m, tea.Quit
+}
 
-	return style.Render(fmt.Sprintf("%s %s", icon, risk.Message))
+// refusal explains why opt can't be selected yet, or returns "" if it can.
+func refusal(opt commands.Option, generator *commands.Generator, safetyDone bool, s Settings) string {
+	if opt.Blocked != "" {
+		return s.tf("Blocked by policy: %s", opt.Blocked)
+	}
+	if opt.ShellError != "" {
+		return s.tf("Invalid shell syntax: %s", opt.ShellError)
+	}
+	if !safetyDone && generator != nil && generator.RequiresSafety() {
+		return s.t("Policy requires a safety check; wait for it to finish")
+	}
+	return ""
 }
 
 // Selected returns the chosen option, or nil if the user quit.
 func (m SelectorModel) Selected() *commands.Option {
 	return m.selected
 }
+
+// Err returns why the chosen option couldn't be recorded by
+// Settings.Audit, in which case nothing was selected.
+func (m SelectorModel) Err() error {
+	return m.err
+}
+
+// Delivered reports whether Settings.Deliver already output the selected
+// option, leaving only its confirmation to print.
+func (m SelectorModel) Delivered() bool {
+	return m.delivered
+}
+
+// OutputMode returns the output mode the selected option was delivered in
+// or should be output in, which differs from Settings.OutputMode when
+// another mode was chosen after a failure. Empty without Settings.Deliver.
+func (m SelectorModel) OutputMode() string {
+	return m.outputMode
+}
+
+// Query returns the query the options were generated for; empty for
+// options that didn't come from a query, such as snippets.
+func (m SelectorModel) Query() string {
+	return m.query
+}
+
+// Options returns every option offered, with the risks evaluated so far.
+func (m SelectorModel) Options() []commands.Option {
+	return m.options
+}
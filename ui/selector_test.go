@@ -0,0 +1,292 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/llm"
+	"github.com/pixielabs/1lm/safety"
+	"github.com/pixielabs/1lm/snippets"
+)
+
+func TestSelectorEvaluatesEachOption(t *testing.T) {
+	generator := commands.NewGeneratorWithEvaluator(llm.NewMockClient(), safety.NewHeuristicEvaluator())
+	options := []commands.Option{
+		{Title: "List", Command: "ls"},
+		{Title: "Remove", Command: "rm -rf /"},
+	}
+	m := NewSelector(options, generator, Settings{Static: true})
+
+	batch, ok := m.evaluateSafety()().(tea.BatchMsg)
+	if !ok || len(batch) != len(options) {
+		t.Fatalf("evaluateSafety() = %v, want one check per option", batch)
+	}
+	for i, cmd := range batch {
+		msg, ok := cmd().(riskResultMsg)
+		if !ok || msg.id != i {
+			t.Errorf("check %d = %+v, want a result for option %d", i, msg, i)
+		}
+	}
+}
+
+func TestSelectorShowsRisksAsTheyArrive(t *testing.T) {
+	generator := commands.NewGeneratorWithEvaluator(llm.NewMockClient(), safety.NewHeuristicEvaluator())
+	options := []commands.Option{
+		{Title: "List", Command: "ls"},
+		{Title: "Remove", Command: "rm -rf build"},
+	}
+	var m tea.Model = NewSelector(options, generator, Settings{Static: true})
+
+	// The second option's check finishes first.
+	risky := options[1]
+	risky.Risk = &safety.RiskInfo{Level: safety.RiskHigh, Message: "Deletes build recursively"}
+	safer := commands.Option{Title: "Remove", Command: "rm -ri build", SaferFor: "Remove"}
+	m, _ = m.Update(riskResultMsg{id: 1, options: []commands.Option{risky, safer}})
+
+	selector := m.(SelectorModel)
+	if selector.safetyDone() {
+		t.Fatal("safetyDone() = true with the first option still being checked")
+	}
+	if got := len(selector.Options()); got != 3 {
+		t.Fatalf("Options() has %d options, want the safer version inserted", got)
+	}
+	if selector.Options()[2].Command != "rm -ri build" {
+		t.Errorf("Options()[2] = %q, want the safer version after the risky one", selector.Options()[2].Command)
+	}
+	view := m.View()
+	if !strings.Contains(view, "Deletes build recursively") {
+		t.Error("View() hides the second option's risk while the first is checked")
+	}
+	if !strings.Contains(view, "checking safety...") {
+		t.Error("View() doesn't show the first option still being checked")
+	}
+
+	m, _ = m.Update(riskResultMsg{id: 0, options: []commands.Option{options[0]}})
+	if !m.(SelectorModel).safetyDone() {
+		t.Error("safetyDone() = false after every check finished")
+	}
+	if strings.Contains(m.View(), "checking safety...") {
+		t.Error("View() still shows a check running after all finished")
+	}
+}
+
+func TestSelectorChecksDuplicateCommandsSeparately(t *testing.T) {
+	generator := commands.NewGeneratorWithEvaluator(llm.NewMockClient(), safety.NewHeuristicEvaluator())
+	options := []commands.Option{
+		{Title: "Remove", Command: "rm -rf build", Source: "model a"},
+		{Title: "Remove", Command: "rm -rf build", Source: "model b"},
+	}
+	var m tea.Model = NewSelector(options, generator, Settings{Static: true})
+
+	// Each option's result is applied to it alone, whatever order they
+	// arrive in.
+	second := options[1]
+	second.Risk = &safety.RiskInfo{Level: safety.RiskHigh, Message: "Deletes build recursively"}
+	safer := commands.Option{Title: "Remove", Command: "rm -ri build", SaferFor: "Remove"}
+	m, _ = m.Update(riskResultMsg{id: 1, options: []commands.Option{second, safer}})
+	s := m.(SelectorModel)
+	if s.options[0].Risk != nil || !s.checking(0) {
+		t.Fatalf("the second option's result was applied to the first: %+v", s.options[0])
+	}
+	if s.options[1].Risk == nil || s.options[1].Source != "model b" {
+		t.Fatalf("options[1] = %+v, want the second option with its risk", s.options[1])
+	}
+
+	first := options[0]
+	first.Risk = &safety.RiskInfo{Level: safety.RiskHigh, Message: "Deletes build recursively"}
+	m, _ = m.Update(riskResultMsg{id: 0, options: []commands.Option{first}})
+	s = m.(SelectorModel)
+	if !s.safetyDone() {
+		t.Fatalf("riskChecks = %v, want every check finished", s.riskChecks)
+	}
+	if got := len(s.options); got != 3 {
+		t.Fatalf("options has %d entries, want both originals and one safer version", got)
+	}
+	if s.options[0].Risk == nil || s.options[0].Source != "model a" {
+		t.Errorf("options[0] = %+v, want the first option with its risk", s.options[0])
+	}
+}
+
+func TestSelectorRefusesInvalidSyntax(t *testing.T) {
+	options := []commands.Option{{Title: "Broken", Command: "ls | | wc -l", ShellError: "1:6: | can only immediately follow a statement"}}
+	var m tea.Model = NewSelector(options, nil, Settings{Static: true})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	selector := m.(SelectorModel)
+	if selector.Selected() != nil {
+		t.Error("Selected() = an option that doesn't parse, want none")
+	}
+	if !strings.Contains(m.View(), "Invalid shell syntax") {
+		t.Error("View() doesn't explain why the option can't be selected")
+	}
+}
+
+func TestSelectorReflowsLongPipelines(t *testing.T) {
+	command := "find . -type f -name '*.log' -mtime +7 | xargs grep -l 'connection refused' | sort | uniq -c | sort -rn"
+	options := []commands.Option{{Title: "Count", Command: command}}
+	var m tea.Model = NewSelector(options, nil, Settings{Static: true})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 40})
+
+	if strings.Contains(m.View(), "  | xargs") {
+		t.Fatal("View() reflowed the command before f was pressed")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if view := m.View(); !strings.Contains(view, `\`) || !strings.Contains(view, "| xargs grep") {
+		t.Errorf("View() after f doesn't break the pipeline at its pipes:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.(SelectorModel).Selected(); got == nil || got.Command != command {
+		t.Errorf("Selected() = %+v, want the single-line command", got)
+	}
+}
+
+func TestSelectorOffersPlaceholdersBeforeSaving(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	options := []commands.Option{{Title: "Follow log", Command: "tail -f /var/log/app.log"}}
+	var m tea.Model = NewSelector(options, nil, Settings{Static: true})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if view := m.View(); !strings.Contains(view, "/var/log/app.log") || !strings.Contains(view, "y: placeholders") {
+		t.Fatalf("View() after s doesn't offer to replace the path:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.(SelectorModel).pendingSave != nil {
+		t.Fatal("esc didn't cancel saving")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatal("y didn't save the snippet")
+	}
+	if msg, ok := cmd().(snippetSavedMsg); !ok || msg.err != nil {
+		t.Fatalf("saving = %+v, want a saved snippet", msg)
+	}
+
+	lib, err := snippets.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lib.Snippets) != 1 || lib.Snippets[0].Command != "tail -f {{path}}" {
+		t.Errorf("saved snippets = %+v, want tail -f {{path}}", lib.Snippets)
+	}
+}
+
+func TestSelectorEscReturnsToPrompt(t *testing.T) {
+	var m tea.Model = NewInputModel(nil, nil, Settings{Static: true})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("find big files")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(optionsMsg{options: []commands.Option{{Title: "Find", Command: "find . -size +100M"}}})
+	if _, ok := m.(SelectorModel); !ok {
+		t.Fatalf("options gave %T, want SelectorModel", m)
+	}
+
+	// A filter is cleared before going back.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := m.(SelectorModel); !ok {
+		t.Fatalf("esc while filtering gave %T, want SelectorModel", m)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	input, ok := m.(InputModel)
+	if !ok {
+		t.Fatalf("esc gave %T, want InputModel", m)
+	}
+	if got := input.input.Value(); got != "find big files" {
+		t.Errorf("query after going back = %q, want %q", got, "find big files")
+	}
+	if !strings.Contains(input.View(), "find big files") {
+		t.Errorf("prompt doesn't show the query:\n%s", input.View())
+	}
+
+	// And it can be sent again.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := m.(LoadingModel); !ok {
+		t.Errorf("enter after going back gave %T, want LoadingModel", m)
+	}
+}
+
+func TestSelectorEscWithoutPromptStays(t *testing.T) {
+	var m tea.Model = NewSelector([]commands.Option{{Title: "List", Command: "ls"}}, nil, Settings{Static: true})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if s, ok := m.(SelectorModel); !ok || s.quitting || cmd != nil {
+		t.Errorf("esc with no prompt to return to gave %T, %v", m, cmd)
+	}
+}
+
+// hangingEvaluator never answers until its context is done.
+type hangingEvaluator struct{}
+
+func (hangingEvaluator) Evaluate(ctx context.Context, _ []string) ([]*safety.RiskInfo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSelectorSafetyTimeout(t *testing.T) {
+	generator := commands.NewGeneratorWithEvaluator(llm.NewMockClient(), hangingEvaluator{})
+	generator.SetSafetyTimeout(10 * time.Millisecond)
+	options := []commands.Option{{Title: "List", Command: "ls"}}
+	var m tea.Model = NewSelector(options, generator, Settings{Static: true})
+
+	// The check gives up by itself at the deadline.
+	m, _ = m.Update(m.(SelectorModel).evaluateSafety()())
+	s := m.(SelectorModel)
+	if !s.safetyDone() || !s.unverified[0] {
+		t.Fatalf("after the deadline riskChecks = %v, unverified = %v", s.riskChecks, s.unverified)
+	}
+	view := s.View()
+	for _, want := range []string{"[unverified]", "r: recheck safety"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	// r checks again; the first run's deadline no longer applies.
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	s = m.(SelectorModel)
+	if cmd == nil || !s.riskChecks[0] || s.unverified[0] {
+		t.Fatalf("r gave riskChecks = %v, unverified = %v", s.riskChecks, s.unverified)
+	}
+	m, _ = m.Update(safetyDeadlineMsg{run: 0})
+	if !m.(SelectorModel).riskChecks[0] {
+		t.Error("a stale deadline gave up on the new check")
+	}
+
+	// A check that never returns is given up on at its own deadline.
+	m, _ = m.Update(safetyDeadlineMsg{run: 1})
+	if s := m.(SelectorModel); !s.safetyDone() || !s.unverified[0] {
+		t.Errorf("deadline left riskChecks = %v, unverified = %v", s.riskChecks, s.unverified)
+	}
+
+	// A late answer still counts.
+	m, _ = m.Update(riskResultMsg{id: 0, options: []commands.Option{{Title: "List", Command: "ls", Risk: &safety.RiskInfo{Level: safety.RiskNone}}}})
+	if s := m.(SelectorModel); s.unverified[0] || s.options[0].Risk == nil {
+		t.Errorf("late result not applied: unverified = %v, risk = %v", s.unverified, s.options[0].Risk)
+	}
+}
+
+func TestSelectorShowsCheckWithoutVerdictAsUnverified(t *testing.T) {
+	generator := commands.NewGeneratorWithEvaluator(llm.NewMockClient(), safety.NewHeuristicEvaluator())
+	options := []commands.Option{{Title: "List", Command: "ls"}}
+	var m tea.Model = NewSelector(options, generator, Settings{Static: true})
+
+	m, _ = m.Update(riskResultMsg{id: 0, options: []commands.Option{{Title: "List", Command: "ls", Unverified: true}}})
+	s := m.(SelectorModel)
+	if !s.safetyDone() || !s.unverified[0] {
+		t.Fatalf("riskChecks = %v, unverified = %v; want ls unverified", s.riskChecks, s.unverified)
+	}
+	if !strings.Contains(s.View(), "[unverified]") {
+		t.Errorf("view missing the unverified badge:\n%s", s.View())
+	}
+}
@@ -0,0 +1,450 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/safety"
+)
+
+// View renders the option list with safety indicators.
+func (m SelectorModel) View() string {
+	if m.quitting && (m.selected == nil || m.settings.Quiet) {
+		return ""
+	}
+
+	if m.settings.AltScreen {
+		list := m.viewport.View()
+		if m.settings.Preview {
+			list = m.withPreview(list, m.viewport.Height)
+		}
+		return m.header() + list + "\n" + m.footer()
+	}
+
+	body, _ := m.renderOptions()
+	if m.settings.Preview {
+		body = m.withPreview(body, 0) + "\n\n"
+	}
+	return m.header() + body + m.footer()
+}
+
+// header renders the title and, when active, the filter input.
+func (m SelectorModel) header() string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(m.settings.t("Select a command:") + "\n\n")
+
+	if fallback := m.fallback(); fallback != "" {
+		b.WriteString(WarningLowStyle.Render(m.settings.tf("Answered by fallback model %s", fallback)))
+		b.WriteString("\n\n")
+	}
+
+	if redacted := m.redacted(); len(redacted) > 0 {
+		b.WriteString(WarningLowStyle.Render(m.settings.tf("Redacted before sending: %s", strings.Join(redacted, ", "))))
+		b.WriteString("\n\n")
+	}
+
+	if trimmed := m.trimmed(); len(trimmed) > 0 {
+		b.WriteString(WarningLowStyle.Render(m.settings.tf("Context trimmed for token limit: %s", strings.Join(trimmed, ", "))))
+		b.WriteString("\n\n")
+	}
+
+	if suspicious := m.suspicious(); len(suspicious) > 0 {
+		b.WriteString(WarningHighStyle.Render(m.settings.tf("Possible prompt injection in: %s", strings.Join(suspicious, ", "))))
+		b.WriteString("\n\n")
+	}
+
+	if m.filtering || m.filter.Value() != "" {
+		b.WriteString(m.filter.View())
+		b.WriteString("\n\n")
+	}
+
+	return b.String()
+}
+
+// footer renders the help line, plus the scroll position in alt-screen
+// mode so users know there is more above or below.
+func (m SelectorModel) footer() string {
+	if m.selected != nil {
+		return ""
+	}
+
+	var status string
+	if m.status != "" {
+		status = HelpStyle.Render(m.status) + "\n"
+	}
+
+	var help string
+	switch {
+	case m.pendingSave != nil:
+		help = m.settings.help("y: placeholders", "n: save as is", "esc: cancel")
+	case m.filtering:
+		help = m.settings.help("↑/↓: move", "enter: select", "esc: clear filter")
+	default:
+		help = m.settings.help(m.keyHints()...)
+	}
+
+	if m.settings.AltScreen && len(m.visible) > 0 {
+		position := fmt.Sprintf("%d/%d", m.cursor+1, len(m.visible))
+		if !m.viewport.AtTop() || !m.viewport.AtBottom() {
+			position += fmt.Sprintf(" • %3.f%%", m.viewport.ScrollPercent()*100)
+		}
+		help = HelpStyle.Render(position) + "  " + help
+	}
+
+	return status + help + "\n"
+}
+
+// keyHints lists the selector's keys, leaving out ones that do nothing for
+// the current options.
+func (m SelectorModel) keyHints() []string {
+	hints := []string{"↑/k: up", "↓/j: down", "/: filter"}
+	// The preview pane always shows everything in full.
+	if !m.settings.Preview {
+		if m.hasTruncated() {
+			hints = append(hints, "v: view full")
+		}
+		if m.hasReflowable() {
+			hints = append(hints, "f: format")
+		}
+		if m.hasCollapsed() {
+			hints = append(hints, "tab: expand")
+		}
+	}
+	hints = append(hints, "m/t: man/tldr")
+	if m.generator != nil {
+		if len(m.unverified) > 0 {
+			hints = append(hints, "r: recheck safety")
+		}
+		hints = append(hints, "i: safety details")
+		if m.hasPipelines() {
+			hints = append(hints, "p: pipeline steps")
+		}
+	}
+	hints = append(hints, "s: save")
+	if m.hasExpressions() {
+		hints = append(hints, "e: expression only")
+	}
+	hints = append(hints, "enter: select")
+	if m.back != nil {
+		hints = append(hints, "esc: back")
+	}
+	return append(hints, "q: quit")
+}
+
+// renderOptions renders every visible option and returns the line on which
+// each one starts, so the alt-screen viewport can scroll to the cursor.
+func (m SelectorModel) renderOptions() (string, []int) {
+	if m.settings.Preview {
+		return m.renderCompactOptions()
+	}
+
+	var b strings.Builder
+	starts := make([]int, 0, len(m.visible)+1)
+	line := 0
+
+	contentWidth := m.width - 4
+	glyphs := m.settings.glyphs()
+	distinct := m.distinct()
+
+	if len(m.visible) == 0 {
+		b.WriteString(HelpStyle.Render(m.settings.t("No options match the filter")))
+		b.WriteString("\n\n")
+	}
+
+	for i, idx := range m.visible {
+		option := m.options[idx]
+		isSelected := m.cursor == i
+
+		cursor := " "
+		title := TitleStyle.Render(option.Title)
+		if isSelected {
+			cursor = SelectedStyle.Render(glyphs.Cursor)
+			title = SelectedStyle.Render(option.Title)
+		}
+
+		command := m.renderOptionCommand(option, contentWidth, m.showFull, distinct[idx])
+
+		expanded := m.expanded[option.Command]
+
+		var riskWarning string
+		if option.Risk != nil {
+			risk := *option.Risk
+			if !expanded {
+				risk.Message, _ = collapse(risk.Message, contentWidth-lipgloss.Width(riskGlyph(risk.Level, glyphs))-1, glyphs.Ellipsis)
+			}
+			riskWarning = formatRiskWarning(&risk, isSelected, glyphs)
+		} else if m.checking(idx) {
+			riskWarning = m.settings.spinnerView(m.spinner) + CheckingStyle.Render(" "+m.settings.t("checking safety..."))
+		} else if m.isUnverified(idx) {
+			riskWarning = unverifiedWarning(m.settings)
+		}
+
+		notes := m.notes(option)
+
+		description := option.Description
+		if !expanded {
+			description, _ = collapse(description, contentWidth, glyphs.Ellipsis)
+		}
+		description = DescriptionStyle.Width(contentWidth).Render(description)
+
+		if badges := formatBadges(option.Badges(), m.settings); badges != "" {
+			title += "  " + badges
+		}
+
+		var block strings.Builder
+		block.WriteString(fmt.Sprintf("%s %s\n", cursor, title))
+		block.WriteString(indent(command, 2) + "\n")
+		if riskWarning != "" {
+			block.WriteString(fmt.Sprintf("  %s\n", riskWarning))
+		}
+		for _, note := range notes {
+			block.WriteString(fmt.Sprintf("  %s\n", note))
+		}
+		block.WriteString(fmt.Sprintf("  %s\n\n", description))
+
+		starts = append(starts, line)
+		line += strings.Count(block.String(), "\n")
+		b.WriteString(block.String())
+	}
+
+	// A sentinel start marks where the last option ends.
+	starts = append(starts, line)
+
+	return b.String(), starts
+}
+
+// renderOptionCommand renders an option's command block, broken across
+// lines at its pipes when formatting is on and it doesn't fit on one. The
+// option itself keeps the single-line form, which is what gets output.
+func (m SelectorModel) renderOptionCommand(option commands.Option, width int, full bool, distinct map[string]bool) string {
+	// The command block's padding takes a column either side.
+	if m.reflow && lipgloss.Width(option.Command)+2 > width {
+		if reflowed := commands.Reflow(option.Command); reflowed != option.Command {
+			return renderContinued(reflowed, width, distinct, option.Risk)
+		}
+	}
+	return renderCommand(option.Command, width, full, m.settings, distinct, option.Risk)
+}
+
+// hasReflowable reports whether formatting would break any visible
+// option's command in the option list across lines.
+func (m SelectorModel) hasReflowable() bool {
+	width := m.width - 4
+	for _, idx := range m.visible {
+		command := m.options[idx].Command
+		if lipgloss.Width(command)+2 > width && commands.Reflow(command) != command {
+			return true
+		}
+	}
+	return false
+}
+
+// notes returns the styled remarks shown under an option: what it is a
+// safer version of, policy and syntax problems, fixes and unquoted
+// parameters, avoided tools and which model suggested it.
+func (m SelectorModel) notes(option commands.Option) []string {
+	var notes []string
+	if option.SaferFor != "" {
+		notes = append(notes, HelpStyle.Render(m.settings.tf("Safer version of: %s", option.SaferFor)))
+	}
+	if option.Blocked != "" {
+		notes = append(notes, WarningHighStyle.Render(m.settings.tf("Blocked by policy: %s", option.Blocked)))
+	}
+	if option.ShellError != "" {
+		notes = append(notes, WarningHighStyle.Render(m.settings.tf("Invalid shell syntax: %s", option.ShellError)))
+	}
+	if option.ShellFix != "" {
+		notes = append(notes, HelpStyle.Render(m.settings.tf("Fixed: %s", option.ShellFix)))
+	}
+	if len(option.Unquoted) > 0 {
+		notes = append(notes, WarningLowStyle.Render(m.settings.tf("May split on spaces: %s", strings.Join(option.Unquoted, " "))))
+	}
+	if option.SyntaxError != "" {
+		notes = append(notes, WarningHighStyle.Render(m.settings.tf("Syntax error: %s", option.SyntaxError)))
+	}
+	if len(option.AvoidedTools) > 0 {
+		notes = append(notes, WarningLowStyle.Render(m.settings.tf("Uses avoided tool: %s", strings.Join(option.AvoidedTools, ", "))))
+	}
+	if len(option.Violations) > 0 {
+		notes = append(notes, WarningLowStyle.Render(m.settings.tf("Breaks constraint: %s", option.Violations)))
+	}
+	if option.Source != "" {
+		notes = append(notes, HelpStyle.Render(m.settings.tf("From %s", option.Source)))
+	}
+	return notes
+}
+
+// syncViewport refreshes the alt-screen viewport's content and scrolls
+// just enough to keep the highlighted option fully in view.
+func (m *SelectorModel) syncViewport() {
+	// Header and footer take up the rest of the screen. Both end in a
+	// newline, which lipgloss.Height counts as an extra line.
+	reserved := lipgloss.Height(m.header()) - 1 + lipgloss.Height(m.footer()) - 1
+	m.viewport.Width = m.listWidth()
+	if m.settings.Preview && !m.previewBeside() {
+		// The pane below the list takes its own height plus a separator.
+		reserved += lipgloss.Height(m.renderPreview(0)) + 1
+	}
+	m.viewport.Height = max(m.height-reserved, 1)
+
+	body, starts := m.renderOptions()
+	m.viewport.SetContent(body)
+
+	if len(m.visible) == 0 {
+		m.viewport.GotoTop()
+		return
+	}
+
+	top, bottom := starts[m.cursor], starts[m.cursor+1]
+	switch {
+	case top < m.viewport.YOffset:
+		m.viewport.SetYOffset(top)
+	case bottom > m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(min(bottom-m.viewport.Height, top))
+	}
+}
+
+// distinct returns each option's distinguishing words, compared across all
+// options rather than just the filtered ones so highlights don't shift
+// while typing a filter.
+func (m SelectorModel) distinct() []map[string]bool {
+	cmds := make([]string, len(m.options))
+	for i, opt := range m.options {
+		cmds[i] = opt.Command
+	}
+	return distinctTokens(cmds)
+}
+
+// hasTruncated reports whether any visible command is folded or could be
+// folded again, so the "view full" key is only advertised when useful.
+func (m SelectorModel) hasTruncated() bool {
+	for _, idx := range m.visible {
+		if isTruncated(m.options[idx].Command) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCollapsed reports whether any visible option's description or risk
+// text is long enough to be collapsed, so the expand key is only
+// advertised when useful.
+func (m SelectorModel) hasCollapsed() bool {
+	width, glyphs := m.width-4, m.settings.glyphs()
+	for _, idx := range m.visible {
+		opt := m.options[idx]
+		if _, long := collapse(opt.Description, width, glyphs.Ellipsis); long {
+			return true
+		}
+		if opt.Risk != nil {
+			if _, long := collapse(opt.Risk.Message, width-lipgloss.Width(riskGlyph(opt.Risk.Level, glyphs))-1, glyphs.Ellipsis); long {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasPipelines reports whether any visible command has more than one
+// segment to annotate.
+func (m SelectorModel) hasPipelines() bool {
+	for _, idx := range m.visible {
+		if len(commands.SplitPipeline(m.options[idx].Command)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExpressions reports whether any visible option has a DSL expression
+// that can be selected on its own.
+func (m SelectorModel) hasExpressions() bool {
+	for _, idx := range m.visible {
+		if m.options[idx].Expression != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRiskWarning returns a styled warning string for the given risk level.
+func formatRiskWarning(risk *safety.RiskInfo, selected bool, glyphs glyphSet) string {
+	if risk.Level == safety.RiskNone {
+		return ""
+	}
+
+	style := riskStyle(risk.Level)
+	if selected {
+		style = style.Bold(true)
+	}
+
+	return style.Render(fmt.Sprintf("%s %s", riskGlyph(risk.Level, glyphs), risk.Message))
+}
+
+// riskStyle returns the style warnings of a risk level are rendered in.
+func riskStyle(level safety.RiskLevel) lipgloss.Style {
+	switch level {
+	case safety.RiskLow:
+		return WarningLowStyle
+	case safety.RiskCritical:
+		return WarningHighStyle.Underline(true)
+	default:
+		return WarningHighStyle
+	}
+}
+
+// unverifiedWarning marks an option whose safety check failed or timed
+// out, so its risk is unknown.
+func unverifiedWarning(settings Settings) string {
+	return WarningLowStyle.Render("[" + settings.t("unverified") + "] " + settings.t("safety check didn't finish (r: check again)"))
+}
+
+// formatBadges renders an option's badges as compact bracketed tags, with
+// the ones that deserve attention in the warning colors.
+func formatBadges(badges []commands.Badge, settings Settings) string {
+	tags := make([]string, len(badges))
+	for i, badge := range badges {
+		style := BadgeStyle
+		switch badge {
+		case commands.BadgeSudo, commands.BadgeNetwork:
+			style = WarningLowStyle
+		case commands.BadgeIrreversible, commands.BadgeInvalidSyntax:
+			style = WarningHighStyle
+		case commands.BadgeRecommended:
+			style = SelectedStyle
+		}
+		tags[i] = style.Render("[" + settings.t(string(badge)) + "]")
+	}
+	return strings.Join(tags, " ")
+}
+
+// riskGlyph returns the symbol marking a risk level.
+func riskGlyph(level safety.RiskLevel, glyphs glyphSet) string {
+	switch level {
+	case safety.RiskLow:
+		return glyphs.RiskLow
+	case safety.RiskHigh:
+		return glyphs.RiskHigh
+	case safety.RiskCritical:
+		return glyphs.RiskCritical
+	default:
+		return ""
+	}
+}
+
+// collapse shortens text to its first line when wrapped to width, ending
+// in an ellipsis if anything was cut. It reports whether it was shortened.
+func collapse(text string, width int, ellipsis string) (string, bool) {
+	if !strings.Contains(text, "\n") && lipgloss.Width(text) <= width {
+		return text, false
+	}
+
+	// Leave room for the ellipsis on the kept line.
+	room := max(width-lipgloss.Width(ellipsis)-1, 10)
+	first, _, _ := strings.Cut(lipgloss.NewStyle().Width(room).Render(text), "\n")
+	return strings.TrimRight(first, " ") + " " + ellipsis, true
+}
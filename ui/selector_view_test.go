@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/commands"
+)
+
+func TestCollapse(t *testing.T) {
+	if got, long := collapse("Lists files", 40, "…"); got != "Lists files" || long {
+		t.Errorf("collapse(short) = %q, %v", got, long)
+	}
+
+	text := "Finds every log file under the current directory and deletes the ones older than a week"
+	got, long := collapse(text, 40, "…")
+	if !long || !strings.HasSuffix(got, " …") || lipgloss.Width(got) > 40 {
+		t.Errorf("collapse(long) = %q, %v", got, long)
+	}
+
+	// Wide characters take two cells each.
+	wide := "カレントディレクトリ以下のログファイルをすべて探し、一週間より古いものを削除します"
+	if got, long := collapse(wide, 40, "…"); !long || lipgloss.Width(got) > 40 {
+		t.Errorf("collapse(wide) = %q (%d cells), %v", got, lipgloss.Width(got), long)
+	}
+
+	if got, long := collapse("First line\nsecond line", 40, "..."); got != "First line ..." || !long {
+		t.Errorf("collapse(multi-line) = %q, %v", got, long)
+	}
+}
+
+func TestSelectorWideCharactersFitWidth(t *testing.T) {
+	m := NewSelector([]commands.Option{
+		{Title: "ログを検索", Command: "grep -rn 'エラー' /var/log/アプリ/ | sort | uniq -c | sort -rn | head -20", Description: "アプリのログからエラーを含む行を探し、出現回数の多い順に並べます 🔍"},
+		{Title: "Café menu", Command: "cat menu.txt", Description: "Shows the café's menu, with its résumé of prices and every item on it"},
+	}, nil, Settings{Static: true})
+	m.width = 50
+
+	list, _ := m.renderOptions()
+	for _, line := range strings.Split(list, "\n") {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Errorf("line is %d cells wide, more than %d:\n%s", w, m.width, line)
+		}
+	}
+}
+
+func TestSelectorExpandDescription(t *testing.T) {
+	description := strings.Repeat("word ", 40) + "END"
+	m := NewSelector([]commands.Option{{Title: "Long", Command: "ls", Description: description}}, nil, Settings{Static: true})
+	m.width = 60
+
+	if strings.Contains(m.View(), "END") {
+		t.Error("View() shows the full description before expanding")
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if !strings.Contains(next.View(), "END") {
+		t.Error("View() hides the description after tab")
+	}
+
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if strings.Contains(next.View(), "END") {
+		t.Error("View() shows the full description after collapsing again")
+	}
+}
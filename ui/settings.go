@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/pixielabs/1lm/commands"
+	"github.com/pixielabs/1lm/i18n"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Settings holds presentation choices shared by every model in the flow.
+// It is passed from InputModel to LoadingModel to SelectorModel so that
+// the whole session renders consistently.
+type Settings struct {
+	// Static replaces spinner animations with a fixed glyph, avoiding the
+	// constant redraws (low-power mode).
+	Static bool
+	// Plain swaps emoji, arrows and box-drawing characters for ASCII so
+	// output is readable in logs and on terminals without the fonts to
+	// draw them. Colors are set separately.
+	Plain bool
+	// Accessible announces state changes as discrete printed lines so
+	// screen readers pick them up, instead of relying on redraws.
+	Accessible bool
+	// AltScreen renders the selector full-screen with a scrollable
+	// viewport, for option lists taller than the terminal.
+	AltScreen bool
+	// Preview keeps the option list compact and shows the highlighted
+	// option in full in a pane beside or below it.
+	Preview bool
+	// Mouse turns on mouse reporting: the wheel moves the cursor, and in
+	// alt-screen mode clicking highlights an option and double-clicking
+	// selects it.
+	Mouse bool
+	// Quiet clears the UI when it exits instead of leaving the option list
+	// on screen, so only the emitted command remains.
+	Quiet bool
+	// Language is the code UI strings are translated into, e.g. "es".
+	// Empty means English.
+	Language string
+	// FullCommands shows multi-line commands in full from the start, as
+	// if v had been pressed, rather than folding long ones.
+	FullCommands bool
+	// Deliver, when set, does the output step for a chosen command before
+	// the UI exits, in the given output mode (e.g. "clipboard"), so a
+	// failure is shown with a chance to retry or choose another mode. It
+	// reports whether the command was delivered; modes that print after
+	// the UI exits deliver nothing.
+	Deliver func(opt commands.Option, mode string) (bool, error)
+	// Audit, when set, records a chosen command, with the query and the
+	// options it was chosen from, before it is delivered or output. If
+	// it fails the UI exits without the command; see SelectorModel.Err.
+	Audit func(query string, options []commands.Option, selected commands.Option) error
+	// OutputMode is the output mode Deliver is first asked for.
+	OutputMode string
+	// LocalMatch, when set, looks for a confident answer to a query
+	// without the model, such as the command chosen last time it was
+	// asked. It is offered as option 0 while the model's options load.
+	LocalMatch func(query string) *commands.Option
+}
+
+// glyphSet holds the symbols used to decorate the UI.
+type glyphSet struct {
+	Cursor       string
+	RiskLow      string
+	RiskHigh     string
+	RiskCritical string
+	Gutter       string
+	Ellipsis     string
+}
+
+var (
+	fancyGlyphs = glyphSet{
+		Cursor:       "▸",
+		RiskLow:      "⚠️",
+		RiskHigh:     "🚨",
+		RiskCritical: "⛔",
+		Gutter:       "│",
+		Ellipsis:     "…",
+	}
+
+	plainGlyphs = glyphSet{
+		Cursor:       ">",
+		RiskLow:      "[low risk]",
+		RiskHigh:     "[HIGH RISK]",
+		RiskCritical: "[CRITICAL RISK]",
+		Gutter:       "|",
+		Ellipsis:     "...",
+	}
+)
+
+// plainHelp rewrites the arrows and bullets used in help text.
+var plainHelp = strings.NewReplacer("↑", "up", "↓", "down", "←", "left", "→", "right", " • ", " | ")
+
+// glyphs returns the symbol set for these settings.
+func (s Settings) glyphs() glyphSet {
+	if s.Plain {
+		g := plainGlyphs
+		g.RiskLow, g.RiskHigh, g.RiskCritical = s.t(g.RiskLow), s.t(g.RiskHigh), s.t(g.RiskCritical)
+		return g
+	}
+	return fancyGlyphs
+}
+
+// t translates a UI string into the configured language.
+func (s Settings) t(msg string) string {
+	return i18n.T(s.Language, msg)
+}
+
+// tf translates a format string and formats it with args.
+func (s Settings) tf(format string, args ...any) string {
+	return i18n.Tf(s.Language, format, args...)
+}
+
+// help translates key hints and joins them into a single help line.
+func (s Settings) help(items ...string) string {
+	translated := make([]string, len(items))
+	for i, item := range items {
+		translated[i] = s.t(item)
+	}
+
+	line := strings.Join(translated, " • ")
+	if s.Plain {
+		line = plainHelp.Replace(line)
+	}
+	return HelpStyle.Render(line)
+}
+
+// newSpinner creates a spinner in the given style. Plain mode uses the
+// ASCII line spinner instead of braille dots.
+func (s Settings) newSpinner(style lipgloss.Style) spinner.Model {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	if s.Plain {
+		sp.Spinner = spinner.Line
+	}
+	sp.Style = style
+	return sp
+}
+
+// announce prints a translated line above the UI in accessible mode.
+// Screen readers read appended lines reliably, unlike in-place redraws.
+func (s Settings) announce(format string, args ...any) tea.Cmd {
+	if !s.Accessible {
+		return nil
+	}
+	return tea.Println(s.tf(format, args...))
+}
+
+// spinnerTick returns the command that starts a spinner's animation loop,
+// or nil when animations are disabled.
+func (s Settings) spinnerTick(sp spinner.Model) tea.Cmd {
+	if s.Static {
+		return nil
+	}
+	return sp.Tick
+}
+
+// spinnerView renders the spinner's current frame, or a fixed ellipsis
+// when animations are disabled.
+func (s Settings) spinnerView(sp spinner.Model) string {
+	if s.Static {
+		return sp.Style.Render(s.glyphs().Ellipsis)
+	}
+	return sp.View()
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSettingsHelp(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Settings
+		want     string
+	}{
+		{name: "english", settings: Settings{}, want: "↑/k: up • q: quit"},
+		{name: "plain", settings: Settings{Plain: true}, want: "up/k: up | q: quit"},
+		{name: "translated", settings: Settings{Language: "de"}, want: "↑/k: hoch • q: beenden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.settings.help("↑/k: up", "q: quit"); !strings.Contains(got, tt.want) {
+				t.Errorf("help() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsPlainHelpArrows(t *testing.T) {
+	got := Settings{Plain: true}.help("←/→: switch model", "→: accept")
+	if want := "left/right: switch model | right: accept"; !strings.Contains(got, want) {
+		t.Errorf("help() = %q, want %q", got, want)
+	}
+}
+
+func TestSettingsPlainGlyphsTranslated(t *testing.T) {
+	g := Settings{Plain: true, Language: "fr"}.glyphs()
+	if g.RiskHigh != "[RISQUE ÉLEVÉ]" {
+		t.Errorf("glyphs().RiskHigh = %q, want %q", g.RiskHigh, "[RISQUE ÉLEVÉ]")
+	}
+	if plainGlyphs.RiskHigh != "[HIGH RISK]" {
+		t.Error("glyphs() modified the shared plain glyph set")
+	}
+}
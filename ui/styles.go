@@ -3,44 +3,93 @@ package ui
 
 import "github.com/charmbracelet/lipgloss"
 
+// Colors adapt to the terminal background. Light variants are darker and
+// more saturated so they stay legible on white backgrounds.
+var (
+	titleColor       = lipgloss.AdaptiveColor{Light: "162", Dark: "205"}
+	commandFgColor   = lipgloss.AdaptiveColor{Light: "23", Dark: "86"}
+	commandBgColor   = lipgloss.AdaptiveColor{Light: "254", Dark: "235"}
+	diffFgColor      = lipgloss.AdaptiveColor{Light: "25", Dark: "228"}
+	subtleColor      = lipgloss.AdaptiveColor{Light: "243", Dark: "241"}
+	selectedColor    = lipgloss.AdaptiveColor{Light: "127", Dark: "170"}
+	warningLowColor  = lipgloss.AdaptiveColor{Light: "130", Dark: "220"}
+	warningHighColor = lipgloss.AdaptiveColor{Light: "160", Dark: "196"}
+	checkingColor    = lipgloss.AdaptiveColor{Light: "250", Dark: "238"}
+)
+
 var (
 	// TitleStyle is used for option titles
 	TitleStyle = lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("205"))
+			Foreground(titleColor)
 
 	// CommandStyle is used for displaying commands
 	CommandStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86")).
-			Background(lipgloss.Color("235")).
+			Foreground(commandFgColor).
+			Background(commandBgColor).
 			Padding(0, 1)
 
+	// DiffStyle highlights the parts of a command that differ from the
+	// other options; it keeps the command background so blocks stay solid
+	DiffStyle = lipgloss.NewStyle().
+			Foreground(diffFgColor).
+			Background(commandBgColor).
+			Bold(true).
+			Underline(true)
+
+	// DangerStyle marks the high-risk part of a command, such as the
+	// "-rf /" in a longer pipeline; like DiffStyle it keeps the command
+	// background
+	DangerStyle = lipgloss.NewStyle().
+			Foreground(warningHighColor).
+			Background(commandBgColor).
+			Bold(true)
+
+	// CautionStyle marks the low-risk part of a command
+	CautionStyle = lipgloss.NewStyle().
+			Foreground(warningLowColor).
+			Background(commandBgColor).
+			Bold(true)
+
 	// DescriptionStyle is used for option descriptions
 	DescriptionStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("241"))
+				Foreground(subtleColor)
 
 	// SelectedStyle is used for the currently selected option
 	SelectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("170")).
+			Foreground(selectedColor).
 			Bold(true)
 
 	// HelpStyle is used for help text
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
+			Foreground(subtleColor).
 			Italic(true)
 
 	// WarningLowStyle for low-risk operations (network, downloads, scans)
 	WarningLowStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("220")).
+			Foreground(warningLowColor).
 			Italic(true)
 
 	// WarningHighStyle for high-risk operations (destructive, data loss)
 	WarningHighStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("196")).
+				Foreground(warningHighColor).
 				Bold(true)
 
+	// BadgeStyle is used for the tags summarizing what an option does
+	BadgeStyle = lipgloss.NewStyle().
+			Foreground(subtleColor)
+
+	// LineNumberStyle is used for the gutter of multi-line commands
+	LineNumberStyle = lipgloss.NewStyle().
+			Foreground(subtleColor)
+
+	// SuggestionStyle is used for the autosuggestion after the cursor
+	SuggestionStyle = lipgloss.NewStyle().
+			Foreground(subtleColor).
+			Faint(true)
+
 	// CheckingStyle for the per-option safety check placeholder
 	CheckingStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("238")).
+			Foreground(checkingColor).
 			Italic(true)
 )
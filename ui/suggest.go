@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/snippets"
+	"github.com/rivo/uniseg"
+)
+
+// snippetTitlesMsg carries the snippet library's titles, which are offered
+// as suggestions after past queries.
+type snippetTitlesMsg []string
+
+// loadSnippetTitles reads the snippet library in the background. A library
+// that can't be read just means no snippet suggestions.
+func loadSnippetTitles() tea.Msg {
+	lib, err := snippets.Load()
+	if err != nil {
+		return snippetTitlesMsg(nil)
+	}
+	titles := make([]string, len(lib.Snippets))
+	for i, s := range lib.Snippets {
+		titles[i] = s.Title
+	}
+	return snippetTitlesMsg(titles)
+}
+
+// suggestion returns the rest of the first past query or snippet title that
+// starts with what has been typed, ignoring case, like fish's
+// autosuggestions. It is empty unless the query is a single line with the
+// cursor at its end. Queries are compared a grapheme cluster at a time, so
+// a suggestion never starts with half an emoji or a stray accent.
+func (m InputModel) suggestion() string {
+	value := m.input.Value()
+	if value == "" || strings.Contains(value, "\n") || !m.cursorAtEnd() {
+		return ""
+	}
+
+	typed := uniseg.GraphemeClusterCount(value)
+	for _, candidates := range [][]string{m.past, m.snippetTitles} {
+		for _, candidate := range candidates {
+			clusters := graphemes(candidate)
+			if len(clusters) <= typed || strings.Contains(candidate, "\n") {
+				continue
+			}
+			if strings.EqualFold(strings.Join(clusters[:typed], ""), value) {
+				return strings.Join(clusters[typed:], "")
+			}
+		}
+	}
+	return ""
+}
+
+// cursorAtEnd reports whether the cursor is after the last character.
+func (m InputModel) cursorAtEnd() bool {
+	end := m.input
+	end.CursorEnd()
+	return m.input.Line() == m.input.LineCount()-1 && end.LineInfo() == m.input.LineInfo()
+}
+
+// acceptSuggestion completes the query with the current suggestion.
+func (m *InputModel) acceptSuggestion() bool {
+	rest := m.suggestion()
+	if rest == "" {
+		return false
+	}
+	m.setQuery(m.input.Value() + rest)
+	return true
+}
+
+// suggestionView renders the prompt with the suggestion after the cursor
+// in a faint style. The textarea can't draw it, so the single line is
+// drawn here; the suggestion is cut to the space left on the line.
+func (m InputModel) suggestionView(rest string) string {
+	value := m.input.Value()
+	space := m.input.Width() - lipgloss.Width(value)
+	if space <= 0 {
+		return m.input.View()
+	}
+
+	ghost := graphemes(rest)
+	for lipgloss.Width(strings.Join(ghost, "")) > space {
+		ghost = ghost[:len(ghost)-1]
+	}
+	if len(ghost) == 0 {
+		return m.input.View()
+	}
+
+	cursor := m.input.Cursor
+	cursor.SetChar(ghost[0])
+	cursor.TextStyle = SuggestionStyle
+
+	style := m.input.FocusedStyle
+	return style.Prompt.Render(m.input.Prompt) +
+		style.Text.Render(value) +
+		cursor.View() +
+		SuggestionStyle.Render(strings.Join(ghost[1:], ""))
+}
+
+// graphemes splits s into the characters a terminal draws as one: a
+// letter with its combining accents, or an emoji sequence joined with
+// zero-width joiners.
+func graphemes(s string) []string {
+	var clusters []string
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		clusters = append(clusters, g.Str())
+	}
+	return clusters
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pixielabs/1lm/history"
+)
+
+func TestInputSuggestion(t *testing.T) {
+	past, err := history.LoadFrom(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past.Entries = []history.Entry{
+		{Query: "cafe\u0301 menu prices"},
+		{Query: "party 👨‍👩‍👧 photos"},
+		{Query: "ファイルを検索する"},
+		{Query: "find large files"},
+		{Query: "show disk usage\nby directory"},
+		{Query: "find files changed today"},
+	}
+
+	tests := []struct {
+		name  string
+		typed string
+		want  string
+	}{
+		{"most recent query first", "find", " files changed today"},
+		{"ignores case", "FIND L", "arge files"},
+		{"falls back to snippets", "tail", " nginx errors"},
+		{"skips multi-line queries", "show", ""},
+		{"nothing typed", "", ""},
+		{"exact match", "find large files", ""},
+		{"no match", "compress", ""},
+		{"wide characters", "ファイル", "を検索する"},
+		{"combining accent kept whole", "CAFE\u0301", " menu prices"},
+		{"never splits an accent", "cafe", ""},
+		{"never splits an emoji", "party 👨", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m tea.Model = NewInputModel(nil, past, Settings{Static: true})
+			m, _ = m.Update(snippetTitlesMsg{"Tail nginx errors"})
+			if tt.typed != "" {
+				m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.typed)})
+			}
+			if got := m.(InputModel).suggestion(); got != tt.want {
+				t.Errorf("suggestion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputAcceptsSuggestion(t *testing.T) {
+	var m tea.Model = NewInputModel(nil, nil, Settings{Static: true})
+	m, _ = m.Update(snippetTitlesMsg{"Tail nginx errors"})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("tail")})
+	if view := m.View(); !strings.Contains(view, "nginx errors") {
+		t.Errorf("view doesn't show the suggestion:\n%s", view)
+	}
+
+	// Only offered with the cursor at the end.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got := m.(InputModel).suggestion(); got != "" {
+		t.Errorf("suggestion with cursor inside = %q, want none", got)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.(InputModel).input.Value(); got != "tail" {
+		t.Fatalf("→ back to the end changed the value to %q", got)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.(InputModel).input.Value(); got != "tail nginx errors" {
+		t.Errorf("value after accepting = %q, want %q", got, "tail nginx errors")
+	}
+}
+
+func TestInputSuggestionFitsWideCharacters(t *testing.T) {
+	var m tea.Model = NewInputModel(nil, nil, Settings{Static: true})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 30, Height: 24})
+	m, _ = m.Update(snippetTitlesMsg{"tail ログファイルのエラーを最新のものから表示する"})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("tail")})
+
+	input := m.(InputModel).input
+	view := m.(InputModel).suggestionView(m.(InputModel).suggestion())
+	if !strings.Contains(view, "ログ") {
+		t.Fatalf("view doesn't show the suggestion:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > lipgloss.Width(input.Prompt)+input.Width() {
+			t.Errorf("line is %d cells wide, more than the prompt's %d:\n%s", w, input.Width(), line)
+		}
+	}
+}